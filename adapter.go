@@ -1,6 +1,7 @@
 package fiber
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -248,6 +249,104 @@ func adaptFastHTTPHandler(handler any) (Handler, bool) {
 	}
 }
 
+// Typed adapts a function taking a typed request and returning a typed
+// response to a Fiber handler.
+//
+// Before fn is called, Req is bound with Bind().All, so a single struct can
+// collect URI params, the body, query values, headers and cookies, and is then
+// checked by the app's StructValidator. A binding or validation failure ends
+// the request with 400 Bad Request without calling fn: the error passed to the
+// error handler wraps the *BindError or the error of the validator, so
+// ProblemErrorHandler lists the failed inputs. Req must be a struct or a
+// pointer to one; Typed panics otherwise.
+//
+// An error returned by fn is passed to the error handler as it is. Otherwise
+// the response is rendered with AutoFormat, in the format the client accepts.
+// fn may set the status code, or any header, on c before it returns.
+//
+//	app.Post("/users/:org", fiber.Typed(func(c fiber.Ctx, in CreateUser) (User, error) {
+//		c.Status(fiber.StatusCreated)
+//		return users.Create(c, in)
+//	}))
+func Typed[Req, Resp any](fn func(c Ctx, in Req) (Resp, error)) Handler {
+	reqType := reflect.TypeFor[Req]()
+	isPointer := reqType.Kind() == reflect.Pointer
+	structType := reqType
+	if isPointer {
+		structType = reqType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Typed: request type %s must be a struct or a pointer to one", reqType))
+	}
+
+	return func(c Ctx) error {
+		var in Req
+		target := any(&in)
+		if isPointer {
+			target = reflect.New(structType).Interface()
+			in = target.(Req) //nolint:errcheck,forcetypeassert // target was allocated as the element type of Req
+		}
+
+		if err := bindTyped(c, target); err != nil {
+			return err
+		}
+
+		out, err := fn(c, in)
+		if err != nil {
+			return err
+		}
+
+		return c.AutoFormat(out)
+	}
+}
+
+// bindTyped binds and validates the request of a Typed handler. The Bind
+// instance is shared with whatever runs after the handler on this request, so
+// its settings are restored.
+func bindTyped(c Ctx, target any) error {
+	bind := c.Bind()
+	skipErrHandling, skipValidation := bind.shouldSkipErrHandling, bind.shouldSkipValidation
+	defer func() {
+		bind.shouldSkipErrHandling, bind.shouldSkipValidation = skipErrHandling, skipValidation
+	}()
+
+	// Manual handling keeps the *BindError and the validation error, which
+	// auto handling would flatten into a *Error
+	bind.shouldSkipErrHandling = true
+	bind.shouldSkipValidation = true
+	if err := bind.All(target); err != nil {
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) {
+			// A *Error keeps its status, and a malformed binding tag is a
+			// programmer error
+			return err
+		}
+		c.Status(StatusBadRequest)
+		return &badRequestError{err: err}
+	}
+
+	bind.shouldSkipValidation = skipValidation
+	if err := bind.validateStruct(target); err != nil {
+		c.Status(StatusBadRequest)
+		return &badRequestError{err: err}
+	}
+	return nil
+}
+
+// badRequestError is a binding or validation failure of a Typed handler,
+// answered with 400 Bad Request by DefaultErrorHandler and ProblemErrorHandler.
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string {
+	return "Bad request: " + e.err.Error()
+}
+
+func (e *badRequestError) Unwrap() error {
+	return e.err
+}
+
 // wrapHTTPHandler adapts a net/http handler to a Fiber handler.
 func wrapHTTPHandler(handler http.Handler) Handler {
 	if handler == nil {
@@ -1,6 +1,7 @@
 package fiber
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -1034,3 +1036,238 @@ func TestCollectHandlers_Nil(t *testing.T) {
 		collectHandlers("nil", nil)
 	})
 }
+
+type typedRequest struct {
+	Org  string `uri:"org"`
+	Name string `json:"name"`
+	Page int    `query:"page"`
+}
+
+type typedResponse struct {
+	Org  string `json:"org" xml:"org"`
+	Name string `json:"name" xml:"name"`
+	Page int    `json:"page" xml:"page"`
+}
+
+type typedValidator struct{}
+
+func (*typedValidator) Validate(out any) error {
+	if req, ok := out.(*typedRequest); ok && req.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestTyped(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Post("/orgs/:org/users", Typed(func(c Ctx, in typedRequest) (typedResponse, error) {
+		c.Status(StatusCreated)
+		return typedResponse(in), nil
+	}))
+
+	req := httptest.NewRequest(MethodPost, "/orgs/gofiber/users?page=2", strings.NewReader(`{"name":"john"}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusCreated, resp.StatusCode)
+	require.Equal(t, MIMEApplicationJSONCharsetUTF8, resp.Header.Get(HeaderContentType))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"org":"gofiber","name":"john","page":2}`, string(body))
+}
+
+func TestTyped_AutoFormat(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/orgs/:org", Typed(func(_ Ctx, in typedRequest) (typedResponse, error) {
+		return typedResponse{Org: in.Org}, nil
+	}))
+
+	req := httptest.NewRequest(MethodGet, "/orgs/gofiber", http.NoBody)
+	req.Header.Set(HeaderAccept, MIMEApplicationXML)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Equal(t, MIMEApplicationXMLCharsetUTF8, resp.Header.Get(HeaderContentType))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "<org>gofiber</org>")
+}
+
+func TestTyped_PointerRequest(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/orgs/:org", Typed(func(_ Ctx, in *typedRequest) (string, error) {
+		return in.Org, nil
+	}))
+
+	req := httptest.NewRequest(MethodGet, "/orgs/gofiber", http.NoBody)
+	req.Header.Set(HeaderAccept, MIMETextPlain)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "gofiber", string(body))
+}
+
+func TestTyped_BindError(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	called := false
+	app.Get("/", Typed(func(_ Ctx, _ typedRequest) (string, error) {
+		called = true
+		return "ok", nil
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/?page=first", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+	require.False(t, called)
+}
+
+func TestTyped_ValidationError(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{StructValidator: &typedValidator{}})
+	app.Post("/orgs/:org/users", Typed(func(_ Ctx, in typedRequest) (typedResponse, error) {
+		return typedResponse(in), nil
+	}))
+
+	req := httptest.NewRequest(MethodPost, "/orgs/gofiber/users", strings.NewReader(`{}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "name is required")
+}
+
+type typedFieldValidator struct{}
+
+func (*typedFieldValidator) Validate(out any) error {
+	if req, ok := out.(*typedRequest); ok && req.Name == "" {
+		return problemValidationErrs{{field: "Name", tag: "required"}}
+	}
+	return nil
+}
+
+func TestTyped_ProblemErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{
+		ErrorHandler:    ProblemErrorHandler,
+		StructValidator: &typedFieldValidator{},
+	})
+	called := false
+	app.Post("/orgs/:org/users", Typed(func(_ Ctx, in typedRequest) (typedResponse, error) {
+		called = true
+		return typedResponse(in), nil
+	}))
+
+	problem := func(target, body string) []ProblemFieldError {
+		t.Helper()
+
+		req := httptest.NewRequest(MethodPost, target, strings.NewReader(body))
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, StatusBadRequest, resp.StatusCode)
+		require.Equal(t, MIMEApplicationProblemJSON, resp.Header.Get(HeaderContentType))
+
+		var p struct {
+			Errors []ProblemFieldError `json:"errors"`
+			Status int                 `json:"status"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+		require.Equal(t, StatusBadRequest, p.Status)
+		return p.Errors
+	}
+
+	errs := problem("/orgs/gofiber/users?page=first", `{"name":"john"}`)
+	require.Len(t, errs, 1)
+	require.Equal(t, BindSourceQuery, errs[0].Source)
+	require.Equal(t, "page", errs[0].Field)
+
+	errs = problem("/orgs/gofiber/users", `{}`)
+	require.Equal(t, []ProblemFieldError{
+		{Field: "Name", Detail: "Key: 'Name' Error:Field validation for 'Name' failed on the 'required' tag"},
+	}, errs)
+	require.False(t, called)
+}
+
+func TestTyped_BindErrorUnwraps(t *testing.T) {
+	t.Parallel()
+
+	var handled error
+	app := New(Config{ErrorHandler: func(c Ctx, err error) error {
+		handled = err
+		return c.SendString(err.Error())
+	}})
+	app.Get("/", Typed(func(_ Ctx, _ typedRequest) (string, error) {
+		return "ok", nil
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/?page=first", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+
+	var be *BindError
+	require.ErrorAs(t, handled, &be)
+	require.Equal(t, BindSourceQuery, be.Source)
+}
+
+func TestTyped_HandlerError(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/", Typed(func(_ Ctx, _ struct{}) (string, error) {
+		return "", ErrTeapot
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusTeapot, resp.StatusCode)
+}
+
+func TestTyped_RestoresBindErrorHandling(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	var bindErr error
+	app.Get("/", Typed(func(c Ctx, _ struct{}) (string, error) {
+		var q typedRequest
+		bindErr = c.Bind().Query(&q)
+		return "ok", nil
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/?page=first", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+
+	var be *BindError
+	require.ErrorAs(t, bindErr, &be)
+	require.Equal(t, BindSourceQuery, be.Source)
+}
+
+func TestTyped_InvalidRequestType(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "Typed: request type map[string]string must be a struct or a pointer to one", func() {
+		Typed(func(_ Ctx, _ map[string]string) (string, error) { return "", nil })
+	})
+	require.Panics(t, func() {
+		Typed(func(_ Ctx, _ *int) (string, error) { return "", nil })
+	})
+}
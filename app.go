@@ -383,7 +383,10 @@ type Config struct { //nolint:govet // Aligning the struct fields is not necessa
 
 	// StreamRequestBody enables request body streaming,
 	// and calls the handler sooner when given body is
-	// larger than the current limit.
+	// larger than the current limit. The rest of the body
+	// is then read from the connection by Ctx.BodyStream
+	// and Ctx.MultipartReader, within the limit set by
+	// Ctx.SetBodyLimit.
 	//
 	// Default: false
 	StreamRequestBody bool
@@ -659,6 +662,13 @@ func DefaultErrorHandler(c Ctx, err error) error {
 	if matched && e != nil {
 		code = e.Code
 	}
+	var problem *ProblemDetails
+	var badRequest *badRequestError
+	if !matched && errors.As(err, &problem) && problem != nil {
+		code = problem.status()
+	} else if !matched && errors.As(err, &badRequest) {
+		code = StatusBadRequest
+	}
 	message := utils.StatusMessage(code)
 	if err != nil && (!matched || e != nil) {
 		message = err.Error()
@@ -972,6 +982,38 @@ func (app *App) Name(name string) Router {
 	return app
 }
 
+// Describe attaches documentation to the latest created route, for generators
+// such as the openapi middleware to read back from GetRoutes. It covers the
+// same routes Name would: every method the route was registered for, and the
+// HEAD route generated for a GET one.
+//
+//	app.Post("/users", createUser).Describe(fiber.RouteDoc{
+//	    Summary:     "Create a user",
+//	    RequestBody: CreateUser{},
+//	    Responses:   map[int]fiber.RouteResponse{fiber.StatusCreated: {Body: User{}}},
+//	})
+func (app *App) Describe(doc RouteDoc) Router {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if app.latestRoute == nil {
+		return app
+	}
+
+	for _, routes := range app.stack {
+		for _, route := range routes {
+			isMethodValid := route.Method == app.latestRoute.Method || app.latestRoute.use ||
+				(app.latestRoute.Method == MethodGet && route.Method == MethodHead)
+
+			if route.Path == app.latestRoute.Path && isMethodValid {
+				route.Doc = &doc
+			}
+		}
+	}
+
+	return app
+}
+
 // GetRoute Get route by name
 func (app *App) GetRoute(name string) Route {
 	for _, routes := range app.stack {
@@ -3294,6 +3294,105 @@ func Test_App_GetRoutes(t *testing.T) {
 	}
 }
 
+func Test_App_Describe(t *testing.T) {
+	t.Parallel()
+	app := New()
+	handler := func(c Ctx) error {
+		return c.SendStatus(StatusOK)
+	}
+
+	app.Get("/plain", handler)
+	app.Get("/users", handler).Describe(RouteDoc{Summary: "List users", Tags: []string{"users"}})
+	app.Group("/v1").Post("/items", handler).Describe(RouteDoc{Summary: "Create item"})
+	app.Domain("api.example.com").Delete("/items/:id", handler).Describe(RouteDoc{Summary: "Delete item"})
+	app.RouteChain("/chain").Get(handler).Put(handler).Describe(RouteDoc{Summary: "Replace chain"})
+
+	// The HEAD route generated for a GET one carries its documentation
+	app.startupProcess()
+
+	summaries := make(map[string]string)
+	for _, route := range app.GetRoutes(true) {
+		if route.Doc != nil {
+			summaries[route.Method+" "+route.Path] = route.Doc.Summary
+		}
+	}
+	require.Equal(t, map[string]string{
+		"GET /users":        "List users",
+		"HEAD /users":       "List users",
+		"POST /v1/items":    "Create item",
+		"DELETE /items/:id": "Delete item",
+		"PUT /chain":        "Replace chain",
+	}, summaries)
+}
+
+func Test_App_Describe_NoRoutes(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	require.NotPanics(t, func() {
+		app.Describe(RouteDoc{Summary: "nothing to describe"})
+	})
+}
+
+func Test_Route_PathParams(t *testing.T) {
+	t.Parallel()
+	app := New()
+	handler := func(c Ctx) error {
+		return c.SendStatus(StatusOK)
+	}
+
+	app.Get("/Users/:userID<int>/files/:name?/*", handler)
+
+	route := app.GetRoutes(true)[0]
+	params := route.PathParams()
+	require.Len(t, params, 3)
+
+	require.Equal(t, "userID", params[0].Name)
+	require.Len(t, params[0].Constraints, 1)
+	require.Equal(t, ConstraintInt, params[0].Constraints[0].Name)
+	require.False(t, params[0].Optional)
+
+	require.Equal(t, "name", params[1].Name)
+	require.True(t, params[1].Optional)
+	require.False(t, params[1].Greedy)
+
+	require.Equal(t, "*1", params[2].Name)
+	require.True(t, params[2].Greedy)
+
+	formatted := route.FormatPath(func(param RouteParam) string {
+		return "{" + param.Name + "}"
+	})
+	require.Equal(t, "/Users/{userID}/files/{name}/{*1}", formatted)
+}
+
+func Test_Route_FormatPath_Mounted(t *testing.T) {
+	t.Parallel()
+	app := New()
+	sub := New()
+	sub.Get("/:id<guid>", func(c Ctx) error {
+		return c.SendStatus(StatusOK)
+	})
+	app.Use("/api/:version", sub)
+	app.startupProcess()
+
+	var route Route
+	for _, r := range app.GetRoutes(true) {
+		if r.Method == MethodGet {
+			route = r
+		}
+	}
+
+	params := route.PathParams()
+	require.Len(t, params, 2)
+	require.Equal(t, "version", params[0].Name)
+	require.Empty(t, params[0].Constraints)
+	require.Equal(t, "id", params[1].Name)
+	require.Equal(t, ConstraintGUID, params[1].Constraints[0].Name)
+	require.Equal(t, "/api/<version>/<id>", route.FormatPath(func(param RouteParam) string {
+		return "<" + param.Name + ">"
+	}))
+}
+
 func Test_Middleware_Route_Naming_With_Use(t *testing.T) {
 	t.Parallel()
 	named := "named"
@@ -189,6 +189,20 @@ func (b *Bind) returnBindErr(err error, source string) error {
 	return nil
 }
 
+// body returns the request body to bind, or ErrRequestEntityTooLarge when a
+// streamed body exceeds the body limit. Other errors are left to Body, which
+// returns them as the body, as before.
+func (b *Bind) body() ([]byte, error) {
+	body, err := b.ctx.ReadBody()
+	if err == nil {
+		return body, nil
+	}
+	if errors.Is(err, ErrRequestEntityTooLarge) {
+		return nil, err
+	}
+	return b.ctx.Body(), nil
+}
+
 // Struct validation.
 func (b *Bind) validateStruct(out any) error {
 	if b.shouldSkipValidation {
@@ -304,7 +318,11 @@ func (b *Bind) JSON(out any) error {
 
 	defer releasePooledBinder(&binder.JSONBinderPool, bind)
 
-	if err := b.returnBindErr(bind.Bind(b.ctx.Body(), out), BindSourceBody); err != nil {
+	body, err := b.body()
+	if err != nil {
+		return err
+	}
+	if err := b.returnBindErr(bind.Bind(body, out), BindSourceBody); err != nil {
 		return err
 	}
 
@@ -319,7 +337,11 @@ func (b *Bind) CBOR(out any) error {
 
 	defer releasePooledBinder(&binder.CBORBinderPool, bind)
 
-	if err := b.returnBindErr(bind.Bind(b.ctx.Body(), out), BindSourceBody); err != nil {
+	body, err := b.body()
+	if err != nil {
+		return err
+	}
+	if err := b.returnBindErr(bind.Bind(body, out), BindSourceBody); err != nil {
 		return err
 	}
 	return b.validateStruct(out)
@@ -333,7 +355,11 @@ func (b *Bind) XML(out any) error {
 
 	defer releasePooledBinder(&binder.XMLBinderPool, bind)
 
-	if err := b.returnBindErr(bind.Bind(b.ctx.Body(), out), BindSourceBody); err != nil {
+	body, err := b.body()
+	if err != nil {
+		return err
+	}
+	if err := b.returnBindErr(bind.Bind(body, out), BindSourceBody); err != nil {
 		return err
 	}
 
@@ -383,7 +409,11 @@ func (b *Bind) MsgPack(out any) error {
 
 	defer releasePooledBinder(&binder.MsgPackBinderPool, bind)
 
-	if err := b.returnBindErr(bind.Bind(b.ctx.Body(), out), BindSourceBody); err != nil {
+	body, err := b.body()
+	if err != nil {
+		return err
+	}
+	if err := b.returnBindErr(bind.Bind(body, out), BindSourceBody); err != nil {
 		return err
 	}
 
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AppTransport is a Transport serving the requests with the handler of a
+// Fiber app in the same process, without a listener or a connection. It is
+// meant for tests: the app runs as it would behind a server, except that
+// every response body is read before Do returns.
+type AppTransport struct {
+	handler fasthttp.RequestHandler
+}
+
+// NewAppTransport creates an AppTransport serving the requests with app.
+func NewAppTransport(app *fiber.App) *AppTransport {
+	if app == nil {
+		panic("fiber.App must not be nil")
+	}
+	return &AppTransport{handler: app.Handler()}
+}
+
+// Do serves the request with the app.
+func (t *AppTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := bufferBody(req); err != nil {
+		return err
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	t.handler(ctx)
+
+	// Body reads the stream of a streamed response, which CopyTo skips
+	body := ctx.Response.Body()
+	ctx.Response.CopyTo(resp)
+	resp.SetBody(body)
+	return nil
+}
+
+// bufferBody reads the body stream of a request into its body, as the
+// transports which are not fasthttp clients cannot send streams.
+func bufferBody(req *fasthttp.Request) error {
+	if !req.IsBodyStream() {
+		return nil
+	}
+	body, err := io.ReadAll(req.BodyStream())
+	if err != nil {
+		return fmt.Errorf("client: failed to read request body: %w", err)
+	}
+	req.SetBody(body)
+	return nil
+}
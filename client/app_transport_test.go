@@ -0,0 +1,84 @@
+package client
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func Test_AppTransport(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/hello/:name", func(c fiber.Ctx) error {
+		c.Cookie(&fiber.Cookie{Name: "seen", Value: "1"})
+		return c.SendString("hello " + c.Params("name") + " from " + c.IP())
+	})
+	app.Post("/echo", func(c fiber.Ctx) error {
+		c.Set("X-Method", c.Method())
+		return c.Send(c.Body())
+	})
+	app.Get("/redirect", func(c fiber.Ctx) error {
+		return c.Redirect().To("/hello/redirected")
+	})
+	app.Get("/stream", func(c fiber.Ctx) error {
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			for i := range 3 {
+				_, _ = w.WriteString(strings.Repeat("x", i+1)) //nolint:errcheck // test
+				_ = w.Flush()                                  //nolint:errcheck // test
+			}
+		})
+	})
+
+	cc := NewWithTransport(NewAppTransport(app))
+
+	resp, err := cc.Get("http://example.com/hello/fiber")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, "hello fiber from 0.0.0.0", resp.String())
+	require.Len(t, resp.Cookies(), 1)
+	resp.Close()
+
+	resp, err = cc.Post("http://example.com/echo", Config{Body: map[string]string{"k": "v"}})
+	require.NoError(t, err)
+	require.Equal(t, `{"k":"v"}`, resp.String())
+	require.Equal(t, fiber.MethodPost, resp.Header("X-Method"))
+	resp.Close()
+
+	req := cc.R()
+	req.RawRequest.SetBodyStream(strings.NewReader("streamed"), -1)
+	resp, err = req.Post("http://example.com/echo")
+	require.NoError(t, err)
+	require.Equal(t, "streamed", resp.String())
+	resp.Close()
+
+	resp, err = cc.Get("http://example.com/redirect", Config{MaxRedirects: 1})
+	require.NoError(t, err)
+	require.Equal(t, "hello redirected from 0.0.0.0", resp.String())
+	resp.Close()
+
+	resp, err = cc.Get("http://example.com/stream")
+	require.NoError(t, err)
+	require.Equal(t, "xxxxxx", resp.String())
+	resp.Close()
+
+	resp, err = cc.Get("http://example.com/missing")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode())
+	resp.Close()
+}
+
+func Test_AppTransport_Nil(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "fiber.App must not be nil", func() {
+		NewAppTransport(nil)
+	})
+	require.PanicsWithValue(t, "client.Transport must not be nil", func() {
+		NewWithTransport(nil)
+	})
+}
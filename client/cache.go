@@ -0,0 +1,483 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/cachecontrol"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+	"github.com/gofiber/fiber/v3/log"
+)
+
+// cacheKeyPrefix prefixes the keys of the cache in its Storage.
+const cacheKeyPrefix = "client_cache_"
+
+// CacheConfig defines the config of a response cache.
+type CacheConfig struct {
+	// Storage stores the cached responses. Clients sharing it share their
+	// cached responses, except those to requests with other Authorization,
+	// Proxy-Authorization or Cookie headers.
+	//
+	// Optional. Default: an in-memory storage
+	Storage fiber.Storage
+
+	// MaxBodySize is the maximum size of a cached response body, in bytes.
+	// Larger responses are not stored.
+	//
+	// Optional. Default: 1 MB
+	MaxBodySize int
+
+	// StaleExpiration is how long a stale response having an ETag or a
+	// Last-Modified header is kept after it expired, to be revalidated with a
+	// conditional request.
+	//
+	// Optional. Default: 24 * time.Hour
+	StaleExpiration time.Duration
+}
+
+// Cache is a private HTTP cache of the responses of a Client, following
+// RFC 9111. Fresh responses are served without sending the request, and stale
+// ones are revalidated with If-None-Match or If-Modified-Since, their body
+// being served from the cache when the server answers 304 Not Modified.
+//
+// Only the responses to GET requests are stored, when their Cache-Control
+// max-age or Expires header makes them fresh, or when they have a validator.
+// Set-Cookie headers are not stored, and responses are only served to
+// requests with the same Authorization, Proxy-Authorization and Cookie
+// headers as the request they answered. A successful request of another method,
+// such as POST, invalidates the response stored for its URL.
+type Cache struct {
+	storage         fiber.Storage
+	maxBodySize     int
+	staleExpiration time.Duration
+}
+
+// NewCache creates a response cache, to be set with Client.SetCache.
+func NewCache(config ...CacheConfig) *Cache {
+	var cfg CacheConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if cfg.Storage == nil {
+		cfg.Storage = memory.New()
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1024 * 1024
+	}
+	if cfg.StaleExpiration <= 0 {
+		cfg.StaleExpiration = 24 * time.Hour
+	}
+
+	return &Cache{
+		storage:         cfg.Storage,
+		maxBodySize:     cfg.MaxBodySize,
+		staleExpiration: cfg.StaleExpiration,
+	}
+}
+
+// cacheIndex lists the request headers a response varies on. It is stored
+// under the URL, and the responses under the URL and the values of these
+// headers.
+type cacheIndex struct {
+	Vary []string `json:"vary,omitempty"`
+}
+
+// cacheEntry is a stored response.
+type cacheEntry struct {
+	Header [][2]string `json:"header"`
+	Body   []byte      `json:"body"`
+	// Birth is when the response was generated, in Unix nanoseconds: the
+	// time it was received minus its age then.
+	Birth int64 `json:"birth"`
+	// Lifetime is the freshness lifetime of the response.
+	Lifetime time.Duration `json:"lifetime"`
+	Status   int           `json:"status"`
+	// NoCache requires a revalidation on every use.
+	NoCache bool `json:"no_cache,omitempty"`
+}
+
+// send sends the request of c through the cache.
+func (cache *Cache) send(c *core) (*Response, error) {
+	req := c.req.RawRequest
+	method := utils.UnsafeString(req.Header.Method())
+	base := cacheKeyPrefix + string(req.URI().FullURI())
+
+	if method != fiber.MethodGet {
+		resp, err := c.execFunc()
+		// RFC 9111, 4.4: unsafe methods invalidate the stored responses
+		if err == nil && !isSafeMethod(method) && resp.StatusCode() < fiber.StatusBadRequest {
+			if err := cache.storage.DeleteWithContext(c.ctx, base); err != nil {
+				cache.warn(c, fmt.Errorf("client: failed to invalidate cached response: %w", err))
+			}
+		}
+		return resp, err
+	}
+
+	// Requests which are conditional or ask for a part of the response are
+	// left to the server
+	if req.Header.Peek(fiber.HeaderIfNoneMatch) != nil || req.Header.Peek(fiber.HeaderIfModifiedSince) != nil ||
+		req.Header.Peek(fiber.HeaderRange) != nil || req.IsBodyStream() {
+		return c.execFunc()
+	}
+
+	var reqNoStore, reqNoCache, maxAgeSet bool
+	var maxAge uint64
+	cachecontrol.ParseDirectives(req.Header.Peek(fiber.HeaderCacheControl), func(key, value []byte) {
+		switch {
+		case utils.EqualFold(utils.UnsafeString(key), "no-store"):
+			reqNoStore = true
+		case utils.EqualFold(utils.UnsafeString(key), "no-cache"):
+			reqNoCache = true
+		case utils.EqualFold(utils.UnsafeString(key), "max-age"):
+			maxAge, maxAgeSet = cachecontrol.ParseUint(value)
+		default:
+			// ignore the other directives
+		}
+	})
+	if reqNoStore {
+		return c.execFunc()
+	}
+	if pragma := req.Header.Peek(fiber.HeaderPragma); len(pragma) > 0 && req.Header.Peek(fiber.HeaderCacheControl) == nil {
+		reqNoCache = strings.Contains(strings.ToLower(string(pragma)), "no-cache")
+	}
+
+	key, entry, err := cache.lookup(c.ctx, base, req)
+	if err != nil {
+		cache.warn(c, err)
+	}
+
+	if entry != nil {
+		age := time.Since(time.Unix(0, entry.Birth))
+		fresh := age < entry.Lifetime && !entry.NoCache && !reqNoCache
+		if maxAgeSet && age > time.Duration(maxAge)*time.Second {
+			fresh = false
+		}
+		if fresh {
+			return entry.response(c, age), nil
+		}
+
+		if etag, lastModified := entry.header(fiber.HeaderETag), entry.header(fiber.HeaderLastModified); etag != "" || lastModified != "" {
+			return cache.revalidate(c, base, key, entry, etag, lastModified)
+		}
+	}
+
+	resp, err := c.execFunc()
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.store(c, base, resp); err != nil {
+		cache.warn(c, err)
+	}
+	return resp, nil
+}
+
+// revalidate sends a conditional request for a stale response, and serves
+// it from the cache when the server answers 304 Not Modified.
+func (cache *Cache) revalidate(c *core, base, key string, entry *cacheEntry, etag, lastModified string) (*Response, error) {
+	header := &c.req.RawRequest.Header
+	if etag != "" {
+		header.Set(fiber.HeaderIfNoneMatch, etag)
+	}
+	if lastModified != "" {
+		header.Set(fiber.HeaderIfModifiedSince, lastModified)
+	}
+	resp, err := c.execFunc()
+	header.Del(fiber.HeaderIfNoneMatch)
+	header.Del(fiber.HeaderIfModifiedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != fiber.StatusNotModified {
+		if err := cache.store(c, base, resp); err != nil {
+			cache.warn(c, err)
+		}
+		return resp, nil
+	}
+
+	// RFC 9111, 4.3.4: the stored headers are updated with the ones of the
+	// 304 response
+	entry.update(storedHeaders(&resp.RawResponse.Header))
+	now := time.Now()
+	entry.Birth, entry.Lifetime, entry.NoCache = freshness(&resp.RawResponse.Header, now)
+	// The request is still the caller's
+	ReleaseResponse(resp)
+
+	if err := cache.put(c.ctx, base, key, entry, nil); err != nil {
+		cache.warn(c, err)
+	}
+	return entry.response(c, now.Sub(time.Unix(0, entry.Birth))), nil
+}
+
+// warn logs a failure of the Storage. The cache is skipped when its Storage
+// fails, rather than failing the requests.
+func (*Cache) warn(c *core, err error) {
+	if logger := c.client.Logger(); logger != nil {
+		logger.Warnf("%v", err)
+		return
+	}
+	log.Warnf("%v", err)
+}
+
+// store stores a response to a GET request if it is cacheable.
+func (cache *Cache) store(c *core, base string, resp *Response) error {
+	raw := resp.RawResponse
+	if !isCacheableStatus(raw.StatusCode()) || raw.IsBodyStream() || len(raw.Body()) > cache.maxBodySize {
+		return nil
+	}
+	// A redirected request got the response of another URL
+	if host, path := resp.respondedOrigin(c.req.RawRequest.URI()); string(host) != string(c.req.RawRequest.URI().Host()) ||
+		string(path) != string(c.req.RawRequest.URI().Path()) {
+		return nil
+	}
+
+	noStore := false
+	cachecontrol.ParseDirectives(raw.Header.Peek(fiber.HeaderCacheControl), func(key, _ []byte) {
+		if utils.EqualFold(utils.UnsafeString(key), "no-store") {
+			noStore = true
+		}
+	})
+	vary, varyStar := cachecontrol.ParseVary(string(raw.Header.Peek(fiber.HeaderVary)))
+	if noStore || varyStar {
+		return nil
+	}
+
+	entry := &cacheEntry{
+		Status: raw.StatusCode(),
+		Body:   append([]byte(nil), raw.Body()...),
+	}
+	entry.Birth, entry.Lifetime, entry.NoCache = freshness(&raw.Header, time.Now())
+	entry.Header = storedHeaders(&raw.Header)
+	if entry.Lifetime <= 0 && entry.header(fiber.HeaderETag) == "" && entry.header(fiber.HeaderLastModified) == "" {
+		return nil
+	}
+
+	return cache.put(c.ctx, base, varyKey(base, vary, &c.req.RawRequest.Header), entry, &cacheIndex{Vary: vary})
+}
+
+// lookup returns the stored response matching the request, and its key.
+func (cache *Cache) lookup(ctx context.Context, base string, req *fasthttp.Request) (string, *cacheEntry, error) {
+	data, err := cache.storage.GetWithContext(ctx, base)
+	if err != nil {
+		return "", nil, fmt.Errorf("client: failed to get cached response: %w", err)
+	}
+	if data == nil {
+		return "", nil, nil
+	}
+	var index cacheIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return "", nil, fmt.Errorf("client: failed to decode cached response: %w", err)
+	}
+
+	key := varyKey(base, index.Vary, &req.Header)
+	data, err = cache.storage.GetWithContext(ctx, key)
+	if err != nil {
+		return "", nil, fmt.Errorf("client: failed to get cached response: %w", err)
+	}
+	if data == nil {
+		return key, nil, nil
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return "", nil, fmt.Errorf("client: failed to decode cached response: %w", err)
+	}
+	return key, entry, nil
+}
+
+// put stores a response, and the index of its URL when it is not nil, until
+// it expires or, if it has a validator, StaleExpiration after.
+func (cache *Cache) put(ctx context.Context, base, key string, entry *cacheEntry, index *cacheIndex) error {
+	ttl := entry.Lifetime - time.Since(time.Unix(0, entry.Birth))
+	if entry.header(fiber.HeaderETag) != "" || entry.header(fiber.HeaderLastModified) != "" {
+		ttl = max(ttl, 0) + cache.staleExpiration
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("client: failed to encode cached response: %w", err)
+	}
+	if err := cache.storage.SetWithContext(ctx, key, data, ttl); err != nil {
+		return fmt.Errorf("client: failed to store cached response: %w", err)
+	}
+
+	if index == nil {
+		return nil
+	}
+	if data, err = json.Marshal(index); err != nil {
+		return fmt.Errorf("client: failed to encode cached response: %w", err)
+	}
+	if err := cache.storage.SetWithContext(ctx, base, data, ttl); err != nil {
+		return fmt.Errorf("client: failed to store cached response: %w", err)
+	}
+	return nil
+}
+
+// response returns a Response holding the stored response.
+func (e *cacheEntry) response(c *core, age time.Duration) *Response {
+	resp := AcquireResponse()
+	resp.setClient(c.client)
+	resp.setRequest(c.req)
+	resp.fromCache = true
+
+	raw := resp.RawResponse
+	raw.SetStatusCode(e.Status)
+	for _, h := range e.Header {
+		raw.Header.Add(h[0], h[1])
+	}
+	raw.Header.Set(fiber.HeaderAge, strconv.FormatInt(int64(max(age, 0)/time.Second), 10))
+	raw.SetBody(e.Body)
+	return resp
+}
+
+// header returns the first value of a stored header.
+func (e *cacheEntry) header(name string) string {
+	for _, h := range e.Header {
+		if utils.EqualFold(h[0], name) {
+			return h[1]
+		}
+	}
+	return ""
+}
+
+// update replaces the stored headers with the ones of a 304 response of the
+// same names.
+func (e *cacheEntry) update(header [][2]string) {
+	e.Header = slices.DeleteFunc(e.Header, func(stored [2]string) bool {
+		return slices.ContainsFunc(header, func(h [2]string) bool {
+			return utils.EqualFold(h[0], stored[0])
+		})
+	})
+	e.Header = append(e.Header, header...)
+}
+
+// freshness returns when a response was generated and its freshness
+// lifetime, following RFC 9111, 4.2, without heuristic freshness. A private
+// cache ignores s-maxage.
+func freshness(header *fasthttp.ResponseHeader, now time.Time) (birth int64, lifetime time.Duration, noCache bool) { //nolint:nonamedreturns // names document the results
+	var maxAge uint64
+	maxAgeSet := false
+	cachecontrol.ParseDirectives(header.Peek(fiber.HeaderCacheControl), func(key, value []byte) {
+		switch {
+		case utils.EqualFold(utils.UnsafeString(key), "no-cache"):
+			noCache = true
+		case utils.EqualFold(utils.UnsafeString(key), "max-age"):
+			maxAge, maxAgeSet = cachecontrol.ParseUint(value)
+		default:
+			// ignore the other directives
+		}
+	})
+
+	date, dateErr := http.ParseTime(string(header.Peek(fiber.HeaderDate)))
+	if dateErr != nil || date.After(now) {
+		date = now
+	}
+	age := now.Sub(date)
+	if v, ok := cachecontrol.ParseUint(header.Peek(fiber.HeaderAge)); ok {
+		age = max(age, time.Duration(v)*time.Second)
+	}
+
+	switch {
+	case maxAgeSet:
+		lifetime = time.Duration(maxAge) * time.Second
+	case len(header.Peek(fiber.HeaderExpires)) > 0:
+		// An invalid Expires means the response is stale
+		if expires, err := http.ParseTime(string(header.Peek(fiber.HeaderExpires))); err == nil {
+			lifetime = expires.Sub(date)
+		}
+	default:
+		// Without explicit freshness, the response is only revalidated
+	}
+
+	return now.Add(-age).UnixNano(), lifetime, noCache
+}
+
+// credentialHeaders are the request headers carrying credentials. Responses
+// are stored per value of these headers, so that clients sharing a Storage
+// never get a response to the credentials of another (RFC 9111, 3.5).
+var credentialHeaders = []string{fiber.HeaderAuthorization, fiber.HeaderProxyAuthorization, fiber.HeaderCookie}
+
+// varyKey returns the key of the response to a request, from the values of
+// the request headers the response varies on and of its credentials.
+func varyKey(base string, vary []string, header *fasthttp.RequestHeader) string {
+	sum := sha256.New()
+	for _, names := range [...][]string{vary, credentialHeaders} {
+		for _, name := range names {
+			_, _ = sum.Write([]byte(name)) //nolint:errcheck // hash.Hash.Write never errors
+			for _, v := range header.PeekAll(name) {
+				_, _ = sum.Write([]byte{0}) //nolint:errcheck // hash.Hash.Write never errors
+				_, _ = sum.Write(v)         //nolint:errcheck // hash.Hash.Write never errors
+			}
+			_, _ = sum.Write([]byte{'\n'}) //nolint:errcheck // hash.Hash.Write never errors
+		}
+	}
+	return base + "|" + hex.EncodeToString(sum.Sum(nil))
+}
+
+// storedHeaders returns the headers of a response which are stored.
+func storedHeaders(header *fasthttp.ResponseHeader) [][2]string {
+	var stored [][2]string
+	for k, v := range header.All() {
+		if name := string(k); storedHeader(name) {
+			stored = append(stored, [2]string{name, string(v)})
+		}
+	}
+	return stored
+}
+
+// storedHeader reports whether a response header is stored. Set-Cookie is
+// not, so that cached responses do not set cookies again, and neither are
+// the headers describing the connection or recomputed when it is served.
+func storedHeader(name string) bool {
+	switch {
+	case utils.EqualFold(name, fiber.HeaderSetCookie),
+		utils.EqualFold(name, fiber.HeaderConnection),
+		utils.EqualFold(name, fiber.HeaderKeepAlive),
+		utils.EqualFold(name, fiber.HeaderTransferEncoding),
+		utils.EqualFold(name, fiber.HeaderContentLength),
+		utils.EqualFold(name, fiber.HeaderAge):
+		return false
+	default:
+		return true
+	}
+}
+
+// isCacheableStatus reports whether responses of a status may be stored
+// (RFC 9110, 15.1: heuristically cacheable statuses, here only stored with
+// explicit freshness or a validator).
+func isCacheableStatus(status int) bool {
+	switch status {
+	case fiber.StatusOK, fiber.StatusNonAuthoritativeInformation, fiber.StatusNoContent,
+		fiber.StatusMultipleChoices, fiber.StatusMovedPermanently, fiber.StatusPermanentRedirect,
+		fiber.StatusNotFound, fiber.StatusMethodNotAllowed, fiber.StatusGone,
+		fiber.StatusRequestURITooLong, fiber.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// isSafeMethod reports whether a method is safe (RFC 9110, 9.2.1).
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace, fiber.MethodQuery:
+		return true
+	default:
+		return false
+	}
+}
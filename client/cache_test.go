@@ -0,0 +1,553 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func newCacheTestClient(t *testing.T, setup func(app *fiber.App)) *Client {
+	t.Helper()
+
+	app, dial, start := createHelperServer(t)
+	setup(app)
+	go start()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	return New().SetDial(dial).SetCache(NewCache())
+}
+
+func cacheGet(t *testing.T, c *Client, url string, header ...string) (status int, body string, fromCache bool) { //nolint:nonamedreturns // names document the results
+	t.Helper()
+
+	req := AcquireRequest().SetClient(c)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.SetHeader(header[i], header[i+1])
+	}
+	resp, err := req.Get(url)
+	require.NoError(t, err)
+	defer resp.Close()
+	return resp.StatusCode(), resp.String(), resp.FromCache()
+}
+
+func Test_Cache_Fresh(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/", func(c fiber.Ctx) error {
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			c.Set("X-Hit", strconv.Itoa(int(hits.Add(1))))
+			return c.SendString("config")
+		})
+	})
+
+	status, body, fromCache := cacheGet(t, c, "http://example.com/")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "config", body)
+	require.False(t, fromCache)
+
+	resp, err := c.Get("http://example.com/")
+	require.NoError(t, err)
+	require.True(t, resp.FromCache())
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, "config", resp.String())
+	require.Equal(t, "1", resp.Header("X-Hit"))
+	require.Equal(t, "max-age=60", resp.Header(fiber.HeaderCacheControl))
+	// Date has a precision of a second
+	require.Contains(t, []string{"0", "1"}, resp.Header(fiber.HeaderAge))
+	resp.Close()
+	require.Equal(t, int32(1), hits.Load())
+
+	// The query is part of the URL
+	_, _, fromCache = cacheGet(t, c, "http://example.com/?v=2")
+	require.False(t, fromCache)
+	require.Equal(t, int32(2), hits.Load())
+}
+
+func Test_Cache_Revalidate(t *testing.T) {
+	t.Parallel()
+
+	var hits, notModified atomic.Int32
+	version := atomic.Value{}
+	version.Store("v1")
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/etag", func(c fiber.Ctx) error {
+			hits.Add(1)
+			etag := `"` + version.Load().(string) + `"` //nolint:forcetypeassert,errcheck // always a string
+			c.Set(fiber.HeaderCacheControl, "no-cache")
+			c.Set(fiber.HeaderETag, etag)
+			if c.Get(fiber.HeaderIfNoneMatch) == etag {
+				notModified.Add(1)
+				c.Set("X-Revalidated", "true")
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+			return c.SendString(version.Load().(string)) //nolint:forcetypeassert,errcheck // always a string
+		})
+		app.Get("/last-modified", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=0")
+			c.Set(fiber.HeaderLastModified, lastModified)
+			if c.Get(fiber.HeaderIfModifiedSince) == lastModified {
+				notModified.Add(1)
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+			return c.SendString("modified")
+		})
+	})
+
+	_, body, fromCache := cacheGet(t, c, "http://example.com/etag")
+	require.Equal(t, "v1", body)
+	require.False(t, fromCache)
+
+	// no-cache responses are revalidated on every use
+	resp, err := c.Get("http://example.com/etag")
+	require.NoError(t, err)
+	require.True(t, resp.FromCache())
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, "v1", resp.String())
+	// The stored headers are updated by the 304 response
+	require.Equal(t, "true", resp.Header("X-Revalidated"))
+	resp.Close()
+	require.Equal(t, int32(2), hits.Load())
+	require.Equal(t, int32(1), notModified.Load())
+
+	version.Store("v2")
+	_, body, fromCache = cacheGet(t, c, "http://example.com/etag")
+	require.Equal(t, "v2", body)
+	require.False(t, fromCache)
+	_, body, fromCache = cacheGet(t, c, "http://example.com/etag")
+	require.Equal(t, "v2", body)
+	require.True(t, fromCache)
+	require.Equal(t, int32(2), notModified.Load())
+
+	_, body, fromCache = cacheGet(t, c, "http://example.com/last-modified")
+	require.Equal(t, "modified", body)
+	require.False(t, fromCache)
+	_, body, fromCache = cacheGet(t, c, "http://example.com/last-modified")
+	require.Equal(t, "modified", body)
+	require.True(t, fromCache)
+	require.Equal(t, int32(3), notModified.Load())
+
+	// Conditional requests of the caller are sent as they are
+	status, _, fromCache := cacheGet(t, c, "http://example.com/etag", fiber.HeaderIfNoneMatch, `"v2"`)
+	require.Equal(t, fiber.StatusNotModified, status)
+	require.False(t, fromCache)
+}
+
+func Test_Cache_RequestDirectives(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			c.Set(fiber.HeaderETag, `"v1"`)
+			if c.Get(fiber.HeaderIfNoneMatch) == `"v1"` {
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+			return c.SendString("config")
+		})
+	})
+
+	// no-store requests bypass the cache
+	_, _, fromCache := cacheGet(t, c, "http://example.com/", fiber.HeaderCacheControl, "no-store")
+	require.False(t, fromCache)
+	_, _, fromCache = cacheGet(t, c, "http://example.com/", fiber.HeaderCacheControl, "no-store")
+	require.False(t, fromCache)
+	require.Equal(t, int32(2), hits.Load())
+
+	_, _, fromCache = cacheGet(t, c, "http://example.com/")
+	require.False(t, fromCache)
+	require.Equal(t, int32(3), hits.Load())
+
+	// no-cache and max-age requests revalidate
+	for _, header := range [][]string{
+		{fiber.HeaderCacheControl, "no-cache"},
+		{fiber.HeaderCacheControl, "max-age=0"},
+		{fiber.HeaderPragma, "no-cache"},
+	} {
+		_, body, fromCache := cacheGet(t, c, "http://example.com/", header...)
+		require.Equal(t, "config", body)
+		require.True(t, fromCache)
+	}
+	require.Equal(t, int32(6), hits.Load())
+
+	_, _, fromCache = cacheGet(t, c, "http://example.com/", fiber.HeaderCacheControl, "max-age=3600")
+	require.True(t, fromCache)
+	require.Equal(t, int32(6), hits.Load())
+}
+
+func Test_Cache_Vary(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			c.Set(fiber.HeaderVary, fiber.HeaderAcceptLanguage)
+			return c.SendString("hello " + c.Get(fiber.HeaderAcceptLanguage))
+		})
+		app.Get("/star", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			c.Set(fiber.HeaderVary, "*")
+			return c.SendString("star")
+		})
+	})
+
+	_, body, fromCache := cacheGet(t, c, "http://example.com/", fiber.HeaderAcceptLanguage, "en")
+	require.Equal(t, "hello en", body)
+	require.False(t, fromCache)
+	_, body, fromCache = cacheGet(t, c, "http://example.com/", fiber.HeaderAcceptLanguage, "fr")
+	require.Equal(t, "hello fr", body)
+	require.False(t, fromCache)
+
+	_, body, fromCache = cacheGet(t, c, "http://example.com/", fiber.HeaderAcceptLanguage, "en")
+	require.Equal(t, "hello en", body)
+	require.True(t, fromCache)
+	_, body, fromCache = cacheGet(t, c, "http://example.com/", fiber.HeaderAcceptLanguage, "fr")
+	require.Equal(t, "hello fr", body)
+	require.True(t, fromCache)
+	require.Equal(t, int32(2), hits.Load())
+
+	_, _, fromCache = cacheGet(t, c, "http://example.com/star")
+	require.False(t, fromCache)
+	_, _, fromCache = cacheGet(t, c, "http://example.com/star")
+	require.False(t, fromCache)
+	require.Equal(t, int32(4), hits.Load())
+}
+
+func Test_Cache_NotStored(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/no-store", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "no-store, max-age=60")
+			return c.SendString("secret")
+		})
+		app.Get("/no-freshness", func(c fiber.Ctx) error {
+			hits.Add(1)
+			return c.SendString("plain")
+		})
+		app.Get("/error", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			return c.SendStatus(fiber.StatusInternalServerError)
+		})
+		app.Get("/large", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			return c.Send(make([]byte, 2048))
+		})
+	})
+	c.SetCache(NewCache(CacheConfig{MaxBodySize: 1024}))
+
+	for _, path := range []string{"/no-store", "/no-freshness", "/error", "/large"} {
+		_, _, fromCache := cacheGet(t, c, "http://example.com"+path)
+		require.False(t, fromCache, path)
+		_, _, fromCache = cacheGet(t, c, "http://example.com"+path)
+		require.False(t, fromCache, path)
+	}
+	require.Equal(t, int32(8), hits.Load())
+}
+
+func Test_Cache_Expires(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/future", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderExpires, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+			return c.SendString("future")
+		})
+		app.Get("/past", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderExpires, "0")
+			return c.SendString("past")
+		})
+	})
+
+	_, _, fromCache := cacheGet(t, c, "http://example.com/future")
+	require.False(t, fromCache)
+	_, _, fromCache = cacheGet(t, c, "http://example.com/future")
+	require.True(t, fromCache)
+
+	_, _, fromCache = cacheGet(t, c, "http://example.com/past")
+	require.False(t, fromCache)
+	_, _, fromCache = cacheGet(t, c, "http://example.com/past")
+	require.False(t, fromCache)
+	require.Equal(t, int32(3), hits.Load())
+}
+
+func Test_Cache_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			return c.SendString("config")
+		})
+		app.Post("/", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+	})
+
+	cacheGet(t, c, "http://example.com/")
+	_, _, fromCache := cacheGet(t, c, "http://example.com/")
+	require.True(t, fromCache)
+
+	resp, err := c.Post("http://example.com/")
+	require.NoError(t, err)
+	require.False(t, resp.FromCache())
+	resp.Close()
+
+	_, _, fromCache = cacheGet(t, c, "http://example.com/")
+	require.False(t, fromCache)
+	require.Equal(t, int32(2), hits.Load())
+}
+
+func Test_Cache_SetCookie(t *testing.T) {
+	t.Parallel()
+
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/", func(c fiber.Ctx) error {
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			c.Cookie(&fiber.Cookie{Name: "session", Value: "secret"})
+			return c.SendString("config")
+		})
+	})
+
+	resp, err := c.Get("http://example.com/")
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Header(fiber.HeaderSetCookie))
+	resp.Close()
+
+	resp, err = c.Get("http://example.com/")
+	require.NoError(t, err)
+	require.True(t, resp.FromCache())
+	require.Empty(t, resp.Header(fiber.HeaderSetCookie))
+	require.Empty(t, resp.Cookies())
+	resp.Close()
+}
+
+func Test_Cache_SharedStorage(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	app, dial, start := createHelperServer(t)
+	app.Get("/", func(c fiber.Ctx) error {
+		hits.Add(1)
+		c.Set(fiber.HeaderCacheControl, "max-age=60")
+		return c.SendString("config")
+	})
+	go start()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	storage := memory.New()
+	c1 := New().SetDial(dial).SetCache(NewCache(CacheConfig{Storage: storage}))
+	c2 := New().SetDial(dial).SetCache(NewCache(CacheConfig{Storage: storage}))
+
+	_, _, fromCache := cacheGet(t, c1, "http://example.com/")
+	require.False(t, fromCache)
+	_, _, fromCache = cacheGet(t, c2, "http://example.com/")
+	require.True(t, fromCache)
+	require.Equal(t, int32(1), hits.Load())
+}
+
+func Test_Cache_StorageError(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	c := newCacheTestClient(t, func(app *fiber.App) {
+		app.Get("/", func(c fiber.Ctx) error {
+			hits.Add(1)
+			c.Set(fiber.HeaderCacheControl, "max-age=60")
+			return c.SendString("config")
+		})
+	})
+	c.SetCache(NewCache(CacheConfig{Storage: failingStorage{}}))
+
+	// The requests are sent when the storage fails
+	for range 2 {
+		status, body, fromCache := cacheGet(t, c, "http://example.com/")
+		require.Equal(t, fiber.StatusOK, status)
+		require.Equal(t, "config", body)
+		require.False(t, fromCache)
+	}
+	require.Equal(t, int32(2), hits.Load())
+}
+
+func Test_Client_SetCache(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+	require.Nil(t, c.Cache())
+
+	cache := NewCache()
+	c.SetCache(cache)
+	require.Same(t, cache, c.Cache())
+
+	c.Reset()
+	require.Nil(t, c.Cache())
+}
+
+func Test_Cache_Freshness(t *testing.T) {
+	t.Parallel()
+
+	// fasthttp ignores a Date set on a response header, so the headers are parsed
+	parse := func(headers ...string) *fasthttp.ResponseHeader {
+		t.Helper()
+		header := &fasthttp.ResponseHeader{}
+		raw := "HTTP/1.1 200 OK\r\n" + strings.Join(headers, "\r\n") + "\r\n\r\n"
+		require.NoError(t, header.Read(bufio.NewReader(strings.NewReader(raw))))
+		return header
+	}
+	now := time.Now()
+	date := func(d time.Duration) string {
+		return now.Add(d).UTC().Format(http.TimeFormat)
+	}
+
+	birth, lifetime, noCache := freshness(parse(
+		"Cache-Control: max-age=120, s-maxage=600",
+		"Date: "+date(-30*time.Second),
+		"Age: 50",
+	), now)
+	require.Equal(t, 120*time.Second, lifetime)
+	require.Equal(t, now.Add(-50*time.Second).UnixNano(), birth)
+	require.False(t, noCache)
+
+	// Expires is relative to Date
+	_, lifetime, noCache = freshness(parse(
+		"Cache-Control: no-cache",
+		"Date: "+date(-time.Minute),
+		"Expires: "+date(time.Minute),
+	), now)
+	require.InDelta(t, 2*time.Minute, lifetime, float64(time.Second))
+	require.True(t, noCache)
+
+	// A Date in the future is ignored
+	birth, lifetime, _ = freshness(parse("Date: "+date(time.Hour)), now)
+	require.Equal(t, now.UnixNano(), birth)
+	require.Zero(t, lifetime)
+}
+
+func Test_CacheEntry_Update(t *testing.T) {
+	t.Parallel()
+
+	e := &cacheEntry{Header: [][2]string{
+		{"Content-Type", "text/plain"},
+		{"X-Tag", "a"},
+		{"X-Tag", "b"},
+		{"ETag", `"v1"`},
+	}}
+	e.update([][2]string{{"x-tag", "c"}, {"ETag", `"v2"`}, {"X-Tag", "d"}})
+	require.Equal(t, [][2]string{
+		{"Content-Type", "text/plain"},
+		{"x-tag", "c"},
+		{"ETag", `"v2"`},
+		{"X-Tag", "d"},
+	}, e.Header)
+	require.Equal(t, "c", e.header("X-Tag"))
+	require.Empty(t, e.header("Last-Modified"))
+}
+
+type failingStorage struct{}
+
+var errStorage = errors.New("storage failed")
+
+func (failingStorage) GetWithContext(context.Context, string) ([]byte, error) {
+	return nil, errStorage
+}
+
+func (failingStorage) Get(string) ([]byte, error) {
+	return nil, errStorage
+}
+
+func (failingStorage) SetWithContext(context.Context, string, []byte, time.Duration) error {
+	return errStorage
+}
+
+func (failingStorage) Set(string, []byte, time.Duration) error {
+	return errStorage
+}
+
+func (failingStorage) DeleteWithContext(context.Context, string) error {
+	return errStorage
+}
+
+func (failingStorage) Delete(string) error {
+	return errStorage
+}
+
+func (failingStorage) ResetWithContext(context.Context) error {
+	return errStorage
+}
+
+func (failingStorage) Reset() error {
+	return errStorage
+}
+
+func (failingStorage) Close() error {
+	return nil
+}
+
+func Test_Cache_SharedStorage_Credentials(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	app, dial, start := createHelperServer(t)
+	app.Get("/", func(c fiber.Ctx) error {
+		hits.Add(1)
+		c.Set(fiber.HeaderCacheControl, "max-age=60")
+		return c.SendString(c.Get(fiber.HeaderAuthorization))
+	})
+	go start()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	storage := memory.New()
+	c1 := New().SetDial(dial).SetCache(NewCache(CacheConfig{Storage: storage}))
+	c2 := New().SetDial(dial).SetCache(NewCache(CacheConfig{Storage: storage}))
+
+	_, body, fromCache := cacheGet(t, c1, "http://example.com/", fiber.HeaderAuthorization, "Bearer alice")
+	require.False(t, fromCache)
+	require.Equal(t, "Bearer alice", body)
+
+	// Another token must not get the response of the first
+	_, body, fromCache = cacheGet(t, c2, "http://example.com/", fiber.HeaderAuthorization, "Bearer bob")
+	require.False(t, fromCache)
+	require.Equal(t, "Bearer bob", body)
+
+	_, body, fromCache = cacheGet(t, c2, "http://example.com/", fiber.HeaderAuthorization, "Bearer alice")
+	require.True(t, fromCache)
+	require.Equal(t, "Bearer alice", body)
+
+	_, _, fromCache = cacheGet(t, c1, "http://example.com/")
+	require.False(t, fromCache)
+	require.Equal(t, int32(3), hits.Load())
+}
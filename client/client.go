@@ -57,14 +57,18 @@ type Client struct {
 	cborUnmarshal utils.CBORUnmarshal
 
 	cookieJar            *CookieJar
+	cache                *Cache
 	retryConfig          *RetryConfig
 	baseURL              string
 	userAgent            string
 	referer              string
 	userRequestHooks     []RequestHook
 	builtinRequestHooks  []RequestHook
+	userSendHooks        []SendHook
 	userResponseHooks    []ResponseHook
 	builtinResponseHooks []ResponseHook
+	userErrorHooks       []ErrorHook
+	userMiddleware       []Middleware
 
 	timeout                   time.Duration
 	mu                        sync.RWMutex
@@ -138,6 +142,32 @@ func (c *Client) LBClient() *fasthttp.LBClient {
 	return nil
 }
 
+// Transport returns the custom Transport if the client was created with one.
+func (c *Client) Transport() Transport {
+	if client, ok := c.transport.(*customTransport); ok {
+		return client.transport
+	}
+	return nil
+}
+
+// Middleware returns a copy of the user-defined middleware.
+func (c *Client) Middleware() []Middleware {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.userMiddleware)
+}
+
+// Use adds user-defined middleware wrapping the sending of the requests. The
+// middleware runs in the order it is added.
+func (c *Client) Use(m ...Middleware) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.userMiddleware = append(c.userMiddleware, m...)
+	return c
+}
+
 // R creates a new Request associated with the client.
 func (c *Client) R() *Request {
 	return AcquireRequest().SetClient(c)
@@ -160,6 +190,24 @@ func (c *Client) AddRequestHook(h ...RequestHook) *Client {
 	return c
 }
 
+// SendHook returns a copy of the user-defined send hooks.
+func (c *Client) SendHook() []SendHook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.userSendHooks)
+}
+
+// AddSendHook adds user-defined send hooks, which run after the built-in
+// request hooks.
+func (c *Client) AddSendHook(h ...SendHook) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.userSendHooks = append(c.userSendHooks, h...)
+	return c
+}
+
 // ResponseHook returns a copy of the user-defined response hooks.
 func (c *Client) ResponseHook() []ResponseHook {
 	c.mu.RLock()
@@ -177,6 +225,23 @@ func (c *Client) AddResponseHook(h ...ResponseHook) *Client {
 	return c
 }
 
+// ErrorHook returns a copy of the user-defined error hooks.
+func (c *Client) ErrorHook() []ErrorHook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.userErrorHooks)
+}
+
+// AddErrorHook adds user-defined error hooks.
+func (c *Client) AddErrorHook(h ...ErrorHook) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.userErrorHooks = append(c.userErrorHooks, h...)
+	return c
+}
+
 // JSONMarshal returns the JSON marshal function used by the client.
 func (c *Client) JSONMarshal() utils.JSONMarshal {
 	c.mu.RLock()
@@ -707,6 +772,25 @@ func (c *Client) SetCookieJar(cookieJar *CookieJar) *Client {
 	return c
 }
 
+// Cache returns the response cache of the client, or nil if it has none.
+func (c *Client) Cache() *Cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cache
+}
+
+// SetCache sets the response cache of the client. Fresh responses to GET
+// requests are then served from the cache, and stale ones are revalidated.
+// Set nil to disable caching.
+func (c *Client) SetCache(cache *Cache) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache = cache
+	return c
+}
+
 // Get sends a GET request to the specified URL, similar to axios.
 func (c *Client) Get(url string, cfg ...Config) (*Response, error) {
 	req := AcquireRequest().SetClient(c)
@@ -816,6 +900,7 @@ func (c *Client) Reset() {
 		c.cookieJar.Release()
 		c.cookieJar = nil
 	}
+	c.cache = nil
 
 	c.path.Reset()
 	c.cookies.Reset()
@@ -954,6 +1039,16 @@ func NewWithLBClient(c *fasthttp.LBClient) *Client {
 	return newClient(newLBClientTransport(c))
 }
 
+// NewWithTransport creates and returns a new Client object sending its
+// requests with a custom Transport, such as a MockTransport, a ReplayTransport
+// or an AppTransport.
+func NewWithTransport(t Transport) *Client {
+	if t == nil {
+		panic("client.Transport must not be nil")
+	}
+	return newClient(newCustomTransport(t))
+}
+
 func newClient(transport httpClientTransport) *Client {
 	return &Client{
 		transport: transport,
@@ -968,8 +1063,11 @@ func newClient(transport httpClientTransport) *Client {
 
 		userRequestHooks:     []RequestHook{},
 		builtinRequestHooks:  []RequestHook{parserRequestURL, parserRequestHeader, parserRequestBody},
+		userSendHooks:        []SendHook{},
 		userResponseHooks:    []ResponseHook{},
 		builtinResponseHooks: []ResponseHook{parserResponseCookie, logger},
+		userErrorHooks:       []ErrorHook{},
+		userMiddleware:       []Middleware{},
 		jsonMarshal:          json.Marshal,
 		jsonUnmarshal:        json.Unmarshal,
 		xmlMarshal:           xml.Marshal,
@@ -301,6 +301,45 @@ func Test_Client_Add_Hook(t *testing.T) {
 
 		require.Len(t, client.ResponseHook(), 3)
 	})
+
+	t.Run("add send hooks", func(t *testing.T) {
+		t.Parallel()
+		client := New().AddSendHook(func(_ *Client, _ *Request) error {
+			return nil
+		})
+
+		require.Len(t, client.SendHook(), 1)
+
+		hook := func(_ *Client, _ *Request) error {
+			return nil
+		}
+		client.AddSendHook(hook, hook)
+
+		require.Len(t, client.SendHook(), 3)
+		require.Empty(t, client.RequestHook())
+	})
+
+	t.Run("add error hooks", func(t *testing.T) {
+		t.Parallel()
+		client := New().AddErrorHook(func(_ *Client, _ *Request, _ error) {})
+
+		require.Len(t, client.ErrorHook(), 1)
+
+		client.AddErrorHook(func(_ *Client, _ *Request, _ error) {}, func(_ *Client, _ *Request, _ error) {})
+
+		require.Len(t, client.ErrorHook(), 3)
+	})
+
+	t.Run("add middleware", func(t *testing.T) {
+		t.Parallel()
+		client := New().Use(func(next Handler) Handler { return next })
+
+		require.Len(t, client.Middleware(), 1)
+
+		client.Use(func(next Handler) Handler { return next }, func(next Handler) Handler { return next })
+
+		require.Len(t, client.Middleware(), 3)
+	})
 }
 
 func Test_Client_HostClient_Behavior(t *testing.T) {
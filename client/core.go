@@ -4,6 +4,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -24,12 +25,36 @@ const boundary = "FiberFormBoundary"
 // type's concurrency contract), so per-request changes belong on the Request.
 type RequestHook func(*Client, *Request) error
 
+// SendHook is a request hook invoked after the built-in request hooks, right
+// before the request is sent. Request.RawRequest then holds the final URL,
+// headers and body of the request, so send hooks suit the logic which depends
+// on the request as sent, such as signing it.
+type SendHook = RequestHook
+
 // ResponseHook is a function invoked after a response is received.
 // It receives a Client, Response, and Request. Mutate the Response or act on it;
 // do not mutate the shared Client config, which is read concurrently by other
 // in-flight requests (see the Client type's concurrency contract).
 type ResponseHook func(*Client, *Response, *Request) error
 
+// ErrorHook is a function invoked when a request fails, whether in a request
+// hook, while it is sent or in a response hook. It receives the Client, the
+// Request and the error returned to the caller. Unlike response hooks, it sees
+// the requests that never got a response, such as on connection errors and
+// timeouts.
+type ErrorHook func(*Client, *Request, error)
+
+// Handler sends a request and returns its response.
+type Handler func(*Request) (*Response, error)
+
+// Middleware wraps the Handler sending the requests of a Client. It runs after
+// the request and send hooks, and before the response hooks, so it sees the
+// request as sent and the response as received or the error. A middleware may
+// call next several times, such as to retry the request, or not at all, and
+// return a response of its own. The first middleware added runs first, and the
+// response cache of the client and the transport run last.
+type Middleware func(next Handler) Handler
+
 // RetryConfig is an alias for the `retry.Config` type from the `addon/retry` package.
 type RetryConfig = retry.Config
 
@@ -48,6 +73,14 @@ func (c *core) getRetryConfig() *RetryConfig {
 // execFunc is the core logic to send the request and receive the response.
 // It leverages the fasthttp client, optionally with retries or redirects.
 func (c *core) execFunc() (*Response, error) {
+	// A body stream can only be read once
+	if c.req.RawRequest.IsBodyStream() {
+		if c.req.bodyStreamSent {
+			return nil, ErrBodyStreamSent
+		}
+		c.req.bodyStreamSent = true
+	}
+
 	// do not close, these will be returned to the pool
 	errChan := acquireErrChan()
 	respChan := acquireResponseChan()
@@ -62,6 +95,8 @@ func (c *core) execFunc() (*Response, error) {
 		defer fasthttp.ReleaseRequest(reqv)
 
 		respv := fasthttp.AcquireResponse()
+		// The progress of a download is reported as the body is streamed
+		respv.StreamBody = c.req.streamResponseBody || c.req.downloadProgress != nil
 		defer func() {
 			if respv != nil {
 				fasthttp.ReleaseResponse(respv)
@@ -79,25 +114,35 @@ func (c *core) execFunc() (*Response, error) {
 		}()
 
 		c.req.RawRequest.CopyTo(reqv)
-		if bodyStream := c.req.RawRequest.BodyStream(); bodyStream != nil {
-			reqv.SetBodyStream(bodyStream, c.req.RawRequest.Header.ContentLength())
+		bodyStream := c.req.RawRequest.BodyStream()
+		if bodyStream != nil {
+			size := c.req.RawRequest.Header.ContentLength()
+			if c.req.uploadProgress != nil {
+				bodyStream = newProgressReader(bodyStream, int64(size), c.req.uploadProgress)
+			}
+			reqv.SetBodyStream(bodyStream, size)
 		}
 
-		var err error
-		if cfg != nil {
-			// Use an exponential backoff retry strategy.
-			err = retry.NewExponentialBackoff(*cfg).Retry(func() error {
-				if c.req.maxRedirects > 0 && (string(reqv.Header.Method()) == fiber.MethodGet || string(reqv.Header.Method()) == fiber.MethodHead || string(reqv.Header.Method()) == fiber.MethodQuery) {
-					return c.client.DoRedirects(reqv, respv, c.req.maxRedirects)
+		do := func() error {
+			// A buffered body is streamed again on each attempt to report its progress
+			if bodyStream == nil && c.req.uploadProgress != nil {
+				if body := c.req.RawRequest.Body(); len(body) > 0 {
+					reqv.SetBodyStream(newProgressReader(bytes.NewReader(body), int64(len(body)), c.req.uploadProgress), len(body))
 				}
-				return c.client.Do(reqv, respv)
-			})
-		} else {
+			}
 			if c.req.maxRedirects > 0 && (string(reqv.Header.Method()) == fiber.MethodGet || string(reqv.Header.Method()) == fiber.MethodHead || string(reqv.Header.Method()) == fiber.MethodQuery) {
-				err = c.client.DoRedirects(reqv, respv, c.req.maxRedirects)
-			} else {
-				err = c.client.Do(reqv, respv)
+				return c.client.DoRedirects(reqv, respv, c.req.maxRedirects)
 			}
+			return c.client.Do(reqv, respv)
+		}
+
+		var err error
+		// A body stream can only be sent once
+		if cfg != nil && bodyStream == nil {
+			// Use an exponential backoff retry strategy.
+			err = retry.NewExponentialBackoff(*cfg).Retry(do)
+		} else {
+			err = do()
 		}
 
 		if err != nil {
@@ -110,9 +155,10 @@ func (c *core) execFunc() (*Response, error) {
 		resp.setRequest(c.req)
 		// reqv carries the URI of the hop that produced this response, which after a
 		// redirect is not c.req's. Record it before reqv is pooled so the response
-		// hooks can attribute cookies to its real origin — and only where a jar
-		// will read it, since the copy is otherwise pure cost on every request.
-		if c.client != nil && c.client.cookieJar != nil {
+		// hooks can attribute cookies to its real origin, and the cache does not
+		// store it for c.req's URL — and only where a jar or a cache will read
+		// it, since the copy is otherwise pure cost on every request.
+		if c.client != nil && (c.client.cookieJar != nil || c.client.cache != nil) {
 			resp.setRespondedURI(reqv.URI())
 		}
 
@@ -125,6 +171,9 @@ func (c *core) execFunc() (*Response, error) {
 		// The defer statement above ensures that the original RawResponse
 		// (now stored in respv) will be properly released.
 		resp.RawResponse, respv = respv, resp.RawResponse
+		if c.req.downloadProgress != nil {
+			resp.setDownloadProgress(c.req.downloadProgress)
+		}
 		respChan <- resp
 	}()
 
@@ -145,7 +194,46 @@ func (c *core) execFunc() (*Response, error) {
 	}
 }
 
-// preHooks runs all request hooks before sending the request.
+// send sends the request through the middleware of the client, then its
+// response cache when it has one.
+func (c *core) send() (*Response, error) {
+	c.client.mu.RLock()
+	cache := c.client.cache
+	middleware := slices.Clone(c.client.userMiddleware)
+	c.client.mu.RUnlock()
+
+	next := func(req *Request) (*Response, error) {
+		// A middleware may send another request than the one it was given
+		rc := *c
+		rc.req = req
+		if cache == nil {
+			return rc.execFunc()
+		}
+		return cache.send(&rc)
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+
+	resp, err := next(c.req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, ErrNoResponse
+	}
+	// Responses created by a middleware belong to the request as well
+	if resp.client == nil {
+		resp.setClient(c.client)
+	}
+	if resp.request == nil {
+		resp.setRequest(c.req)
+	}
+	return resp, nil
+}
+
+// preHooks runs all request hooks, then the send hooks, before sending the
+// request.
 func (c *core) preHooks() error {
 	c.client.mu.RLock()
 	userHooks := slices.Clone(c.client.userRequestHooks)
@@ -158,9 +246,16 @@ func (c *core) preHooks() error {
 	}
 
 	c.client.mu.Lock()
-	defer c.client.mu.Unlock()
-
 	for _, f := range c.client.builtinRequestHooks {
+		if err := f(c.client, c.req); err != nil {
+			c.client.mu.Unlock()
+			return err
+		}
+	}
+	sendHooks := slices.Clone(c.client.userSendHooks)
+	c.client.mu.Unlock()
+
+	for _, f := range sendHooks {
 		if err := f(c.client, c.req); err != nil {
 			return err
 		}
@@ -190,6 +285,17 @@ func (c *core) afterHooks(resp *Response) error {
 	return nil
 }
 
+// errorHooks runs all error hooks after the request failed with err.
+func (c *core) errorHooks(err error) {
+	c.client.mu.RLock()
+	userHooks := slices.Clone(c.client.userErrorHooks)
+	c.client.mu.RUnlock()
+
+	for _, f := range userHooks {
+		f(c.client, c.req, err)
+	}
+}
+
 // timeout applies the configured timeout to the request, if any.
 func (c *core) timeout() context.CancelFunc {
 	var cancel context.CancelFunc
@@ -208,15 +314,27 @@ func (c *core) timeout() context.CancelFunc {
 	return cancel
 }
 
-// execute runs all hooks, applies timeouts, sends the request, and runs response hooks.
-func (c *core) execute(ctx context.Context, client *Client, req *Request) (*Response, error) {
+// execute runs all hooks, applies timeouts, sends the request, and runs response hooks,
+// or error hooks when any of these steps fails.
+func (c *core) execute(ctx context.Context, client *Client, req *Request) (resp *Response, err error) {
 	// Store references locally.
 	c.ctx = ctx
 	c.client = client
 	c.req = req
 
+	// Execute error hooks whatever step failed.
+	defer func() {
+		if err != nil {
+			c.errorHooks(err)
+		}
+	}()
+
+	// Close the body stream whatever step failed, as the files of a streamed
+	// multipart body are opened by the hooks. It is already closed once sent.
+	defer c.req.RawRequest.CloseBodyStream() //nolint:errcheck // the body is no longer read
+
 	// Execute pre request hooks (user-defined and built-in).
-	if err := c.preHooks(); err != nil {
+	if err = c.preHooks(); err != nil {
 		return nil, err
 	}
 
@@ -227,13 +345,13 @@ func (c *core) execute(ctx context.Context, client *Client, req *Request) (*Resp
 	}
 
 	// Perform the actual HTTP request.
-	resp, err := c.execFunc()
+	resp, err = c.send()
 	if err != nil {
 		return nil, err
 	}
 
 	// Execute after response hooks (built-in and then user-defined).
-	if err := c.afterHooks(resp); err != nil {
+	if err = c.afterHooks(resp); err != nil {
 		resp.Close()
 		return nil, err
 	}
@@ -304,4 +422,5 @@ var (
 	ErrBodyType             = errors.New("the body type should be []byte")
 	ErrNotSupportSaveMethod = errors.New("only file paths and io.Writer are supported")
 	ErrBodyTypeNotSupported = errors.New("the body type is not supported")
+	ErrNoResponse           = errors.New("the middleware returned neither a response nor an error")
 )
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -242,6 +243,94 @@ func Test_Execute(t *testing.T) {
 		require.Equal(t, "Not Found", string(resp.RawResponse.Body()))
 	})
 
+	t.Run("add user send hooks", func(t *testing.T) {
+		t.Parallel()
+		errHook := errors.New("send hook failed")
+
+		client := New()
+		client.SetDial(func(_ string) (net.Conn, error) {
+			return ln.Dial()
+		})
+		var order []string
+		client.AddRequestHook(func(_ *Client, req *Request) error {
+			order = append(order, "request")
+			// The request is not built yet
+			require.Empty(t, req.RawRequest.Body())
+			return nil
+		})
+		client.AddSendHook(func(_ *Client, req *Request) error {
+			order = append(order, "send")
+			require.Equal(t, "http://example.com/users/42?page=2", req.RawRequest.URI().String())
+			require.JSONEq(t, `{"name":"john"}`, string(req.RawRequest.Body()))
+			require.Equal(t, "application/json", string(req.RawRequest.Header.ContentType()))
+			return nil
+		})
+
+		req := AcquireRequest().
+			SetURL("http://example.com/users/:id").
+			SetPathParam("id", "42").
+			SetParam("page", "2").
+			SetJSON(map[string]string{"name": "john"})
+		_, err := newCore().execute(context.Background(), client, req)
+		require.NoError(t, err)
+		require.Equal(t, []string{"request", "send"}, order)
+
+		// An error stops the request
+		client = New().AddSendHook(func(_ *Client, _ *Request) error {
+			return errHook
+		})
+		client.SetDial(func(_ string) (net.Conn, error) {
+			return ln.Dial()
+		})
+		_, err = newCore().execute(context.Background(), client, AcquireRequest().SetURL("http://example.com"))
+		require.ErrorIs(t, err, errHook)
+	})
+
+	t.Run("add user error hooks", func(t *testing.T) {
+		t.Parallel()
+		errHook := errors.New("response hook failed")
+
+		var got []error
+		newClient := func(dial fasthttp.DialFunc) *Client {
+			client := New().AddErrorHook(func(_ *Client, _ *Request, err error) {
+				got = append(got, err)
+			})
+			client.SetDial(dial)
+			return client
+		}
+		newReq := func() *Request {
+			return AcquireRequest().SetURL("http://example.com")
+		}
+
+		// Errors while sending
+		client := newClient(func(_ string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		})
+		_, err := newCore().execute(context.Background(), client, newReq())
+		require.ErrorContains(t, err, "connection refused")
+		require.Equal(t, []error{err}, got)
+
+		// Errors in response hooks
+		client = newClient(func(_ string) (net.Conn, error) {
+			return ln.Dial()
+		})
+		client.AddResponseHook(func(_ *Client, _ *Response, _ *Request) error {
+			return errHook
+		})
+		_, err = newCore().execute(context.Background(), client, newReq())
+		require.ErrorIs(t, err, errHook)
+		require.Len(t, got, 2)
+		require.ErrorIs(t, got[1], errHook)
+
+		// Successful requests don't run them
+		client = newClient(func(_ string) (net.Conn, error) {
+			return ln.Dial()
+		})
+		_, err = newCore().execute(context.Background(), client, newReq())
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+	})
+
 	t.Run("no timeout", func(t *testing.T) {
 		t.Parallel()
 		core, client, req := newCore(), New(), AcquireRequest()
@@ -727,3 +816,169 @@ func Test_Core_RequestBodyStream(t *testing.T) {
 		require.Equal(t, len(streamContent), result.length)
 	})
 }
+
+func Test_Core_Middleware(t *testing.T) {
+	t.Parallel()
+
+	var hits int32
+	app, dial, start := createHelperServer(t)
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("hello " + c.Get("X-Name"))
+	})
+	app.Get("/retry", func(c fiber.Ctx) error {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		return c.SendString("hello " + c.Get("X-Name"))
+	})
+	go start()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	t.Run("order", func(t *testing.T) {
+		t.Parallel()
+
+		var order []string
+		trace := func(name string) Middleware {
+			return func(next Handler) Handler {
+				return func(req *Request) (*Response, error) {
+					order = append(order, name+" before")
+					resp, err := next(req)
+					order = append(order, name+" after")
+					return resp, err
+				}
+			}
+		}
+
+		client := New().
+			SetDial(dial).
+			AddRequestHook(func(_ *Client, _ *Request) error {
+				order = append(order, "request hook")
+				return nil
+			}).
+			AddSendHook(func(_ *Client, req *Request) error {
+				order = append(order, "send hook")
+				req.RawRequest.Header.Set("X-Name", "fiber")
+				return nil
+			}).
+			AddResponseHook(func(_ *Client, _ *Response, _ *Request) error {
+				order = append(order, "response hook")
+				return nil
+			}).
+			Use(trace("first"), trace("second")).
+			Use(func(next Handler) Handler {
+				return func(req *Request) (*Response, error) {
+					// The request is the one sent
+					require.Equal(t, "fiber", string(req.RawRequest.Header.Peek("X-Name")))
+					return next(req)
+				}
+			})
+
+		resp, err := client.Get("http://example.com/")
+		require.NoError(t, err)
+		resp.Close()
+		require.Equal(t, []string{
+			"request hook", "send hook",
+			"first before", "second before", "second after", "first after",
+			"response hook",
+		}, order)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		client := New().SetDial(dial).Use(func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				for {
+					resp, err := next(req)
+					if err != nil || resp.StatusCode() != fiber.StatusServiceUnavailable {
+						return resp, err
+					}
+					ReleaseResponse(resp)
+				}
+			}
+		})
+
+		resp, err := client.Get("http://example.com/retry", Config{Header: map[string]string{"X-Name": "retry"}})
+		require.NoError(t, err)
+		defer resp.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode())
+		require.Equal(t, "hello retry", resp.String())
+		require.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("short circuit", func(t *testing.T) {
+		t.Parallel()
+
+		var cookies []string
+		client := New().
+			SetDial(func(_ string) (net.Conn, error) {
+				return nil, errors.New("not dialed")
+			}).
+			Use(func(_ Handler) Handler {
+				return func(_ *Request) (*Response, error) {
+					resp := AcquireResponse()
+					resp.RawResponse.SetStatusCode(fiber.StatusTeapot)
+					resp.RawResponse.Header.Set(fiber.HeaderSetCookie, "k=v")
+					resp.RawResponse.SetBodyString("mocked")
+					return resp, nil
+				}
+			}).
+			AddResponseHook(func(_ *Client, resp *Response, _ *Request) error {
+				for _, c := range resp.Cookies() {
+					cookies = append(cookies, string(c.Key()))
+				}
+				return nil
+			})
+
+		req := client.R()
+		resp, err := req.Get("http://example.com/")
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusTeapot, resp.StatusCode())
+		require.Equal(t, "mocked", resp.String())
+		require.Equal(t, []string{"k"}, cookies)
+		require.Same(t, req, resp.request)
+		require.Same(t, client, resp.client)
+		resp.Close()
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		errDial := errors.New("not dialed")
+		var seen, hooked error
+		client := New().
+			SetDial(func(_ string) (net.Conn, error) {
+				return nil, errDial
+			}).
+			Use(func(next Handler) Handler {
+				return func(req *Request) (*Response, error) {
+					resp, err := next(req)
+					seen = err
+					return resp, err
+				}
+			}).
+			AddErrorHook(func(_ *Client, _ *Request, err error) {
+				hooked = err
+			})
+
+		_, err := client.Get("http://example.com/")
+		require.ErrorIs(t, err, errDial)
+		require.ErrorIs(t, seen, errDial)
+		require.ErrorIs(t, hooked, errDial)
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		t.Parallel()
+
+		client := New().Use(func(_ Handler) Handler {
+			return func(_ *Request) (*Response, error) {
+				return nil, nil //nolint:nilnil // tests the invalid middleware
+			}
+		})
+
+		_, err := client.Get("http://example.com/")
+		require.ErrorIs(t, err, ErrNoResponse)
+	})
+}
@@ -16,4 +16,16 @@ var (
 	// to plaintext HTTP, on every transport. Set MaxRedirects to 0 to take such a
 	// hop yourself instead.
 	ErrRedirectDowngrade = errors.New("client: HTTPS to HTTP redirect blocked")
+
+	// ErrNoMockMatch is returned by a MockTransport for a request matching none
+	// of its mocks.
+	ErrNoMockMatch = errors.New("client: no mock matches the request")
+
+	// ErrFixtureNotFound is returned by a ReplayTransport replaying a request
+	// for which no exchange was recorded.
+	ErrFixtureNotFound = errors.New("client: no recorded exchange matches the request")
+
+	// ErrBodyStreamSent is returned when a request with a body stream is sent
+	// again, such as by a middleware, as the stream was read by the first send.
+	ErrBodyStreamSent = errors.New("client: the body stream of the request was already sent")
 )
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"bytes"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -216,7 +218,16 @@ func parserRequestBody(c *Client, req *Request) error {
 	case formBody:
 		req.RawRequest.SetBody(req.formData.QueryString())
 	case filesBody:
+		if req.streamMultipart {
+			return parserRequestBodyFileStream(req)
+		}
 		return parserRequestBodyFile(req)
+	case streamBody:
+		if body, ok := req.body.(io.Reader); ok && body != nil {
+			req.RawRequest.SetBodyStream(body, req.bodySize)
+		} else {
+			return ErrBodyType
+		}
 	case rawBody:
 		if body, ok := req.body.([]byte); ok { //nolint:revive // ignore simplicity
 			req.RawRequest.SetBody(body)
@@ -276,29 +287,191 @@ func writeMultipartBody(mw *multipart.Writer, req *Request) error {
 	defer fileBufPool.Put(fileBuf)
 
 	for i, f := range req.files {
-		if f.name == "" && f.path == "" {
-			return ErrFileNoName
+		if err := prepareFormFile(i, f); err != nil {
+			return err
 		}
 
-		// Set the file name if not provided.
-		if f.name == "" && f.path != "" {
-			f.path = filepath.Clean(f.path)
-			f.name = filepath.Base(f.path)
+		if err := addFormFile(mw, f, fileBuf); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// prepareFormFile sets the file and field names of the i-th file of a request
+// when they are not provided.
+func prepareFormFile(i int, f *File) error {
+	if f.name == "" && f.path == "" {
+		return ErrFileNoName
+	}
+
+	// Set the file name if not provided.
+	if f.name == "" && f.path != "" {
+		f.path = filepath.Clean(f.path)
+		f.name = filepath.Base(f.path)
+	}
+
+	// Set the field name if not provided.
+	if f.fieldName == "" {
+		f.fieldName = "file" + strconv.Itoa(i+1)
+	}
+
+	return nil
+}
+
+// parserRequestBodyFileStream sets the multipart body of the files of req as
+// a stream. The form fields and the headers of the parts are written up front,
+// and the contents of the files are read from their readers as the body is
+// sent. The files are opened here, so that opening errors are returned before
+// the request is sent.
+func parserRequestBodyFileStream(req *Request) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary(req.boundary); err != nil {
+		return fmt.Errorf("set boundary error: %w", err)
+	}
+
+	stream := &multipartStream{}
+	if err := writeMultipartStream(mw, &buf, req, stream); err != nil {
+		stream.Close() //nolint:errcheck // the body write already failed; surface that error instead
+		return err
+	}
 
-		// Set the field name if not provided.
-		if f.fieldName == "" {
-			f.fieldName = "file" + strconv.Itoa(i+1)
+	req.RawRequest.SetBodyStream(stream, stream.size())
+	// The stream is new, unlike a stream set with SetBodyStream
+	req.bodyStreamSent = false
+	return nil
+}
+
+// writeMultipartStream writes the form fields of req and the parts of its
+// files to stream, buf holding what mw writes until it is added as a part.
+func writeMultipartStream(mw *multipart.Writer, buf *bytes.Buffer, req *Request, stream *multipartStream) error {
+	// Add form data.
+	for key, value := range req.formData.All() {
+		if err := mw.WriteField(utils.UnsafeString(key), utils.UnsafeString(value)); err != nil {
+			return fmt.Errorf("write formdata error: %w", err)
 		}
+	}
 
-		if err := addFormFile(mw, f, fileBuf); err != nil {
+	// Add files.
+	for i, f := range req.files {
+		if err := prepareFormFile(i, f); err != nil {
 			return err
 		}
+
+		// If reader is not set, open the file.
+		if f.reader == nil {
+			file, err := os.Open(f.path)
+			if err != nil {
+				return fmt.Errorf("open file error: %w", err)
+			}
+			f.reader = file
+		}
+		// The reader is owned by the stream from then on, and closed with it.
+		reader := f.reader
+		f.reader = nil
+		stream.closers = append(stream.closers, reader)
+
+		if _, err := mw.CreateFormFile(f.fieldName, f.name); err != nil {
+			return fmt.Errorf("create file error: %w", err)
+		}
+		stream.addPart(buf, reader)
+	}
+
+	// Close writes the trailing boundary.
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
+	stream.addPart(buf, nil)
 
 	return nil
 }
 
+// multipartStream is the streamed multipart body of the files of a request:
+// the written headers of each part, followed by the reader of its file.
+type multipartStream struct {
+	reader  io.Reader
+	readers []io.Reader
+	closers []io.Closer
+	// headerSize is the size of the written headers, and fileSize the size of
+	// the files, or -1 when one of them is unknown.
+	headerSize int
+	fileSize   int
+}
+
+// addPart adds the content of buf, followed by the file read from reader
+// unless it is nil, and resets buf.
+func (s *multipartStream) addPart(buf *bytes.Buffer, reader io.Reader) {
+	s.readers = append(s.readers, bytes.NewReader(bytes.Clone(buf.Bytes())))
+	s.headerSize += buf.Len()
+	buf.Reset()
+
+	if reader == nil {
+		return
+	}
+	s.readers = append(s.readers, reader)
+	if size := readerSize(reader); size < 0 || s.fileSize < 0 {
+		s.fileSize = -1
+	} else {
+		s.fileSize += size
+	}
+}
+
+// size returns the size of the body, or -1 when it is unknown.
+func (s *multipartStream) size() int {
+	if s.fileSize < 0 {
+		return -1
+	}
+	return s.headerSize + s.fileSize
+}
+
+// Read reads the parts of the body in turn.
+func (s *multipartStream) Read(b []byte) (int, error) {
+	if s.reader == nil {
+		s.reader = io.MultiReader(s.readers...)
+	}
+	return s.reader.Read(b) //nolint:wrapcheck // the errors of the files are returned as is, io.EOF included
+}
+
+// Close closes the files, which fasthttp does once a body stream is sent.
+func (s *multipartStream) Close() error {
+	var errs []error
+	for _, c := range s.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.closers = nil
+	return errors.Join(errs...)
+}
+
+// readerSize returns the number of bytes left to read from a file reader, or
+// -1 when it is unknown.
+func readerSize(reader io.Reader) int {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return r.Len()
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		size := info.Size()
+		// The reader may not be at the start of the file
+		if seeker, ok := reader.(io.Seeker); ok {
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return -1
+			}
+			size -= offset
+		}
+		return int(size)
+	default:
+		return -1
+	}
+}
+
 func addFormFile(mw *multipart.Writer, f *File, fileBuf *[]byte) error {
 	// If reader is not set, open the file.
 	if f.reader == nil {
@@ -599,6 +599,75 @@ func Test_Parser_Request_Body(t *testing.T) {
 		require.Contains(t, string(req.RawRequest.Body()), "bar")
 	})
 
+	t.Run("streamed file body", func(t *testing.T) {
+		t.Parallel()
+		client := New()
+		req := AcquireRequest().
+			SetStreamMultipart(true).
+			SetFormData("foo", "bar").
+			AddFileWithReader("hello", io.NopCloser(strings.NewReader("world")))
+
+		err := parserRequestBody(client, req)
+		require.NoError(t, err)
+		require.True(t, req.RawRequest.IsBodyStream())
+		// The size of a reader hidden by io.NopCloser is unknown
+		require.Equal(t, -1, req.RawRequest.Header.ContentLength())
+		require.Contains(t, string(req.RawRequest.Body()), "--FiberFormBoundary")
+		require.Contains(t, string(req.RawRequest.Body()), "world")
+		require.Contains(t, string(req.RawRequest.Body()), "bar")
+	})
+
+	t.Run("streamed file body size", func(t *testing.T) {
+		t.Parallel()
+		client := New()
+		req := AcquireRequest().
+			SetStreamMultipart(true).
+			SetFormData("foo", "bar").
+			AddFile("../.github/testdata/index.html")
+
+		err := parserRequestBody(client, req)
+		require.NoError(t, err)
+		size := req.RawRequest.Header.ContentLength()
+		require.Positive(t, size)
+		require.Len(t, req.RawRequest.Body(), size)
+	})
+
+	t.Run("streamed file body open error", func(t *testing.T) {
+		t.Parallel()
+		client := New()
+		closed := false
+		req := AcquireRequest().
+			SetStreamMultipart(true).
+			AddFileWithReader("hello", closeFunc{Reader: strings.NewReader("world"), close: func() { closed = true }}).
+			AddFile(filepath.Join(t.TempDir(), "missing.txt"))
+
+		err := parserRequestBody(client, req)
+		require.ErrorContains(t, err, "open file error")
+		require.True(t, closed)
+	})
+
+	t.Run("stream body", func(t *testing.T) {
+		t.Parallel()
+		client := New()
+		req := AcquireRequest().
+			SetBodyStream(strings.NewReader("hello world"), -1)
+
+		err := parserRequestBody(client, req)
+		require.NoError(t, err)
+		require.Equal(t, -1, req.RawRequest.Header.ContentLength())
+		require.Equal(t, []byte("hello world"), req.RawRequest.Body())
+	})
+
+	t.Run("stream body error", func(t *testing.T) {
+		t.Parallel()
+		client := New()
+		req := AcquireRequest().
+			SetBodyStream(nil, -1)
+
+		err := parserRequestBody(client, req)
+		require.ErrorIs(t, err, ErrBodyType)
+	})
+
 	t.Run("raw body", func(t *testing.T) {
 		t.Parallel()
 		client := New()
@@ -822,3 +891,14 @@ func releaseBenchmarkRequest(req *Request) {
 		ReleaseFile(f)
 	}
 }
+
+// closeFunc is an io.ReadCloser calling close when it is closed.
+type closeFunc struct {
+	io.Reader
+	close func()
+}
+
+func (c closeFunc) Close() error {
+	c.close()
+	return nil
+}
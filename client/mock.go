@@ -0,0 +1,204 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// MockTransport is an in-memory Transport answering the requests with canned
+// responses, to test the code using a Client without a server.
+//
+// The mocks are matched in the order they are added: a request is answered by
+// the first mock matching it whose Times are not used up. A request matching
+// no mock fails with ErrNoMockMatch.
+type MockTransport struct {
+	mocks []*Mock
+	mu    sync.Mutex
+}
+
+// NewMockTransport creates a MockTransport without mocks.
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+// On adds a mock for the requests of a method to a URL, and returns it to
+// configure its response. An empty method matches every method. A URL
+// starting with "/" matches the path and query of the requests, and any other
+// URL their full URL.
+func (t *MockTransport) On(method, url string) *Mock {
+	m := &Mock{
+		mu:     &t.mu,
+		method: strings.ToUpper(method),
+		url:    url,
+		status: fasthttp.StatusOK,
+	}
+
+	t.mu.Lock()
+	t.mocks = append(t.mocks, m)
+	t.mu.Unlock()
+	return m
+}
+
+// Reset removes all the mocks.
+func (t *MockTransport) Reset() {
+	t.mu.Lock()
+	t.mocks = nil
+	t.mu.Unlock()
+}
+
+// Pending returns the mocks expecting more calls: the ones which were never
+// called, and the ones whose Times are not used up.
+func (t *MockTransport) Pending() []*Mock {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var pending []*Mock
+	for _, m := range t.mocks {
+		if m.calls == 0 || (m.times > 0 && m.calls < m.times) {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Do answers the request with the first mock matching it.
+func (t *MockTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := bufferBody(req); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	var match *Mock
+	for _, m := range t.mocks {
+		if (m.times == 0 || m.calls < m.times) && m.matches(req) {
+			m.calls++
+			match = m
+			break
+		}
+	}
+	t.mu.Unlock()
+
+	if match == nil {
+		return fmt.Errorf("%w: %s %s", ErrNoMockMatch, req.Header.Method(), req.URI().FullURI())
+	}
+	return match.respond(resp)
+}
+
+// Mock is a canned response of a MockTransport, and the requests it answers.
+// Configure it before sending the requests.
+type Mock struct {
+	err        error
+	mu         *sync.Mutex
+	method     string
+	url        string
+	header     [][2]string
+	respHeader [][2]string
+	body       []byte
+	respBody   []byte
+	status     int
+	times      int
+	calls      int
+	matchBody  bool
+}
+
+// WithHeader restricts the mock to the requests having a header with the
+// value.
+func (m *Mock) WithHeader(key, value string) *Mock {
+	m.header = append(m.header, [2]string{key, value})
+	return m
+}
+
+// WithBody restricts the mock to the requests having the body.
+func (m *Mock) WithBody(body []byte) *Mock {
+	m.body = body
+	m.matchBody = true
+	return m
+}
+
+// Reply sets the status code and the body of the response. The default
+// response is a 200 OK without body.
+func (m *Mock) Reply(status int, body []byte) *Mock {
+	m.status = status
+	m.respBody = body
+	return m
+}
+
+// ReplyString sets the status code and the body of the response.
+func (m *Mock) ReplyString(status int, body string) *Mock {
+	return m.Reply(status, []byte(body))
+}
+
+// ReplyHeader adds a header to the response.
+func (m *Mock) ReplyHeader(key, value string) *Mock {
+	m.respHeader = append(m.respHeader, [2]string{key, value})
+	return m
+}
+
+// ReplyError makes the requests fail with err, as on a connection error.
+func (m *Mock) ReplyError(err error) *Mock {
+	m.err = err
+	return m
+}
+
+// Times restricts the mock to n requests. The default 0 answers any number
+// of requests.
+func (m *Mock) Times(n int) *Mock {
+	m.times = n
+	return m
+}
+
+// Calls returns the number of requests answered by the mock.
+func (m *Mock) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls
+}
+
+// String returns the method and the URL of the mock.
+func (m *Mock) String() string {
+	method := m.method
+	if method == "" {
+		method = "*"
+	}
+	return method + " " + m.url
+}
+
+func (m *Mock) matches(req *fasthttp.Request) bool {
+	if m.method != "" && m.method != string(req.Header.Method()) {
+		return false
+	}
+
+	if strings.HasPrefix(m.url, "/") {
+		if m.url != string(req.URI().RequestURI()) {
+			return false
+		}
+	} else if m.url != string(req.URI().FullURI()) {
+		return false
+	}
+
+	for _, h := range m.header {
+		if string(req.Header.Peek(h[0])) != h[1] {
+			return false
+		}
+	}
+
+	return !m.matchBody || bytes.Equal(m.body, req.Body())
+}
+
+func (m *Mock) respond(resp *fasthttp.Response) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	resp.SetStatusCode(m.status)
+	for _, h := range m.respHeader {
+		resp.Header.Add(h[0], h[1])
+	}
+	resp.SetBody(m.respBody)
+	return nil
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func Test_MockTransport(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockTransport()
+	users := mock.On(fiber.MethodGet, "/users/1").
+		ReplyString(fiber.StatusOK, `{"name":"john"}`).
+		ReplyHeader(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	mock.On(fiber.MethodGet, "http://example.com/users/2").ReplyString(fiber.StatusNotFound, "not found")
+	created := mock.On("post", "/users").
+		WithHeader(fiber.HeaderAuthorization, "Bearer token").
+		WithBody([]byte(`{"name":"jane"}`)).
+		ReplyString(fiber.StatusCreated, "created").
+		Times(1)
+	mock.On("", "/any").ReplyHeader(fiber.HeaderSetCookie, "a=1").ReplyHeader(fiber.HeaderSetCookie, "b=2")
+	errDown := errors.New("connection refused")
+	mock.On(fiber.MethodGet, "/down").ReplyError(errDown)
+
+	cc := NewWithTransport(mock)
+	require.Same(t, mock, cc.Transport())
+
+	var user struct {
+		Name string `json:"name"`
+	}
+	resp, err := cc.Get("http://example.com/users/1")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, fiber.MIMEApplicationJSON, resp.Header(fiber.HeaderContentType))
+	require.NoError(t, resp.JSON(&user))
+	require.Equal(t, "john", user.Name)
+	resp.Close()
+	require.Equal(t, 1, users.Calls())
+
+	resp, err = cc.Get("http://example.com/users/2")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode())
+	resp.Close()
+
+	// The headers and the body are matched
+	_, err = cc.Post("http://example.com/users", Config{Body: map[string]string{"name": "jane"}})
+	require.ErrorIs(t, err, ErrNoMockMatch)
+	require.ErrorContains(t, err, "POST http://example.com/users")
+
+	req := cc.R().SetHeader(fiber.HeaderAuthorization, "Bearer token").SetRawBody([]byte(`{"name":"jane"}`))
+	resp, err = req.Post("http://example.com/users")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode())
+	require.Equal(t, "created", resp.String())
+	resp.Close()
+
+	// Times are used up
+	req = cc.R().SetHeader(fiber.HeaderAuthorization, "Bearer token").SetRawBody([]byte(`{"name":"jane"}`))
+	_, err = req.Post("http://example.com/users")
+	require.ErrorIs(t, err, ErrNoMockMatch)
+	ReleaseRequest(req)
+	require.Equal(t, 1, created.Calls())
+
+	resp, err = cc.Delete("http://example.com/any")
+	require.NoError(t, err)
+	require.Len(t, resp.Cookies(), 2)
+	resp.Close()
+
+	_, err = cc.Get("http://example.com/down")
+	require.ErrorIs(t, err, errDown)
+
+	require.Empty(t, mock.Pending())
+	mock.Reset()
+	_, err = cc.Get("http://example.com/users/1")
+	require.ErrorIs(t, err, ErrNoMockMatch)
+}
+
+func Test_MockTransport_Pending(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockTransport()
+	mock.On(fiber.MethodGet, "/a")
+	twice := mock.On(fiber.MethodGet, "/b").Times(2)
+	require.Equal(t, "GET /b", twice.String())
+	require.Equal(t, "* /c", mock.On("", "/c").String())
+
+	cc := NewWithTransport(mock)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		resp, err := cc.Get("http://example.com" + path)
+		require.NoError(t, err)
+		resp.Close()
+	}
+	require.Equal(t, []*Mock{twice}, mock.Pending())
+}
+
+func Test_MockTransport_BodyStream(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockTransport()
+	mock.On(fiber.MethodPost, "/upload").WithBody([]byte("streamed")).ReplyString(fiber.StatusOK, "ok")
+
+	cc := NewWithTransport(mock)
+	req := cc.R()
+	req.RawRequest.SetBodyStream(strings.NewReader("streamed"), -1)
+	resp, err := req.Post("http://example.com/upload")
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp.String())
+	resp.Close()
+}
+
+func Test_MockTransport_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockTransport()
+	m := mock.On(fiber.MethodGet, "/").ReplyString(fiber.StatusOK, "ok")
+	cc := NewWithTransport(mock)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			resp, err := cc.Get("http://example.com/")
+			if err == nil {
+				resp.Close()
+			}
+		})
+	}
+	wg.Wait()
+	require.Equal(t, 10, m.Calls())
+}
@@ -0,0 +1,43 @@
+package client
+
+import "io"
+
+// ProgressFunc reports the progress of an upload or a download: the number
+// of body bytes transferred so far, and the size of the whole body, or -1
+// when it is unknown. It is called from the goroutine reading the body, after
+// each read.
+type ProgressFunc func(transferred, total int64)
+
+// progressReader is an io.Reader reporting the bytes read from a body to a
+// ProgressFunc.
+type progressReader struct {
+	reader      io.Reader
+	progress    ProgressFunc
+	total       int64
+	transferred int64
+}
+
+func newProgressReader(reader io.Reader, total int64, progress ProgressFunc) *progressReader {
+	if total < 0 {
+		total = -1
+	}
+	return &progressReader{reader: reader, progress: progress, total: total}
+}
+
+// Read reads from the body, and reports the bytes read.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if n > 0 {
+		p.transferred += int64(n)
+		p.progress(p.transferred, p.total)
+	}
+	return n, err //nolint:wrapcheck // the errors of the body are returned as is, io.EOF included
+}
+
+// Close closes the body, which fasthttp does once a body stream is sent.
+func (p *progressReader) Close() error {
+	if closer, ok := p.reader.(io.Closer); ok {
+		return closer.Close() //nolint:wrapcheck // the errors of the body are returned as is
+	}
+	return nil
+}
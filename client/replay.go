@@ -0,0 +1,253 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ReplayMode defines whether a ReplayTransport replays or records exchanges.
+type ReplayMode int
+
+const (
+	// ReplayOnly replays the recorded exchanges, and fails the requests
+	// which were not recorded with ErrFixtureNotFound.
+	ReplayOnly ReplayMode = iota
+	// ReplayOrRecord replays the recorded exchanges, and records the
+	// requests which were not recorded.
+	ReplayOrRecord
+	// RecordOnly sends every request and records it, overwriting the
+	// exchange recorded before.
+	RecordOnly
+)
+
+// ReplayConfig defines the config of a ReplayTransport.
+type ReplayConfig struct {
+	// Transport sends the requests to record, such as a &fasthttp.Client{}.
+	//
+	// Required unless Mode is ReplayOnly.
+	Transport Transport
+
+	// Dir is the directory of the recorded exchanges, one JSON file per
+	// exchange.
+	//
+	// Required.
+	Dir string
+
+	// MatchHeaders lists the request headers which, besides the method, the
+	// URL and the body, tell the requests apart. They are the only request
+	// headers recorded.
+	//
+	// Optional. Default: nil
+	MatchHeaders []string
+
+	// Redact is called with each exchange before it is written, to remove
+	// the secrets it holds, such as API keys in the URL or an OAuth
+	// client_secret in the request body. The file of an exchange is named
+	// after the request as it was sent, so redacting it does not change how
+	// it is matched. Set-Cookie response headers are never recorded.
+	//
+	// Optional. Default: nil
+	Redact func(exchange *RecordedExchange)
+
+	// Mode defines whether the requests are replayed or recorded.
+	//
+	// Optional. Default: ReplayOnly
+	Mode ReplayMode
+}
+
+// ReplayTransport is a Transport recording the exchanges of a Transport to
+// golden files, and replaying them without sending the requests. Record the
+// exchanges once with RecordOnly, commit the files, then replay them offline
+// in tests with ReplayOnly.
+type ReplayTransport struct {
+	cfg ReplayConfig
+	mu  sync.Mutex
+}
+
+// NewReplayTransport creates a ReplayTransport.
+func NewReplayTransport(config ReplayConfig) *ReplayTransport {
+	if config.Dir == "" {
+		panic("client: ReplayConfig.Dir is required")
+	}
+	if config.Mode != ReplayOnly && config.Transport == nil {
+		panic("client: ReplayConfig.Transport is required to record")
+	}
+	config.MatchHeaders = append([]string(nil), config.MatchHeaders...)
+	return &ReplayTransport{cfg: config}
+}
+
+// RecordedExchange is an exchange written to a file by a ReplayTransport.
+type RecordedExchange struct {
+	Request  RecordedMessage `json:"request"`
+	Response RecordedMessage `json:"response"`
+}
+
+// RecordedMessage is a recorded request or response. Bodies which are not
+// valid UTF-8 are encoded in base64, as set in Encoding.
+type RecordedMessage struct {
+	Method   string      `json:"method,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	Encoding string      `json:"encoding,omitempty"`
+	Body     string      `json:"body,omitempty"`
+	Header   [][2]string `json:"header,omitempty"`
+	Status   int         `json:"status,omitempty"`
+}
+
+// Do replays the exchange recorded for the request, or sends and records it.
+func (t *ReplayTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := bufferBody(req); err != nil {
+		return err
+	}
+	path := filepath.Join(t.cfg.Dir, t.fixtureName(req))
+
+	if t.cfg.Mode != RecordOnly {
+		data, err := os.ReadFile(path) //nolint:gosec // the path is built from a hash
+		switch {
+		case err == nil:
+			return replay(data, resp)
+		case !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("client: failed to read recorded exchange: %w", err)
+		case t.cfg.Mode == ReplayOnly:
+			return fmt.Errorf("%w: %s %s", ErrFixtureNotFound, req.Header.Method(), req.URI().FullURI())
+		default:
+			// Record the request
+		}
+	}
+
+	if err := t.cfg.Transport.Do(req, resp); err != nil {
+		return err
+	}
+	return t.record(path, req, resp)
+}
+
+// fixtureName returns the name of the file of the exchange of a request,
+// made of its URL, for readability, and of a hash of what tells it apart.
+func (t *ReplayTransport) fixtureName(req *fasthttp.Request) string {
+	h := sha256.New()
+	h.Write(req.Header.Method()) //nolint:errcheck // hash.Hash.Write for std hashes never errors
+	h.Write([]byte{0})           //nolint:errcheck // hash.Hash.Write for std hashes never errors
+	h.Write(req.URI().FullURI()) //nolint:errcheck // hash.Hash.Write for std hashes never errors
+	for _, name := range t.cfg.MatchHeaders {
+		h.Write([]byte{0})             //nolint:errcheck // hash.Hash.Write for std hashes never errors
+		h.Write(req.Header.Peek(name)) //nolint:errcheck // hash.Hash.Write for std hashes never errors
+	}
+	h.Write([]byte{0})  //nolint:errcheck // hash.Hash.Write for std hashes never errors
+	h.Write(req.Body()) //nolint:errcheck // hash.Hash.Write for std hashes never errors
+
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, string(req.URI().Host())+string(req.URI().Path()))
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return strings.ToLower(string(req.Header.Method())) + "_" + name + "_" + hex.EncodeToString(h.Sum(nil)[:8]) + ".json"
+}
+
+// record writes the exchange of a request to path.
+func (t *ReplayTransport) record(path string, req *fasthttp.Request, resp *fasthttp.Response) error {
+	f := RecordedExchange{
+		Request: RecordedMessage{
+			Method: string(req.Header.Method()),
+			URL:    string(req.URI().FullURI()),
+		},
+		Response: RecordedMessage{
+			Status: resp.StatusCode(),
+		},
+	}
+	for _, name := range t.cfg.MatchHeaders {
+		if v := req.Header.Peek(name); v != nil {
+			f.Request.Header = append(f.Request.Header, [2]string{name, string(v)})
+		}
+	}
+	f.Request.Body, f.Request.Encoding = encodeFixtureBody(req.Body())
+
+	for k, v := range resp.Header.All() {
+		if !replayedHeader(string(k)) {
+			continue
+		}
+		f.Response.Header = append(f.Response.Header, [2]string{string(k), string(v)})
+	}
+	// Body reads the stream of a streamed response
+	f.Response.Body, f.Response.Encoding = encodeFixtureBody(resp.Body())
+	if t.cfg.Redact != nil {
+		t.cfg.Redact(&f)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("client: failed to encode recorded exchange: %w", err)
+	}
+	data = append(data, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(t.cfg.Dir, 0o750); err != nil {
+		return fmt.Errorf("client: failed to record exchange: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("client: failed to record exchange: %w", err)
+	}
+	return nil
+}
+
+// replay fills resp with a recorded exchange.
+func replay(data []byte, resp *fasthttp.Response) error {
+	var f RecordedExchange
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("client: failed to decode recorded exchange: %w", err)
+	}
+	body, err := decodeFixtureBody(f.Response.Body, f.Response.Encoding)
+	if err != nil {
+		return fmt.Errorf("client: failed to decode recorded exchange: %w", err)
+	}
+
+	resp.SetStatusCode(f.Response.Status)
+	for _, h := range f.Response.Header {
+		resp.Header.Add(h[0], h[1])
+	}
+	resp.SetBody(body)
+	return nil
+}
+
+// replayedHeader reports whether a response header is recorded. The headers
+// describing the framing of the body are set again when it is replayed, and
+// Set-Cookie holds session credentials.
+func replayedHeader(name string) bool {
+	return !strings.EqualFold(name, fasthttp.HeaderSetCookie) &&
+		!strings.EqualFold(name, fasthttp.HeaderContentLength) &&
+		!strings.EqualFold(name, fasthttp.HeaderTransferEncoding) &&
+		!strings.EqualFold(name, fasthttp.HeaderConnection)
+}
+
+func encodeFixtureBody(body []byte) (data, encoding string) { //nolint:nonamedreturns // names document the results
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeFixtureBody(data, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(data), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(data)
+	default:
+		return nil, fmt.Errorf("unknown body encoding %q", encoding)
+	}
+}
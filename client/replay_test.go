@@ -0,0 +1,187 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func Test_ReplayTransport(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	app := fiber.New()
+	app.Get("/users/:id", func(c fiber.Ctx) error {
+		hits.Add(1)
+		c.Set("X-Lang", c.Get(fiber.HeaderAcceptLanguage))
+		c.Cookie(&fiber.Cookie{Name: "a", Value: "1"})
+		c.Cookie(&fiber.Cookie{Name: "b", Value: "2"})
+		return c.JSON(fiber.Map{"id": c.Params("id")})
+	})
+	app.Post("/binary", func(c fiber.Ctx) error {
+		hits.Add(1)
+		return c.Send(append([]byte{0xff, 0x00}, c.Body()...))
+	})
+
+	dir := t.TempDir()
+	get := func(cc *Client, url, lang string) *Response {
+		t.Helper()
+		resp, err := cc.R().SetHeader(fiber.HeaderAcceptLanguage, lang).SetHeader(fiber.HeaderAuthorization, "secret").Get(url)
+		require.NoError(t, err)
+		return resp
+	}
+
+	recorder := NewWithTransport(NewReplayTransport(ReplayConfig{
+		Transport:    NewAppTransport(app),
+		Dir:          dir,
+		Mode:         RecordOnly,
+		MatchHeaders: []string{fiber.HeaderAcceptLanguage},
+	}))
+	get(recorder, "http://example.com/users/1", "en").Close()
+	get(recorder, "http://example.com/users/1", "fr").Close()
+	resp, err := recorder.Post("http://example.com/binary", Config{Body: []byte{0x01}})
+	require.NoError(t, err)
+	resp.Close()
+	require.Equal(t, int32(3), hits.Load())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	for _, f := range files {
+		require.True(t, strings.HasPrefix(f.Name(), "get_example.com_users_1_") || strings.HasPrefix(f.Name(), "post_example.com_binary_"), f.Name())
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		require.NoError(t, err)
+		// Only the matched request headers are recorded, and no cookie
+		require.NotContains(t, string(data), "secret")
+		require.NotContains(t, string(data), fiber.HeaderSetCookie)
+	}
+
+	replayer := NewWithTransport(NewReplayTransport(ReplayConfig{
+		Dir:          dir,
+		MatchHeaders: []string{fiber.HeaderAcceptLanguage},
+	}))
+	resp = get(replayer, "http://example.com/users/1", "fr")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.JSONEq(t, `{"id":"1"}`, resp.String())
+	require.Equal(t, fiber.MIMEApplicationJSONCharsetUTF8, resp.Header(fiber.HeaderContentType))
+	require.Equal(t, "fr", resp.Header("X-Lang"))
+	// Set-Cookie headers are not recorded
+	require.Empty(t, resp.Cookies())
+	resp.Close()
+
+	resp, err = replayer.Post("http://example.com/binary", Config{Body: []byte{0x01}})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xff, 0x00, 0x01}, resp.Body())
+	resp.Close()
+	require.Equal(t, int32(3), hits.Load())
+
+	// A request which was not recorded
+	_, err = replayer.Get("http://example.com/users/2")
+	require.ErrorIs(t, err, ErrFixtureNotFound)
+	_, err = replayer.R().SetHeader(fiber.HeaderAcceptLanguage, "de").Get("http://example.com/users/1")
+	require.ErrorIs(t, err, ErrFixtureNotFound)
+
+	both := NewWithTransport(NewReplayTransport(ReplayConfig{
+		Transport:    NewAppTransport(app),
+		Dir:          dir,
+		Mode:         ReplayOrRecord,
+		MatchHeaders: []string{fiber.HeaderAcceptLanguage},
+	}))
+	get(both, "http://example.com/users/1", "en").Close()
+	require.Equal(t, int32(3), hits.Load())
+	get(both, "http://example.com/users/2", "en").Close()
+	get(both, "http://example.com/users/2", "en").Close()
+	require.Equal(t, int32(4), hits.Load())
+}
+
+func Test_ReplayTransport_Redact(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Post("/token", func(c fiber.Ctx) error {
+		return c.SendString("token for " + c.Query("api_key"))
+	})
+
+	dir := t.TempDir()
+	redact := func(exchange *RecordedExchange) {
+		exchange.Request.URL = strings.ReplaceAll(exchange.Request.URL, "key123", "REDACTED")
+		exchange.Request.Body = strings.ReplaceAll(exchange.Request.Body, "s3cr3t", "REDACTED")
+		exchange.Response.Body = strings.ReplaceAll(exchange.Response.Body, "key123", "REDACTED")
+	}
+	send := func(cc *Client) *Response {
+		t.Helper()
+		resp, err := cc.Post("http://example.com/token?api_key=key123", Config{Body: "client_secret=s3cr3t"})
+		require.NoError(t, err)
+		return resp
+	}
+
+	recorder := NewWithTransport(NewReplayTransport(ReplayConfig{
+		Transport: NewAppTransport(app),
+		Dir:       dir,
+		Mode:      RecordOnly,
+		Redact:    redact,
+	}))
+	resp := send(recorder)
+	// The response of the recording request is not redacted
+	require.Equal(t, "token for key123", resp.String())
+	resp.Close()
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "key123")
+	require.NotContains(t, string(data), "s3cr3t")
+
+	// The redacted exchange is still matched by the request as sent
+	replayer := NewWithTransport(NewReplayTransport(ReplayConfig{Dir: dir}))
+	resp = send(replayer)
+	require.Equal(t, "token for REDACTED", resp.String())
+	resp.Close()
+}
+
+func Test_ReplayTransport_InvalidFixture(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockTransport()
+	mock.On(fiber.MethodGet, "/").ReplyString(fiber.StatusOK, "ok")
+	dir := t.TempDir()
+	recorder := NewWithTransport(NewReplayTransport(ReplayConfig{Transport: mock, Dir: dir, Mode: RecordOnly}))
+	resp, err := recorder.Get("http://example.com/")
+	require.NoError(t, err)
+	resp.Close()
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	path := filepath.Join(dir, files[0].Name())
+
+	replayer := NewWithTransport(NewReplayTransport(ReplayConfig{Dir: dir}))
+	for _, data := range []string{
+		"{",
+		`{"response":{"status":200,"body":"%%","encoding":"base64"}}`,
+		`{"response":{"status":200,"body":"ok","encoding":"gzip"}}`,
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		_, err = replayer.Get("http://example.com/")
+		require.ErrorContains(t, err, "failed to decode recorded exchange", data)
+	}
+}
+
+func Test_NewReplayTransport_Panics(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "client: ReplayConfig.Dir is required", func() {
+		NewReplayTransport(ReplayConfig{})
+	})
+	require.PanicsWithValue(t, "client: ReplayConfig.Transport is required to record", func() {
+		NewReplayTransport(ReplayConfig{Dir: t.TempDir(), Mode: ReplayOrRecord})
+	})
+}
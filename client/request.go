@@ -37,6 +37,7 @@ const (
 	filesBody
 	rawBody
 	cborBody
+	streamBody
 )
 
 var ErrClientNil = errors.New("client cannot be nil")
@@ -63,12 +64,29 @@ type Request struct {
 	referer    string
 	files      []*File
 
+	uploadProgress   ProgressFunc
+	downloadProgress ProgressFunc
+
 	timeout      time.Duration
 	maxRedirects int
+	// sseMaxLineSize is the maximum size of a line of an event stream, 0 for
+	// the default.
+	sseMaxLineSize int
+	// bodySize is the size of a stream body, or -1 when it is unknown.
+	bodySize int
 
 	bodyType bodyType
 
 	isPathNormalizingDisabled bool
+	// streamMultipart streams the multipart body of the files instead of
+	// buffering it.
+	streamMultipart bool
+	// bodyStreamSent records that the body stream was sent, as it cannot be
+	// sent again.
+	bodyStreamSent bool
+	// streamResponseBody streams the response body of this request whatever
+	// the setting of the client, for event streams.
+	streamResponseBody bool
 }
 
 // Method returns the HTTP method set in the Request.
@@ -432,6 +450,52 @@ func (r *Request) SetRawBody(v []byte) *Request {
 	return r
 }
 
+// SetBodyStream sets the request body to a stream, which is read while the
+// request is sent instead of being loaded into memory. size is the size of
+// the body, or -1 when it is unknown, in which case the body is sent with
+// chunked transfer encoding. An io.Closer is closed once the request is sent,
+// or has failed.
+//
+// A stream can only be read once, so the request is not retried, and sending
+// it again, such as from a middleware, returns ErrBodyStreamSent.
+func (r *Request) SetBodyStream(reader io.Reader, size int) *Request {
+	r.body = reader
+	r.bodySize = size
+	r.bodyType = streamBody
+	return r
+}
+
+// SetStreamMultipart enables or disables the streaming of the multipart body
+// of the files added to the request. A streamed body is read from the files
+// while the request is sent instead of being loaded into memory. Its
+// Content-Length is set when the size of every file is known, from the files
+// opened by path or the readers with a Stat or Len method, and it is sent with
+// chunked transfer encoding otherwise.
+//
+// The files are opened by the request hooks, and closed once the request is
+// sent, or has failed. A stream can only be read once, so the request is not
+// retried, and sending it again from a middleware returns ErrBodyStreamSent.
+func (r *Request) SetStreamMultipart(enable bool) *Request {
+	r.streamMultipart = enable
+	return r
+}
+
+// SetUploadProgress sets a function reporting the progress of the upload of
+// the request body.
+func (r *Request) SetUploadProgress(progress ProgressFunc) *Request {
+	r.uploadProgress = progress
+	return r
+}
+
+// SetDownloadProgress sets a function reporting the progress of the download
+// of the response body, as it is read. The response body is streamed from the
+// connection, so the response is not stored in the response cache of the
+// client.
+func (r *Request) SetDownloadProgress(progress ProgressFunc) *Request {
+	r.downloadProgress = progress
+	return r
+}
+
 // resetBody clears the existing body. If the current body type is filesBody and
 // the new type is formBody, the formBody setting is ignored to preserve files.
 func (r *Request) resetBody(t bodyType) {
@@ -609,6 +673,20 @@ func (r *Request) SetMaxRedirects(count int) *Request {
 	return r
 }
 
+// SSEMaxLineSize returns the maximum size of a line of the event stream read
+// by SSE, or 0 for the default of 1 MB.
+func (r *Request) SSEMaxLineSize() int {
+	return r.sseMaxLineSize
+}
+
+// SetSSEMaxLineSize sets the maximum size of a line of the event stream read
+// by SSE, such as a data field. A longer line ends the stream with an error
+// wrapping bufio.ErrTooLong. Zero or less restores the default of 1 MB.
+func (r *Request) SetSSEMaxLineSize(size int) *Request {
+	r.sseMaxLineSize = size
+	return r
+}
+
 // DisablePathNormalizing reports whether path normalizing is disabled for the Request.
 func (r *Request) DisablePathNormalizing() bool {
 	return r.isPathNormalizingDisabled
@@ -690,9 +768,16 @@ func (r *Request) Reset() {
 	r.body = nil
 	r.timeout = 0
 	r.maxRedirects = 0
+	r.sseMaxLineSize = 0
+	r.bodySize = 0
+	r.uploadProgress = nil
+	r.downloadProgress = nil
 	r.bodyType = noBody
 	r.boundary = boundary
 	r.isPathNormalizingDisabled = false
+	r.streamMultipart = false
+	r.bodyStreamSent = false
+	r.streamResponseBody = false
 
 	for len(r.files) != 0 {
 		t := r.files[0]
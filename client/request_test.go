@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"maps"
 	"mime/multipart"
@@ -11,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -1457,6 +1459,276 @@ func Test_Request_Body_With_Server(t *testing.T) {
 			"hello",
 		)
 	})
+
+	t.Run("stream body", func(t *testing.T) {
+		t.Parallel()
+		testRequest(
+			t,
+			func(c fiber.Ctx) error {
+				return c.SendString(strconv.Itoa(c.Request().Header.ContentLength()) + " " + string(c.Request().Body()))
+			},
+			func(agent *Request) {
+				agent.SetBodyStream(strings.NewReader("hello"), 5)
+			},
+			"5 hello",
+		)
+	})
+
+	t.Run("stream body with unknown size", func(t *testing.T) {
+		t.Parallel()
+		testRequest(
+			t,
+			func(c fiber.Ctx) error {
+				return c.SendString(c.Get(fiber.HeaderTransferEncoding) + " " + string(c.Request().Body()))
+			},
+			func(agent *Request) {
+				agent.SetBodyStream(io.MultiReader(strings.NewReader("hel"), strings.NewReader("lo")), -1)
+			},
+			"chunked hello",
+		)
+	})
+
+	t.Run("streamed multipart form send file", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Post("/", func(c fiber.Ctx) error {
+			form, err := c.MultipartForm()
+			if err != nil {
+				return err
+			}
+			fh, err := c.FormFile("file2")
+			if err != nil {
+				return err
+			}
+			f, err := fh.Open()
+			if err != nil {
+				return err
+			}
+			defer f.Close() //nolint:errcheck // the file is only read
+			b, err := io.ReadAll(f)
+			if err != nil {
+				return err
+			}
+			return c.SendString(fmt.Sprintf("%d %s %s %s %s",
+				c.Request().Header.ContentLength(), c.Get(fiber.HeaderContentType),
+				form.Value["foo"][0], form.File["field1"][0].Filename, b))
+		})
+
+		go start()
+
+		client := New().SetDial(ln)
+
+		req := AcquireRequest().
+			SetClient(client).
+			SetStreamMultipart(true).
+			SetFormData("foo", "bar").
+			AddFiles(
+				AcquireFile(
+					SetFileFieldName("field1"),
+					SetFileName("name"),
+					// io.NopCloser hides the size of the reader
+					SetFileReader(io.NopCloser(bytes.NewReader([]byte("form file")))),
+				),
+			).
+			AddFileWithReader("hello.txt", io.NopCloser(strings.NewReader("world"))).
+			SetBoundary("myBoundary")
+
+		resp, err := req.Post("http://example.com")
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode())
+		require.Equal(t, "-1 multipart/form-data; boundary=myBoundary bar name world", resp.String())
+		resp.Close()
+	})
+
+	t.Run("streamed multipart form send file with size", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Post("/", func(c fiber.Ctx) error {
+			if _, err := c.MultipartForm(); err != nil {
+				return err
+			}
+			return c.SendString(strconv.Itoa(c.Request().Header.ContentLength()))
+		})
+
+		go start()
+
+		client := New().SetDial(ln)
+
+		content, err := os.ReadFile("../.github/testdata/index.html")
+		require.NoError(t, err)
+
+		var expected bytes.Buffer
+		mw := multipart.NewWriter(&expected)
+		require.NoError(t, mw.SetBoundary("myBoundary"))
+		require.NoError(t, mw.WriteField("foo", "bar"))
+		w, err := mw.CreateFormFile("file1", "index.html")
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		req := AcquireRequest().
+			SetClient(client).
+			SetStreamMultipart(true).
+			SetFormData("foo", "bar").
+			AddFile("../.github/testdata/index.html").
+			SetBoundary("myBoundary")
+
+		resp, err := req.Post("http://example.com")
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode())
+		require.Equal(t, strconv.Itoa(expected.Len()), resp.String())
+		resp.Close()
+	})
+
+	t.Run("stream body sent twice", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Post("/", func(c fiber.Ctx) error {
+			return c.Send(c.Request().Body())
+		})
+
+		go start()
+
+		client := New().SetDial(ln)
+		client.Use(func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				resp, err := next(req)
+				if err != nil {
+					return nil, err
+				}
+				ReleaseResponse(resp)
+				return next(req)
+			}
+		})
+
+		_, err := AcquireRequest().
+			SetClient(client).
+			SetBodyStream(strings.NewReader("hello"), 5).
+			Post("http://example.com")
+		require.ErrorIs(t, err, ErrBodyStreamSent)
+	})
+}
+
+func Test_Request_Progress_With_Server(t *testing.T) {
+	t.Parallel()
+
+	t.Run("upload", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Post("/", func(c fiber.Ctx) error {
+			return c.SendString(strconv.Itoa(len(c.Request().Body())))
+		})
+
+		go start()
+
+		var transferred, total int64
+		req := AcquireRequest().
+			SetClient(New().SetDial(ln)).
+			SetRawBody(bytes.Repeat([]byte("a"), 1<<16)).
+			SetUploadProgress(func(n, size int64) {
+				transferred, total = n, size
+			})
+
+		resp, err := req.Post("http://example.com")
+		require.NoError(t, err)
+		require.Equal(t, "65536", resp.String())
+		require.Equal(t, int64(1<<16), transferred)
+		require.Equal(t, int64(1<<16), total)
+		resp.Close()
+	})
+
+	t.Run("upload of a stream with unknown size", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Post("/", func(c fiber.Ctx) error {
+			return c.SendString(strconv.Itoa(len(c.Request().Body())))
+		})
+
+		go start()
+
+		var transferred, total int64
+		req := AcquireRequest().
+			SetClient(New().SetDial(ln)).
+			SetBodyStream(io.LimitReader(zeroReader{}, 1<<16), -1).
+			SetUploadProgress(func(n, size int64) {
+				transferred, total = n, size
+			})
+
+		resp, err := req.Post("http://example.com")
+		require.NoError(t, err)
+		require.Equal(t, "65536", resp.String())
+		require.Equal(t, int64(1<<16), transferred)
+		require.Equal(t, int64(-1), total)
+		resp.Close()
+	})
+
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.Send(bytes.Repeat([]byte("a"), 1<<16))
+		})
+
+		go start()
+
+		var transferred, total int64
+		req := AcquireRequest().
+			SetClient(New().SetDial(ln)).
+			SetDownloadProgress(func(n, size int64) {
+				transferred, total = n, size
+			})
+
+		resp, err := req.Get("http://example.com")
+		require.NoError(t, err)
+		require.Len(t, resp.Body(), 1<<16)
+		require.Equal(t, int64(1<<16), transferred)
+		require.Equal(t, int64(1<<16), total)
+		resp.Close()
+	})
+
+	t.Run("download of a body stream", func(t *testing.T) {
+		t.Parallel()
+
+		app, ln, start := createHelperServer(t)
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.Send(bytes.Repeat([]byte("a"), 1<<16))
+		})
+
+		go start()
+
+		var calls int
+		var transferred int64
+		req := AcquireRequest().
+			SetClient(New().SetDial(ln)).
+			SetDownloadProgress(func(n, _ int64) {
+				calls++
+				transferred = n
+			})
+
+		resp, err := req.Get("http://example.com")
+		require.NoError(t, err)
+		n, err := io.Copy(io.Discard, resp.BodyStream())
+		require.NoError(t, err)
+		require.Equal(t, int64(1<<16), n)
+		require.Equal(t, int64(1<<16), transferred)
+		require.Positive(t, calls)
+		resp.Close()
+	})
+}
+
+// zeroReader is an io.Reader of zeros with no known size.
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
 }
 
 func Test_Request_AllFormData(t *testing.T) {
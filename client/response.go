@@ -23,11 +23,18 @@ type Response struct {
 	RawResponse *fasthttp.Response
 	cookie      []*fasthttp.Cookie
 
+	// bodyStream reports the progress of the download of a streamed body.
+	bodyStream *progressReader
+	// bodyErr is the error which ended the read of bodyStream by Body.
+	bodyErr error
+
 	// respondedHost and respondedPath record where the response was actually served
 	// from, which cookie storage must follow or a redirect target plants cookies
 	// for an origin it does not control. Byte slices: a URI would cost ~296 bytes.
 	respondedHost []byte
 	respondedPath []byte
+
+	fromCache bool
 }
 
 // setClient sets the client instance in the response. The client object is used by core functionalities.
@@ -40,6 +47,29 @@ func (r *Response) setRequest(req *Request) {
 	r.request = req
 }
 
+// setDownloadProgress reports the progress of the download of the body to
+// progress. A body which is not streamed is already downloaded, so its
+// progress is reported at once.
+func (r *Response) setDownloadProgress(progress ProgressFunc) {
+	stream := r.RawResponse.BodyStream()
+	if stream == nil {
+		size := int64(len(r.RawResponse.Body()))
+		progress(size, size)
+		return
+	}
+	r.bodyStream = newProgressReader(stream, int64(r.RawResponse.Header.ContentLength()), progress)
+}
+
+// progressStream returns the reader reporting the download progress of the
+// body, or nil when the body is not streamed through one.
+func (r *Response) progressStream() io.Reader {
+	// The stream is gone once the body was read from RawResponse
+	if r.bodyStream == nil || r.RawResponse.BodyStream() == nil {
+		return nil
+	}
+	return r.bodyStream
+}
+
 // setRespondedURI records where the response was served from, copying into the
 // pooled Response's own buffers so the values stay valid after the caller
 // releases its request.
@@ -127,14 +157,48 @@ func (r *Response) Cookies() []*fasthttp.Cookie {
 }
 
 // Body returns the HTTP response body as a byte slice.
+//
+// A body streamed to report the progress of its download is read whole, and
+// the error ending the read, if any, is returned by BodyErr.
 func (r *Response) Body() []byte {
+	if stream := r.progressStream(); stream != nil {
+		// Read the stream through the progress reporting, as RawResponse would
+		// read it without
+		body, err := io.ReadAll(stream)
+		if closer, ok := r.RawResponse.BodyStream().(fasthttp.ReadCloserWithError); ok && err != nil {
+			// Close the connection, the body read before the error is kept
+			_ = closer.CloseWithError(err) //nolint:errcheck // the connection is discarded
+		}
+		r.bodyErr = err
+		r.bodyStream = nil
+		// SetBody closes the stream, which releases the connection once read
+		r.RawResponse.SetBody(body)
+	}
 	return r.RawResponse.Body()
 }
 
+// BodyErr returns the error which ended the read of the body by Body, when it
+// was streamed to report the progress of its download, or nil.
+func (r *Response) BodyErr() error {
+	return r.bodyErr
+}
+
+// body returns the body read by Body, or the error ending its read.
+func (r *Response) body() ([]byte, error) {
+	body := r.Body()
+	if r.bodyErr != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", r.bodyErr)
+	}
+	return body, nil
+}
+
 // BodyStream returns the response body as a stream reader.
 // Note: When using BodyStream(), the response body is not copied to memory,
 // so calling Body() afterwards may return an empty slice.
 func (r *Response) BodyStream() io.Reader {
+	if stream := r.progressStream(); stream != nil {
+		return stream
+	}
 	if stream := r.RawResponse.BodyStream(); stream != nil {
 		return stream
 	}
@@ -142,6 +206,12 @@ func (r *Response) BodyStream() io.Reader {
 	return bytes.NewReader(r.RawResponse.Body())
 }
 
+// FromCache reports whether the response was served by the response cache
+// of the client, either fresh or revalidated with the server.
+func (r *Response) FromCache() bool {
+	return r.fromCache
+}
+
 // IsStreaming returns true if the response body is being streamed.
 func (r *Response) IsStreaming() bool {
 	return r.RawResponse.BodyStream() != nil
@@ -158,7 +228,12 @@ func (r *Response) JSON(v any) error {
 		return ErrClientNil
 	}
 
-	return r.client.jsonUnmarshal(r.Body(), v)
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	return r.client.jsonUnmarshal(body, v)
 }
 
 // CBOR unmarshal the response body into the given any using CBOR.
@@ -167,7 +242,12 @@ func (r *Response) CBOR(v any) error {
 		return ErrClientNil
 	}
 
-	return r.client.cborUnmarshal(r.Body(), v)
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	return r.client.cborUnmarshal(body, v)
 }
 
 // XML unmarshal the response body into the given any using XML.
@@ -176,7 +256,12 @@ func (r *Response) XML(v any) error {
 		return ErrClientNil
 	}
 
-	return r.client.xmlUnmarshal(r.Body(), v)
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	return r.client.xmlUnmarshal(body, v)
 }
 
 // Save writes the response body to a file or io.Writer.
@@ -237,6 +322,9 @@ func (r *Response) Reset() {
 	r.request = nil
 	r.respondedHost = resetOriginBuf(r.respondedHost)
 	r.respondedPath = resetOriginBuf(r.respondedPath)
+	r.fromCache = false
+	r.bodyStream = nil
+	r.bodyErr = nil
 
 	for len(r.cookie) != 0 {
 		t := r.cookie[0]
@@ -244,6 +332,8 @@ func (r *Response) Reset() {
 		fasthttp.ReleaseCookie(t)
 	}
 
+	// Resetting RawResponse closes a body stream which was never read,
+	// releasing its connection
 	r.RawResponse.Reset()
 }
 
@@ -0,0 +1,375 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/sse"
+)
+
+const (
+	// defaultSSERetry is the reconnection delay until the server sets one.
+	defaultSSERetry = 3 * time.Second
+	// defaultSSEMaxLineSize is the maximum size of a line of an event stream
+	// unless the request sets one.
+	defaultSSEMaxLineSize = 1024 * 1024
+)
+
+var (
+	// ErrSSEStatus is returned when an event stream is answered with a status
+	// other than 200 OK.
+	ErrSSEStatus = errors.New("client: unexpected event stream status")
+	// ErrSSEContentType is returned when an event stream is answered with a
+	// Content-Type other than text/event-stream.
+	ErrSSEContentType = errors.New("client: unexpected event stream content type")
+	// ErrSSEClosed is returned by EventStream.Next once the stream is closed.
+	ErrSSEClosed = errors.New("client: event stream closed")
+)
+
+// EventStream reads the Server-Sent Events of a request, following the
+// WHATWG HTML specification. When the connection ends, the request is sent
+// again after the reconnection delay, which the server sets with the retry
+// field, with a Last-Event-ID header holding the ID of the last event.
+//
+// The stream ends when the request context is canceled, when it is closed,
+// when the server answers 204 No Content, when a reconnection is answered
+// with a status other than 200 OK or a Content-Type other than
+// text/event-stream, or when a line exceeds the maximum size set with
+// Request.SetSSEMaxLineSize, which would be sent again on every reconnection.
+type EventStream struct {
+	err    error
+	parent context.Context //nolint:containedctx // The stream outlives the call creating it.
+	ctx    context.Context //nolint:containedctx // The stream outlives the call creating it.
+	cancel context.CancelFunc
+	events chan sse.Event
+	done   chan struct{}
+	req    *Request
+	lastID string
+	retry  time.Duration
+	// maxLineSize is the maximum size of a line of the stream.
+	maxLineSize int
+	mu          sync.Mutex
+}
+
+// SSE sends the request and returns the stream of the Server-Sent Events of
+// its response. The response body is streamed whatever the setting of the
+// client, except with a custom Transport, which reads it whole before its
+// events are parsed.
+//
+// The first connection is made before SSE returns, and its failure is
+// returned. The request is owned by the stream from then on, and released
+// when the stream ends: do not use or release it afterwards.
+func (r *Request) SSE() (*EventStream, error) {
+	r.checkClient()
+	r.streamResponseBody = true
+	r.SetHeader(fiber.HeaderAccept, fiber.MIMETextEventStream)
+	r.SetHeader(fiber.HeaderCacheControl, "no-cache")
+
+	parent := r.Context()
+	ctx, cancel := context.WithCancel(parent)
+	r.SetContext(ctx)
+	s := &EventStream{
+		parent: parent,
+		ctx:    ctx,
+		cancel: cancel,
+		events: make(chan sse.Event),
+		done:   make(chan struct{}),
+		req:    r,
+		retry:  defaultSSERetry,
+
+		maxLineSize: defaultSSEMaxLineSize,
+	}
+	if r.sseMaxLineSize > 0 {
+		s.maxLineSize = r.sseMaxLineSize
+	}
+
+	resp, err := s.connect()
+	if err != nil {
+		cancel()
+		r.SetContext(parent)
+		return nil, err
+	}
+	go s.run(resp)
+	return s, nil
+}
+
+// SSE sends a GET request to the URL and returns the stream of the
+// Server-Sent Events of its response. See Request.SSE.
+func (c *Client) SSE(url string, cfg ...Config) (*EventStream, error) {
+	req := AcquireRequest().SetClient(c)
+	setConfigToRequest(req, cfg...)
+	stream, err := req.SetURL(url).SetMethod(fiber.MethodGet).SSE()
+	if err != nil {
+		ReleaseRequest(req)
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Next returns the next event of the stream. It blocks until an event is
+// received, and returns the error which ended the stream once it has no more
+// events: the context error when the request context is canceled, and
+// ErrSSEClosed when the stream is closed or the server ended it.
+func (s *EventStream) Next() (sse.Event, error) {
+	select {
+	case event := <-s.events:
+		return event, nil
+	case <-s.done:
+		return sse.Event{}, s.Err()
+	case <-s.ctx.Done():
+		return sse.Event{}, s.canceled()
+	}
+}
+
+// LastEventID returns the ID of the last event received, which is sent in
+// the Last-Event-ID header when reconnecting.
+func (s *EventStream) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastID
+}
+
+// Err returns the error which ended the stream, or nil while it is open.
+func (s *EventStream) Err() error {
+	s.mu.Lock()
+	err := s.err
+	s.mu.Unlock()
+
+	if err == nil && s.ctx.Err() != nil {
+		return s.canceled()
+	}
+	return err
+}
+
+// canceled returns the error ending a canceled stream: the error of the
+// request context, or ErrSSEClosed when the stream was closed.
+func (s *EventStream) canceled() error {
+	if err := s.parent.Err(); err != nil {
+		return err
+	}
+	return ErrSSEClosed
+}
+
+// Close ends the stream. A connection waiting for the server is released at
+// its next data, such as a heartbeat, or when the server closes it.
+func (s *EventStream) Close() {
+	s.cancel()
+}
+
+// connect sends the request, and checks that it is answered with an event
+// stream.
+func (s *EventStream) connect() (*Response, error) {
+	resp, err := s.req.Send()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != fiber.StatusOK {
+		status := resp.StatusCode()
+		ReleaseResponse(resp)
+		if status == fiber.StatusNoContent {
+			return nil, ErrSSEClosed
+		}
+		return nil, fmt.Errorf("%w: %d", ErrSSEStatus, status)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header(fiber.HeaderContentType)); err != nil || mediaType != fiber.MIMETextEventStream {
+		contentType := resp.Header(fiber.HeaderContentType)
+		ReleaseResponse(resp)
+		return nil, fmt.Errorf("%w: %q", ErrSSEContentType, contentType)
+	}
+	return resp, nil
+}
+
+// run reads the events of the connections until the stream ends.
+func (s *EventStream) run(resp *Response) {
+	defer ReleaseRequest(s.req)
+	defer s.cancel()
+
+	for {
+		readErr := s.read(resp)
+		if s.ctx.Err() != nil || readErr != nil {
+			// Close the connection, which is left in the middle of the body
+			if stream, ok := resp.RawResponse.BodyStream().(fasthttp.ReadCloserWithError); ok {
+				_ = stream.CloseWithError(ErrSSEClosed) //nolint:errcheck // the connection is discarded
+			}
+		}
+		ReleaseResponse(resp)
+		if readErr != nil {
+			s.end(readErr)
+			return
+		}
+
+		timer := time.NewTimer(s.retryDelay())
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			s.end(s.canceled())
+			return
+		case <-timer.C:
+		}
+
+		if id := s.LastEventID(); id != "" {
+			s.req.SetHeader(fiber.HeaderLastEventID, id)
+		}
+		var err error
+		for resp, err = s.connect(); err != nil; resp, err = s.connect() {
+			// Network errors are retried, the answers of the server are not
+			if errors.Is(err, ErrSSEClosed) || errors.Is(err, ErrSSEStatus) || errors.Is(err, ErrSSEContentType) {
+				s.end(err)
+				return
+			}
+
+			timer.Reset(s.retryDelay())
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				s.end(s.canceled())
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// end ends the stream with err.
+func (s *EventStream) end(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.done)
+}
+
+func (s *EventStream) retryDelay() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.retry
+}
+
+// read parses the events of a response, until its body ends or the stream
+// is canceled. It returns an error if a line exceeds the maximum size, which
+// ends the stream; a connection error is left to the reconnection.
+func (s *EventStream) read(resp *Response) error {
+	scanner := bufio.NewScanner(resp.BodyStream())
+	scanner.Buffer(make([]byte, 0, min(s.maxLineSize, 4096)), s.maxLineSize)
+	scanner.Split(scanLines)
+
+	var (
+		data      strings.Builder
+		name      string
+		retry     time.Duration
+		first     = true
+		dataFound bool
+	)
+	for scanner.Scan() {
+		if s.ctx.Err() != nil {
+			return nil
+		}
+
+		line := scanner.Bytes()
+		if first {
+			line = bytes.TrimPrefix(line, []byte("\xEF\xBB\xBF"))
+			first = false
+		}
+
+		if len(line) == 0 {
+			// Dispatch the event
+			if dataFound {
+				if name == "" {
+					name = "message"
+				}
+				event := sse.Event{
+					Data:  strings.TrimSuffix(data.String(), "\n"),
+					ID:    s.LastEventID(),
+					Name:  name,
+					Retry: retry,
+				}
+				select {
+				case s.events <- event:
+				case <-s.ctx.Done():
+					return nil
+				}
+			}
+			data.Reset()
+			name = ""
+			retry = 0
+			dataFound = false
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			value = bytes.TrimPrefix(value, []byte(" "))
+		}
+		switch string(field) {
+		case "event":
+			name = string(value)
+		case "data":
+			data.Write(value)    //nolint:errcheck // strings.Builder writes never fail
+			data.WriteByte('\n') //nolint:errcheck // strings.Builder writes never fail
+			dataFound = true
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				s.mu.Lock()
+				s.lastID = string(value)
+				s.mu.Unlock()
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+				s.mu.Lock()
+				s.retry = retry
+				s.mu.Unlock()
+			}
+		default:
+			// ignore the unknown fields
+		}
+	}
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("client: event stream line exceeds %d bytes: %w", s.maxLineSize, err)
+	}
+	// An event which is not terminated by an empty line is discarded
+	return nil
+}
+
+// scanLines is a bufio.SplitFunc splitting the lines of an event stream,
+// which end with CRLF, LF or CR.
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) { //nolint:nonamedreturns // names document the results
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the data may be followed by a LF
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+	if atEOF {
+		// The last line is not terminated, so it is discarded
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
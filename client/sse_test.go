@@ -0,0 +1,269 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/sse"
+)
+
+func newSSETestClient(t *testing.T, setup func(app *fiber.App)) *Client {
+	t.Helper()
+
+	app, dial, start := createHelperServer(t)
+	setup(app)
+	go start()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	return New().SetDial(dial)
+}
+
+func nextEvent(t *testing.T, stream *EventStream) sse.Event {
+	t.Helper()
+
+	event, err := stream.Next()
+	require.NoError(t, err)
+	return event
+}
+
+func Test_SSE_Parse(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	var lastEventID atomic.Value
+	c := newSSETestClient(t, func(app *fiber.App) {
+		app.Get("/events", func(c fiber.Ctx) error {
+			if connections.Add(1) > 1 {
+				lastEventID.Store(c.Get(fiber.HeaderLastEventID))
+				return c.SendStatus(fiber.StatusNoContent)
+			}
+			require.Equal(t, fiber.MIMETextEventStream, c.Get(fiber.HeaderAccept))
+			c.Set(fiber.HeaderContentType, fiber.MIMETextEventStream+"; charset=utf-8")
+			return c.SendStreamWriter(func(w *bufio.Writer) {
+				_, _ = w.WriteString("\xEF\xBB\xBFretry: 10\n" + //nolint:errcheck // test
+					": comment\n" +
+					"data: first\n\n" +
+					"event: update\r\ndata:  two\r\ndata:lines\r\nid: 1\r\n\r\n" +
+					"id: 2\rdata\rfoo: bar\r\r" +
+					"event: empty\n\n" +
+					"retry: x\ndata: {\"k\":\"v\"}\n\n" +
+					"id\ndata: no id\n\n" +
+					"data: unterminated")
+				_ = w.Flush() //nolint:errcheck // test
+			})
+		})
+	})
+
+	stream, err := c.SSE("http://example.com/events")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.Equal(t, sse.Event{Data: "first", Name: "message", Retry: 10 * time.Millisecond}, nextEvent(t, stream))
+	require.Equal(t, sse.Event{Data: " two\nlines", ID: "1", Name: "update"}, nextEvent(t, stream))
+	require.Equal(t, sse.Event{Data: "", ID: "2", Name: "message"}, nextEvent(t, stream))
+	// Events without data are not dispatched, and invalid retries are ignored
+	require.Equal(t, sse.Event{Data: `{"k":"v"}`, ID: "2", Name: "message"}, nextEvent(t, stream))
+	require.Equal(t, sse.Event{Data: "no id", Name: "message"}, nextEvent(t, stream))
+
+	// The unterminated event is discarded, and the client reconnects until
+	// the server answers 204 No Content
+	_, err = stream.Next()
+	require.ErrorIs(t, err, ErrSSEClosed)
+	require.ErrorIs(t, stream.Err(), ErrSSEClosed)
+	require.Equal(t, int32(2), connections.Load())
+	require.Empty(t, lastEventID.Load())
+}
+
+func Test_SSE_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	c := newSSETestClient(t, func(app *fiber.App) {
+		app.Get("/events", sse.New(sse.Config{
+			Retry:            10 * time.Millisecond,
+			DisableHeartbeat: true,
+			Handler: func(_ fiber.Ctx, stream *sse.Stream) error {
+				switch connections.Add(1) {
+				case 1:
+					require.Empty(t, stream.LastEventID())
+					if err := stream.Event(sse.Event{ID: "1", Name: "greeting", Data: fiber.Map{"hello": "world"}}); err != nil {
+						return err
+					}
+					return stream.Event(sse.Event{ID: "2", Data: "bye"})
+				default:
+					return stream.Event(sse.Event{ID: "3", Data: "resumed after " + stream.LastEventID()})
+				}
+			},
+		}))
+	})
+
+	stream, err := c.SSE("http://example.com/events")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.Equal(t, sse.Event{ID: "1", Name: "greeting", Data: `{"hello":"world"}`}, nextEvent(t, stream))
+	require.Equal(t, sse.Event{ID: "2", Name: "message", Data: "bye"}, nextEvent(t, stream))
+	require.Equal(t, sse.Event{ID: "3", Name: "message", Data: "resumed after 2"}, nextEvent(t, stream))
+	require.Equal(t, "3", stream.LastEventID())
+	require.Nil(t, stream.Err())
+}
+
+func Test_SSE_Cancel(t *testing.T) {
+	t.Parallel()
+
+	c := newSSETestClient(t, func(app *fiber.App) {
+		app.Get("/events", sse.New(sse.Config{
+			HeartbeatInterval: 10 * time.Millisecond,
+			Handler: func(_ fiber.Ctx, stream *sse.Stream) error {
+				if err := stream.Event(sse.Event{Data: "first"}); err != nil {
+					return err
+				}
+				<-stream.Done()
+				return nil
+			},
+		}))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		stream, err := c.SSE("http://example.com/events")
+		require.NoError(t, err)
+		require.Equal(t, "first", nextEvent(t, stream).Data)
+
+		stream.Close()
+		_, err = stream.Next()
+		require.ErrorIs(t, err, ErrSSEClosed)
+		require.ErrorIs(t, stream.Err(), ErrSSEClosed)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := c.SSE("http://example.com/events", Config{Ctx: ctx})
+		require.NoError(t, err)
+		require.Equal(t, "first", nextEvent(t, stream).Data)
+
+		cancel()
+		_, err = stream.Next()
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func Test_SSE_Errors(t *testing.T) {
+	t.Parallel()
+
+	c := newSSETestClient(t, func(app *fiber.App) {
+		app.Get("/status", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		})
+		app.Get("/content-type", func(c fiber.Ctx) error {
+			return c.SendString("data: not an event stream\n\n")
+		})
+		app.Get("/no-content", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+	})
+
+	_, err := c.SSE("http://example.com/status")
+	require.ErrorIs(t, err, ErrSSEStatus)
+	require.ErrorContains(t, err, "401")
+
+	_, err = c.SSE("http://example.com/content-type")
+	require.ErrorIs(t, err, ErrSSEContentType)
+
+	_, err = c.SSE("http://example.com/no-content")
+	require.ErrorIs(t, err, ErrSSEClosed)
+}
+
+func Test_SSE_MaxLineSize(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	c := newSSETestClient(t, func(app *fiber.App) {
+		app.Get("/events", func(c fiber.Ctx) error {
+			connections.Add(1)
+			c.Set(fiber.HeaderContentType, fiber.MIMETextEventStream)
+			return c.SendStreamWriter(func(w *bufio.Writer) {
+				_, _ = w.WriteString("retry: 1\nid: 1\ndata: small\n\n" + //nolint:errcheck // test
+					"id: 2\ndata: " + strings.Repeat("a", 2048) + "\n\n")
+				_ = w.Flush() //nolint:errcheck // test
+			})
+		})
+	})
+
+	req := AcquireRequest().SetClient(c).SetURL("http://example.com/events").SetSSEMaxLineSize(1024)
+	require.Equal(t, 1024, req.SSEMaxLineSize())
+	stream, err := req.SSE()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.Equal(t, "small", nextEvent(t, stream).Data)
+
+	// The long line ends the stream rather than being received again on
+	// every reconnection
+	_, err = stream.Next()
+	require.ErrorIs(t, err, bufio.ErrTooLong)
+	require.ErrorIs(t, stream.Err(), bufio.ErrTooLong)
+	require.Equal(t, int32(1), connections.Load())
+}
+
+func Test_SSE_AppTransport(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	var connections atomic.Int32
+	app.Get("/events", sse.New(sse.Config{
+		DisableHeartbeat: true,
+		Handler: func(c fiber.Ctx, stream *sse.Stream) error {
+			if connections.Add(1) > 1 {
+				return c.SendStatus(fiber.StatusNoContent)
+			}
+			if err := stream.Retry(time.Millisecond); err != nil {
+				return err
+			}
+			return stream.Event(sse.Event{Data: "buffered"})
+		},
+	}))
+
+	stream, err := NewWithTransport(NewAppTransport(app)).SSE("http://example.com/events")
+	require.NoError(t, err)
+	defer stream.Close()
+	require.Equal(t, "buffered", nextEvent(t, stream).Data)
+}
+
+func Test_ScanLines(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader("a\nb\r\nc\rd\r"))
+	scanner.Split(scanLines)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{"a", "b", "c", "d"}, lines)
+
+	// A CR at the end of the data waits for a possible LF
+	advance, token, err := scanLines([]byte("a\r"), false)
+	require.NoError(t, err)
+	require.Zero(t, advance)
+	require.Nil(t, token)
+
+	advance, token, err = scanLines([]byte("unterminated"), true)
+	require.NoError(t, err)
+	require.Equal(t, len("unterminated"), advance)
+	require.Nil(t, token)
+	require.True(t, bytes.Equal(nil, token))
+}
@@ -244,6 +244,63 @@ func (l *lbClientTransport) SetStreamResponseBody(enable bool) {
 	})
 }
 
+// Transport sends the requests of a Client in place of the fasthttp clients,
+// such as to serve them from canned responses in tests. Do must fill resp with
+// the response to req, and must not retain either after it returns.
+type Transport interface {
+	Do(req *fasthttp.Request, resp *fasthttp.Response) error
+}
+
+// customTransport adapts a Transport to the httpClientTransport interface
+// used by Fiber's client helpers. The settings of the fasthttp clients, such
+// as TLS and dialing, do not apply to it.
+type customTransport struct {
+	transport Transport
+}
+
+func newCustomTransport(transport Transport) *customTransport {
+	return &customTransport{transport: transport}
+}
+
+func (t *customTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	return t.transport.Do(req, resp)
+}
+
+// DoTimeout sends the request with Do: the Client enforces its timeouts by
+// itself, so a Transport needs not bound its requests.
+func (t *customTransport) DoTimeout(req *fasthttp.Request, resp *fasthttp.Response, _ time.Duration) error {
+	return t.transport.Do(req, resp)
+}
+
+// DoDeadline sends the request with Do, like DoTimeout.
+func (t *customTransport) DoDeadline(req *fasthttp.Request, resp *fasthttp.Response, _ time.Time) error {
+	return t.transport.Do(req, resp)
+}
+
+func (t *customTransport) DoRedirects(req *fasthttp.Request, resp *fasthttp.Response, maxRedirects int) error {
+	return doRedirectsWithClient(req, resp, maxRedirects, t.transport)
+}
+
+func (*customTransport) CloseIdleConnections() {}
+
+func (*customTransport) TLSConfig() *tls.Config {
+	return nil
+}
+
+func (*customTransport) SetTLSConfig(_ *tls.Config) {}
+
+func (*customTransport) SetDial(_ fasthttp.DialFunc) {}
+
+func (t *customTransport) Client() any {
+	return t.transport
+}
+
+func (*customTransport) StreamResponseBody() bool {
+	return false
+}
+
+func (*customTransport) SetStreamResponseBody(_ bool) {}
+
 // forEachHostClient applies fn to every host client reachable from the provided
 // load balancer by recursively following nested balancers and wrapper types.
 func forEachHostClient(lb *fasthttp.LBClient, fn func(*fasthttp.HostClient)) {
@@ -1217,3 +1217,36 @@ func Test_Transport_DoRedirects_RefusesUnparsableTarget(t *testing.T) {
 		})
 	}
 }
+
+func Test_CustomTransport(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockTransport()
+	mock.On(fiber.MethodGet, "/").ReplyString(fiber.StatusOK, "ok")
+	transport := newCustomTransport(mock)
+
+	require.Same(t, mock, transport.Client())
+	require.Nil(t, transport.TLSConfig())
+	transport.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+	require.Nil(t, transport.TLSConfig())
+	transport.SetDial(nil)
+	transport.SetStreamResponseBody(true)
+	require.False(t, transport.StreamResponseBody())
+	transport.CloseIdleConnections()
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://example.com/")
+
+	require.NoError(t, transport.DoTimeout(req, resp, time.Second))
+	require.Equal(t, "ok", string(resp.Body()))
+	require.NoError(t, transport.DoDeadline(req, resp, time.Now().Add(time.Second)))
+	require.NoError(t, transport.DoRedirects(req, resp, 1))
+	require.Empty(t, mock.Pending())
+
+	cc := NewWithTransport(mock)
+	require.Nil(t, cc.FasthttpClient())
+	require.Nil(t, New().Transport())
+}
@@ -32,6 +32,9 @@ const (
 	MIMEMultipartForm         = "multipart/form-data"
 	MIMEApplicationMsgPack    = "application/vnd.msgpack"
 
+	MIMEApplicationProblemJSON = "application/problem+json"
+	MIMEApplicationProblemXML  = "application/problem+xml"
+
 	MIMETextXMLCharsetUTF8         = "text/xml; charset=utf-8"
 	MIMETextHTMLCharsetUTF8        = "text/html; charset=utf-8"
 	MIMETextPlainCharsetUTF8       = "text/plain; charset=utf-8"
@@ -276,6 +279,8 @@ const (
 	HeaderRetryAfter                         = "Retry-After"
 	HeaderServerTiming                       = "Server-Timing"
 	HeaderSignature                          = "Signature"
+	HeaderSignatureInput                     = "Signature-Input"
+	HeaderContentDigest                      = "Content-Digest"
 	HeaderSignedHeaders                      = "Signed-Headers"
 	HeaderSourceMap                          = "SourceMap"
 	HeaderUpgrade                            = "Upgrade"
@@ -56,6 +56,7 @@ const (
 //go:generate ifacemaker --file ctx.go --file req.go --file res.go --struct DefaultCtx --iface Ctx --pkg fiber --promoted --output ctx_interface_gen.go --not-exported true --iface-comment "Ctx represents the Context which hold the HTTP request and response.\nIt has methods for the request query string, parameters, body, HTTP headers and so on."
 type DefaultCtx struct {
 	handlerCtx             CustomCtx            // Active custom context implementation, if any
+	bodyErr                error                // Error which ended the read of a streamed body
 	DefaultReq                                  // Default request api
 	DefaultRes                                  // Default response api
 	app                    *App                 // Reference to *App
@@ -77,6 +78,7 @@ type DefaultCtx struct {
 	indexHandler           int                  // Index of the current handler
 	firstMatchIndex        int                  // Pre-resolved endpoint index from the SkipUnmatchedRoutes lookahead; -1 when unused
 	methodInt              int                  // HTTP method INT equivalent
+	bodyLimit              int                  // Body limit set by SetBodyLimit, 0 for Config.BodyLimit
 	isAbandoned            atomic.Bool          // If true, ctx won't be pooled until ForceRelease is called
 	isMatched              bool                 // Non use route matched
 	shouldSkipNonUseRoutes bool                 // Skip non-use routes while iterating middleware
@@ -720,6 +722,9 @@ func (c *DefaultCtx) Reset(fctx *fasthttp.RequestCtx) {
 	c.isMatched = false
 	c.shouldSkipNonUseRoutes = false
 	c.firstMatchIndex = -1
+	// Reset body limit
+	c.bodyLimit = 0
+	c.bodyErr = nil
 	// Set paths
 	c.pathOriginal = c.app.toString(fctx.URI().PathOriginal())
 	// Set method
@@ -283,6 +283,36 @@ type Ctx interface {
 	// Don't store direct references to the returned data.
 	// If you need to keep the body's data later, make a copy or use the Immutable option.
 	Body() []byte
+	// ReadBody returns the body like Body, and the error which prevented reading
+	// or decoding it, without changing the response. It returns
+	// ErrRequestEntityTooLarge when a streamed body exceeds the body limit, which
+	// Body only reports with an empty body.
+	ReadBody() ([]byte, error)
+	// BodyStream returns a reader of the raw request body, without decoding its
+	// Content-Encoding. With Config.StreamRequestBody, the part of the body beyond
+	// the BodyLimit is read from the connection as the reader is read, so that
+	// large bodies can be piped to their destination without being buffered in
+	// memory. The reader fails with ErrRequestEntityTooLarge once the limit set by
+	// SetBodyLimit, or else Config.BodyLimit, is exceeded.
+	// The reader is only valid within the handler, and the body can only be read
+	// once.
+	BodyStream() io.Reader
+	// MultipartReader returns a reader of the parts of a multipart request body,
+	// read one by one from BodyStream, so that large files can be streamed to
+	// their destination. The form must not have been parsed before: with
+	// Config.StreamRequestBody, Config.DisablePreParseMultipartForm must be set
+	// for the parts to be read from the connection.
+	MultipartReader() (*multipart.Reader, error)
+	// SetBodyLimit sets the maximum size of the request body for the rest of the
+	// handlers, in place of Config.BodyLimit. It applies to Body, BodyRaw,
+	// BodyStream, MultipartForm and MultipartReader. A limit above
+	// Config.BodyLimit only takes effect with Config.StreamRequestBody, as the
+	// server rejects larger bodies before routing otherwise.
+	SetBodyLimit(limit int)
+	// BodyLimit returns the maximum size of the request body: the limit set by
+	// SetBodyLimit, or else Config.BodyLimit. Middleware reading the body, such
+	// as decompress, uses it to stay within the limit of the route.
+	BodyLimit() int
 	// Cookies are used for getting a cookie value by key.
 	// Defaults to the empty string "" if the cookie doesn't exist.
 	// If a default value is given, it will return that value if the cookie doesn't exist.
@@ -38,6 +38,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/bytebufferpool"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 
 	"github.com/gofiber/fiber/v3/internal/storage/memory"
 )
@@ -1082,6 +1083,174 @@ func Benchmark_Ctx_Body_Immutable(b *testing.B) {
 	require.Equal(b, []byte(input), c.Body())
 }
 
+// go test -run Test_Ctx_BodyStream
+func Test_Ctx_BodyStream(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{}).(*DefaultCtx) //nolint:errcheck,forcetypeassert // not needed
+
+	c.Request().SetBody([]byte("john=doe"))
+	body, err := io.ReadAll(c.BodyStream())
+	require.NoError(t, err)
+	require.Equal(t, []byte("john=doe"), body)
+
+	// A body of the exact size of the limit is read
+	c.SetBodyLimit(8)
+	body, err = io.ReadAll(c.BodyStream())
+	require.NoError(t, err)
+	require.Equal(t, []byte("john=doe"), body)
+
+	c.SetBodyLimit(5)
+	body, err = io.ReadAll(c.BodyStream())
+	require.ErrorIs(t, err, ErrRequestEntityTooLarge)
+	require.Equal(t, []byte("john="), body)
+}
+
+// go test -run Test_Ctx_BodyLimit
+func Test_Ctx_BodyLimit(t *testing.T) {
+	t.Parallel()
+	app := New(Config{BodyLimit: 64})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	require.Equal(t, 64, c.BodyLimit())
+	c.SetBodyLimit(16)
+	require.Equal(t, 16, c.BodyLimit())
+	require.Equal(t, 16, c.Req().BodyLimit())
+}
+
+// go test -run Test_Ctx_BodyStream_StreamRequestBody
+func Test_Ctx_BodyStream_StreamRequestBody(t *testing.T) {
+	t.Parallel()
+	app := New(Config{StreamRequestBody: true, BodyLimit: 16})
+	app.Post("/", func(c Ctx) error {
+		c.SetBodyLimit(1024)
+		n, err := io.Copy(io.Discard, c.BodyStream())
+		if err != nil {
+			return err
+		}
+		return c.SendString(fmt.Sprintf("%v %d", c.Request().IsBodyStream(), n))
+	})
+
+	// The body is read beyond the BodyLimit, up to the limit of the route
+	resp, err := app.Test(httptest.NewRequest(MethodPost, "/", bytes.NewReader(bytes.Repeat([]byte("a"), 1024))))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "true 1024", string(respBody))
+
+	resp, err = app.Test(httptest.NewRequest(MethodPost, "/", bytes.NewReader(bytes.Repeat([]byte("a"), 1025))))
+	require.NoError(t, err)
+	require.Equal(t, StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// Chunked bodies have no Content-Length, and are limited while read
+	require.Equal(t, []string{"200 true 1024", "413 Request Entity Too Large"},
+		testChunkedBodies(t, app, strings.Repeat("a", 1024), strings.Repeat("a", 4096)))
+}
+
+// testChunkedBodies sends each body to app as a chunked POST request, which
+// app.Test cannot send, and returns the status codes and the bodies of the
+// responses.
+func testChunkedBodies(t *testing.T, app *App, bodies ...string) []string {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		_ = app.Listener(ln, ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped below
+	}()
+	defer func() {
+		require.NoError(t, app.Shutdown())
+	}()
+
+	responses := make([]string, 0, len(bodies))
+	for _, body := range bodies {
+		conn, err := ln.Dial()
+		require.NoError(t, err)
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+		_, err = fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n%x\r\n%s\r\n0\r\n\r\n", len(body), body)
+		require.NoError(t, err)
+
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		require.NoError(t, err)
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, conn.Close())
+		responses = append(responses, fmt.Sprintf("%d %s", resp.StatusCode, respBody))
+	}
+	return responses
+}
+
+// go test -run Test_Ctx_Body_SetBodyLimit
+func Test_Ctx_Body_SetBodyLimit(t *testing.T) {
+	t.Parallel()
+	app := New(Config{StreamRequestBody: true, BodyLimit: 16})
+	app.Post("/", func(c Ctx) error {
+		c.SetBodyLimit(64)
+		body, err := c.ReadBody()
+		if err != nil {
+			return err
+		}
+		return c.SendString(strconv.Itoa(len(body)))
+	})
+
+	// A streamed body is read within the limit of the request
+	require.Equal(t, []string{"200 64", "413 Request Entity Too Large"},
+		testChunkedBodies(t, app, strings.Repeat("a", 64), strings.Repeat("a", 65)))
+}
+
+// go test -run Test_Ctx_Body_StreamLimit_NoSideEffects
+func Test_Ctx_Body_StreamLimit_NoSideEffects(t *testing.T) {
+	t.Parallel()
+	app := New(Config{StreamRequestBody: true, BodyLimit: 16})
+	app.Post("/", func(c Ctx) error {
+		c.SetBodyLimit(64)
+		// Body reports the error with an empty body, and leaves the response
+		// unchanged
+		first, second := len(c.Body()), len(c.Body())
+		_, err := c.ReadBody()
+		return c.SendString(fmt.Sprintf("%d %d %d %v", first, second, c.Response().StatusCode(), errors.Is(err, ErrRequestEntityTooLarge)))
+	})
+
+	require.Equal(t, []string{"200 0 0 200 true"},
+		testChunkedBodies(t, app, strings.Repeat("a", 65)))
+}
+
+// go test -run Test_Ctx_Bind_StreamLimit
+func Test_Ctx_Bind_StreamLimit(t *testing.T) {
+	t.Parallel()
+	app := New(Config{StreamRequestBody: true, BodyLimit: 16})
+	app.Post("/", func(c Ctx) error {
+		c.SetBodyLimit(64)
+		c.Request().Header.SetContentType(MIMEApplicationJSON)
+		var v map[string]any
+		return c.Bind().JSON(&v)
+	})
+
+	// The error is returned instead of being parsed as the body
+	require.Equal(t, []string{"413 Request Entity Too Large"},
+		testChunkedBodies(t, app, `{"a":"`+strings.Repeat("a", 64)+`"}`))
+}
+
+// go test -run Test_Ctx_BodyStream_AppBodyLimit
+func Test_Ctx_BodyStream_AppBodyLimit(t *testing.T) {
+	t.Parallel()
+	app := New(Config{StreamRequestBody: true, BodyLimit: 16})
+	app.Post("/", func(c Ctx) error {
+		n, err := io.Copy(io.Discard, c.BodyStream())
+		if err != nil {
+			return err
+		}
+		return c.SendString(strconv.FormatInt(n, 10))
+	})
+
+	// Without SetBodyLimit, the stream is limited by the BodyLimit of the app
+	require.Equal(t, []string{"200 16", "413 Request Entity Too Large"},
+		testChunkedBodies(t, app, strings.Repeat("a", 16), strings.Repeat("a", 64)))
+}
+
 // go test -run Test_Ctx_Body_With_Compression
 func Test_Ctx_Body_With_Compression(t *testing.T) {
 	t.Parallel()
@@ -5086,6 +5255,72 @@ func Test_Ctx_MultipartForm_BodyLimitExceeded(t *testing.T) {
 	require.ErrorIs(t, err, fasthttp.ErrBodyTooLarge)
 }
 
+// go test -run Test_Ctx_MultipartReader
+func Test_Ctx_MultipartReader(t *testing.T) {
+	t.Parallel()
+
+	handler := func(c Ctx) error {
+		c.SetBodyLimit(4096)
+		mr, err := c.MultipartReader()
+		if err != nil {
+			return err
+		}
+
+		var parts []string
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			n, err := io.Copy(io.Discard, part)
+			if err != nil {
+				return err
+			}
+			parts = append(parts, fmt.Sprintf("%s:%s:%d", part.FormName(), part.FileName(), n))
+		}
+		return c.SendString(fmt.Sprintf("%v %s", c.Request().IsBodyStream(), strings.Join(parts, ",")))
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	require.NoError(t, w.WriteField("name", "john"))
+	fw, err := w.CreateFormFile("file", "data.bin")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte("a"), 2048))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	for name, tc := range map[string]struct {
+		config   Config
+		expected string
+	}{
+		// The parts are read from the connection
+		"streamed": {config: Config{StreamRequestBody: true, DisablePreParseMultipartForm: true, BodyLimit: 64}, expected: "true name::4,file:data.bin:2048"},
+		// The parts are read from the buffered body
+		"buffered": {config: Config{}, expected: "false name::4,file:data.bin:2048"},
+	} {
+		app := New(tc.config)
+		app.Post("/", handler)
+
+		req := httptest.NewRequest(MethodPost, "/", bytes.NewReader(body.Bytes()))
+		req.Header.Set(HeaderContentType, w.FormDataContentType())
+		resp, err := app.Test(req)
+		require.NoError(t, err, name)
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, name)
+		require.Equal(t, tc.expected, string(respBody), name)
+	}
+
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{}).(*DefaultCtx) //nolint:errcheck,forcetypeassert // not needed
+	c.Request().Header.SetContentType(MIMEApplicationJSON)
+	_, err = c.MultipartReader()
+	require.ErrorIs(t, err, fasthttp.ErrNoMultipartForm)
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_MultipartForm -benchmem -count=4
 func Benchmark_Ctx_MultipartForm(b *testing.B) {
 	app := New()
@@ -816,6 +816,13 @@ func (d *domainRouter) Name(name string) Router {
 	return d
 }
 
+// Describe attaches documentation to the most recently registered route.
+// See App.Describe.
+func (d *domainRouter) Describe(doc RouteDoc) Router {
+	d.app.Describe(doc)
+	return d
+}
+
 // Domain creates a new domain router that inherits this domain router's
 // group (if any) but uses a different hostname pattern.
 func (d *domainRouter) Domain(host string) Router {
@@ -898,3 +905,8 @@ func (r *domainRegistering) RouteChain(path string) Register {
 		path:   getGroupPath(r.path, path),
 	}
 }
+
+func (r *domainRegistering) Describe(doc RouteDoc) Register {
+	r.domain.app.Describe(doc)
+	return r
+}
@@ -46,6 +46,14 @@ func (grp *Group) Name(name string) Router {
 	return grp
 }
 
+// Describe attaches documentation to the latest route added to the group.
+// See App.Describe.
+func (grp *Group) Describe(doc RouteDoc) Router {
+	grp.app.Describe(doc)
+
+	return grp
+}
+
 // Use registers a middleware route that will match requests
 // with the provided prefix (which is optional and defaults to "/").
 // Also, you can pass another app instance as a sub-router along a routing path.
@@ -0,0 +1,79 @@
+// Package bearer builds the WWW-Authenticate challenges of RFC 6750 shared by
+// the authentication middlewares. It is internal because the challenge format
+// is configured through each middleware's own options.
+package bearer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/utils/v2"
+)
+
+// Error codes of RFC 6750 section 3.1.
+const (
+	ErrorInvalidRequest    = "invalid_request"
+	ErrorInvalidToken      = "invalid_token"
+	ErrorInsufficientScope = "insufficient_scope"
+)
+
+// Params holds the RFC 6750 parameters of a Bearer challenge.
+type Params struct {
+	// Error is the error code. The other parameters are only sent with it.
+	Error string
+	// ErrorDescription is a human-readable explanation of the error.
+	ErrorDescription string
+	// ErrorURI is an absolute URI of a page explaining the error.
+	ErrorURI string
+	// Scope is the space-delimited list of scopes required, sent with
+	// ErrorInsufficientScope only.
+	Scope string
+}
+
+// Challenge returns the challenge of an auth scheme for realm. The
+// parameters are added to Bearer challenges only.
+func Challenge(scheme, realm string, p Params) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s realm=%q", scheme, realm)
+	if !utils.EqualFold(scheme, "Bearer") || p.Error == "" {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, ", error=%q", p.Error)
+	if p.ErrorDescription != "" {
+		fmt.Fprintf(&b, ", error_description=%q", p.ErrorDescription)
+	}
+	if p.ErrorURI != "" {
+		fmt.Fprintf(&b, ", error_uri=%q", p.ErrorURI)
+	}
+	if p.Error == ErrorInsufficientScope && p.Scope != "" {
+		fmt.Fprintf(&b, ", scope=%q", p.Scope)
+	}
+	return b.String()
+}
+
+// ValidScope reports whether scope is a space-delimited list of RFC 6750
+// scope tokens.
+func ValidScope(scope string) bool {
+	if scope == "" {
+		return false
+	}
+	for token := range strings.SplitSeq(scope, " ") {
+		if !IsScopeToken(token) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsScopeToken reports whether s is an RFC 6750 scope token: printable ASCII
+// except the double quote and backslash.
+func IsScopeToken(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x21 || c > 0x7e || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return s != ""
+}
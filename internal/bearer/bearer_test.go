@@ -0,0 +1,37 @@
+package bearer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Challenge(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `Bearer realm="api"`, Challenge("Bearer", "api", Params{}))
+	require.Equal(t, `ApiKey realm="api"`, Challenge("ApiKey", "api", Params{Error: ErrorInvalidToken}))
+	require.Equal(t,
+		`Bearer realm="api", error="invalid_token", error_description="token \"expired\"", error_uri="https://example.com/errors"`,
+		Challenge("Bearer", "api", Params{
+			Error:            ErrorInvalidToken,
+			ErrorDescription: `token "expired"`,
+			ErrorURI:         "https://example.com/errors",
+			Scope:            "ignored",
+		}))
+	require.Equal(t,
+		`bearer realm="api", error="insufficient_scope", scope="read write"`,
+		Challenge("bearer", "api", Params{Error: ErrorInsufficientScope, Scope: "read write"}))
+}
+
+func Test_ValidScope(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, ValidScope("read"))
+	require.True(t, ValidScope("read write:all"))
+	require.False(t, ValidScope(""))
+	require.False(t, ValidScope("read  write"))
+	require.False(t, ValidScope(`read "write"`))
+	require.False(t, ValidScope(`read\write`))
+	require.False(t, ValidScope("read\twrite"))
+}
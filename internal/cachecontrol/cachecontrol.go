@@ -0,0 +1,142 @@
+// Package cachecontrol parses the Cache-Control and Vary fields of RFC 9111,
+// for the cache middleware and the response cache of the client.
+package cachecontrol
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+// ParseUint parses the delta-seconds value of a directive, such as max-age.
+func ParseUint(val []byte) (uint64, bool) {
+	if len(val) == 0 {
+		return 0, false
+	}
+	parsed, err := fasthttp.ParseUint(val)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+	return uint64(parsed), true
+}
+
+// ParseDirectives calls fn with the name and the value of each directive of a
+// Cache-Control field value, in order. The value is nil for a directive
+// without one, and a quoted-string value is unquoted.
+func ParseDirectives(cc []byte, fn func(key, value []byte)) {
+	for i := 0; i < len(cc); {
+		// skip leading separators and OWS (space/tab per RFC 9110 §5.6.3)
+		for i < len(cc) && (cc[i] == ' ' || cc[i] == '\t' || cc[i] == ',') {
+			i++
+		}
+		if i >= len(cc) {
+			break
+		}
+
+		// A directive value may be a quoted-string (RFC 9111 §5.2), and a comma
+		// inside one is data rather than the end of the directive. Scanning past
+		// it read the tokens in an extension value as directives of their own,
+		// so `ext="a, public, b"` said public and authorized sharing.
+		// Unterminated quotes swallow the rest of the field, which is the safe
+		// way round: what follows is read as one value rather than as directives
+		// a sender never separated.
+		start := i
+		inQuotes := false
+		for i < len(cc) {
+			if inQuotes && cc[i] == '\\' && i+1 < len(cc) {
+				// A quoted-pair: the byte after the backslash is data, a quote
+				// among it, so the string does not end there.
+				i += 2
+				continue
+			}
+			if cc[i] == '"' {
+				inQuotes = !inQuotes
+			} else if cc[i] == ',' && !inQuotes {
+				break
+			}
+			i++
+		}
+		partEnd := i
+		for partEnd > start && (cc[partEnd-1] == ' ' || cc[partEnd-1] == '\t') {
+			partEnd--
+		}
+
+		keyStart := start
+		for keyStart < partEnd && (cc[keyStart] == ' ' || cc[keyStart] == '\t') {
+			keyStart++
+		}
+		if keyStart >= partEnd {
+			continue
+		}
+
+		keyEnd := keyStart
+		for keyEnd < partEnd && cc[keyEnd] != '=' {
+			keyEnd++
+		}
+		// Trim trailing OWS from key
+		keyEndTrimmed := keyEnd
+		for keyEndTrimmed > keyStart && (cc[keyEndTrimmed-1] == ' ' || cc[keyEndTrimmed-1] == '\t') {
+			keyEndTrimmed--
+		}
+		key := cc[keyStart:keyEndTrimmed]
+
+		var value []byte
+		if keyEnd < partEnd && cc[keyEnd] == '=' {
+			valueStart := keyEnd + 1
+			for valueStart < partEnd && (cc[valueStart] == ' ' || cc[valueStart] == '\t') {
+				valueStart++
+			}
+			valueEnd := partEnd
+			for valueEnd > valueStart && (cc[valueEnd-1] == ' ' || cc[valueEnd-1] == '\t') {
+				valueEnd--
+			}
+			if valueStart <= valueEnd {
+				value = cc[valueStart:valueEnd]
+				// Handle quoted-string values per RFC 9111 Section 5.2
+				if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+					value = unquote(value)
+				}
+			}
+		}
+
+		fn(key, value)
+		i++ // skip comma
+	}
+}
+
+// unquote removes quotes and handles escaped characters in quoted-string values.
+// Per RFC 9111 Section 5.2, quoted-string values follow RFC 9110 Section 5.6.4.
+func unquote(quoted []byte) []byte {
+	if len(quoted) < 2 {
+		return quoted
+	}
+
+	// Remove surrounding quotes
+	inner := quoted[1 : len(quoted)-1]
+
+	// Check if there are any escaped characters (backslash followed by another character)
+	hasEscapes := false
+	for i := 0; i < len(inner)-1; i++ {
+		if inner[i] == '\\' {
+			hasEscapes = true
+			break
+		}
+	}
+
+	// If no escapes, return the inner content directly
+	if !hasEscapes {
+		return inner
+	}
+
+	// Process escaped characters
+	result := make([]byte, 0, len(inner))
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			// Skip the backslash and take the next character
+			i++
+			result = append(result, inner[i])
+		} else {
+			result = append(result, inner[i])
+		}
+	}
+
+	return result
+}
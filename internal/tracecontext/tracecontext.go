@@ -0,0 +1,30 @@
+// Package tracecontext lets the middleware forwarding requests upstream, such
+// as the proxy, propagate the trace context of a request without depending on
+// the tracing middleware, which registers the injector when it is created.
+package tracecontext
+
+import (
+	"sync/atomic"
+
+	"github.com/valyala/fasthttp"
+)
+
+// InjectFunc sets the trace context headers of an upstream request h from the
+// span of the request from context ctx, if there is one.
+type InjectFunc func(ctx any, h *fasthttp.RequestHeader)
+
+var injector atomic.Pointer[InjectFunc]
+
+// SetInjector sets the function called by Inject.
+func SetInjector(f InjectFunc) {
+	injector.Store(&f)
+}
+
+// Inject sets the trace context headers of an upstream request h from the
+// span of the request from context ctx. It does nothing when no injector is
+// set, as when the tracing middleware is not used.
+func Inject(ctx any, h *fasthttp.RequestHeader) {
+	if f := injector.Load(); f != nil {
+		(*f)(ctx, h)
+	}
+}
@@ -0,0 +1,24 @@
+package tracecontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_Inject(t *testing.T) {
+	// Not parallel: the injector is global
+
+	var h fasthttp.RequestHeader
+	Inject("ctx", &h)
+	require.Empty(t, h.Peek("traceparent"))
+
+	SetInjector(func(ctx any, h *fasthttp.RequestHeader) {
+		h.Set("traceparent", ctx.(string)) //nolint:forcetypeassert,errcheck // the test passes a string
+	})
+	t.Cleanup(func() { injector.Store(nil) })
+
+	Inject("ctx", &h)
+	require.Equal(t, "ctx", string(h.Peek("traceparent")))
+}
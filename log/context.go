@@ -19,7 +19,7 @@ const TagContextValue = "value:"
 
 // Tag names registered by Fiber's built-in middlewares. Treat them as the
 // canonical identifiers for the values produced by requestid, basicauth,
-// keyauth, csrf, and session — keeping format strings derived from these
+// keyauth, csrf, session, and tracing — keeping format strings derived from these
 // constants means renaming a tag here cascades automatically.
 const (
 	TagRequestID       = "requestid"
@@ -28,6 +28,8 @@ const (
 	TagAPIKey          = "api-key"
 	TagCSRFToken       = "csrf-token"
 	TagSessionID       = "session-id"
+	TagTraceID         = "trace-id"
+	TagSpanID          = "span-id"
 )
 
 const (
@@ -168,7 +170,7 @@ func MustRegisterContextTag(tag string, fn ContextTagFunc) {
 }
 
 // defaultContextTagMap pre-seeds renderers for the tag names used by Fiber's
-// built-in middleware (basicauth, csrf, keyauth, requestid, session). The
+// built-in middleware (basicauth, csrf, keyauth, requestid, session, tracing). The
 // stubs render empty strings so a format that references e.g. ${requestid}
 // compiles even when the corresponding middleware has not been initialized
 // yet — the slot is filled in once the middleware's New() runs.
@@ -179,6 +181,8 @@ func defaultContextTagMap() map[string]ContextTagFunc {
 		TagRequestIDDashed: emptyContextTag,
 		TagRequestID:       emptyContextTag,
 		TagSessionID:       emptyContextTag,
+		TagSpanID:          emptyContextTag,
+		TagTraceID:         emptyContextTag,
 		TagUsername:        emptyContextTag,
 		TagContextValue:    defaultContextValueTag,
 	}
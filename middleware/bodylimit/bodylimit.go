@@ -0,0 +1,39 @@
+package bodylimit
+
+import (
+	"github.com/gofiber/fiber/v3"
+)
+
+// New creates a new body limit middleware handler. Requests whose
+// Content-Length exceeds the limit are rejected before their body is read,
+// and the limit is set with c.SetBodyLimit for the bodies of unknown length
+// read with c.BodyStream.
+func New(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// A chunked body has no Content-Length. It is checked here once it is
+		// buffered, or while it is read when it is streamed
+		req := c.Request()
+		size := req.Header.ContentLength()
+		if size < 0 && !req.IsBodyStream() {
+			size = len(req.Body())
+		}
+		if size > cfg.Limit {
+			// The rest of a streamed body is not read, so the connection
+			// cannot be reused
+			if req.IsBodyStream() {
+				c.RequestCtx().SetConnectionClose()
+			}
+			return cfg.ErrorHandler(c, fiber.ErrRequestEntityTooLarge)
+		}
+
+		c.SetBodyLimit(cfg.Limit)
+		return c.Next()
+	}
+}
@@ -0,0 +1,160 @@
+package bodylimit
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func testStatus(t *testing.T, app *fiber.App, path string, size int) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, path, bytes.NewReader(bytes.Repeat([]byte("a"), size)))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func Test_ConfigDefault(t *testing.T) {
+	t.Parallel()
+
+	cfg := configDefault(Config{Limit: 64})
+	require.NotNil(t, cfg.ErrorHandler)
+	require.Equal(t, 64, cfg.Limit)
+
+	require.PanicsWithValue(t, "bodylimit: Limit must be positive", func() {
+		configDefault()
+	})
+	require.Panics(t, func() {
+		New(Config{Limit: -1})
+	})
+}
+
+func Test_BodyLimit(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{Limit: 64}))
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.SendString(strconv.Itoa(len(c.Body())))
+	})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", 0))
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", 64))
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, testStatus(t, app, "/", 65))
+}
+
+func Test_BodyLimit_Groups(t *testing.T) {
+	t.Parallel()
+
+	// The app limit is the part of the body buffered before the handlers
+	app := fiber.New(fiber.Config{StreamRequestBody: true, BodyLimit: 1024})
+	api := app.Group("/api", New(Config{Limit: 64}))
+	api.Post("/users", func(c fiber.Ctx) error {
+		return c.SendString(strconv.Itoa(len(c.Body())))
+	})
+	app.Post("/upload", New(Config{Limit: 16 * 1024}), func(c fiber.Ctx) error {
+		n, err := io.Copy(io.Discard, c.BodyStream())
+		if err != nil {
+			return err
+		}
+		return c.SendString(strconv.FormatInt(n, 10))
+	})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/api/users", 64))
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, testStatus(t, app, "/api/users", 65))
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/upload", 16*1024))
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, testStatus(t, app, "/upload", 16*1024+1))
+}
+
+func Test_BodyLimit_Chunked(t *testing.T) {
+	t.Parallel()
+
+	for _, stream := range []bool{false, true} {
+		app := fiber.New(fiber.Config{StreamRequestBody: stream, BodyLimit: 32})
+		app.Use(New(Config{Limit: 64}))
+		app.Post("/", func(c fiber.Ctx) error {
+			n, err := io.Copy(io.Discard, c.BodyStream())
+			if err != nil {
+				return err
+			}
+			return c.SendString(strconv.FormatInt(n, 10))
+		})
+
+		ln := fasthttputil.NewInmemoryListener()
+		go func() {
+			_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped below
+		}()
+
+		expected := map[int]int{16: fiber.StatusOK, 65: fiber.StatusRequestEntityTooLarge}
+		if stream {
+			// Streamed bodies are limited while they are read
+			expected = map[int]int{64: fiber.StatusOK, 65: fiber.StatusRequestEntityTooLarge, 4096: fiber.StatusRequestEntityTooLarge}
+		}
+		for size, status := range expected {
+			conn, err := ln.Dial()
+			require.NoError(t, err)
+			require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+			body := strings.Repeat("a", size)
+			_, err = fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n%x\r\n%s\r\n0\r\n\r\n", len(body), body)
+			require.NoError(t, err)
+
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			require.NoError(t, err)
+			require.Equal(t, status, resp.StatusCode, "stream %v, size %d", stream, size)
+			require.NoError(t, resp.Body.Close())
+			require.NoError(t, conn.Close())
+		}
+
+		require.NoError(t, app.Shutdown())
+	}
+}
+
+func Test_BodyLimit_Next(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Next: func(c fiber.Ctx) bool {
+			return c.Path() == "/upload"
+		},
+		Limit: 64,
+	}))
+	app.Post("/*", func(c fiber.Ctx) error {
+		return c.SendString(strconv.Itoa(len(c.Body())))
+	})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/upload", 128))
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, testStatus(t, app, "/", 128))
+}
+
+func Test_BodyLimit_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Limit: 64,
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			require.ErrorIs(t, err, fiber.ErrRequestEntityTooLarge)
+			return c.Status(fiber.StatusBadRequest).SendString("too large")
+		},
+	}))
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	require.Equal(t, fiber.StatusBadRequest, testStatus(t, app, "/", 128))
+}
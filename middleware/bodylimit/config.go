@@ -0,0 +1,51 @@
+package bodylimit
+
+import (
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config defines the config for the body limit middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	// Use this to exclude the routes having a limit of their own.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// ErrorHandler is called when a request body exceeds the limit.
+	// Receives fiber.ErrRequestEntityTooLarge as the error.
+	//
+	// Optional. Default: returns 413 Request Entity Too Large.
+	ErrorHandler fiber.ErrorHandler
+
+	// Limit is the maximum size of the request body, in bytes. A limit above
+	// the app BodyLimit only takes effect with the app StreamRequestBody
+	// setting, as the server rejects larger bodies before routing otherwise.
+	//
+	// Required.
+	Limit int
+}
+
+// ConfigDefault is the default config.
+var ConfigDefault = Config{
+	ErrorHandler: func(c fiber.Ctx, _ error) error {
+		return c.SendStatus(fiber.StatusRequestEntityTooLarge)
+	},
+}
+
+func configDefault(config ...Config) Config {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if cfg.Limit <= 0 {
+		panic("bodylimit: Limit must be positive")
+	}
+
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+
+	return cfg
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/valyala/fasthttp"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/cachecontrol"
 	"github.com/gofiber/fiber/v3/internal/fieldname"
 	"github.com/gofiber/fiber/v3/internal/headerlookup"
 )
@@ -643,7 +644,7 @@ func New(config ...Config) fiber.Handler {
 		varyHeader := utils.UnsafeString(joinedHeader(&c.Response().Header, fiber.HeaderVary, respCanonical))
 		hasPrivate := respCacheControl.hasPrivate
 		hasNoCache := respCacheControl.hasNoCache
-		varyNames, varyHasStar := parseVary(varyHeader)
+		varyNames, varyHasStar := cachecontrol.ParseVary(varyHeader)
 
 		// Respect server cache-control: no-store
 		if respCacheControl.hasNoStore {
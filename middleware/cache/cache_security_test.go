@@ -104,7 +104,7 @@ func Test_Cache_Security_DoS_ExcessiveVaryHeaders(t *testing.T) {
 	var count int
 	app.Get("/", func(c fiber.Ctx) error {
 		count++
-		// Generate more than maxVaryHeaders (32) headers
+		// Generate more than cachecontrol.MaxVaryHeaders (32) headers
 		varyHeaders := make([]string, 50)
 		for i := range 50 {
 			varyHeaders[i] = fmt.Sprintf("X-Custom-Header-%d", i)
@@ -1300,7 +1300,7 @@ func Test_VaryContentType_KeyIsStableAcrossTheFormFold(t *testing.T) {
 //
 // PeekAll compares stored keys byte for byte. With DisableHeaderNormalizing the
 // store keeps the spelling the client sent, while the names reaching the key
-// come lower-cased from parseVary — so every lookup missed and the dimension
+// come lower-cased from cachecontrol.ParseVary — so every lookup missed and the dimension
 // dropped out of the key silently. A request sending application/json was then
 // served the entry stored for a form.
 func Test_Vary_PartitionsWithoutHeaderNormalizing(t *testing.T) {
@@ -2,7 +2,8 @@ package cache
 
 import (
 	"github.com/gofiber/utils/v2"
-	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3/internal/cachecontrol"
 )
 
 // hasDirective checks if a cache directive header value contains a directive (case-insensitive).
@@ -44,137 +45,6 @@ func hasDirective(cc, directive string) bool {
 	return false
 }
 
-func parseUintDirective(val []byte) (uint64, bool) {
-	if len(val) == 0 {
-		return 0, false
-	}
-	parsed, err := fasthttp.ParseUint(val)
-	if err != nil || parsed < 0 {
-		return 0, false
-	}
-	return uint64(parsed), true
-}
-
-func parseCacheControlDirectives(cc []byte, fn func(key, value []byte)) {
-	for i := 0; i < len(cc); {
-		// skip leading separators and OWS (space/tab per RFC 9110 §5.6.3)
-		for i < len(cc) && (cc[i] == ' ' || cc[i] == '\t' || cc[i] == ',') {
-			i++
-		}
-		if i >= len(cc) {
-			break
-		}
-
-		// A directive value may be a quoted-string (RFC 9111 §5.2), and a comma
-		// inside one is data rather than the end of the directive. Scanning past
-		// it read the tokens in an extension value as directives of their own,
-		// so `ext="a, public, b"` said public and authorized sharing.
-		// Unterminated quotes swallow the rest of the field, which is the safe
-		// way round: what follows is read as one value rather than as directives
-		// a sender never separated.
-		start := i
-		inQuotes := false
-		for i < len(cc) {
-			if inQuotes && cc[i] == '\\' && i+1 < len(cc) {
-				// A quoted-pair: the byte after the backslash is data, a quote
-				// among it, so the string does not end there.
-				i += 2
-				continue
-			}
-			if cc[i] == '"' {
-				inQuotes = !inQuotes
-			} else if cc[i] == ',' && !inQuotes {
-				break
-			}
-			i++
-		}
-		partEnd := i
-		for partEnd > start && (cc[partEnd-1] == ' ' || cc[partEnd-1] == '\t') {
-			partEnd--
-		}
-
-		keyStart := start
-		for keyStart < partEnd && (cc[keyStart] == ' ' || cc[keyStart] == '\t') {
-			keyStart++
-		}
-		if keyStart >= partEnd {
-			continue
-		}
-
-		keyEnd := keyStart
-		for keyEnd < partEnd && cc[keyEnd] != '=' {
-			keyEnd++
-		}
-		// Trim trailing OWS from key
-		keyEndTrimmed := keyEnd
-		for keyEndTrimmed > keyStart && (cc[keyEndTrimmed-1] == ' ' || cc[keyEndTrimmed-1] == '\t') {
-			keyEndTrimmed--
-		}
-		key := cc[keyStart:keyEndTrimmed]
-
-		var value []byte
-		if keyEnd < partEnd && cc[keyEnd] == '=' {
-			valueStart := keyEnd + 1
-			for valueStart < partEnd && (cc[valueStart] == ' ' || cc[valueStart] == '\t') {
-				valueStart++
-			}
-			valueEnd := partEnd
-			for valueEnd > valueStart && (cc[valueEnd-1] == ' ' || cc[valueEnd-1] == '\t') {
-				valueEnd--
-			}
-			if valueStart <= valueEnd {
-				value = cc[valueStart:valueEnd]
-				// Handle quoted-string values per RFC 9111 Section 5.2
-				if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
-					value = unquoteCacheDirective(value)
-				}
-			}
-		}
-
-		fn(key, value)
-		i++ // skip comma
-	}
-}
-
-// unquoteCacheDirective removes quotes and handles escaped characters in quoted-string values.
-// Per RFC 9111 Section 5.2, quoted-string values follow RFC 9110 Section 5.6.4.
-func unquoteCacheDirective(quoted []byte) []byte {
-	if len(quoted) < 2 {
-		return quoted
-	}
-
-	// Remove surrounding quotes
-	inner := quoted[1 : len(quoted)-1]
-
-	// Check if there are any escaped characters (backslash followed by another character)
-	hasEscapes := false
-	for i := 0; i < len(inner)-1; i++ {
-		if inner[i] == '\\' {
-			hasEscapes = true
-			break
-		}
-	}
-
-	// If no escapes, return the inner content directly
-	if !hasEscapes {
-		return inner
-	}
-
-	// Process escaped characters
-	result := make([]byte, 0, len(inner))
-	for i := 0; i < len(inner); i++ {
-		if inner[i] == '\\' && i+1 < len(inner) {
-			// Skip the backslash and take the next character
-			i++
-			result = append(result, inner[i])
-		} else {
-			result = append(result, inner[i])
-		}
-	}
-
-	return result
-}
-
 type responseCacheControl struct {
 	maxAge          uint64
 	sMaxAge         uint64
@@ -190,7 +60,7 @@ type responseCacheControl struct {
 
 func parseResponseCacheControl(cc []byte) responseCacheControl {
 	parsed := responseCacheControl{}
-	parseCacheControlDirectives(cc, func(key, value []byte) {
+	cachecontrol.ParseDirectives(cc, func(key, value []byte) {
 		switch {
 		case utils.EqualFold(utils.UnsafeString(key), noStore):
 			parsed.hasNoStore = true
@@ -201,12 +71,12 @@ func parseResponseCacheControl(cc []byte) responseCacheControl {
 		case utils.EqualFold(utils.UnsafeString(key), "public"):
 			parsed.hasPublic = true
 		case utils.EqualFold(utils.UnsafeString(key), "max-age"):
-			if v, ok := parseUintDirective(value); ok {
+			if v, ok := cachecontrol.ParseUint(value); ok {
 				parsed.maxAgeSet = true
 				parsed.maxAge = v
 			}
 		case utils.EqualFold(utils.UnsafeString(key), "s-maxage"):
-			if v, ok := parseUintDirective(value); ok {
+			if v, ok := cachecontrol.ParseUint(value); ok {
 				parsed.sMaxAgeSet = true
 				parsed.sMaxAge = v
 			}
@@ -223,7 +93,7 @@ func parseResponseCacheControl(cc []byte) responseCacheControl {
 
 func parseRequestCacheControl(cc []byte) requestCacheDirectives {
 	directives := requestCacheDirectives{}
-	parseCacheControlDirectives(cc, func(key, value []byte) {
+	cachecontrol.ParseDirectives(cc, func(key, value []byte) {
 		switch {
 		case utils.EqualFold(utils.UnsafeString(key), noStore):
 			directives.noStore = true
@@ -232,7 +102,7 @@ func parseRequestCacheControl(cc []byte) requestCacheDirectives {
 		case utils.EqualFold(utils.UnsafeString(key), "only-if-cached"):
 			directives.onlyIfCached = true
 		case utils.EqualFold(utils.UnsafeString(key), "max-age"):
-			if sec, ok := parseUintDirective(value); ok {
+			if sec, ok := cachecontrol.ParseUint(value); ok {
 				directives.maxAgeSet = true
 				directives.maxAge = sec
 			}
@@ -240,12 +110,12 @@ func parseRequestCacheControl(cc []byte) requestCacheDirectives {
 			directives.maxStaleSet = true
 			directives.maxStaleAny = len(value) == 0
 			if !directives.maxStaleAny {
-				if sec, ok := parseUintDirective(value); ok {
+				if sec, ok := cachecontrol.ParseUint(value); ok {
 					directives.maxStale = sec
 				}
 			}
 		case utils.EqualFold(utils.UnsafeString(key), "min-fresh"):
-			if sec, ok := parseUintDirective(value); ok {
+			if sec, ok := cachecontrol.ParseUint(value); ok {
 				directives.minFreshSet = true
 				directives.minFresh = sec
 			}
@@ -305,19 +305,9 @@ func Test_indexedHeap_PushAndOps(t *testing.T) {
 	require.Equal(t, "z", h2.entries[0].key)
 }
 
-func Test_parseUintDirective(t *testing.T) {
+func Test_parseResponseCacheControl_InvalidUint(t *testing.T) {
 	t.Parallel()
 
-	v, ok := parseUintDirective([]byte("60"))
-	require.True(t, ok)
-	require.Equal(t, uint64(60), v)
-
-	_, ok = parseUintDirective(nil)
-	require.False(t, ok)
-
-	_, ok = parseUintDirective([]byte("not-a-number"))
-	require.False(t, ok)
-
 	// Invalid max-age values are ignored when parsing full directives.
 	parsed := parseResponseCacheControl([]byte("max-age=abc, s-maxage=xyz"))
 	require.False(t, parsed.maxAgeSet)
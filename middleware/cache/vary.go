@@ -6,66 +6,14 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/gofiber/utils/v2"
-	utilsstrings "github.com/gofiber/utils/v2/strings"
 	"github.com/valyala/fasthttp"
-)
-
-// maxVaryHeaders caps the number of Vary headers processed to prevent DoS.
-const maxVaryHeaders = 32
-
-// defaultVaryNames is the capacity parseVary starts its name slice at, sized to
-// hold what a real Vary field carries without regrowing.
-const defaultVaryNames = 8
-
-func parseVary(vary string) ([]string, bool) {
-	// Asked of every response, and almost none carry the field. The scan below
-	// would answer the same, after allocating the slice it never fills.
-	if utils.TrimSpace(vary) == "" {
-		return nil, false
-	}
 
-	// Given a capacity up front, because appending into a nil slice regrows the
-	// backing array as it goes — three allocations for a three-name manifest —
-	// and this runs per lookup for every entry that varies.
-	//
-	// A fixed size rather than one counted off the separators: counting them
-	// reads the whole field, while the walk below stops at the cap. That gave a
-	// long list one full pass it is about to be rejected for, which is the work
-	// maxVaryHeaders exists to refuse. Real fields carry a name or three, so the
-	// exact size bought one allocation over this in a case that does not arise.
-	names := make([]string, 0, defaultVaryNames)
-	count := 0
-	for part := range strings.SplitSeq(vary, ",") {
-		name := utils.TrimSpace(utilsstrings.ToLower(part))
-		if name == "" {
-			continue
-		}
-		if name == "*" {
-			return nil, true
-		}
-
-		// Protect against DoS via excessive Vary headers
-		count++
-		if count > maxVaryHeaders {
-			// Too many Vary headers, treat as uncacheable (same as Vary: *)
-			return nil, true
-		}
-
-		names = append(names, name)
-	}
-
-	if len(names) == 0 {
-		return nil, false
-	}
-
-	sort.Strings(names)
-	return names, false
-}
+	"github.com/gofiber/fiber/v3/internal/cachecontrol"
+)
 
 // appendVaryKey appends the variant suffix — "|vary|" and the digest of the
 // Vary'd request headers — to dst.
@@ -134,7 +82,7 @@ func loadVaryManifest(ctx context.Context, manager *manager, manifestKey string)
 		return nil, false, err
 	}
 	manifest := utils.UnsafeString(raw)
-	names, hasStar := parseVary(manifest)
+	names, hasStar := cachecontrol.ParseVary(manifest)
 	if hasStar {
 		return nil, false, nil
 	}
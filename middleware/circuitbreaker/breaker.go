@@ -0,0 +1,248 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit.
+type State int
+
+const (
+	// StateClosed lets every request through and counts the failures.
+	StateClosed State = iota
+	// StateOpen rejects every request until OpenTimeout has passed.
+	StateOpen
+	// StateHalfOpen lets HalfOpenRequests probe requests through to decide
+	// whether to close the circuit again.
+	StateHalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrOpen is returned for requests rejected by an open circuit.
+var ErrOpen = errors.New("circuitbreaker: circuit is open")
+
+// windowBuckets is the number of buckets the rolling window is divided into.
+const windowBuckets = 10
+
+// settings are the options shared by the middleware and the client hooks.
+type settings struct {
+	onStateChange    func(key string, from, to State)
+	clock            func() time.Time
+	failureThreshold int
+	failureRatio     float64
+	minRequests      int
+	window           time.Duration
+	openTimeout      time.Duration
+	halfOpenRequests int
+}
+
+func (s *settings) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// bucket counts the requests of one slice of the rolling window.
+type bucket struct {
+	epoch    int64
+	requests int
+	failures int
+}
+
+// circuit is the breaker of a single key.
+type circuit struct {
+	openedAt time.Time
+	key      string
+	buckets  [windowBuckets]bucket
+	// generation changes with every state, so outcomes of requests let
+	// through in an earlier state are ignored.
+	generation  uint64
+	state       State
+	consecutive int
+	probes      int
+	successes   int
+	mu          sync.Mutex
+}
+
+// allow reports whether the circuit lets a request through. When it does, the
+// returned generation must be passed to record with the request's outcome;
+// when it does not, it returns how long the circuit stays open.
+func (c *circuit) allow(s *settings) (generation uint64, retry time.Duration, ok bool) {
+	now := s.now()
+
+	c.mu.Lock()
+	from := c.state
+	if c.state == StateOpen {
+		if wait := s.openTimeout - now.Sub(c.openedAt); wait > 0 {
+			c.mu.Unlock()
+			return 0, wait, false
+		}
+		c.setState(StateHalfOpen, now)
+	}
+	if c.state == StateHalfOpen {
+		if c.probes >= s.halfOpenRequests {
+			c.mu.Unlock()
+			return 0, 0, false
+		}
+		c.probes++
+	}
+	generation, to := c.generation, c.state
+	c.mu.Unlock()
+
+	c.notify(s, from, to)
+	return generation, 0, true
+}
+
+// record counts the outcome of a request let through by allow.
+func (c *circuit) record(s *settings, generation uint64, failed bool) {
+	now := s.now()
+
+	c.mu.Lock()
+	if generation != c.generation {
+		c.mu.Unlock()
+		return
+	}
+	from := c.state
+	switch c.state {
+	case StateClosed:
+		c.count(s, now, failed)
+		if c.tripped(s, now) {
+			c.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		c.probes--
+		if failed {
+			c.setState(StateOpen, now)
+		} else if c.successes++; c.successes >= s.halfOpenRequests {
+			c.setState(StateClosed, now)
+		}
+	default:
+	}
+	to := c.state
+	c.mu.Unlock()
+
+	c.notify(s, from, to)
+}
+
+// count adds a request to the rolling window.
+func (c *circuit) count(s *settings, now time.Time, failed bool) {
+	if failed {
+		c.consecutive++
+	} else {
+		c.consecutive = 0
+	}
+
+	epoch := c.epoch(s, now)
+	b := &c.buckets[epoch%windowBuckets]
+	if b.epoch != epoch {
+		*b = bucket{epoch: epoch}
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+}
+
+// tripped reports whether the failures counted so far open the circuit.
+func (c *circuit) tripped(s *settings, now time.Time) bool {
+	if s.failureThreshold > 0 && c.consecutive >= s.failureThreshold {
+		return true
+	}
+	if s.failureRatio <= 0 {
+		return false
+	}
+
+	var requests, failures int
+	epoch := c.epoch(s, now)
+	for i := range c.buckets {
+		if b := &c.buckets[i]; b.epoch > epoch-windowBuckets {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests >= s.minRequests && float64(failures) >= s.failureRatio*float64(requests)
+}
+
+// epoch returns the index of the bucket now falls in, counted from the Unix
+// epoch.
+func (c *circuit) epoch(s *settings, now time.Time) int64 {
+	return now.UnixNano() / max(int64(s.window/windowBuckets), 1)
+}
+
+// setState moves the circuit to state and starts it over.
+func (c *circuit) setState(state State, now time.Time) {
+	c.state = state
+	c.generation++
+	c.consecutive = 0
+	c.probes = 0
+	c.successes = 0
+	c.buckets = [windowBuckets]bucket{}
+	if state == StateOpen {
+		c.openedAt = now
+	}
+}
+
+// notify calls OnStateChange if the state changed from from to to.
+func (c *circuit) notify(s *settings, from, to State) {
+	if from != to && s.onStateChange != nil {
+		s.onStateChange(c.key, from, to)
+	}
+}
+
+// circuits holds the circuit of every key.
+type circuits struct {
+	settings *settings
+	m        map[string]*circuit
+	mu       sync.Mutex
+}
+
+func newCircuits(s *settings) *circuits {
+	return &circuits{settings: s, m: make(map[string]*circuit)}
+}
+
+// get returns the circuit of key, creating a closed one if needed.
+func (r *circuits) get(key string) *circuit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.m[key]
+	if !ok {
+		c = &circuit{key: key}
+		r.m[key] = c
+	}
+	return c
+}
+
+// state returns the state of the circuit of key.
+func (r *circuits) state(key string) State {
+	r.mu.Lock()
+	c, ok := r.m[key]
+	r.mu.Unlock()
+	if !ok {
+		return StateClosed
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// An open circuit whose timeout passed lets the next request through
+	if c.state == StateOpen && r.settings.now().Sub(c.openedAt) >= r.settings.openTimeout {
+		return StateHalfOpen
+	}
+	return c.state
+}
@@ -0,0 +1,49 @@
+// Package circuitbreaker stops calling a failing downstream for a while so
+// requests fail fast instead of waiting for it to time out. It provides a
+// middleware guarding route handlers and hooks guarding the requests of a
+// client.Client, keyed by host.
+package circuitbreaker
+
+import (
+	"math"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+)
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	circuits := newCircuits(newSettings(cfg.OnStateChange, cfg.clock, cfg.FailureThreshold, cfg.FailureRatio,
+		cfg.MinRequests, cfg.Window, cfg.OpenTimeout, cfg.HalfOpenRequests))
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		circuit := circuits.get(cfg.KeyGenerator(c))
+		generation, retry, ok := circuit.allow(circuits.settings)
+		if !ok {
+			c.Set(fiber.HeaderRetryAfter, utils.FormatUint(uint64(max(math.Ceil(retry.Seconds()), 1))))
+			return cfg.OpenHandler(c)
+		}
+
+		// A panicking handler counts as a failure
+		recorded := false
+		defer func() {
+			if !recorded {
+				circuit.record(circuits.settings, generation, true)
+			}
+		}()
+
+		err := c.Next()
+		circuit.record(circuits.settings, generation, cfg.IsFailure(c, err))
+		recorded = true
+		return err
+	}
+}
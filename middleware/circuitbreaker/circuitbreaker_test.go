@@ -0,0 +1,291 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/recover"
+)
+
+// testClock is a manually advanced clock.
+type testClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// transitions records the state changes of circuits.
+type transitions struct {
+	list []string
+	mu   sync.Mutex
+}
+
+func (tr *transitions) record(key string, from, to State) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.list = append(tr.list, key+":"+from.String()+"->"+to.String())
+}
+
+func (tr *transitions) get() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return append([]string(nil), tr.list...)
+}
+
+func newBreakerApp(t *testing.T, cfg Config) *fiber.App {
+	t.Helper()
+
+	app := fiber.New()
+	app.Use(New(cfg))
+	app.Get("/:status", func(c fiber.Ctx) error {
+		switch c.Params("status") {
+		case "fail":
+			return c.SendStatus(fiber.StatusBadGateway)
+		case "error":
+			return errors.New("downstream failed")
+		case "notfound":
+			return fiber.ErrNotFound
+		default:
+			return c.SendString("ok")
+		}
+	})
+	return app
+}
+
+func step(t *testing.T, app *fiber.App, path string, status int) *http.Response {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, status, resp.StatusCode)
+	return resp
+}
+
+func Test_CircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	clock := newTestClock()
+	tr := &transitions{}
+	app := newBreakerApp(t, Config{
+		FailureThreshold: 3,
+		OpenTimeout:      10 * time.Second,
+		OnStateChange:    tr.record,
+		clock:            clock.Now,
+	})
+
+	// A success resets the count, client errors are not failures
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/error", fiber.StatusInternalServerError)
+	step(t, app, "/ok", fiber.StatusOK)
+	step(t, app, "/notfound", fiber.StatusNotFound)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	require.Empty(t, tr.get())
+
+	step(t, app, "/error", fiber.StatusInternalServerError)
+	require.Equal(t, []string{":closed->open"}, tr.get())
+
+	// The open circuit rejects requests without calling the handler
+	resp := step(t, app, "/ok", fiber.StatusServiceUnavailable)
+	require.Equal(t, "10", resp.Header.Get(fiber.HeaderRetryAfter))
+	clock.Add(7500 * time.Millisecond)
+	resp = step(t, app, "/ok", fiber.StatusServiceUnavailable)
+	require.Equal(t, "3", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	// A failed probe opens it again
+	clock.Add(2500 * time.Millisecond)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/ok", fiber.StatusServiceUnavailable)
+
+	// A successful one closes it
+	clock.Add(10 * time.Second)
+	step(t, app, "/ok", fiber.StatusOK)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/ok", fiber.StatusOK)
+
+	require.Equal(t, []string{
+		":closed->open",
+		":open->half-open",
+		":half-open->open",
+		":open->half-open",
+		":half-open->closed",
+	}, tr.get())
+}
+
+func Test_CircuitBreaker_FailureRatio(t *testing.T) {
+	t.Parallel()
+
+	clock := newTestClock()
+	app := newBreakerApp(t, Config{
+		FailureThreshold: -1,
+		FailureRatio:     0.5,
+		MinRequests:      4,
+		Window:           10 * time.Second,
+		clock:            clock.Now,
+	})
+
+	// Not enough requests yet
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+
+	// The failures slide out of the window
+	clock.Add(10 * time.Second)
+	step(t, app, "/ok", fiber.StatusOK)
+	step(t, app, "/ok", fiber.StatusOK)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/ok", fiber.StatusOK)
+
+	// Two failures out of five, then three out of six
+	clock.Add(5 * time.Second)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/ok", fiber.StatusServiceUnavailable)
+}
+
+func Test_CircuitBreaker_HalfOpenRequests(t *testing.T) {
+	t.Parallel()
+
+	s := newSettings(nil, newTestClock().Now, 1, 0, 0, 0, time.Second, 2)
+	c := &circuit{}
+
+	generation, _, ok := c.allow(s)
+	require.True(t, ok)
+	c.record(s, generation, true)
+	require.Equal(t, StateOpen, c.state)
+
+	s.clock = func() time.Time { return time.Unix(1_700_000_001, 0) }
+
+	// Two probes at once, a third is rejected
+	first, _, ok := c.allow(s)
+	require.True(t, ok)
+	second, _, ok := c.allow(s)
+	require.True(t, ok)
+	_, retry, ok := c.allow(s)
+	require.False(t, ok)
+	require.Zero(t, retry)
+
+	// Both must succeed to close the circuit
+	c.record(s, first, false)
+	require.Equal(t, StateHalfOpen, c.state)
+	c.record(s, second, false)
+	require.Equal(t, StateClosed, c.state)
+
+	// Outcomes of an earlier state are ignored
+	c.record(s, first, true)
+	require.Equal(t, StateClosed, c.state)
+}
+
+func Test_CircuitBreaker_KeyGenerator(t *testing.T) {
+	t.Parallel()
+
+	tr := &transitions{}
+	app := fiber.New()
+	app.Use(New(Config{
+		FailureThreshold: 1,
+		KeyGenerator: func(c fiber.Ctx) string {
+			return c.Get("X-Downstream")
+		},
+		OnStateChange: tr.record,
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusServiceUnavailable)
+	})
+	app.Get("/ok", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	request := func(path, downstream string) int {
+		req := httptest.NewRequest(fiber.MethodGet, path, http.NoBody)
+		req.Header.Set("X-Downstream", downstream)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	require.Equal(t, fiber.StatusServiceUnavailable, request("/", "a"))
+	require.Equal(t, []string{"a:closed->open"}, tr.get())
+	require.Equal(t, fiber.StatusServiceUnavailable, request("/ok", "a"))
+	require.Equal(t, fiber.StatusOK, request("/ok", "b"))
+}
+
+func Test_CircuitBreaker_Custom(t *testing.T) {
+	t.Parallel()
+
+	app := newBreakerApp(t, Config{
+		FailureThreshold: 1,
+		IsFailure: func(c fiber.Ctx, _ error) bool {
+			return c.Response().StatusCode() == fiber.StatusBadGateway
+		},
+		OpenHandler: func(c fiber.Ctx) error {
+			return c.Status(fiber.StatusTeapot).SendString("open")
+		},
+		Next: func(c fiber.Ctx) bool {
+			return c.Path() == "/skip"
+		},
+	})
+
+	step(t, app, "/error", fiber.StatusInternalServerError)
+	step(t, app, "/fail", fiber.StatusBadGateway)
+	step(t, app, "/ok", fiber.StatusTeapot)
+	step(t, app, "/skip", fiber.StatusOK)
+}
+
+func Test_CircuitBreaker_Panic(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(recover.New())
+	app.Use(New(Config{FailureThreshold: 1}))
+	app.Get("/", func(_ fiber.Ctx) error {
+		panic("boom")
+	})
+
+	step(t, app, "/", fiber.StatusInternalServerError)
+	step(t, app, "/", fiber.StatusServiceUnavailable)
+}
+
+func Test_CircuitBreaker_Config(t *testing.T) {
+	t.Parallel()
+
+	cfg := configDefault()
+	require.Equal(t, 5, cfg.FailureThreshold)
+	require.Equal(t, time.Minute, cfg.Window)
+
+	cfg = configDefault(Config{FailureThreshold: -1, FailureRatio: 0.2})
+	require.Equal(t, -1, cfg.FailureThreshold)
+	require.Equal(t, 10, cfg.MinRequests)
+	require.Equal(t, 30*time.Second, cfg.OpenTimeout)
+	require.Equal(t, 1, cfg.HalfOpenRequests)
+
+	require.PanicsWithValue(t, "circuitbreaker: FailureRatio must be between 0 and 1", func() {
+		New(Config{FailureRatio: 2})
+	})
+	require.PanicsWithValue(t, "circuitbreaker: FailureThreshold or FailureRatio must be enabled", func() {
+		New(Config{FailureThreshold: -1})
+	})
+
+	require.Equal(t, "unknown", State(42).String())
+}
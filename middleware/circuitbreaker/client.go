@@ -0,0 +1,116 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3/client"
+)
+
+// ClientBreaker guards the requests of a client.Client with a circuit per
+// key, the host of the request URL by default. Its hooks are added to a
+// client with Register:
+//
+//	cb := circuitbreaker.NewClient()
+//	cc := cb.Register(client.New())
+//
+// Requests rejected by an open circuit fail with an error wrapping ErrOpen
+// without being sent.
+type ClientBreaker struct {
+	circuits *circuits
+	pending  map[*client.Request]admission
+	cfg      ClientConfig
+	mu       sync.Mutex
+}
+
+// admission is a request let through by a circuit, waiting for its outcome.
+type admission struct {
+	circuit    *circuit
+	generation uint64
+}
+
+// NewClient creates a new ClientBreaker.
+func NewClient(config ...ClientConfig) *ClientBreaker {
+	// Set default config
+	cfg := clientConfigDefault(config...)
+
+	return &ClientBreaker{
+		circuits: newCircuits(newSettings(cfg.OnStateChange, cfg.clock, cfg.FailureThreshold, cfg.FailureRatio,
+			cfg.MinRequests, cfg.Window, cfg.OpenTimeout, cfg.HalfOpenRequests)),
+		pending: make(map[*client.Request]admission),
+		cfg:     cfg,
+	}
+}
+
+// Register adds the request, response and error hooks of the breaker to c and
+// returns it. Adding the breaker after other request hooks keeps their
+// failures from being counted against the circuit.
+func (b *ClientBreaker) Register(c *client.Client) *client.Client {
+	return c.AddRequestHook(b.RequestHook).
+		AddResponseHook(b.ResponseHook).
+		AddErrorHook(b.ErrorHook)
+}
+
+// State returns the state of the circuit of key.
+func (b *ClientBreaker) State(key string) State {
+	return b.circuits.state(key)
+}
+
+// RequestHook rejects the request if its circuit is open.
+func (b *ClientBreaker) RequestHook(c *client.Client, req *client.Request) error {
+	key := b.cfg.KeyGenerator(c, req)
+	circuit := b.circuits.get(key)
+	generation, _, ok := circuit.allow(b.circuits.settings)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOpen, key)
+	}
+
+	b.mu.Lock()
+	b.pending[req] = admission{circuit: circuit, generation: generation}
+	b.mu.Unlock()
+	return nil
+}
+
+// ResponseHook counts the response of a request against its circuit.
+func (b *ClientBreaker) ResponseHook(_ *client.Client, resp *client.Response, req *client.Request) error {
+	if a, ok := b.admitted(req); ok {
+		a.circuit.record(b.circuits.settings, a.generation, b.cfg.IsFailure(resp, nil))
+	}
+	return nil
+}
+
+// ErrorHook counts a request that failed before its response reached
+// ResponseHook, such as on connection errors and timeouts, against its
+// circuit.
+func (b *ClientBreaker) ErrorHook(_ *client.Client, req *client.Request, err error) {
+	if a, ok := b.admitted(req); ok {
+		a.circuit.record(b.circuits.settings, a.generation, b.cfg.IsFailure(nil, err))
+	}
+}
+
+// admitted removes and returns the admission of req.
+func (b *ClientBreaker) admitted(req *client.Request) (admission, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	a, ok := b.pending[req]
+	if ok {
+		delete(b.pending, req)
+	}
+	return a, ok
+}
+
+// requestHost returns the host of the URL req is sent to.
+func requestHost(c *client.Client, req *client.Request) string {
+	raw := req.URL()
+	if !strings.Contains(raw, "://") {
+		raw = c.BaseURL() + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,164 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+)
+
+func startClientTestServer(t *testing.T) *fasthttputil.InmemoryListener {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	app := fiber.New()
+	app.Get("/:status", func(c fiber.Ctx) error {
+		if c.Params("status") == "fail" {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString("ok")
+	})
+
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped in cleanup
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+	return ln
+}
+
+func Test_ClientBreaker_TransportErrors(t *testing.T) {
+	t.Parallel()
+
+	clock := newTestClock()
+	tr := &transitions{}
+	cb := NewClient(ClientConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      5 * time.Second,
+		OnStateChange:    tr.record,
+		clock:            clock.Now,
+	})
+
+	ln := startClientTestServer(t)
+	var dials, down atomic.Int32
+	down.Store(1)
+	cc := cb.Register(client.New())
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		dials.Add(1)
+		if down.Load() == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return ln.Dial()
+	})
+
+	// The dead backend opens the circuit
+	for range 2 {
+		_, err := cc.Get("http://backend.local/ok")
+		require.ErrorContains(t, err, "connection refused")
+	}
+	require.Equal(t, StateOpen, cb.State("backend.local"))
+	require.Equal(t, []string{"backend.local:closed->open"}, tr.get())
+
+	// Requests then fail fast without dialing
+	_, err := cc.Get("http://backend.local/ok")
+	require.ErrorIs(t, err, ErrOpen)
+	require.Equal(t, int32(2), dials.Load())
+
+	// Other hosts have circuits of their own
+	require.Equal(t, StateClosed, cb.State("other.local"))
+
+	// Once the backend is back, a probe closes the circuit
+	down.Store(0)
+	clock.Add(5 * time.Second)
+	require.Equal(t, StateHalfOpen, cb.State("backend.local"))
+	resp, err := cc.Get("http://backend.local/ok")
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp.String())
+	require.Equal(t, StateClosed, cb.State("backend.local"))
+	require.Equal(t, []string{
+		"backend.local:closed->open",
+		"backend.local:open->half-open",
+		"backend.local:half-open->closed",
+	}, tr.get())
+}
+
+func Test_ClientBreaker_ServerErrors(t *testing.T) {
+	t.Parallel()
+
+	ln := startClientTestServer(t)
+	cb := NewClient(ClientConfig{FailureThreshold: 2})
+	cc := cb.Register(client.New()).SetBaseURL("http://backend.local")
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return ln.Dial()
+	})
+
+	resp, err := cc.Get("/fail")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode())
+	_, err = cc.Get("/ok")
+	require.NoError(t, err)
+
+	// A success in between resets the count
+	for range 2 {
+		_, err = cc.Get("/fail")
+		require.NoError(t, err)
+	}
+	_, err = cc.Get("/ok")
+	require.ErrorIs(t, err, ErrOpen)
+	require.Equal(t, StateOpen, cb.State("backend.local"))
+}
+
+func Test_ClientBreaker_Custom(t *testing.T) {
+	t.Parallel()
+
+	ln := startClientTestServer(t)
+	cb := NewClient(ClientConfig{
+		FailureThreshold: 1,
+		KeyGenerator: func(_ *client.Client, _ *client.Request) string {
+			return "shared"
+		},
+		IsFailure: func(resp *client.Response, err error) bool {
+			return err == nil && resp.String() == "ok"
+		},
+	})
+	cc := cb.Register(client.New())
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return ln.Dial()
+	})
+
+	_, err := cc.Get("http://a.local/fail")
+	require.NoError(t, err)
+	_, err = cc.Get("http://b.local/ok")
+	require.NoError(t, err)
+	_, err = cc.Get("http://c.local/ok")
+	require.ErrorIs(t, err, ErrOpen)
+	require.ErrorContains(t, err, "shared")
+}
+
+func Test_ClientBreaker_Config(t *testing.T) {
+	t.Parallel()
+
+	cfg := clientConfigDefault()
+	require.NotNil(t, cfg.KeyGenerator)
+	require.Equal(t, 5, cfg.FailureThreshold)
+
+	cfg = clientConfigDefault(ClientConfig{Window: time.Second})
+	require.NotNil(t, cfg.IsFailure)
+	require.Equal(t, time.Second, cfg.Window)
+	require.Equal(t, 1, cfg.HalfOpenRequests)
+
+	require.PanicsWithValue(t, "circuitbreaker: FailureRatio must be between 0 and 1", func() {
+		NewClient(ClientConfig{FailureRatio: -1})
+	})
+
+	require.Equal(t, "api.local:8080", requestHost(client.New(), client.AcquireRequest().SetURL("http://api.local:8080/x")))
+	require.Empty(t, requestHost(client.New(), client.AcquireRequest().SetURL("http://[::1")))
+}
@@ -0,0 +1,277 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// KeyGenerator returns the key of the circuit a request goes through,
+	// such as the route or the downstream it calls. Every key has a circuit
+	// of its own.
+	//
+	// Optional. Default: all requests share a single circuit
+	KeyGenerator func(c fiber.Ctx) string
+
+	// IsFailure reports whether a request failed, given the error returned
+	// by the next handlers.
+	//
+	// Optional. Default: errors and responses with a 5xx status code
+	IsFailure func(c fiber.Ctx, err error) bool
+
+	// OpenHandler is called for the requests an open circuit rejects. The
+	// Retry-After header is set beforehand.
+	//
+	// Optional. Default: func(c fiber.Ctx) error {
+	//   return c.SendStatus(fiber.StatusServiceUnavailable)
+	// }
+	OpenHandler fiber.Handler
+
+	// OnStateChange is called whenever a circuit changes state, for example
+	// to log it or to update a metric.
+	//
+	// Optional. Default: nil
+	OnStateChange func(key string, from, to State)
+
+	// clock returns the current time. Tests use it to control the circuit.
+	clock func() time.Time
+
+	// FailureThreshold is the number of consecutive failures that opens
+	// the circuit. A negative value disables it.
+	//
+	// Optional. Default: 5
+	FailureThreshold int
+
+	// FailureRatio is the ratio of failed requests within Window, from 0 to
+	// 1, that opens the circuit.
+	//
+	// Optional. Default: 0 (disabled)
+	FailureRatio float64
+
+	// MinRequests is the number of requests within Window below which
+	// FailureRatio is not applied.
+	//
+	// Optional. Default: 10
+	MinRequests int
+
+	// Window is the rolling window FailureRatio is computed over.
+	//
+	// Optional. Default: 1 * time.Minute
+	Window time.Duration
+
+	// OpenTimeout is how long the circuit stays open before letting probe
+	// requests through.
+	//
+	// Optional. Default: 30 * time.Second
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of probe requests a half-open circuit
+	// lets through at once, all of which must succeed to close it.
+	//
+	// Optional. Default: 1
+	HalfOpenRequests int
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:         nil,
+	KeyGenerator: func(fiber.Ctx) string { return "" },
+	IsFailure:    isFailure,
+	OpenHandler: func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusServiceUnavailable)
+	},
+	FailureThreshold: 5,
+	MinRequests:      10,
+	Window:           time.Minute,
+	OpenTimeout:      30 * time.Second,
+	HalfOpenRequests: 1,
+}
+
+// ClientConfig defines the config for the client hooks. The circuit options
+// mean the same as in Config.
+type ClientConfig struct {
+	// KeyGenerator returns the key of the circuit a request goes through.
+	//
+	// Optional. Default: the host of the request URL
+	KeyGenerator func(c *client.Client, req *client.Request) string
+
+	// IsFailure reports whether a request failed, given its response or,
+	// when it got none, its error.
+	//
+	// Optional. Default: errors and responses with a 5xx status code
+	IsFailure func(resp *client.Response, err error) bool
+
+	// OnStateChange is called whenever a circuit changes state.
+	//
+	// Optional. Default: nil
+	OnStateChange func(key string, from, to State)
+
+	// clock returns the current time. Tests use it to control the circuit.
+	clock func() time.Time
+
+	// FailureThreshold is the number of consecutive failures that opens
+	// the circuit. A negative value disables it.
+	//
+	// Optional. Default: 5
+	FailureThreshold int
+
+	// FailureRatio is the ratio of failed requests within Window that opens
+	// the circuit.
+	//
+	// Optional. Default: 0 (disabled)
+	FailureRatio float64
+
+	// MinRequests is the number of requests within Window below which
+	// FailureRatio is not applied.
+	//
+	// Optional. Default: 10
+	MinRequests int
+
+	// Window is the rolling window FailureRatio is computed over.
+	//
+	// Optional. Default: 1 * time.Minute
+	Window time.Duration
+
+	// OpenTimeout is how long the circuit stays open before letting probe
+	// requests through.
+	//
+	// Optional. Default: 30 * time.Second
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of probe requests a half-open circuit
+	// lets through at once.
+	//
+	// Optional. Default: 1
+	HalfOpenRequests int
+}
+
+// ClientConfigDefault is the default client config
+var ClientConfigDefault = ClientConfig{
+	KeyGenerator:     requestHost,
+	IsFailure:        isClientFailure,
+	FailureThreshold: 5,
+	MinRequests:      10,
+	Window:           time.Minute,
+	OpenTimeout:      30 * time.Second,
+	HalfOpenRequests: 1,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.KeyGenerator == nil {
+		cfg.KeyGenerator = ConfigDefault.KeyGenerator
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = ConfigDefault.IsFailure
+	}
+	if cfg.OpenHandler == nil {
+		cfg.OpenHandler = ConfigDefault.OpenHandler
+	}
+	s := newSettings(cfg.OnStateChange, cfg.clock, cfg.FailureThreshold, cfg.FailureRatio,
+		cfg.MinRequests, cfg.Window, cfg.OpenTimeout, cfg.HalfOpenRequests)
+	cfg.FailureThreshold, cfg.MinRequests = s.failureThreshold, s.minRequests
+	cfg.Window, cfg.OpenTimeout, cfg.HalfOpenRequests = s.window, s.openTimeout, s.halfOpenRequests
+	return cfg
+}
+
+// Helper function to set default values
+func clientConfigDefault(config ...ClientConfig) ClientConfig {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ClientConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.KeyGenerator == nil {
+		cfg.KeyGenerator = ClientConfigDefault.KeyGenerator
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = ClientConfigDefault.IsFailure
+	}
+	s := newSettings(cfg.OnStateChange, cfg.clock, cfg.FailureThreshold, cfg.FailureRatio,
+		cfg.MinRequests, cfg.Window, cfg.OpenTimeout, cfg.HalfOpenRequests)
+	cfg.FailureThreshold, cfg.MinRequests = s.failureThreshold, s.minRequests
+	cfg.Window, cfg.OpenTimeout, cfg.HalfOpenRequests = s.window, s.openTimeout, s.halfOpenRequests
+	return cfg
+}
+
+// newSettings validates the circuit options and fills in their defaults.
+func newSettings(
+	onStateChange func(key string, from, to State),
+	clock func() time.Time,
+	failureThreshold int,
+	failureRatio float64,
+	minRequests int,
+	window, openTimeout time.Duration,
+	halfOpenRequests int,
+) *settings {
+	if failureRatio < 0 || failureRatio > 1 {
+		panic("circuitbreaker: FailureRatio must be between 0 and 1")
+	}
+	if failureThreshold == 0 {
+		failureThreshold = ConfigDefault.FailureThreshold
+	}
+	if failureThreshold < 0 && failureRatio == 0 {
+		panic("circuitbreaker: FailureThreshold or FailureRatio must be enabled")
+	}
+	if minRequests <= 0 {
+		minRequests = ConfigDefault.MinRequests
+	}
+	if window <= 0 {
+		window = ConfigDefault.Window
+	}
+	if openTimeout <= 0 {
+		openTimeout = ConfigDefault.OpenTimeout
+	}
+	if halfOpenRequests <= 0 {
+		halfOpenRequests = ConfigDefault.HalfOpenRequests
+	}
+	return &settings{
+		onStateChange:    onStateChange,
+		clock:            clock,
+		failureThreshold: failureThreshold,
+		failureRatio:     failureRatio,
+		minRequests:      minRequests,
+		window:           window,
+		openTimeout:      openTimeout,
+		halfOpenRequests: halfOpenRequests,
+	}
+}
+
+// isFailure counts errors and 5xx responses as failures.
+func isFailure(c fiber.Ctx, err error) bool {
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return fiberErr.Code >= fiber.StatusInternalServerError
+		}
+		return true
+	}
+	return c.Response().StatusCode() >= fiber.StatusInternalServerError
+}
+
+// isClientFailure counts errors and 5xx responses as failures.
+func isClientFailure(resp *client.Response, err error) bool {
+	return err != nil || resp.StatusCode() >= fiber.StatusInternalServerError
+}
@@ -0,0 +1,68 @@
+package decompress
+
+import (
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config defines the config for the decompress middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// ErrorHandler is called when a request body cannot be decompressed.
+	// Receives fiber.ErrUnsupportedMediaType for an unsupported encoding,
+	// fiber.ErrRequestEntityTooLarge for a body exceeding MaxSize once
+	// decompressed, and fiber.ErrBadRequest for a corrupted body.
+	//
+	// Optional. Default: returns the error, for the app ErrorHandler.
+	ErrorHandler fiber.ErrorHandler
+
+	// Encodings lists the content codings decoded: "gzip", "deflate", "br"
+	// and "zstd". Requests with other codings are rejected with
+	// 415 Unsupported Media Type.
+	//
+	// Optional. Default: []string{"gzip", "deflate", "br", "zstd"}
+	Encodings []string
+
+	// MaxSize is the maximum size of a request body once decompressed, in
+	// bytes, which protects against decompression bombs: small bodies
+	// expanding to huge ones. It can only lower the body limit of the
+	// request, set with Ctx.SetBodyLimit or else the app BodyLimit.
+	//
+	// Optional. Default: the body limit of the request
+	MaxSize int
+}
+
+// ConfigDefault is the default config.
+var ConfigDefault = Config{
+	ErrorHandler: func(_ fiber.Ctx, err error) error {
+		return err
+	},
+	Encodings: []string{fiber.StrGzip, fiber.StrDeflate, fiber.StrBr, fiber.StrZstd},
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+	cfg := config[0]
+
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = ConfigDefault.Encodings
+	}
+	for _, encoding := range cfg.Encodings {
+		if normalizeEncoding(encoding) == "" {
+			panic("decompress: unsupported encoding " + encoding)
+		}
+	}
+	if cfg.MaxSize < 0 {
+		panic("decompress: MaxSize must not be negative")
+	}
+
+	return cfg
+}
@@ -0,0 +1,158 @@
+package decompress
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+// New creates a new middleware handler decompressing the request bodies
+// encoded with Content-Encoding, so that c.Body, c.Bind and c.BodyStream
+// return the decoded body. The Content-Encoding header is removed once the
+// body is decoded.
+func New(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+
+	supported := make(map[string]struct{}, len(cfg.Encodings))
+	for _, encoding := range cfg.Encodings {
+		supported[normalizeEncoding(encoding)] = struct{}{}
+	}
+	acceptEncoding := strings.Join(cfg.Encodings, ", ")
+
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		if req.Header.ContentEncoding() == nil {
+			return c.Next()
+		}
+
+		encodings, ok := contentEncodings(&req.Header, supported)
+		if !ok {
+			// RFC 9110, 15.5.16: list the codings accepted in the response
+			c.Set(fiber.HeaderAcceptEncoding, acceptEncoding)
+			return cfg.ErrorHandler(c, fiber.ErrUnsupportedMediaType)
+		}
+
+		// MaxSize may only lower the body limit of the route
+		maxSize := c.BodyLimit()
+		if cfg.MaxSize > 0 && cfg.MaxSize < maxSize {
+			maxSize = cfg.MaxSize
+		}
+
+		if err := readStream(c, maxSize); err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		// The codings are listed in the order they were applied
+		if len(req.Body()) > 0 {
+			for i := len(encodings) - 1; i >= 0; i-- {
+				if err := decode(req, encodings[i], maxSize); err != nil {
+					if errors.Is(err, fasthttp.ErrBodyTooLarge) {
+						return cfg.ErrorHandler(c, fiber.ErrRequestEntityTooLarge)
+					}
+					return cfg.ErrorHandler(c, fiber.ErrBadRequest)
+				}
+			}
+		}
+
+		req.Header.Del(fiber.HeaderContentEncoding)
+		req.Header.SetContentLength(len(req.Body()))
+		return c.Next()
+	}
+}
+
+// contentEncodings returns the codings of the Content-Encoding header, which
+// may span several lines, without identity. It returns false if a coding is
+// not supported.
+func contentEncodings(header *fasthttp.RequestHeader, supported map[string]struct{}) ([]string, bool) {
+	var encodings []string
+	for _, value := range header.PeekAll(fiber.HeaderContentEncoding) {
+		for encoding := range strings.SplitSeq(string(value), ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding == "" || encoding == fiber.StrIdentity {
+				continue
+			}
+			encoding = normalizeEncoding(encoding)
+			if _, ok := supported[encoding]; !ok {
+				return nil, false
+			}
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings, true
+}
+
+// normalizeEncoding returns the name of a supported coding, resolving its
+// aliases, or "" if it is not supported.
+func normalizeEncoding(encoding string) string {
+	switch strings.ToLower(encoding) {
+	case fiber.StrGzip, "x-gzip":
+		return fiber.StrGzip
+	case fiber.StrDeflate:
+		return fiber.StrDeflate
+	case fiber.StrBr, fiber.StrBrotli:
+		return fiber.StrBr
+	case fiber.StrZstd:
+		return fiber.StrZstd
+	default:
+		return ""
+	}
+}
+
+// readStream buffers a streamed body, which is compressed, up to maxSize,
+// which is at most the body limit BodyStream enforces. With the app
+// StreamRequestBody setting, the body beyond the BodyLimit is not read yet.
+func readStream(c fiber.Ctx, maxSize int) error {
+	req := c.Request()
+	if !req.IsBodyStream() {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.BodyStream(), int64(maxSize)+1))
+	if err != nil {
+		if errors.Is(err, fiber.ErrRequestEntityTooLarge) {
+			return fiber.ErrRequestEntityTooLarge
+		}
+		return fiber.ErrBadRequest
+	}
+	if len(body) > maxSize {
+		// The rest of the body is not read, so the connection cannot be reused
+		c.RequestCtx().SetConnectionClose()
+		return fiber.ErrRequestEntityTooLarge
+	}
+	req.SetBodyRaw(body)
+	return nil
+}
+
+// decode replaces the body of req with its decoded data, of up to maxSize
+// bytes.
+func decode(req *fasthttp.Request, encoding string, maxSize int) error {
+	var (
+		body []byte
+		err  error
+	)
+	switch encoding {
+	case fiber.StrGzip:
+		body, err = req.BodyGunzipWithLimit(maxSize)
+	case fiber.StrDeflate:
+		body, err = req.BodyInflateWithLimit(maxSize)
+	case fiber.StrBr:
+		body, err = req.BodyUnbrotliWithLimit(maxSize)
+	case fiber.StrZstd:
+		body, err = req.BodyUnzstdWithLimit(maxSize)
+	default:
+		return errors.New("decompress: unsupported encoding " + encoding)
+	}
+	if err != nil {
+		return err
+	}
+	req.SetBodyRaw(body)
+	return nil
+}
@@ -0,0 +1,311 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	switch encoding {
+	case fiber.StrGzip:
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	case fiber.StrDeflate:
+		w := zlib.NewWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	case fiber.StrBr:
+		return fasthttp.AppendBrotliBytes(nil, data)
+	case fiber.StrZstd:
+		return fasthttp.AppendZstdBytes(nil, data)
+	default:
+		t.Fatalf("unknown encoding %q", encoding)
+	}
+	return buf.Bytes()
+}
+
+func newApp(config fiber.Config, cfg ...Config) *fiber.App {
+	app := fiber.New(config)
+	app.Use(New(cfg...))
+	app.Post("/", func(c fiber.Ctx) error {
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := c.Bind().Body(&user); err != nil {
+			return err
+		}
+		c.Set("X-Content-Encoding", c.Get(fiber.HeaderContentEncoding))
+		return c.SendString(user.Name)
+	})
+	app.Put("/", func(c fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+	return app
+}
+
+func decompressRequest(method string, body []byte, encodings ...string) *http.Request {
+	req := httptest.NewRequest(method, "/", bytes.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for _, encoding := range encodings {
+		req.Header.Add(fiber.HeaderContentEncoding, encoding)
+	}
+	return req
+}
+
+func Test_Decompress(t *testing.T) {
+	t.Parallel()
+
+	app := newApp(fiber.Config{})
+	data := []byte(`{"name":"john"}`)
+
+	for _, encoding := range []string{fiber.StrGzip, fiber.StrDeflate, fiber.StrBr, fiber.StrZstd} {
+		resp, err := app.Test(decompressRequest(fiber.MethodPost, compress(t, encoding, data), encoding))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode, encoding)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "john", string(body), encoding)
+		require.Empty(t, resp.Header.Get("X-Content-Encoding"), encoding)
+	}
+
+	// Aliases and identity
+	for _, encoding := range []string{"x-gzip", "GZIP", "identity, gzip"} {
+		resp, err := app.Test(decompressRequest(fiber.MethodPost, compress(t, fiber.StrGzip, data), encoding))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode, encoding)
+	}
+	resp, err := app.Test(decompressRequest(fiber.MethodPost, compress(t, fiber.StrBr, data), "brotli"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Bodies without Content-Encoding are untouched
+	resp, err = app.Test(decompressRequest(fiber.MethodPost, data))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp, err = app.Test(decompressRequest(fiber.MethodPost, data, "identity"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func Test_Decompress_Multiple(t *testing.T) {
+	t.Parallel()
+
+	app := newApp(fiber.Config{})
+	data := []byte(`{"name":"john"}`)
+	body := compress(t, fiber.StrGzip, compress(t, fiber.StrDeflate, data))
+
+	// The codings are listed in the order they were applied
+	resp, err := app.Test(decompressRequest(fiber.MethodPost, body, "deflate, gzip"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(decompressRequest(fiber.MethodPost, body, "deflate", "gzip"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(decompressRequest(fiber.MethodPost, body, "gzip, deflate"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_Decompress_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	app := newApp(fiber.Config{}, Config{Encodings: []string{fiber.StrGzip, fiber.StrZstd}})
+	data := []byte(`{"name":"john"}`)
+
+	for _, encoding := range []string{"compress", "br", "gzip, br", "unknown"} {
+		resp, err := app.Test(decompressRequest(fiber.MethodPost, data, encoding))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode, encoding)
+		require.Equal(t, "gzip, zstd", resp.Header.Get(fiber.HeaderAcceptEncoding), encoding)
+	}
+}
+
+func Test_Decompress_Invalid(t *testing.T) {
+	t.Parallel()
+
+	app := newApp(fiber.Config{})
+
+	for _, encoding := range []string{fiber.StrGzip, fiber.StrDeflate, fiber.StrBr, fiber.StrZstd} {
+		resp, err := app.Test(decompressRequest(fiber.MethodPost, []byte("not compressed"), encoding))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, encoding)
+	}
+
+	// An empty body is not decoded
+	resp, err := app.Test(decompressRequest(fiber.MethodPut, nil, fiber.StrGzip))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func Test_Decompress_MaxSize(t *testing.T) {
+	t.Parallel()
+
+	bomb := bytes.Repeat([]byte("a"), 10_000)
+
+	// The app BodyLimit by default
+	app := newApp(fiber.Config{BodyLimit: 9_999})
+	for _, encoding := range []string{fiber.StrGzip, fiber.StrDeflate, fiber.StrBr, fiber.StrZstd} {
+		resp, err := app.Test(decompressRequest(fiber.MethodPut, compress(t, encoding, bomb), encoding))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode, encoding)
+	}
+
+	app = newApp(fiber.Config{}, Config{MaxSize: 10_000})
+	resp, err := app.Test(decompressRequest(fiber.MethodPut, compress(t, fiber.StrGzip, bomb), fiber.StrGzip))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, bomb, body)
+
+	// Each coding is limited
+	twice := compress(t, fiber.StrGzip, compress(t, fiber.StrGzip, bytes.Repeat([]byte("a"), 10_001)))
+	resp, err = app.Test(decompressRequest(fiber.MethodPut, twice, "gzip, gzip"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func Test_Decompress_StreamRequestBody(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 4096)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	body := compress(t, fiber.StrGzip, data)
+	require.Greater(t, len(body), 1024)
+
+	// The part of the body beyond the app BodyLimit is read from the stream,
+	// up to the body limit of the route
+	routeApp := func(limit int, cfg ...Config) *fiber.App {
+		app := fiber.New(fiber.Config{StreamRequestBody: true, BodyLimit: 1024})
+		app.Use(func(c fiber.Ctx) error {
+			c.SetBodyLimit(limit)
+			return c.Next()
+		}, New(cfg...))
+		app.Put("/", func(c fiber.Ctx) error {
+			return c.Send(c.Body())
+		})
+		return app
+	}
+	app := routeApp(8192)
+	resp, err := app.Test(decompressRequest(fiber.MethodPut, body, fiber.StrGzip))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decoded, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, data, decoded)
+
+	// The compressed body is limited by MaxSize too
+	app = routeApp(8192, Config{MaxSize: 2048})
+	resp, err = app.Test(decompressRequest(fiber.MethodPut, body, fiber.StrGzip))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// MaxSize does not raise the app BodyLimit
+	app = newApp(fiber.Config{StreamRequestBody: true, BodyLimit: 1024}, Config{MaxSize: 8192})
+	resp, err = app.Test(decompressRequest(fiber.MethodPut, body, fiber.StrGzip))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func Test_Decompress_RouteBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	// A small body expanding beyond a stricter limit of the route
+	bomb := compress(t, fiber.StrGzip, bytes.Repeat([]byte("a"), 10_000))
+	require.Less(t, len(bomb), 64)
+
+	for _, cfg := range []Config{{}, {MaxSize: 1 << 20}} {
+		app := fiber.New()
+		app.Use(func(c fiber.Ctx) error {
+			c.SetBodyLimit(64)
+			return c.Next()
+		}, New(cfg))
+		var limit int
+		app.Put("/", func(c fiber.Ctx) error {
+			limit = c.BodyLimit()
+			return c.Send(c.Body())
+		})
+
+		resp, err := app.Test(decompressRequest(fiber.MethodPut, bomb, fiber.StrGzip))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode, cfg.MaxSize)
+
+		small := compress(t, fiber.StrGzip, []byte("hello"))
+		resp, err = app.Test(decompressRequest(fiber.MethodPut, small, fiber.StrGzip))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		// The limit of the route is kept for the next handlers
+		require.Equal(t, 64, limit)
+	}
+}
+
+func Test_Decompress_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := newApp(fiber.Config{}, Config{
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			return c.Status(fiber.StatusTeapot).SendString(err.Error())
+		},
+	})
+
+	resp, err := app.Test(decompressRequest(fiber.MethodPost, []byte("data"), "compress"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusTeapot, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "Unsupported Media Type", string(body))
+}
+
+func Test_Decompress_Next(t *testing.T) {
+	t.Parallel()
+
+	app := newApp(fiber.Config{}, Config{
+		Next: func(c fiber.Ctx) bool {
+			return c.Method() == fiber.MethodPut
+		},
+	})
+	body := compress(t, fiber.StrGzip, []byte("hello"))
+
+	resp, err := app.Test(decompressRequest(fiber.MethodPut, body, fiber.StrGzip))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	// c.Body decodes the body itself
+	require.Equal(t, "hello", string(data))
+}
+
+func Test_Decompress_Config(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "decompress: unsupported encoding compress", func() {
+		New(Config{Encodings: []string{"gzip", "compress"}})
+	})
+	require.PanicsWithValue(t, "decompress: MaxSize must not be negative", func() {
+		New(Config{MaxSize: -1})
+	})
+
+	cfg := configDefault(Config{})
+	require.Equal(t, []string{"gzip", "deflate", "br", "zstd"}, cfg.Encodings)
+	require.NotNil(t, cfg.ErrorHandler)
+	require.Equal(t, 0, cfg.MaxSize)
+}
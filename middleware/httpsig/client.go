@@ -0,0 +1,110 @@
+package httpsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+)
+
+// Signer signs the requests of a client.Client with an HTTP message
+// signature, and sets their Content-Digest field. Its hook is added to a
+// client with Register:
+//
+//	signer := httpsig.NewSigner(httpsig.SignerConfig{Key: privateKey, KeyID: "partner-1"})
+//	cc := signer.Register(client.New())
+//
+// The hook is a send hook, so the signature covers the URL, headers and body
+// of the request as sent.
+type Signer struct {
+	cfg        SignerConfig
+	components []component
+	digest     bool
+}
+
+// NewSigner creates a new Signer.
+func NewSigner(config ...SignerConfig) *Signer {
+	// Set default config
+	cfg := signerConfigDefault(config...)
+
+	// Only signing keys are supported
+	switch cfg.Key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		panic(fmt.Sprintf("fiber: httpsig signer does not support %T keys", cfg.Key))
+	default:
+		if keyAlgorithm(cfg.Key) == "" {
+			panic(fmt.Sprintf("fiber: httpsig signer does not support %T keys", cfg.Key))
+		}
+	}
+
+	s := &Signer{cfg: cfg}
+	for _, str := range cfg.Components {
+		c, err := parseComponent(str)
+		if err != nil {
+			panic(fmt.Sprintf("fiber: httpsig %v", err))
+		}
+		s.components = append(s.components, c)
+		s.digest = s.digest || c.name == "content-digest"
+	}
+	return s
+}
+
+// Register adds the send hook of the signer to c and returns it.
+func (s *Signer) Register(c *client.Client) *client.Client {
+	return c.AddSendHook(s.SendHook)
+}
+
+// SendHook signs the request.
+func (s *Signer) SendHook(_ *client.Client, req *client.Request) error {
+	return s.Sign(req.RawRequest)
+}
+
+// Sign signs a request, adding the signature to its Signature-Input and
+// Signature fields.
+func (s *Signer) Sign(req *fasthttp.Request) error {
+	if s.digest && len(req.Header.Peek(fiber.HeaderContentDigest)) == 0 {
+		if req.IsBodyStream() {
+			return errors.New("httpsig: cannot digest a streamed request body")
+		}
+		req.Header.Set(fiber.HeaderContentDigest, contentDigest(req.Body()))
+	}
+
+	now := time.Now()
+	input := innerList{
+		items:  make([]item, len(s.components)),
+		params: params{{key: "created", value: now.Unix()}},
+	}
+	for i, c := range s.components {
+		input.items[i] = c.item()
+	}
+	if s.cfg.Expires > 0 {
+		input.params = append(input.params, param{key: "expires", value: now.Add(s.cfg.Expires).Unix()})
+	}
+	if s.cfg.Nonce {
+		input.params = append(input.params, param{key: "nonce", value: rand.Text()})
+	}
+	input.params = append(input.params, param{key: "keyid", value: s.cfg.KeyID})
+	if s.cfg.Tag != "" {
+		input.params = append(input.params, param{key: "tag", value: s.cfg.Tag})
+	}
+
+	base, err := sentMessage(req).signatureBase(input)
+	if err != nil {
+		return fmt.Errorf("httpsig: failed to sign: %w", err)
+	}
+	signature, err := sign(s.cfg.Key, base)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add(fiber.HeaderSignatureInput, s.cfg.Label+"="+serializeInnerList(input))
+	req.Header.Add(fiber.HeaderSignature, s.cfg.Label+"="+serializeItem(item{value: signature}))
+	return nil
+}
@@ -0,0 +1,89 @@
+package httpsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+func newSignerTestClient(t *testing.T, config Config, signer *Signer) *client.Client {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	app := fiber.New()
+	app.Use(New(config))
+	app.All("/*", func(c fiber.Ctx) error {
+		return c.SendString(KeyIDFromContext(c) + " " + string(c.Body()))
+	})
+
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped in cleanup
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	cc := signer.Register(client.New())
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return ln.Dial()
+	})
+	return cc
+}
+
+func Test_Signer_Client(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cc := newSignerTestClient(t, Config{
+		Keys:       StaticKeys(map[string]any{"partner-1": &key.PublicKey}),
+		Components: []string{"@method", "@authority", "@path", `"@query-param";name="page"`, "content-type", "content-digest"},
+		Storage:    memory.New(),
+	}, NewSigner(SignerConfig{
+		Key:        key,
+		KeyID:      "partner-1",
+		Components: []string{"@method", "@authority", "@path", `"@query-param";name="page"`, "content-type", "content-digest"},
+		Nonce:      true,
+	}))
+
+	// The signature covers the request as sent: its query, headers and body
+	// are set by the client after the request hooks
+	resp, err := cc.R().
+		SetParam("page", "2").
+		SetJSON(map[string]string{"name": "john"}).
+		Post("http://api.example.com/users")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, `partner-1 {"name":"john"}`, resp.String())
+
+	// Each request has another nonce
+	resp, err = cc.R().SetParam("page", "3").SetHeader(fiber.HeaderContentType, fiber.MIMEApplicationJSON).SetRawBody([]byte("{}")).Put("http://api.example.com/users")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+
+	// Requests the signer cannot sign are not sent
+	_, err = cc.Get("http://api.example.com/users")
+	require.ErrorIs(t, err, ErrMissingComponent)
+}
+
+func Test_Signer_Client_Rejected(t *testing.T) {
+	t.Parallel()
+
+	cc := newSignerTestClient(t, Config{
+		Keys: StaticKeys(map[string]any{"hmac": testSecret}),
+	}, NewSigner(SignerConfig{Key: []byte("another secret"), KeyID: "hmac"}))
+
+	resp, err := cc.Get("http://api.example.com/")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+	require.Equal(t, `sig1=("@method" "@authority" "@path" "content-digest")`, resp.Header(fiber.HeaderAcceptSignature))
+}
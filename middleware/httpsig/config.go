@@ -0,0 +1,177 @@
+package httpsig
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Storage records the nonces of the verified signatures until they
+	// expire, to reject replayed requests. When it is set, signatures
+	// without a nonce are rejected. Concurrent replays are rejected within an
+	// instance, but several instances sharing the Storage may each accept one
+	// replay sent to them at the same time.
+	//
+	// Optional. Default: nil (replays are not detected)
+	Storage fiber.Storage
+
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// SuccessHandler defines a function which is executed for a request
+	// with a valid signature.
+	//
+	// Optional. Default: c.Next()
+	SuccessHandler fiber.Handler
+
+	// ErrorHandler defines a function which is executed for a request
+	// without a valid signature. It may be used to define a custom error.
+	//
+	// Optional. Default: 401 Missing or invalid HTTP message signature
+	ErrorHandler fiber.ErrorHandler
+
+	// Keys returns the verification key of a key ID: a []byte secret for
+	// hmac-sha256, an ed25519.PublicKey for ed25519, or an *ecdsa.PublicKey
+	// on the P-256 or P-384 curve for ecdsa-p256-sha256 and
+	// ecdsa-p384-sha384. It returns ErrUnknownKey for unknown key IDs.
+	//
+	// Required.
+	Keys func(c fiber.Ctx, keyID string) (any, error)
+
+	// Label is the label of the signature to verify. When it is empty, the
+	// first valid signature of the request is accepted.
+	//
+	// Optional. Default: ""
+	Label string
+
+	// Tag is the value the `tag` parameter of the signature must have, such
+	// as the name of a webhook integration.
+	//
+	// Optional. Default: "" (not checked)
+	Tag string
+
+	// Components lists the components the signature must cover, as in the
+	// Signature-Input field: derived components such as "@method" or
+	// `"@query-param";name="id"`, and lowercase field names. A covered
+	// content-digest field is checked against the body.
+	//
+	// Optional. Default: "@method", "@authority", "@path", "content-digest"
+	Components []string
+
+	// MaxAge is how far the `created` parameter of the signature may be from
+	// now, and how long its nonce is recorded.
+	//
+	// Optional. Default: 5 minutes
+	MaxAge time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	SuccessHandler: func(c fiber.Ctx) error {
+		return c.Next()
+	},
+	ErrorHandler: func(c fiber.Ctx, _ error) error {
+		return c.Status(fiber.StatusUnauthorized).SendString("missing or invalid HTTP message signature")
+	},
+	Components: []string{"@method", "@authority", "@path", "content-digest"},
+	MaxAge:     5 * time.Minute,
+}
+
+// configDefault is a helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		panic("fiber: httpsig middleware requires a Keys function")
+	}
+	cfg := config[0]
+
+	// Require a key lookup
+	if cfg.Keys == nil {
+		panic("fiber: httpsig middleware requires a Keys function")
+	}
+
+	// Set default values
+	if cfg.SuccessHandler == nil {
+		cfg.SuccessHandler = ConfigDefault.SuccessHandler
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+	if cfg.Components == nil {
+		cfg.Components = ConfigDefault.Components
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = ConfigDefault.MaxAge
+	}
+
+	return cfg
+}
+
+// SignerConfig defines the config of a Signer.
+type SignerConfig struct {
+	// Key is the signing key: a []byte secret for hmac-sha256, an
+	// ed25519.PrivateKey for ed25519, or an *ecdsa.PrivateKey on the P-256
+	// or P-384 curve for ecdsa-p256-sha256 and ecdsa-p384-sha384.
+	//
+	// Required.
+	Key any
+
+	// KeyID is the key ID sent in the `keyid` parameter of the signature.
+	//
+	// Required.
+	KeyID string
+
+	// Label is the label of the signature in the Signature-Input and
+	// Signature fields.
+	//
+	// Optional. Default: "sig1"
+	Label string
+
+	// Tag is sent in the `tag` parameter of the signature.
+	//
+	// Optional. Default: ""
+	Tag string
+
+	// Components lists the components the signature covers. When the
+	// content-digest field is covered, it is set from the body of requests
+	// which don't have it.
+	//
+	// Optional. Default: "@method", "@authority", "@path", "content-digest"
+	Components []string
+
+	// Expires sets the `expires` parameter of the signature to this long
+	// after its creation.
+	//
+	// Optional. Default: 0 (no expires parameter)
+	Expires time.Duration
+
+	// Nonce adds a random `nonce` parameter to the signature, for servers
+	// rejecting replayed requests.
+	//
+	// Optional. Default: false
+	Nonce bool
+}
+
+// signerConfigDefault is a helper function to set default values
+func signerConfigDefault(config ...SignerConfig) SignerConfig {
+	if len(config) < 1 {
+		panic("fiber: httpsig signer requires a Key and a KeyID")
+	}
+	cfg := config[0]
+
+	if cfg.Key == nil || cfg.KeyID == "" {
+		panic("fiber: httpsig signer requires a Key and a KeyID")
+	}
+	if cfg.Label == "" {
+		cfg.Label = defaultLabel
+	}
+	if cfg.Components == nil {
+		cfg.Components = ConfigDefault.Components
+	}
+
+	return cfg
+}
@@ -0,0 +1,329 @@
+// Package httpsig verifies the HTTP Message Signatures (RFC 9421) of
+// requests, and signs the requests of a client.Client with a Signer.
+package httpsig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Errors returned when a request has no valid signature. They are passed to
+// the ErrorHandler.
+var (
+	ErrMissingSignature     = errors.New("missing HTTP message signature")
+	ErrMalformedSignature   = errors.New("malformed HTTP message signature")
+	ErrUnsupportedComponent = errors.New("unsupported HTTP message signature component")
+	ErrMissingComponent     = errors.New("missing HTTP message signature component")
+	ErrUnknownKey           = errors.New("unknown HTTP message signature key")
+	ErrUnsupportedAlgorithm = errors.New("unsupported HTTP message signature algorithm")
+	ErrInvalidSignature     = errors.New("invalid HTTP message signature")
+	ErrSignatureExpired     = errors.New("HTTP message signature has expired")
+	ErrInvalidTag           = errors.New("invalid HTTP message signature tag")
+	ErrMissingNonce         = errors.New("missing HTTP message signature nonce")
+	ErrReplayedSignature    = errors.New("replayed HTTP message signature")
+	ErrInvalidDigest        = errors.New("invalid content digest")
+)
+
+// defaultLabel is the label of the signatures of a Signer, and of the
+// signature requested by the Accept-Signature field.
+const defaultLabel = "sig1"
+
+// The contextKey type is unexported to prevent collisions with context keys defined in
+// other packages.
+type contextKey int
+
+// The key for the key ID in context
+const keyIDKey contextKey = 0
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Init config
+	cfg := configDefault(config...)
+
+	required := make([]component, len(cfg.Components))
+	for i, s := range cfg.Components {
+		c, err := parseComponent(s)
+		if err != nil {
+			panic(fmt.Sprintf("fiber: httpsig %v", err))
+		}
+		required[i] = c
+	}
+
+	// The Accept-Signature field sent with 401 responses, requesting the
+	// signature the middleware verifies
+	label := cfg.Label
+	if label == "" {
+		label = defaultLabel
+	}
+	accept := innerList{items: make([]item, len(required))}
+	for i, c := range required {
+		accept.items[i] = c.item()
+	}
+	if cfg.Tag != "" {
+		accept.params = params{{key: "tag", value: cfg.Tag}}
+	}
+	acceptSignature := label + "=" + serializeInnerList(accept)
+
+	v := &verifier{cfg: &cfg, required: required, now: time.Now}
+
+	// Return middleware handler
+	return func(c fiber.Ctx) error {
+		// Filter request to skip middleware
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		keyID, err := v.verify(c)
+		if err == nil {
+			fiber.StoreInContext(c, keyIDKey, keyID)
+			return cfg.SuccessHandler(c)
+		}
+
+		// Execute the error handler first
+		handlerErr := cfg.ErrorHandler(c, err)
+
+		if c.Response().StatusCode() == fiber.StatusUnauthorized {
+			c.Set(fiber.HeaderAcceptSignature, acceptSignature)
+		}
+
+		return handlerErr
+	}
+}
+
+// KeyIDFromContext returns the key ID of the verified signature from the
+// request context. It accepts fiber.CustomCtx, fiber.Ctx,
+// *fasthttp.RequestCtx, and context.Context. It returns an empty string if
+// the request has no verified signature.
+func KeyIDFromContext(ctx any) string {
+	if keyID, ok := fiber.ValueFromContext[string](ctx, keyIDKey); ok {
+		return keyID
+	}
+
+	return ""
+}
+
+// StaticKeys returns a Keys function looking up the keys of a map.
+func StaticKeys(keys map[string]any) func(c fiber.Ctx, keyID string) (any, error) {
+	return func(_ fiber.Ctx, keyID string) (any, error) {
+		key, ok := keys[keyID]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return key, nil
+	}
+}
+
+// verifier verifies the signatures of requests against the config.
+type verifier struct {
+	cfg      *Config
+	now      func() time.Time
+	required []component
+	// nonces holds the nonces being recorded by this process
+	nonces sync.Map
+}
+
+// verify returns the key ID of the valid signature of a request.
+func (v *verifier) verify(c fiber.Ctx) (string, error) {
+	inputs, signatures, err := signatureFields(c)
+	if err != nil {
+		return "", err
+	}
+
+	var firstErr error
+	for _, input := range inputs {
+		if v.cfg.Label != "" && input.key != v.cfg.Label {
+			continue
+		}
+		keyID, err := v.verifySignature(c, input, signatures)
+		if err == nil {
+			return keyID, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return "", ErrMissingSignature
+	}
+	return "", firstErr
+}
+
+// signatureFields returns the members of the Signature-Input and Signature
+// fields of a request.
+func signatureFields(c fiber.Ctx) (inputs, signatures []member, err error) { //nolint:nonamedreturns // Names tell the two lists apart
+	// PeekAll reuses its result, so the lines of a field are joined before
+	// peeking at the other one
+	header := &c.Request().Header
+	input := joinLines(header.PeekAll(fiber.HeaderSignatureInput))
+	signature := joinLines(header.PeekAll(fiber.HeaderSignature))
+	if input == "" || signature == "" {
+		return nil, nil, ErrMissingSignature
+	}
+	if inputs, err = parseDictionary(input); err != nil {
+		return nil, nil, ErrMalformedSignature
+	}
+	if signatures, err = parseDictionary(signature); err != nil {
+		return nil, nil, ErrMalformedSignature
+	}
+	return inputs, signatures, nil
+}
+
+func joinLines(lines [][]byte) string {
+	values := make([]string, len(lines))
+	for i, line := range lines {
+		values[i] = string(line)
+	}
+	return strings.Join(values, ", ")
+}
+
+// verifySignature verifies the signature of an input member of the
+// Signature-Input field, and returns its key ID.
+func (v *verifier) verifySignature(c fiber.Ctx, input member, signatures []member) (string, error) {
+	list, ok := input.value.(innerList)
+	if !ok {
+		return "", ErrMalformedSignature
+	}
+	signature, err := signatureValue(input.key, signatures)
+	if err != nil {
+		return "", err
+	}
+
+	// The signature must cover the required components
+	covered := make(map[string]bool, len(list.items))
+	for _, it := range list.items {
+		covered[serializeItem(it)] = true
+	}
+	for _, r := range v.required {
+		if !covered[r.String()] {
+			return "", fmt.Errorf("%w: %s is not covered", ErrMissingComponent, r)
+		}
+	}
+
+	// Check the parameters before looking up the key
+	keyID, ok := stringParam(list.params, "keyid")
+	if !ok {
+		return "", ErrMalformedSignature
+	}
+	if v.cfg.Tag != "" {
+		if tag, _ := stringParam(list.params, "tag"); tag != v.cfg.Tag {
+			return "", ErrInvalidTag
+		}
+	}
+	now := v.now()
+	created, ok := intParam(list.params, "created")
+	if !ok {
+		return "", ErrMalformedSignature
+	}
+	if age := now.Sub(time.Unix(created, 0)); age > v.cfg.MaxAge || age < -v.cfg.MaxAge {
+		return "", ErrSignatureExpired
+	}
+	if expires, ok := intParam(list.params, "expires"); ok && !now.Before(time.Unix(expires, 0)) {
+		return "", ErrSignatureExpired
+	}
+	nonce, hasNonce := stringParam(list.params, "nonce")
+	if v.cfg.Storage != nil && !hasNonce {
+		return "", ErrMissingNonce
+	}
+
+	key, err := v.cfg.Keys(c, keyID)
+	if err != nil {
+		return "", err
+	}
+	alg := keyAlgorithm(key)
+	if alg == "" {
+		return "", ErrUnsupportedAlgorithm
+	}
+	if value, ok := list.params.get("alg"); ok && value != alg {
+		return "", ErrUnsupportedAlgorithm
+	}
+
+	base, err := receivedMessage(c.Request(), c.Scheme()).signatureBase(list)
+	if err != nil {
+		return "", err
+	}
+	if !verify(key, base, signature) {
+		return "", ErrInvalidSignature
+	}
+
+	// The digest is trusted once its field is verified to be signed
+	if covered[`"content-digest"`] {
+		if err := verifyDigest(string(c.Request().Header.Peek(fiber.HeaderContentDigest)), c.Request().Body()); err != nil {
+			return "", err
+		}
+	}
+
+	if v.cfg.Storage != nil {
+		if err := v.recordNonce(c, keyID, nonce); err != nil {
+			return "", err
+		}
+	}
+	return keyID, nil
+}
+
+// recordNonce records the nonce of a verified signature, or returns
+// ErrReplayedSignature if it is already recorded. The check and the record
+// are atomic within the process; instances sharing the Storage may both
+// accept a request replayed to them at the same time.
+func (v *verifier) recordNonce(c fiber.Ctx, keyID, nonce string) error {
+	// The key ID is quoted so that it cannot run into the nonce
+	key := "httpsig_nonce:" + serializeItem(item{value: keyID}) + ":" + nonce
+
+	// A request with the same nonce being verified concurrently is a replay
+	if _, loaded := v.nonces.LoadOrStore(key, struct{}{}); loaded {
+		return ErrReplayedSignature
+	}
+	defer v.nonces.Delete(key)
+
+	seen, err := v.cfg.Storage.GetWithContext(c, key)
+	if err != nil {
+		return fmt.Errorf("httpsig: failed to get nonce: %w", err)
+	}
+	if seen != nil {
+		return ErrReplayedSignature
+	}
+	// Signatures are rejected once their creation is older than MaxAge, so
+	// their nonces need not be recorded any longer
+	if err := v.cfg.Storage.SetWithContext(c, key, []byte{1}, 2*v.cfg.MaxAge); err != nil {
+		return fmt.Errorf("httpsig: failed to record nonce: %w", err)
+	}
+	return nil
+}
+
+// signatureValue returns the signature of a label in the Signature field.
+func signatureValue(label string, signatures []member) ([]byte, error) {
+	for _, m := range signatures {
+		if m.key != label {
+			continue
+		}
+		if it, ok := m.value.(item); ok {
+			if signature, ok := it.value.([]byte); ok {
+				return signature, nil
+			}
+		}
+		return nil, ErrMalformedSignature
+	}
+	return nil, ErrMalformedSignature
+}
+
+func stringParam(ps params, key string) (string, bool) {
+	value, ok := ps.get(key)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+func intParam(ps params, key string) (int64, bool) {
+	value, ok := ps.get(key)
+	if !ok {
+		return 0, false
+	}
+	i, ok := value.(int64)
+	return i, ok
+}
@@ -0,0 +1,546 @@
+package httpsig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestRequest returns a request to sign.
+func newTestRequest(method, uri, body string) *fasthttp.Request {
+	req := &fasthttp.Request{}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	if body != "" {
+		req.Header.SetContentType(fiber.MIMEApplicationJSON)
+		req.SetBodyString(body)
+	}
+	return req
+}
+
+// serve sends a signed request to the app, as written on the wire, and
+// returns the response.
+func serve(t *testing.T, app *fiber.App, req *fasthttp.Request) *fasthttp.Response {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, req.Write(w))
+	require.NoError(t, w.Flush())
+
+	fctx := &fasthttp.RequestCtx{}
+	require.NoError(t, fctx.Request.Read(bufio.NewReader(&buf)))
+	app.Handler()(fctx)
+	return &fctx.Response
+}
+
+// newTestApp returns an app verifying signatures, which records the errors
+// of the middleware.
+func newTestApp(config Config, gotErr *error) *fiber.App {
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c fiber.Ctx, err error) error {
+			*gotErr = err
+			return ConfigDefault.ErrorHandler(c, err)
+		}
+	}
+	app := fiber.New()
+	app.Use(New(config))
+	app.All("/*", func(c fiber.Ctx) error {
+		return c.SendString(KeyIDFromContext(c))
+	})
+	return app
+}
+
+// signWith signs a request with the given covered components and parameters.
+func signWith(t *testing.T, req *fasthttp.Request, key any, label string, input innerList) {
+	t.Helper()
+
+	base, err := sentMessage(req).signatureBase(input)
+	require.NoError(t, err)
+	signature, err := sign(key, base)
+	require.NoError(t, err)
+	req.Header.Add(fiber.HeaderSignatureInput, label+"="+serializeInnerList(input))
+	req.Header.Add(fiber.HeaderSignature, label+"="+serializeItem(item{value: signature}))
+}
+
+func components(ids ...string) []item {
+	items := make([]item, len(ids))
+	for i, id := range ids {
+		items[i] = item{value: id}
+	}
+	return items
+}
+
+func Test_HTTPSig_Algorithms(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	var gotErr error
+	app := newTestApp(Config{Keys: StaticKeys(map[string]any{
+		"hmac":  testSecret,
+		"ed":    edKey.Public(),
+		"p256":  &p256.PublicKey,
+		"p384":  &p384.PublicKey,
+		"empty": []byte{},
+		"p521":  &ecdsa.PublicKey{Curve: elliptic.P521()},
+	})}, &gotErr)
+
+	for keyID, key := range map[string]any{"hmac": testSecret, "ed": edKey, "p256": p256, "p384": p384} {
+		req := newTestRequest(fiber.MethodPost, "http://example.com/hooks?event=push", `{"event":"push"}`)
+		require.NoError(t, NewSigner(SignerConfig{Key: key, KeyID: keyID}).Sign(req))
+
+		resp := serve(t, app, req)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode(), keyID)
+		require.Equal(t, keyID, string(resp.Body()))
+	}
+
+	// Unsupported verification keys
+	for _, keyID := range []string{"empty", "p521"} {
+		req := newTestRequest(fiber.MethodGet, "http://example.com/", "")
+		require.NoError(t, NewSigner(SignerConfig{Key: testSecret, KeyID: keyID}).Sign(req))
+		resp := serve(t, app, req)
+		require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+		require.ErrorIs(t, gotErr, ErrUnsupportedAlgorithm)
+	}
+}
+
+func Test_HTTPSig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	var gotErr error
+	app := newTestApp(Config{Keys: StaticKeys(map[string]any{"hmac": testSecret})}, &gotErr)
+	signer := NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac"})
+	signed := func() *fasthttp.Request {
+		req := newTestRequest(fiber.MethodPost, "http://example.com/hooks", `{"event":"push"}`)
+		require.NoError(t, signer.Sign(req))
+		return req
+	}
+	now := time.Now().Unix()
+	defaults := components("@method", "@authority", "@path", "content-digest")
+
+	for _, tc := range []struct {
+		err    error
+		modify func(req *fasthttp.Request)
+		name   string
+	}{
+		{name: "missing", err: ErrMissingSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+		}},
+		{name: "missing input", err: ErrMissingSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignatureInput)
+		}},
+		{name: "malformed input", err: ErrMalformedSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Set(fiber.HeaderSignatureInput, "sig1=(")
+		}},
+		{name: "malformed signature", err: ErrMalformedSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Set(fiber.HeaderSignature, "sig1=:not base64:")
+		}},
+		{name: "other label", err: ErrMalformedSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Set(fiber.HeaderSignature, "sig2=:AAAA:")
+		}},
+		{name: "signature not bytes", err: ErrMalformedSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Set(fiber.HeaderSignature, `sig1="AAAA"`)
+		}},
+		{name: "tampered signature", err: ErrInvalidSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Set(fiber.HeaderSignature, "sig1=:AAAA:")
+		}},
+		{name: "tampered method", err: ErrInvalidSignature, modify: func(req *fasthttp.Request) {
+			req.Header.SetMethod(fiber.MethodPut)
+		}},
+		{name: "tampered path", err: ErrInvalidSignature, modify: func(req *fasthttp.Request) {
+			req.SetRequestURI("http://example.com/admin")
+		}},
+		{name: "tampered body", err: ErrInvalidDigest, modify: func(req *fasthttp.Request) {
+			req.SetBodyString(`{"event":"delete"}`)
+		}},
+		{name: "uncovered component", err: ErrMissingComponent, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{
+				items:  components("@method", "@path", "content-digest"),
+				params: params{{key: "created", value: now}, {key: "keyid", value: "hmac"}},
+			})
+		}},
+		{name: "missing component", err: ErrMissingComponent, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			req.Header.Set("X-Custom", "1")
+			signWith(t, req, testSecret, "sig1", innerList{
+				items:  append(components("x-custom"), defaults...),
+				params: params{{key: "created", value: now}, {key: "keyid", value: "hmac"}},
+			})
+			req.Header.Del("X-Custom")
+		}},
+		{name: "unknown key", err: ErrUnknownKey, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			require.NoError(t, NewSigner(SignerConfig{Key: testSecret, KeyID: "other"}).Sign(req))
+		}},
+		{name: "no key id", err: ErrMalformedSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{items: defaults, params: params{{key: "created", value: now}}})
+		}},
+		{name: "no created", err: ErrMalformedSignature, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{items: defaults, params: params{{key: "keyid", value: "hmac"}}})
+		}},
+		{name: "old", err: ErrSignatureExpired, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{
+				items:  defaults,
+				params: params{{key: "created", value: now - 600}, {key: "keyid", value: "hmac"}},
+			})
+		}},
+		{name: "future", err: ErrSignatureExpired, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{
+				items:  defaults,
+				params: params{{key: "created", value: now + 600}, {key: "keyid", value: "hmac"}},
+			})
+		}},
+		{name: "expired", err: ErrSignatureExpired, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{
+				items: defaults,
+				params: params{
+					{key: "created", value: now - 10},
+					{key: "expires", value: now - 5},
+					{key: "keyid", value: "hmac"},
+				},
+			})
+		}},
+		{name: "algorithm", err: ErrUnsupportedAlgorithm, modify: func(req *fasthttp.Request) {
+			req.Header.Del(fiber.HeaderSignature)
+			req.Header.Del(fiber.HeaderSignatureInput)
+			signWith(t, req, testSecret, "sig1", innerList{
+				items: defaults,
+				params: params{
+					{key: "created", value: now},
+					{key: "keyid", value: "hmac"},
+					{key: "alg", value: "ed25519"},
+				},
+			})
+		}},
+	} {
+		gotErr = nil
+		req := signed()
+		tc.modify(req)
+		resp := serve(t, app, req)
+		require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode(), tc.name)
+		require.ErrorIs(t, gotErr, tc.err, tc.name)
+		require.Equal(t, `sig1=("@method" "@authority" "@path" "content-digest")`, string(resp.Header.Peek(fiber.HeaderAcceptSignature)), tc.name)
+	}
+
+	// The alg parameter may name the algorithm of the key
+	req := newTestRequest(fiber.MethodGet, "http://example.com/", "")
+	req.Header.Set(fiber.HeaderContentDigest, contentDigest(nil))
+	signWith(t, req, testSecret, "sig1", innerList{
+		items: defaults,
+		params: params{
+			{key: "created", value: now},
+			{key: "keyid", value: "hmac"},
+			{key: "alg", value: "hmac-sha256"},
+		},
+	})
+	resp := serve(t, app, req)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, "hmac", string(resp.Body()))
+}
+
+func Test_HTTPSig_Labels(t *testing.T) {
+	t.Parallel()
+
+	other := []byte("another secret of thirty-two bytes")
+	keys := StaticKeys(map[string]any{"hmac": testSecret, "proxy": other})
+	req := newTestRequest(fiber.MethodGet, "http://example.com/", "")
+	require.NoError(t, NewSigner(SignerConfig{Key: other, KeyID: "proxy", Label: "proxy"}).Sign(req))
+	require.NoError(t, NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac"}).Sign(req))
+	require.Len(t, req.Header.PeekAll(fiber.HeaderSignature), 2)
+
+	// The first valid signature is accepted
+	var gotErr error
+	resp := serve(t, newTestApp(Config{Keys: keys}, &gotErr), req)
+	require.Equal(t, "proxy", string(resp.Body()))
+
+	// Only the signature of the label is verified
+	resp = serve(t, newTestApp(Config{Keys: keys, Label: "sig1"}, &gotErr), req)
+	require.Equal(t, "hmac", string(resp.Body()))
+	resp = serve(t, newTestApp(Config{Keys: keys, Label: "sig2"}, &gotErr), req)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+	require.ErrorIs(t, gotErr, ErrMissingSignature)
+	require.Equal(t, `sig2=("@method" "@authority" "@path" "content-digest")`, string(resp.Header.Peek(fiber.HeaderAcceptSignature)))
+}
+
+func Test_HTTPSig_Components(t *testing.T) {
+	t.Parallel()
+
+	var gotErr error
+	app := newTestApp(Config{
+		Keys:       StaticKeys(map[string]any{"hmac": testSecret}),
+		Components: []string{"@method", "@target-uri", `"@query-param";name="id"`, "x-request-id"},
+		Tag:        "webhooks",
+	}, &gotErr)
+	signer := NewSigner(SignerConfig{
+		Key:        testSecret,
+		KeyID:      "hmac",
+		Tag:        "webhooks",
+		Components: []string{"@method", "@target-uri", `@query-param;name="id"`, "x-request-id"},
+		Expires:    time.Minute,
+	})
+
+	req := newTestRequest(fiber.MethodDelete, "http://example.com/items?id=42", "")
+	req.Header.Set(fiber.HeaderXRequestID, "abc")
+	require.NoError(t, signer.Sign(req))
+	require.Empty(t, req.Header.Peek(fiber.HeaderContentDigest))
+	require.Contains(t, string(req.Header.Peek(fiber.HeaderSignatureInput)), `;tag="webhooks"`)
+	require.Contains(t, string(req.Header.Peek(fiber.HeaderSignatureInput)), `;expires=`)
+
+	resp := serve(t, app, req)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+
+	// The tag must match
+	req = newTestRequest(fiber.MethodDelete, "http://example.com/items?id=42", "")
+	req.Header.Set(fiber.HeaderXRequestID, "abc")
+	require.NoError(t, NewSigner(SignerConfig{
+		Key:        testSecret,
+		KeyID:      "hmac",
+		Components: []string{"@method", "@target-uri", `"@query-param";name="id"`, "x-request-id"},
+	}).Sign(req))
+	resp = serve(t, app, req)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+	require.ErrorIs(t, gotErr, ErrInvalidTag)
+	require.Equal(t, `sig1=("@method" "@target-uri" "@query-param";name="id" "x-request-id");tag="webhooks"`, string(resp.Header.Peek(fiber.HeaderAcceptSignature)))
+
+	// Requests missing a component cannot be signed
+	req = newTestRequest(fiber.MethodDelete, "http://example.com/items?id=42", "")
+	require.ErrorIs(t, signer.Sign(req), ErrMissingComponent)
+}
+
+func Test_HTTPSig_Nonce(t *testing.T) {
+	t.Parallel()
+
+	var gotErr error
+	app := newTestApp(Config{
+		Keys:    StaticKeys(map[string]any{"hmac": testSecret}),
+		Storage: memory.New(),
+	}, &gotErr)
+	signer := NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac", Nonce: true})
+
+	req := newTestRequest(fiber.MethodPost, "http://example.com/hooks", `{}`)
+	require.NoError(t, signer.Sign(req))
+	require.Contains(t, string(req.Header.Peek(fiber.HeaderSignatureInput)), `;nonce="`)
+
+	resp := serve(t, app, req)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+
+	// The same request is rejected when it is replayed
+	resp = serve(t, app, req)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+	require.ErrorIs(t, gotErr, ErrReplayedSignature)
+
+	// Another signature has another nonce
+	req = newTestRequest(fiber.MethodPost, "http://example.com/hooks", `{}`)
+	require.NoError(t, signer.Sign(req))
+	resp = serve(t, app, req)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+
+	// Signatures without a nonce are rejected
+	req = newTestRequest(fiber.MethodPost, "http://example.com/hooks", `{}`)
+	require.NoError(t, NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac"}).Sign(req))
+	resp = serve(t, app, req)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+	require.ErrorIs(t, gotErr, ErrMissingNonce)
+}
+
+// slowStorage is a Storage whose reads take time, like a remote one.
+type slowStorage struct {
+	*memory.Storage
+}
+
+func (s slowStorage) GetWithContext(ctx context.Context, key string) ([]byte, error) {
+	v, err := s.Storage.GetWithContext(ctx, key)
+	time.Sleep(20 * time.Millisecond)
+	return v, err
+}
+
+func Test_HTTPSig_Nonce_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{
+		Keys:         StaticKeys(map[string]any{"hmac": testSecret}),
+		Storage:      slowStorage{memory.New()},
+		ErrorHandler: ConfigDefault.ErrorHandler,
+	}, nil)
+	req := newTestRequest(fiber.MethodPost, "http://example.com/hooks", `{}`)
+	require.NoError(t, NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac", Nonce: true}).Sign(req))
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, req.Write(w))
+	require.NoError(t, w.Flush())
+	wire := buf.Bytes()
+
+	// The same request sent several times at once is accepted once
+	const n = 8
+	statuses := make(chan int, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fctx := &fasthttp.RequestCtx{}
+			if err := fctx.Request.Read(bufio.NewReader(bytes.NewReader(wire))); err != nil {
+				statuses <- 0
+				return
+			}
+			app.Handler()(fctx)
+			statuses <- fctx.Response.StatusCode()
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	accepted := 0
+	for status := range statuses {
+		if status == fiber.StatusOK {
+			accepted++
+			continue
+		}
+		require.Equal(t, fiber.StatusUnauthorized, status)
+	}
+	require.Equal(t, 1, accepted)
+}
+
+func Test_HTTPSig_KeysError(t *testing.T) {
+	t.Parallel()
+
+	errKeys := errors.New("key store unavailable")
+	var gotErr error
+	app := newTestApp(Config{
+		Keys: func(_ fiber.Ctx, _ string) (any, error) {
+			return nil, errKeys
+		},
+	}, &gotErr)
+
+	req := newTestRequest(fiber.MethodGet, "http://example.com/", "")
+	require.NoError(t, NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac"}).Sign(req))
+	resp := serve(t, app, req)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode())
+	require.ErrorIs(t, gotErr, errKeys)
+}
+
+func Test_HTTPSig_Next(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Keys: StaticKeys(nil),
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Empty(t, KeyIDFromContext(&fasthttp.RequestCtx{}))
+}
+
+func Test_HTTPSig_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "fiber: httpsig middleware requires a Keys function", func() {
+		New()
+	})
+	require.PanicsWithValue(t, "fiber: httpsig middleware requires a Keys function", func() {
+		New(Config{})
+	})
+	require.Panics(t, func() {
+		New(Config{Keys: StaticKeys(nil), Components: []string{"Content-Type"}})
+	})
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.PanicsWithValue(t, "fiber: httpsig signer requires a Key and a KeyID", func() {
+		NewSigner()
+	})
+	require.PanicsWithValue(t, "fiber: httpsig signer requires a Key and a KeyID", func() {
+		NewSigner(SignerConfig{Key: testSecret})
+	})
+	require.PanicsWithValue(t, "fiber: httpsig signer does not support ed25519.PublicKey keys", func() {
+		NewSigner(SignerConfig{Key: edKey.Public(), KeyID: "ed"})
+	})
+	require.PanicsWithValue(t, "fiber: httpsig signer does not support string keys", func() {
+		NewSigner(SignerConfig{Key: "secret", KeyID: "hmac"})
+	})
+	require.Panics(t, func() {
+		NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac", Components: []string{`"@method`}})
+	})
+}
+
+func Benchmark_HTTPSig(b *testing.B) {
+	app := fiber.New()
+	app.Use(New(Config{Keys: StaticKeys(map[string]any{"hmac": testSecret})}))
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	h := app.Handler()
+
+	req := newTestRequest(fiber.MethodPost, "http://example.com/", `{"event":"push"}`)
+	if err := NewSigner(SignerConfig{Key: testSecret, KeyID: "hmac"}).Sign(req); err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := req.Write(w); err != nil {
+		b.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+	raw := buf.Bytes()
+	fctx := &fasthttp.RequestCtx{}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		fctx.Request.Reset()
+		if err := fctx.Request.Read(bufio.NewReader(bytes.NewReader(raw))); err != nil {
+			b.Fatal(err)
+		}
+		h(fctx)
+	}
+	require.Equal(b, fiber.StatusNoContent, fctx.Response.StatusCode())
+}
@@ -0,0 +1,332 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// The algorithms of RFC 9421 section 3.3 supported by the middleware.
+const (
+	algorithmHMACSHA256      = "hmac-sha256"
+	algorithmEd25519         = "ed25519"
+	algorithmECDSAP256SHA256 = "ecdsa-p256-sha256"
+	algorithmECDSAP384SHA384 = "ecdsa-p384-sha384"
+)
+
+// component is a covered component of a signature: a lowercase field name
+// or a derived component name starting with '@'.
+type component struct {
+	name string
+	// param is the name parameter of the @query-param component.
+	param string
+}
+
+// parseComponent parses a component identifier, such as "@method",
+// "content-type" or `"@query-param";name="id"`.
+func parseComponent(s string) (component, error) {
+	if !strings.HasPrefix(s, `"`) {
+		name, rest, _ := strings.Cut(s, ";")
+		s = `"` + name + `"`
+		if rest != "" {
+			s += ";" + rest
+		}
+	}
+	it, err := parseItem(s)
+	if err != nil {
+		return component{}, fmt.Errorf("invalid component %q: %w", s, err)
+	}
+	return componentFromItem(it)
+}
+
+// componentFromItem returns the component of a covered component item of a
+// Signature-Input field.
+func componentFromItem(it item) (component, error) {
+	name, ok := it.value.(string)
+	if !ok || name == "" || name != strings.ToLower(name) {
+		return component{}, ErrMalformedSignature
+	}
+	c := component{name: name}
+	for _, p := range it.params {
+		value, ok := p.value.(string)
+		if name != "@query-param" || p.key != "name" || !ok {
+			// The sf, key, bs, req and tr parameters are not supported
+			return component{}, ErrUnsupportedComponent
+		}
+		c.param = value
+	}
+	if name == "@query-param" && c.param == "" {
+		return component{}, ErrMalformedSignature
+	}
+	return c, nil
+}
+
+// item returns the item of the component in a Signature-Input field.
+func (c component) item() item {
+	it := item{value: c.name}
+	if c.param != "" {
+		it.params = params{{key: "name", value: c.param}}
+	}
+	return it
+}
+
+func (c component) String() string {
+	return serializeItem(c.item())
+}
+
+// message is an HTTP request, signed by a client or verified by the
+// middleware.
+type message struct {
+	req    *fasthttp.Request
+	scheme string
+	// target is the request target, as sent in the request line.
+	target string
+}
+
+// receivedMessage returns the message of a request received by the server.
+func receivedMessage(req *fasthttp.Request, scheme string) message {
+	return message{req: req, scheme: scheme, target: string(req.Header.RequestURI())}
+}
+
+// sentMessage returns the message of a request sent by a client, which
+// writes the target of its parsed URI.
+func sentMessage(req *fasthttp.Request) message {
+	uri := req.URI()
+	return message{req: req, scheme: string(uri.Scheme()), target: string(uri.RequestURI())}
+}
+
+// authority returns the lowercase host of the request, without the default
+// port of its scheme.
+func (m message) authority() string {
+	host := strings.ToLower(string(m.req.Host()))
+	switch {
+	case m.scheme == "http" && strings.HasSuffix(host, ":80"):
+		return strings.TrimSuffix(host, ":80")
+	case m.scheme == "https" && strings.HasSuffix(host, ":443"):
+		return strings.TrimSuffix(host, ":443")
+	default:
+		return host
+	}
+}
+
+// value returns the value of a component in the request.
+func (m message) value(c component) (string, error) {
+	target := m.target
+	path, query, _ := strings.Cut(target, "?")
+	switch c.name {
+	case "@method":
+		return string(m.req.Header.Method()), nil
+	case "@target-uri":
+		return m.scheme + "://" + m.authority() + target, nil
+	case "@authority":
+		return m.authority(), nil
+	case "@scheme":
+		return m.scheme, nil
+	case "@request-target":
+		return target, nil
+	case "@path":
+		if path == "" {
+			path = "/"
+		}
+		return path, nil
+	case "@query":
+		return "?" + query, nil
+	case "@query-param":
+		values, err := url.ParseQuery(query)
+		if err != nil || len(values[c.param]) != 1 {
+			return "", fmt.Errorf("%w: %s", ErrMissingComponent, c)
+		}
+		return strings.ReplaceAll(url.QueryEscape(values[c.param][0]), "+", "%20"), nil
+	}
+	if strings.HasPrefix(c.name, "@") {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedComponent, c)
+	}
+
+	lines := m.req.Header.PeekAll(c.name)
+	if len(lines) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingComponent, c)
+	}
+	values := make([]string, len(lines))
+	for i, line := range lines {
+		values[i] = string(bytes.Trim(line, " \t"))
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// signatureBase returns the signature base of RFC 9421 section 2.5 for the
+// covered components and parameters of a signature.
+func (m message) signatureBase(input innerList) ([]byte, error) {
+	var b bytes.Buffer
+	seen := make(map[string]bool, len(input.items))
+	for _, it := range input.items {
+		c, err := componentFromItem(it)
+		if err != nil {
+			return nil, err
+		}
+		id := c.String()
+		if seen[id] {
+			return nil, ErrMalformedSignature
+		}
+		seen[id] = true
+
+		value, err := m.value(c)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(id)
+		b.WriteString(": ")
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+	b.WriteString(`"@signature-params": `)
+	b.WriteString(serializeInnerList(input))
+	return b.Bytes(), nil
+}
+
+// keyAlgorithm returns the algorithm of a signing or verification key, or ""
+// if the key is not supported.
+func keyAlgorithm(key any) string {
+	switch k := key.(type) {
+	case []byte:
+		if len(k) == 0 {
+			return ""
+		}
+		return algorithmHMACSHA256
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return algorithmEd25519
+	case *ecdsa.PublicKey:
+		return curveAlgorithm(k.Curve)
+	case *ecdsa.PrivateKey:
+		return curveAlgorithm(k.Curve)
+	default:
+		return ""
+	}
+}
+
+func curveAlgorithm(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return algorithmECDSAP256SHA256
+	case elliptic.P384():
+		return algorithmECDSAP384SHA384
+	default:
+		return ""
+	}
+}
+
+// digest returns the hash of the signature base for the ECDSA algorithms.
+func digest(curve elliptic.Curve, base []byte) []byte {
+	if curve == elliptic.P384() {
+		sum := sha512.Sum384(base)
+		return sum[:]
+	}
+	sum := sha256.Sum256(base)
+	return sum[:]
+}
+
+// sign returns the signature of the signature base with a signing key.
+func sign(key any, base []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write(base)
+		return mac.Sum(nil), nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, base), nil
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest(k.Curve, base))
+		if err != nil {
+			return nil, fmt.Errorf("httpsig: failed to sign: %w", err)
+		}
+		// The signature is the concatenation of R and S, each as long as the
+		// curve order
+		size := (k.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+// verify reports whether signature is a valid signature of the signature
+// base with a verification key.
+func verify(key any, base, signature []byte) bool {
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write(base)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case ed25519.PublicKey:
+		return len(k) == ed25519.PublicKeySize && ed25519.Verify(k, base, signature)
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest(k.Curve, base), r, s)
+	default:
+		return false
+	}
+}
+
+// contentDigest returns the Content-Digest field value of RFC 9530 for a body.
+func contentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256" + "=" + serializeItem(item{value: sum[:]})
+}
+
+// verifyDigest checks a body against its Content-Digest field value. Every
+// supported digest of the field must match, and there must be at least one.
+func verifyDigest(field string, body []byte) error {
+	members, err := parseDictionary(field)
+	if err != nil {
+		return ErrInvalidDigest
+	}
+	verified := false
+	for _, m := range members {
+		var hash crypto.Hash
+		switch m.key {
+		case "sha-256":
+			hash = crypto.SHA256
+		case "sha-512":
+			hash = crypto.SHA512
+		default:
+			continue
+		}
+		it, ok := m.value.(item)
+		if !ok {
+			return ErrInvalidDigest
+		}
+		expected, ok := it.value.([]byte)
+		if !ok {
+			return ErrInvalidDigest
+		}
+		h := hash.New()
+		h.Write(body)
+		if !hmac.Equal(h.Sum(nil), expected) {
+			return ErrInvalidDigest
+		}
+		verified = true
+	}
+	if !verified {
+		return errors.Join(ErrInvalidDigest, errors.New("no supported digest algorithm"))
+	}
+	return nil
+}
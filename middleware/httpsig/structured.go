@@ -0,0 +1,413 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// This file implements the subset of the Structured Field Values of RFC 8941
+// used by message signatures: dictionaries of inner lists and byte sequences,
+// with parameters of integers, strings, tokens, booleans and byte sequences.
+
+var errStructuredField = errors.New("invalid structured field")
+
+// token is a bare token item, as opposed to a string one.
+type token string
+
+// param is a parameter of an item or of an inner list.
+type param struct {
+	value any
+	key   string
+}
+
+// params are the ordered parameters of an item or of an inner list.
+type params []param
+
+// get returns the value of the parameter with the given key, if it exists.
+func (p params) get(key string) (any, bool) {
+	for _, param := range p {
+		if param.key == key {
+			return param.value, true
+		}
+	}
+	return nil, false
+}
+
+// item is a bare item with its parameters.
+type item struct {
+	value  any
+	params params
+}
+
+// innerList is a list of items with its parameters.
+type innerList struct {
+	items  []item
+	params params
+}
+
+// member is a member of a dictionary, whose value is an item or an inner list.
+type member struct {
+	value any
+	key   string
+}
+
+// parser parses a structured field.
+type parser struct {
+	s   string
+	pos int
+}
+
+// parseDictionary parses a dictionary field.
+func parseDictionary(s string) ([]member, error) {
+	p := &parser{s: s}
+	p.skipSP()
+	var members []member
+	for !p.done() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		if p.peek() == '=' {
+			p.pos++
+			if value, err = p.parseItemOrInnerList(); err != nil {
+				return nil, err
+			}
+		} else {
+			ps, err := p.parseParams()
+			if err != nil {
+				return nil, err
+			}
+			value = item{value: true, params: ps}
+		}
+
+		// Later members override earlier ones of the same key
+		members = removeMember(members, key)
+		members = append(members, member{key: key, value: value})
+
+		p.skipOWS()
+		if p.done() {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, errStructuredField
+		}
+		p.pos++
+		p.skipOWS()
+		if p.done() {
+			return nil, errStructuredField
+		}
+	}
+	return members, nil
+}
+
+func removeMember(members []member, key string) []member {
+	for i := range members {
+		if members[i].key == key {
+			return append(members[:i], members[i+1:]...)
+		}
+	}
+	return members
+}
+
+// parseItem parses an item field.
+func parseItem(s string) (item, error) {
+	p := &parser{s: s}
+	p.skipSP()
+	it, err := p.parseItem()
+	if err != nil {
+		return item{}, err
+	}
+	p.skipSP()
+	if !p.done() {
+		return item{}, errStructuredField
+	}
+	return it, nil
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) skipSP() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) skipOWS() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func (p *parser) parseItemOrInnerList() (any, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+	return p.parseItem()
+}
+
+func (p *parser) parseInnerList() (innerList, error) {
+	p.pos++ // (
+	var list innerList
+	for !p.done() {
+		p.skipSP()
+		if p.peek() == ')' {
+			p.pos++
+			ps, err := p.parseParams()
+			if err != nil {
+				return innerList{}, err
+			}
+			list.params = ps
+			return list, nil
+		}
+		it, err := p.parseItem()
+		if err != nil {
+			return innerList{}, err
+		}
+		list.items = append(list.items, it)
+		if c := p.peek(); c != ' ' && c != ')' {
+			return innerList{}, errStructuredField
+		}
+	}
+	return innerList{}, errStructuredField
+}
+
+func (p *parser) parseItem() (item, error) {
+	value, err := p.parseBareItem()
+	if err != nil {
+		return item{}, err
+	}
+	ps, err := p.parseParams()
+	if err != nil {
+		return item{}, err
+	}
+	return item{value: value, params: ps}, nil
+}
+
+func (p *parser) parseParams() (params, error) {
+	var ps params
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var value any = true
+		if p.peek() == '=' {
+			p.pos++
+			if value, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+		for i := range ps {
+			if ps[i].key == key {
+				ps = append(ps[:i], ps[i+1:]...)
+				break
+			}
+		}
+		ps = append(ps, param{key: key, value: value})
+	}
+	return ps, nil
+}
+
+func (p *parser) parseKey() (string, error) {
+	start := p.pos
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		return "", errStructuredField
+	}
+	for !p.done() {
+		c := p.peek()
+		if !isLCAlpha(c) && !isDigit(c) && c != '_' && c != '-' && c != '.' && c != '*' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *parser) parseBareItem() (any, error) {
+	switch c := p.peek(); {
+	case c == '-' || isDigit(c):
+		return p.parseInteger()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || isAlpha(c):
+		return p.parseToken(), nil
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	default:
+		return nil, errStructuredField
+	}
+}
+
+func (p *parser) parseInteger() (int64, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	// Decimals are not used by message signatures
+	if p.pos == digits || p.pos-digits > 15 || p.peek() == '.' {
+		return 0, errStructuredField
+	}
+	return strconv.ParseInt(p.s[start:p.pos], 10, 64)
+}
+
+func (p *parser) parseString() (string, error) {
+	p.pos++ // "
+	var b strings.Builder
+	for !p.done() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\':
+			if next := p.peek(); next == '"' || next == '\\' {
+				b.WriteByte(next)
+				p.pos++
+				continue
+			}
+			return "", errStructuredField
+		case c == '"':
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", errStructuredField
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", errStructuredField
+}
+
+func (p *parser) parseToken() token {
+	start := p.pos
+	for !p.done() && (isTChar(p.peek()) || p.peek() == ':' || p.peek() == '/') {
+		p.pos++
+	}
+	return token(p.s[start:p.pos])
+}
+
+func (p *parser) parseByteSequence() ([]byte, error) {
+	p.pos++ // :
+	end := strings.IndexByte(p.s[p.pos:], ':')
+	if end < 0 {
+		return nil, errStructuredField
+	}
+	encoded := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errStructuredField
+	}
+	return b, nil
+}
+
+func (p *parser) parseBoolean() (bool, error) {
+	p.pos++ // ?
+	switch p.peek() {
+	case '1':
+		p.pos++
+		return true, nil
+	case '0':
+		p.pos++
+		return false, nil
+	default:
+		return false, errStructuredField
+	}
+}
+
+func isLCAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isAlpha(c byte) bool {
+	return isLCAlpha(c) || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isTChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) || strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+// serializeInnerList serializes an inner list with its parameters.
+func serializeInnerList(list innerList) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, it := range list.items {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		writeBareItem(&b, it.value)
+		writeParams(&b, it.params)
+	}
+	b.WriteByte(')')
+	writeParams(&b, list.params)
+	return b.String()
+}
+
+// serializeItem serializes an item with its parameters.
+func serializeItem(it item) string {
+	var b strings.Builder
+	writeBareItem(&b, it.value)
+	writeParams(&b, it.params)
+	return b.String()
+}
+
+func writeParams(b *strings.Builder, ps params) {
+	for _, param := range ps {
+		b.WriteByte(';')
+		b.WriteString(param.key)
+		if v, ok := param.value.(bool); ok && v {
+			continue
+		}
+		b.WriteByte('=')
+		writeBareItem(b, param.value)
+	}
+}
+
+func writeBareItem(b *strings.Builder, value any) {
+	switch v := value.(type) {
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case string:
+		b.WriteByte('"')
+		for i := 0; i < len(v); i++ {
+			if v[i] == '"' || v[i] == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(v[i])
+		}
+		b.WriteByte('"')
+	case token:
+		b.WriteString(string(v))
+	case []byte:
+		b.WriteByte(':')
+		b.WriteString(base64.StdEncoding.EncodeToString(v))
+		b.WriteByte(':')
+	case bool:
+		if v {
+			b.WriteString("?1")
+		} else {
+			b.WriteString("?0")
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package httpsig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseDictionary(t *testing.T) {
+	t.Parallel()
+
+	members, err := parseDictionary(`sig1=("@method" "@query-param";name="id");created=1618884473;keyid="a\"b\\c";alg=ed25519, sig2=:AAEC:,  flag, neg=-5;ok=?0`)
+	require.NoError(t, err)
+	require.Equal(t, []member{
+		{key: "sig1", value: innerList{
+			items: []item{
+				{value: "@method"},
+				{value: "@query-param", params: params{{key: "name", value: "id"}}},
+			},
+			params: params{
+				{key: "created", value: int64(1618884473)},
+				{key: "keyid", value: `a"b\c`},
+				{key: "alg", value: token("ed25519")},
+			},
+		}},
+		{key: "sig2", value: item{value: []byte{0, 1, 2}}},
+		{key: "flag", value: item{value: true}},
+		{key: "neg", value: item{value: int64(-5), params: params{{key: "ok", value: false}}}},
+	}, members)
+
+	// Later members override earlier ones
+	members, err = parseDictionary(`a=1, b=2, a=3`)
+	require.NoError(t, err)
+	require.Equal(t, []member{{key: "b", value: item{value: int64(2)}}, {key: "a", value: item{value: int64(3)}}}, members)
+
+	members, err = parseDictionary("")
+	require.NoError(t, err)
+	require.Empty(t, members)
+
+	for _, field := range []string{
+		"a=1,",
+		"a=1 b=2",
+		"A=1",
+		"a=(1",
+		"a=(1 2)x",
+		`a="unterminated`,
+		`a="bad\escape"`,
+		"a=\"\x01\"",
+		"a=:AA",
+		"a=:A:",
+		"a=1.5",
+		"a=1234567890123456",
+		"a=-",
+		"a=?2",
+		"a=@",
+		"a=1;B=2",
+	} {
+		_, err = parseDictionary(field)
+		require.ErrorIs(t, err, errStructuredField, field)
+	}
+}
+
+func Test_ParseItem(t *testing.T) {
+	t.Parallel()
+
+	it, err := parseItem(` "content-type";sf `)
+	require.NoError(t, err)
+	require.Equal(t, item{value: "content-type", params: params{{key: "sf", value: true}}}, it)
+	value, ok := it.params.get("sf")
+	require.True(t, ok)
+	require.Equal(t, true, value)
+	_, ok = it.params.get("key")
+	require.False(t, ok)
+
+	for _, field := range []string{"", `"a" "b"`, `"a";`} {
+		_, err = parseItem(field)
+		require.Error(t, err, field)
+	}
+}
+
+func Test_Serialize(t *testing.T) {
+	t.Parallel()
+
+	list := innerList{
+		items: []item{{value: "@method"}, {value: "@query-param", params: params{{key: "name", value: "id"}}}},
+		params: params{
+			{key: "created", value: int64(1618884473)},
+			{key: "keyid", value: `a"b\c`},
+			{key: "alg", value: token("ed25519")},
+			{key: "on", value: true},
+			{key: "off", value: false},
+		},
+	}
+	serialized := serializeInnerList(list)
+	require.Equal(t, `("@method" "@query-param";name="id");created=1618884473;keyid="a\"b\\c";alg=ed25519;on;off=?0`, serialized)
+	require.Equal(t, ":AAEC:", serializeItem(item{value: []byte{0, 1, 2}}))
+
+	// Serialized fields are parsed back
+	members, err := parseDictionary("sig1=" + serialized)
+	require.NoError(t, err)
+	require.Equal(t, []member{{key: "sig1", value: list}}, members)
+}
@@ -0,0 +1,103 @@
+package ipfilter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ErrForbiddenIP is returned when the client IP is denied, or is not allowed.
+var ErrForbiddenIP = errors.New("ipfilter: forbidden IP address")
+
+// Config defines the config for the IP filter middleware.
+type Config struct {
+	// Storage holds a dynamic list under StorageKey, in the same format as
+	// File. It is reloaded every ReloadInterval, so that instances sharing
+	// the storage pick up its changes.
+	//
+	// Optional. Default: nil
+	Storage fiber.Storage
+
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// ErrorHandler is called when a request is rejected.
+	// Receives ErrForbiddenIP as the error.
+	//
+	// Optional. Default: returns 403 Forbidden.
+	ErrorHandler fiber.ErrorHandler
+
+	// StorageKey is the key of the dynamic list in Storage.
+	//
+	// Optional. Default: "ipfilter"
+	StorageKey string
+
+	// File is the path of a dynamic list, reloaded every ReloadInterval.
+	// Each line holds an "allow" or "deny" rule followed by an address or a
+	// CIDR range, such as "deny 203.0.113.0/24". Empty lines and lines
+	// starting with "#" are ignored.
+	//
+	// Optional. Default: ""
+	File string
+
+	// Allow lists the IPv4 and IPv6 addresses and CIDR ranges allowed, such
+	// as "10.8.0.0/16" or "2001:db8::/32". When the static or dynamic lists
+	// have allow rules, the other addresses are rejected.
+	//
+	// Optional. Default: nil
+	Allow []string
+
+	// Deny lists the addresses and CIDR ranges rejected. Deny rules take
+	// precedence over allow rules.
+	//
+	// Optional. Default: nil
+	Deny []string
+
+	// ReloadInterval is how often the dynamic lists of File and Storage are
+	// reloaded. A list which fails to reload is kept until the next reload.
+	//
+	// Optional. Default: 30 seconds
+	ReloadInterval time.Duration
+
+	// DenyForwarded matches the deny rules against every address of the
+	// X-Forwarded-For header as well, when the request comes from a trusted
+	// proxy. The allow rules are only matched against c.IP().
+	//
+	// Optional. Default: false
+	DenyForwarded bool
+}
+
+// ConfigDefault is the default config.
+var ConfigDefault = Config{
+	ErrorHandler: func(c fiber.Ctx, _ error) error {
+		return c.SendStatus(fiber.StatusForbidden)
+	},
+	StorageKey:     "ipfilter",
+	ReloadInterval: 30 * time.Second,
+}
+
+func configDefault(config ...Config) Config {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 && cfg.File == "" && cfg.Storage == nil {
+		panic("ipfilter: Allow, Deny, File or Storage is required")
+	}
+
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+	if cfg.StorageKey == "" {
+		cfg.StorageKey = ConfigDefault.StorageKey
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = ConfigDefault.ReloadInterval
+	}
+
+	return cfg
+}
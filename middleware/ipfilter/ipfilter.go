@@ -0,0 +1,115 @@
+package ipfilter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	fiberlog "github.com/gofiber/fiber/v3/log"
+)
+
+// New creates a new IP filter middleware handler. The client address is
+// c.IP(), which is read from the proxy header only for the trusted proxies of
+// the app config. Requests whose address cannot be parsed are rejected.
+func New(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+
+	f := &filter{cfg: &cfg}
+	if err := f.load(context.Background()); err != nil {
+		panic(err.Error())
+	}
+	f.nextReload.Store(time.Now().Add(cfg.ReloadInterval).UnixNano())
+
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		if f.dynamic() {
+			f.reload(c)
+		}
+		r := f.rules.Load()
+
+		addr, ok := parseAddr(c.IP())
+		if !ok || !r.allowed(addr) {
+			return cfg.ErrorHandler(c, ErrForbiddenIP)
+		}
+
+		if cfg.DenyForwarded && c.IsProxyTrusted() {
+			for _, ip := range c.IPs() {
+				addr, ok := parseAddr(ip)
+				if !ok || r.deny.contains(addr) {
+					return cfg.ErrorHandler(c, ErrForbiddenIP)
+				}
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// filter holds the rules of the static and dynamic lists, rebuilt when the
+// dynamic lists are reloaded.
+type filter struct {
+	cfg        *Config
+	rules      atomic.Pointer[rules]
+	nextReload atomic.Int64
+	mu         sync.Mutex
+}
+
+func (f *filter) dynamic() bool {
+	return f.cfg.File != "" || f.cfg.Storage != nil
+}
+
+// reload reloads the dynamic lists when ReloadInterval has passed. Only one
+// request reloads them, the others keep using the current rules.
+func (f *filter) reload(ctx context.Context) {
+	now := time.Now()
+	if now.UnixNano() < f.nextReload.Load() || !f.mu.TryLock() {
+		return
+	}
+	defer f.mu.Unlock()
+
+	f.nextReload.Store(now.Add(f.cfg.ReloadInterval).UnixNano())
+	if err := f.load(ctx); err != nil {
+		fiberlog.Warnf("%v", err)
+	}
+}
+
+// load builds the rules of the static and dynamic lists. The current rules
+// are kept if a list cannot be loaded.
+func (f *filter) load(ctx context.Context) error {
+	r := &rules{}
+	if err := r.addStatic(f.cfg.Allow, f.cfg.Deny); err != nil {
+		return err
+	}
+
+	if f.cfg.File != "" {
+		data, err := os.ReadFile(f.cfg.File)
+		if err != nil {
+			return fmt.Errorf("ipfilter: failed to read list: %w", err)
+		}
+		if err := r.addList(f.cfg.File, data); err != nil {
+			return err
+		}
+	}
+
+	if f.cfg.Storage != nil {
+		// A missing key is an empty list
+		data, err := f.cfg.Storage.GetWithContext(ctx, f.cfg.StorageKey)
+		if err != nil {
+			return fmt.Errorf("ipfilter: failed to get list from storage: %w", err)
+		}
+		if err := r.addList("storage key "+f.cfg.StorageKey, data); err != nil {
+			return err
+		}
+	}
+
+	f.rules.Store(r)
+	return nil
+}
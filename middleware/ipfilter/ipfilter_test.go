@@ -0,0 +1,282 @@
+package ipfilter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+// newTestApp creates an app behind a trusted proxy, as app.Test() uses the
+// remote address 0.0.0.0, so that the client IP is read from X-Forwarded-For.
+func newTestApp(config ...Config) *fiber.App {
+	app := fiber.New(fiber.Config{
+		TrustProxy:         true,
+		ProxyHeader:        fiber.HeaderXForwardedFor,
+		EnableIPValidation: true,
+		TrustProxyConfig: fiber.TrustProxyConfig{
+			Proxies: []string{"0.0.0.0", "10.0.0.1"},
+		},
+	})
+	app.Use(New(config...))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	return app
+}
+
+func testStatus(t *testing.T, app *fiber.App, path, forwardedFor string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, path, http.NoBody)
+	if forwardedFor != "" {
+		req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func Test_ConfigDefault(t *testing.T) {
+	t.Parallel()
+
+	cfg := configDefault(Config{Allow: []string{"10.0.0.0/8"}})
+	require.NotNil(t, cfg.ErrorHandler)
+	require.Equal(t, "ipfilter", cfg.StorageKey)
+	require.Equal(t, 30*time.Second, cfg.ReloadInterval)
+
+	require.PanicsWithValue(t, "ipfilter: Allow, Deny, File or Storage is required", func() {
+		configDefault()
+	})
+	require.PanicsWithValue(t, `ipfilter: invalid CIDR range "10.0.0.0/40"`, func() {
+		New(Config{Deny: []string{"10.0.0.0/40"}})
+	})
+	require.PanicsWithValue(t, `ipfilter: invalid IP address "localhost"`, func() {
+		New(Config{Allow: []string{"localhost"}})
+	})
+}
+
+func Test_IPFilter_Allow(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{Allow: []string{"10.8.0.0/16", "2001:db8::/32"}})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "10.8.0.1"))
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "2001:db8::1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.9.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "2001:db9::1"))
+	// Without a proxy header, the client IP is the trusted proxy itself
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", ""))
+}
+
+func Test_IPFilter_Deny(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{
+		Allow: []string{"10.8.0.0/16"},
+		Deny:  []string{"10.8.1.0/24", "192.0.2.1"},
+	})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "10.8.0.1"))
+	// Deny rules take precedence over allow rules
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.8.1.1"))
+
+	app = newTestApp(Config{Deny: []string{"192.0.2.1", "2001:db8::/32"}})
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "192.0.2.2"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "192.0.2.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "2001:db8::1"))
+}
+
+func Test_IPFilter_TrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{Allow: []string{"10.8.0.0/16"}})
+
+	// The client IP is the rightmost address not of a trusted proxy
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "10.8.0.1, 10.0.0.1"))
+	// A spoofed address before the one added by the proxy is ignored
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.8.0.1, 192.0.2.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.8.0.1, 192.0.2.1, 10.0.0.1"))
+
+	// The proxy header of untrusted proxies is ignored
+	untrusted := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	untrusted.Use(New(Config{Allow: []string{"10.8.0.0/16"}}))
+	untrusted.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, untrusted, "/", "10.8.0.1"))
+
+	// Without IP validation, the raw header value cannot be parsed, and is
+	// rejected
+	unvalidated := fiber.New(fiber.Config{
+		TrustProxy:       true,
+		ProxyHeader:      fiber.HeaderXForwardedFor,
+		TrustProxyConfig: fiber.TrustProxyConfig{Proxies: []string{"0.0.0.0"}},
+	})
+	unvalidated.Use(New(Config{Allow: []string{"10.8.0.0/16"}}))
+	unvalidated.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	require.Equal(t, fiber.StatusOK, testStatus(t, unvalidated, "/", "10.8.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, unvalidated, "/", "10.8.0.1, 192.0.2.1"))
+}
+
+func Test_IPFilter_DenyForwarded(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{Deny: []string{"203.0.113.0/24"}})
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "203.0.113.5, 198.51.100.1"))
+
+	app = newTestApp(Config{Deny: []string{"203.0.113.0/24"}, DenyForwarded: true})
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "198.51.100.2, 198.51.100.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "203.0.113.5, 198.51.100.1"))
+
+	// The forwarded addresses of untrusted proxies are not checked
+	untrusted := fiber.New()
+	untrusted.Use(New(Config{Deny: []string{"203.0.113.0/24"}, DenyForwarded: true}))
+	untrusted.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	require.Equal(t, fiber.StatusOK, testStatus(t, untrusted, "/", "203.0.113.5"))
+}
+
+func Test_IPFilter_Groups(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New(fiber.Config{
+		TrustProxy:         true,
+		ProxyHeader:        fiber.HeaderXForwardedFor,
+		EnableIPValidation: true,
+		TrustProxyConfig:   fiber.TrustProxyConfig{Proxies: []string{"0.0.0.0"}},
+	})
+	app.Use(New(Config{Deny: []string{"203.0.113.0/24"}}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	admin := app.Group("/admin", New(Config{Allow: []string{"10.8.0.0/16"}}))
+	admin.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("admin")
+	})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "198.51.100.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "203.0.113.1"))
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/admin", "10.8.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/admin", "198.51.100.1"))
+}
+
+func Test_IPFilter_File(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ipfilter.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# VPN\nallow 10.8.0.0/16\n"), 0o600))
+
+	app := newTestApp(Config{
+		File:           path,
+		Deny:           []string{"10.8.1.0/24"},
+		ReloadInterval: 10 * time.Millisecond,
+	})
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "10.8.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.8.1.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.9.0.1"))
+
+	// The list is reloaded, and combined with the static rules
+	require.NoError(t, os.WriteFile(path, []byte("allow 10.9.0.0/16\nallow 10.8.1.0/24\n"), 0o600))
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "10.9.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.8.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.8.1.1"))
+
+	// An invalid list is not loaded, and the previous one is kept
+	require.NoError(t, os.WriteFile(path, []byte("allow 10.10.0.0/16\nallow everyone\n"), 0o600))
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "10.9.0.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "10.10.0.1"))
+
+	require.PanicsWithValue(t, `ipfilter: `+path+` line 2: invalid IP address "everyone"`, func() {
+		New(Config{File: path})
+	})
+	require.Panics(t, func() {
+		New(Config{File: filepath.Join(t.TempDir(), "missing.txt")})
+	})
+}
+
+type failingStorage struct {
+	*memory.Storage
+	fail bool
+}
+
+func (s *failingStorage) GetWithContext(ctx context.Context, key string) ([]byte, error) {
+	if s.fail {
+		return nil, errors.New("storage unavailable")
+	}
+	return s.Storage.GetWithContext(ctx, key)
+}
+
+func Test_IPFilter_Storage(t *testing.T) {
+	t.Parallel()
+
+	storage := &failingStorage{Storage: memory.New()}
+
+	// A missing key is an empty list
+	app := newTestApp(Config{
+		Storage:        storage,
+		StorageKey:     "blocklist",
+		ReloadInterval: 10 * time.Millisecond,
+	})
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "203.0.113.1"))
+
+	// Instances sharing the storage pick up its changes
+	require.NoError(t, storage.Set("blocklist", []byte("deny 203.0.113.0/24\n"), 0))
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "203.0.113.1"))
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/", "198.51.100.1"))
+
+	// The rules are kept while the storage fails
+	storage.fail = true
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "203.0.113.1"))
+
+	require.PanicsWithValue(t, "ipfilter: failed to get list from storage: storage unavailable", func() {
+		New(Config{Storage: storage})
+	})
+}
+
+func Test_IPFilter_Next(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{
+		Next: func(c fiber.Ctx) bool {
+			return c.Path() == "/health"
+		},
+		Allow: []string{"10.8.0.0/16"},
+	})
+	app.Get("/health", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	require.Equal(t, fiber.StatusOK, testStatus(t, app, "/health", "192.0.2.1"))
+	require.Equal(t, fiber.StatusForbidden, testStatus(t, app, "/", "192.0.2.1"))
+}
+
+func Test_IPFilter_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{
+		Allow: []string{"10.8.0.0/16"},
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			require.ErrorIs(t, err, ErrForbiddenIP)
+			return c.Status(fiber.StatusNotFound).SendString("not found")
+		},
+	})
+
+	require.Equal(t, fiber.StatusNotFound, testStatus(t, app, "/", "192.0.2.1"))
+}
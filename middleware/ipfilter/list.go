@@ -0,0 +1,146 @@
+package ipfilter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// addrSet is a set of addresses and CIDR ranges. Single addresses are kept in
+// a map, so that long lists of them are matched in constant time.
+type addrSet struct {
+	addrs    map[netip.Addr]struct{}
+	prefixes []netip.Prefix
+}
+
+func (s *addrSet) add(p netip.Prefix) {
+	if p.IsSingleIP() {
+		if s.addrs == nil {
+			s.addrs = make(map[netip.Addr]struct{})
+		}
+		s.addrs[p.Addr()] = struct{}{}
+		return
+	}
+	s.prefixes = append(s.prefixes, p)
+}
+
+func (s *addrSet) contains(addr netip.Addr) bool {
+	if _, ok := s.addrs[addr]; ok {
+		return true
+	}
+	for _, p := range s.prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *addrSet) empty() bool {
+	return len(s.addrs) == 0 && len(s.prefixes) == 0
+}
+
+// rules are the allow and deny rules of the static and dynamic lists.
+type rules struct {
+	allow addrSet
+	deny  addrSet
+}
+
+// allowed reports whether an address is in no deny rule, and in an allow rule
+// unless there are none.
+func (r *rules) allowed(addr netip.Addr) bool {
+	if r.deny.contains(addr) {
+		return false
+	}
+	return r.allow.empty() || r.allow.contains(addr)
+}
+
+// addStatic adds the rules of the Allow and Deny lists of a config.
+func (r *rules) addStatic(allow, deny []string) error {
+	for _, entry := range allow {
+		p, err := parseEntry(entry)
+		if err != nil {
+			return fmt.Errorf("ipfilter: %w", err)
+		}
+		r.allow.add(p)
+	}
+	for _, entry := range deny {
+		p, err := parseEntry(entry)
+		if err != nil {
+			return fmt.Errorf("ipfilter: %w", err)
+		}
+		r.deny.add(p)
+	}
+	return nil
+}
+
+// addList adds the rules of a dynamic list, one "allow" or "deny" rule per
+// line. Empty lines and lines starting with "#" are ignored. The source names
+// the list in the errors.
+func (r *rules) addList(source string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return fmt.Errorf("ipfilter: %s line %d: invalid rule %q", source, line, text)
+		}
+		p, err := parseEntry(fields[1])
+		if err != nil {
+			return fmt.Errorf("ipfilter: %s line %d: %w", source, line, err)
+		}
+		switch action := fields[0]; action {
+		case "allow":
+			r.allow.add(p)
+		case "deny":
+			r.deny.add(p)
+		default:
+			return fmt.Errorf("ipfilter: %s line %d: unknown rule %q", source, line, action)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ipfilter: failed to read %s: %w", source, err)
+	}
+	return nil
+}
+
+// parseEntry parses an address or a CIDR range. IPv4-mapped IPv6 entries are
+// converted to IPv4, as the client addresses are.
+func parseEntry(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.IndexByte(entry, '/') >= 0 {
+		p, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		if p.Addr().Is4In6() {
+			if p.Bits() < 96 {
+				return netip.Prefix{}, fmt.Errorf("invalid CIDR range %q", entry)
+			}
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		return p.Masked(), nil
+	}
+
+	addr, ok := parseAddr(entry)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q", entry)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseAddr parses an address, without its IPv6 zone, converting IPv4-mapped
+// IPv6 addresses to IPv4.
+func parseAddr(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.WithZone("").Unmap(), true
+}
@@ -0,0 +1,96 @@
+package ipfilter
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseEntry(t *testing.T) {
+	t.Parallel()
+
+	for entry, expected := range map[string]string{
+		"192.0.2.1":             "192.0.2.1/32",
+		" 192.0.2.1 ":           "192.0.2.1/32",
+		"192.0.2.77/24":         "192.0.2.0/24",
+		"2001:db8::1":           "2001:db8::1/128",
+		"2001:db8::1/32":        "2001:db8::/32",
+		"fe80::1%eth0":          "fe80::1/128",
+		"::ffff:192.0.2.1":      "192.0.2.1/32",
+		"::ffff:192.0.2.0/120":  "192.0.2.0/24",
+		"0.0.0.0/0":             "0.0.0.0/0",
+		"::/0":                  "::/0",
+		"10.8.0.0/16":           "10.8.0.0/16",
+		"2001:DB8:0:0:0:0:0:1":  "2001:db8::1/128",
+		"192.168.100.200/32":    "192.168.100.200/32",
+		"2001:db8:abcd::/48":    "2001:db8:abcd::/48",
+		"::ffff:192.0.2.1/128":  "192.0.2.1/32",
+		"::ffff:10.0.0.0/104":   "10.0.0.0/8",
+		"2001:db8::ffff:0:1/64": "2001:db8::/64",
+	} {
+		p, err := parseEntry(entry)
+		require.NoError(t, err, entry)
+		require.Equal(t, expected, p.String(), entry)
+	}
+
+	for _, entry := range []string{"", "example.com", "192.0.2", "192.0.2.1/33", "2001:db8::/129", "::ffff:0:0/95", "192.0.2.1/", "fe80::%eth0/64"} {
+		_, err := parseEntry(entry)
+		require.Error(t, err, entry)
+	}
+}
+
+func Test_Rules_Allowed(t *testing.T) {
+	t.Parallel()
+
+	r := &rules{}
+	require.NoError(t, r.addStatic([]string{"10.8.0.0/16", "2001:db8::/32", "192.0.2.1"}, []string{"10.8.1.0/24", "2001:db8::bad"}))
+
+	for addr, expected := range map[string]bool{
+		"10.8.0.1":          true,
+		"10.8.1.1":          false,
+		"10.9.0.1":          false,
+		"192.0.2.1":         true,
+		"192.0.2.2":         false,
+		"2001:db8::1":       true,
+		"2001:db8::bad":     false,
+		"2001:db9::1":       false,
+		"::ffff:10.8.0.1":   true,
+		"fe80::1%eth0":      false,
+		"::ffff:10.8.1.254": false,
+	} {
+		a, ok := parseAddr(addr)
+		require.True(t, ok, addr)
+		require.Equal(t, expected, r.allowed(a), addr)
+	}
+
+	// Without allow rules, every address which is not denied is allowed
+	r = &rules{}
+	require.NoError(t, r.addStatic(nil, []string{"203.0.113.0/24"}))
+	require.True(t, r.allowed(netip.MustParseAddr("198.51.100.1")))
+	require.False(t, r.allowed(netip.MustParseAddr("203.0.113.9")))
+
+	require.ErrorContains(t, (&rules{}).addStatic([]string{"10.0.0.0/8", "invalid"}, nil), `ipfilter: invalid IP address "invalid"`)
+}
+
+func Test_Rules_AddList(t *testing.T) {
+	t.Parallel()
+
+	r := &rules{}
+	require.NoError(t, r.addList("list", []byte("# VPN\nallow 10.8.0.0/16\n\n  deny\t10.8.1.1  \r\ndeny 2001:db8::/32\n")))
+	require.True(t, r.allowed(netip.MustParseAddr("10.8.0.1")))
+	require.False(t, r.allowed(netip.MustParseAddr("10.8.1.1")))
+	require.False(t, r.allowed(netip.MustParseAddr("2001:db8::1")))
+	require.False(t, r.allowed(netip.MustParseAddr("192.0.2.1")))
+
+	require.NoError(t, (&rules{}).addList("list", nil))
+
+	for data, expected := range map[string]string{
+		"allow 10.0.0.0/8\npermit 192.0.2.1": `ipfilter: list line 2: unknown rule "permit"`,
+		"deny 10.0.0.300":                    `ipfilter: list line 1: invalid IP address "10.0.0.300"`,
+		"deny":                               `ipfilter: list line 1: invalid rule "deny"`,
+		"deny 10.0.0.1 10.0.0.2":             `ipfilter: list line 1: invalid rule "deny 10.0.0.1 10.0.0.2"`,
+	} {
+		require.EqualError(t, (&rules{}).addList("list", []byte(data)), expected, data)
+	}
+}
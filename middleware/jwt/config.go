@@ -0,0 +1,135 @@
+package jwt
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/gofiber/fiber/v3/internal/bearer"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// SuccessHandler defines a function which is executed for a valid token.
+	//
+	// Optional. Default: c.Next()
+	SuccessHandler fiber.Handler
+
+	// ErrorHandler defines a function which is executed for a missing or
+	// invalid token. It may be used to define a custom error.
+	//
+	// Optional. Default: 401 Missing or invalid JWT, 403 for ErrInsufficientScope
+	ErrorHandler fiber.ErrorHandler
+
+	// KeySet provides the keys verifying the token signatures, such as
+	// NewKeySet, NewFileKeySet or NewRemoteKeySet.
+	//
+	// Required.
+	KeySet KeySet
+
+	// Extractor is a function to extract the token from the request.
+	//
+	// Optional. Default: extractors.FromAuthHeader("Bearer")
+	Extractor extractors.Extractor
+
+	// Realm defines the protected area for WWW-Authenticate responses.
+	//
+	// Optional. Default: "Restricted"
+	Realm string
+
+	// Issuer is the issuer the `iss` claim must be equal to.
+	//
+	// Optional. Default: "" (not checked)
+	Issuer string
+
+	// Scope is the space-delimited list of scopes the token must all have,
+	// in its `scope` or `scp` claim. Tokens without them are rejected with
+	// ErrInsufficientScope and an RFC 6750 `insufficient_scope` challenge.
+	//
+	// Optional. Default: "" (not checked)
+	Scope string
+
+	// Audience lists the audiences of which the `aud` claim must contain at
+	// least one.
+	//
+	// Optional. Default: nil (not checked)
+	Audience []string
+
+	// Algorithms lists the signing algorithms accepted.
+	//
+	// Optional. Default: every supported algorithm
+	Algorithms []string
+
+	// ClockSkew is the leeway allowed when checking the `exp` and `nbf`
+	// claims, to account for clock differences between servers.
+	//
+	// Optional. Default: 0
+	ClockSkew time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	SuccessHandler: func(c fiber.Ctx) error {
+		return c.Next()
+	},
+	ErrorHandler: func(c fiber.Ctx, err error) error {
+		if errors.Is(err, ErrInsufficientScope) {
+			return c.Status(fiber.StatusForbidden).SendString(ErrInsufficientScope.Error())
+		}
+		return c.Status(fiber.StatusUnauthorized).SendString("missing or invalid JWT")
+	},
+	Realm:      "Restricted",
+	Extractor:  extractors.FromAuthHeader("Bearer"),
+	Algorithms: supportedAlgorithms,
+}
+
+// configDefault is a helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		panic("fiber: jwt middleware requires a key set")
+	}
+	cfg := config[0]
+
+	// Require a key set
+	if cfg.KeySet == nil {
+		panic("fiber: jwt middleware requires a key set")
+	}
+
+	// Set default values
+	if cfg.SuccessHandler == nil {
+		cfg.SuccessHandler = ConfigDefault.SuccessHandler
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+	if cfg.Extractor.Extract == nil {
+		cfg.Extractor = ConfigDefault.Extractor
+	}
+	if cfg.Realm == "" {
+		cfg.Realm = ConfigDefault.Realm
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = ConfigDefault.Algorithms
+	}
+	for _, alg := range cfg.Algorithms {
+		if !slices.Contains(supportedAlgorithms, alg) {
+			panic("fiber: jwt unsupported algorithm " + alg)
+		}
+	}
+	if cfg.Scope != "" && !bearer.ValidScope(cfg.Scope) {
+		panic("fiber: jwt scope contains invalid token")
+	}
+	if cfg.ClockSkew < 0 {
+		panic("fiber: jwt clock skew must not be negative")
+	}
+
+	return cfg
+}
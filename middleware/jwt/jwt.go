@@ -0,0 +1,136 @@
+// Package jwt authenticates requests with JSON Web Tokens (RFC 7519) signed
+// with JWS (RFC 7515), verified with keys from a local or remote JWK Set.
+package jwt
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/gofiber/fiber/v3/internal/bearer"
+)
+
+// The contextKey type is unexported to prevent collisions with context keys defined in
+// other packages.
+type contextKey int
+
+// The keys for the values in context
+const (
+	tokenKey contextKey = iota
+	claimsKey
+)
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Init config
+	cfg := configDefault(config...)
+
+	v := &Verifier{cfg: &cfg, now: time.Now}
+
+	// Determine the auth schemes from the extractor chain.
+	authSchemes := getAuthSchemes(cfg.Extractor)
+
+	// challenge returns the WWW-Authenticate value for an error.
+	challenge := func(err error) string {
+		var params bearer.Params
+		switch {
+		case errors.Is(err, ErrInsufficientScope):
+			params = bearer.Params{Error: bearer.ErrorInsufficientScope, Scope: cfg.Scope}
+		case slices.ContainsFunc(tokenErrors, func(target error) bool { return errors.Is(err, target) }):
+			params = bearer.Params{Error: bearer.ErrorInvalidToken, ErrorDescription: err.Error()}
+		default:
+			// RFC 6750 sends no error code to requests without credentials
+		}
+		challenges := make([]string, len(authSchemes))
+		for i, scheme := range authSchemes {
+			challenges[i] = bearer.Challenge(scheme, cfg.Realm, params)
+		}
+		return strings.Join(challenges, ", ")
+	}
+
+	// Return middleware handler
+	return func(c fiber.Ctx) error {
+		// Filter request to skip middleware
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Extract and verify the token
+		token, err := cfg.Extractor.Extract(c)
+		if errors.Is(err, extractors.ErrNotFound) {
+			// Replace shared extractor not found error with a jwt specific error
+			err = ErrMissingToken
+		}
+		if err == nil {
+			var claims *Claims
+			claims, err = v.Verify(c.Context(), token)
+			if err == nil && !hasScopes(claims, cfg.Scope) {
+				err = ErrInsufficientScope
+			}
+			if err == nil {
+				fiber.StoreInContext(c, tokenKey, token)
+				fiber.StoreInContext(c, claimsKey, claims)
+				return cfg.SuccessHandler(c)
+			}
+		}
+
+		// Execute the error handler first
+		handlerErr := cfg.ErrorHandler(c, err)
+
+		status := c.Response().StatusCode()
+		if (status == fiber.StatusUnauthorized || status == fiber.StatusForbidden) && len(authSchemes) > 0 {
+			c.Set(fiber.HeaderWWWAuthenticate, challenge(err))
+		}
+
+		return handlerErr
+	}
+}
+
+// hasScopes reports whether the claims have every scope of the
+// space-delimited list.
+func hasScopes(claims *Claims, scope string) bool {
+	for s := range strings.FieldsSeq(scope) {
+		if !claims.HasScope(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// TokenFromContext returns the verified token from the request context.
+// It accepts fiber.CustomCtx, fiber.Ctx, *fasthttp.RequestCtx, and context.Context.
+// It returns an empty string if the token does not exist.
+func TokenFromContext(ctx any) string {
+	if token, ok := fiber.ValueFromContext[string](ctx, tokenKey); ok {
+		return token
+	}
+
+	return ""
+}
+
+// ClaimsFromContext returns the claims of the verified token from the request
+// context. It accepts fiber.CustomCtx, fiber.Ctx, *fasthttp.RequestCtx, and
+// context.Context. It returns nil if there is no verified token.
+func ClaimsFromContext(ctx any) *Claims {
+	if claims, ok := fiber.ValueFromContext[*Claims](ctx, claimsKey); ok {
+		return claims
+	}
+
+	return nil
+}
+
+// getAuthSchemes inspects an extractor and its chain to find all auth schemes
+// used by FromAuthHeader.
+func getAuthSchemes(e extractors.Extractor) []string {
+	var schemes []string
+	if e.Source == extractors.SourceAuthHeader && e.AuthScheme != "" {
+		schemes = append(schemes, e.AuthScheme)
+	}
+	for _, ex := range e.Chain {
+		schemes = append(schemes, getAuthSchemes(ex)...)
+	}
+	return schemes
+}
@@ -0,0 +1,511 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+)
+
+var (
+	testSecret     = []byte("0123456789abcdef0123456789abcdef")
+	testRSAKey     = mustRSAKey()
+	testECKey      = mustECKey(elliptic.P256())
+	testEd25519Key = mustEd25519Key()
+)
+
+func mustRSAKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func mustECKey(curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func mustEd25519Key() ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func segment(t testing.TB, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign returns a token of claims signed with key for alg, with the given key
+// ID if it is not empty.
+func sign(t testing.TB, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+
+	h := map[string]any{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		h["kid"] = kid
+	}
+	input := segment(t, h) + "." + segment(t, claims)
+
+	var signature []byte
+	var err error
+	if alg == "EdDSA" {
+		signature = ed25519.Sign(key.(ed25519.PrivateKey), []byte(input)) //nolint:forcetypeassert,errcheck // Test keys
+	} else {
+		hash := algorithmHash(alg)
+		h := hash.New()
+		h.Write([]byte(input))
+		digest := h.Sum(nil)
+
+		switch alg[:2] {
+		case "HS":
+			mac := hmac.New(hash.New, key.([]byte)) //nolint:forcetypeassert,errcheck // Test keys
+			mac.Write([]byte(input))
+			signature = mac.Sum(nil)
+		case "RS":
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), hash, digest) //nolint:forcetypeassert,errcheck // Test keys
+		case "PS":
+			signature, err = rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) //nolint:forcetypeassert,errcheck // Test keys
+		default:
+			priv := key.(*ecdsa.PrivateKey) //nolint:forcetypeassert,errcheck // Test keys
+			var r, s *big.Int
+			r, s, err = ecdsa.Sign(rand.Reader, priv, digest)
+			size := (priv.Curve.Params().BitSize + 7) / 8
+			signature = make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+		}
+	}
+	require.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestApp(config Config) *fiber.App {
+	app := fiber.New()
+	app.Use(New(config))
+	app.Get("/", func(c fiber.Ctx) error {
+		claims := ClaimsFromContext(c)
+		if claims == nil || TokenFromContext(c) == "" {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(claims.Subject)
+	})
+	return app
+}
+
+func request(t *testing.T, app *fiber.App, token string) (status int, body, challenge string) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(b), resp.Header.Get(fiber.HeaderWWWAuthenticate)
+}
+
+func Test_JWT_Algorithms(t *testing.T) {
+	t.Parallel()
+
+	ec384 := mustECKey(elliptic.P384())
+	ec521 := mustECKey(elliptic.P521())
+	app := newTestApp(Config{KeySet: NewKeySet(
+		Key{Key: testSecret},
+		Key{Key: &testRSAKey.PublicKey},
+		Key{Key: &testECKey.PublicKey},
+		Key{Key: &ec384.PublicKey},
+		Key{Key: &ec521.PublicKey},
+		Key{Key: testEd25519Key.Public()},
+	)})
+
+	keys := map[string]any{
+		"HS256": testSecret, "HS384": testSecret, "HS512": testSecret,
+		"RS256": testRSAKey, "RS384": testRSAKey, "RS512": testRSAKey,
+		"PS256": testRSAKey, "PS384": testRSAKey, "PS512": testRSAKey,
+		"ES256": testECKey, "ES384": ec384, "ES512": ec521,
+		"EdDSA": testEd25519Key,
+	}
+	require.Len(t, keys, len(supportedAlgorithms))
+	for alg, key := range keys {
+		t.Run(alg, func(t *testing.T) {
+			t.Parallel()
+			status, body, _ := request(t, app, sign(t, alg, "", key, map[string]any{"sub": "alice"}))
+			require.Equal(t, fiber.StatusOK, status)
+			require.Equal(t, "alice", body)
+		})
+	}
+}
+
+func Test_JWT_MissingToken(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{KeySet: NewKeySet(Key{Key: testSecret}), Realm: "api"})
+
+	status, body, challenge := request(t, app, "")
+	require.Equal(t, fiber.StatusUnauthorized, status)
+	require.Equal(t, "missing or invalid JWT", body)
+	require.Equal(t, `Bearer realm="api"`, challenge)
+}
+
+func Test_JWT_InvalidToken(t *testing.T) {
+	t.Parallel()
+
+	var gotErr error
+	app := newTestApp(Config{
+		KeySet: NewKeySet(
+			Key{Key: testSecret, ID: "hmac"},
+			Key{Key: &testRSAKey.PublicKey, ID: "rsa", Algorithm: "RS256"},
+		),
+		Algorithms: []string{"HS256", "RS256", "PS256"},
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			gotErr = err
+			return c.SendStatus(fiber.StatusUnauthorized)
+		},
+	})
+
+	valid := sign(t, "HS256", "hmac", testSecret, map[string]any{"sub": "alice"})
+	otherKey := mustRSAKey()
+	publicDER, err := json.Marshal(testRSAKey.PublicKey)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		err   error
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-jwt", err: ErrMalformedToken},
+		{name: "two segments", token: "a.b", err: ErrMalformedToken},
+		{name: "four segments", token: valid + ".x", err: ErrMalformedToken},
+		{name: "bad header", token: "e30x" + valid[strings.Index(valid, "."):], err: ErrMalformedToken},
+		{name: "none", token: segment(t, map[string]string{"alg": "none"}) + "." + segment(t, map[string]string{"sub": "alice"}) + ".", err: ErrUnsupportedAlgorithm},
+		{name: "not allowed", token: sign(t, "HS512", "", testSecret, nil), err: ErrUnsupportedAlgorithm},
+		{name: "critical", token: segment(t, map[string]any{"alg": "HS256", "crit": []string{"exp"}}) + ".e30.sig", err: ErrMalformedToken},
+		{name: "unknown kid", token: sign(t, "HS256", "other", testSecret, nil), err: ErrKeyNotFound},
+		{name: "key algorithm", token: sign(t, "PS256", "rsa", testRSAKey, nil), err: ErrKeyNotFound},
+		{name: "algorithm confusion", token: sign(t, "HS256", "rsa", publicDER, nil), err: ErrKeyNotFound},
+		{name: "wrong key", token: sign(t, "RS256", "rsa", otherKey, nil), err: ErrInvalidSignature},
+		{name: "wrong secret", token: sign(t, "HS256", "hmac", []byte("another secret"), nil), err: ErrInvalidSignature},
+	} {
+		status, _, challenge := request(t, app, tc.token)
+		require.Equal(t, fiber.StatusUnauthorized, status, tc.name)
+		require.ErrorIs(t, gotErr, tc.err, tc.name)
+		require.Equal(t, `Bearer realm="Restricted", error="invalid_token", error_description="`+tc.err.Error()+`"`, challenge, tc.name)
+	}
+
+	status, body, _ := request(t, app, valid)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice", body)
+}
+
+func Test_JWT_Claims(t *testing.T) {
+	t.Parallel()
+
+	var gotErr error
+	app := newTestApp(Config{
+		KeySet:    NewKeySet(Key{Key: testSecret}),
+		Issuer:    "https://issuer.example.com",
+		Audience:  []string{"api", "admin"},
+		ClockSkew: time.Minute,
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			gotErr = err
+			return c.SendStatus(fiber.StatusUnauthorized)
+		},
+	})
+	token := func(claims map[string]any) string {
+		base := map[string]any{"iss": "https://issuer.example.com", "aud": "api", "sub": "alice"}
+		for k, v := range claims {
+			if v == nil {
+				delete(base, k)
+			} else {
+				base[k] = v
+			}
+		}
+		return sign(t, "HS256", "", testSecret, base)
+	}
+	now := time.Now()
+
+	for _, tc := range []struct {
+		err    error
+		claims map[string]any
+		name   string
+	}{
+		{name: "valid"},
+		{name: "audience list", claims: map[string]any{"aud": []string{"web", "admin"}}},
+		{name: "expiring", claims: map[string]any{"exp": now.Add(time.Minute).Unix()}},
+		{name: "expired within skew", claims: map[string]any{"exp": now.Add(-30 * time.Second).Unix()}},
+		{name: "expired", claims: map[string]any{"exp": now.Add(-2 * time.Minute).Unix()}, err: ErrTokenExpired},
+		{name: "not before within skew", claims: map[string]any{"nbf": now.Add(30 * time.Second).Unix()}},
+		{name: "not valid yet", claims: map[string]any{"nbf": now.Add(2 * time.Minute).Unix()}, err: ErrTokenNotValidYet},
+		{name: "issuer", claims: map[string]any{"iss": "https://other.example.com"}, err: ErrInvalidIssuer},
+		{name: "no issuer", claims: map[string]any{"iss": nil}, err: ErrInvalidIssuer},
+		{name: "audience", claims: map[string]any{"aud": []string{"web"}}, err: ErrInvalidAudience},
+		{name: "no audience", claims: map[string]any{"aud": nil}, err: ErrInvalidAudience},
+		{name: "invalid exp", claims: map[string]any{"exp": "tomorrow"}, err: ErrMalformedToken},
+	} {
+		gotErr = nil
+		status, _, _ := request(t, app, token(tc.claims))
+		if tc.err == nil {
+			require.Equal(t, fiber.StatusOK, status, tc.name)
+			require.NoError(t, gotErr, tc.name)
+		} else {
+			require.Equal(t, fiber.StatusUnauthorized, status, tc.name)
+			require.ErrorIs(t, gotErr, tc.err, tc.name)
+		}
+	}
+}
+
+func Test_JWT_ClaimsFromContext(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{KeySet: NewKeySet(Key{Key: testSecret})}))
+	app.Get("/", func(c fiber.Ctx) error {
+		claims := ClaimsFromContext(c)
+		var custom struct {
+			Name  string `json:"name"`
+			Admin bool   `json:"admin"`
+		}
+		require.NoError(t, claims.Decode(&custom))
+		require.Equal(t, "Alice", custom.Name)
+		require.True(t, custom.Admin)
+
+		require.Equal(t, "alice", claims.Subject)
+		require.Equal(t, "id-1", claims.ID)
+		require.Equal(t, []string{"api"}, claims.Audience)
+		require.Equal(t, time.Unix(1700000000, 500000000), claims.IssuedAt)
+		require.True(t, claims.ExpiresAt.After(time.Now()))
+		require.True(t, claims.NotBefore.IsZero())
+		require.True(t, claims.HasScope("read"))
+		require.False(t, claims.HasScope("write"))
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	token := sign(t, "HS256", "", testSecret, map[string]any{
+		"sub": "alice", "jti": "id-1", "aud": "api", "iat": 1700000000.5,
+		"exp": time.Now().Add(time.Hour).Unix(), "scope": "read profile",
+		"name": "Alice", "admin": true,
+	})
+	status, _, _ := request(t, app, token)
+	require.Equal(t, fiber.StatusNoContent, status)
+
+	require.Nil(t, ClaimsFromContext(&fasthttp.RequestCtx{}))
+	require.Empty(t, TokenFromContext(&fasthttp.RequestCtx{}))
+}
+
+func Test_JWT_Scope(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(Config{KeySet: NewKeySet(Key{Key: testSecret}), Scope: "read write"})
+
+	status, body, challenge := request(t, app, sign(t, "HS256", "", testSecret, map[string]any{"scope": "read"}))
+	require.Equal(t, fiber.StatusForbidden, status)
+	require.Equal(t, ErrInsufficientScope.Error(), body)
+	require.Equal(t, `Bearer realm="Restricted", error="insufficient_scope", scope="read write"`, challenge)
+
+	// The scopes may be a space-delimited scope claim or an scp list
+	status, _, _ = request(t, app, sign(t, "HS256", "", testSecret, map[string]any{"scope": "write read admin"}))
+	require.Equal(t, fiber.StatusOK, status)
+	status, _, _ = request(t, app, sign(t, "HS256", "", testSecret, map[string]any{"scp": []string{"read", "write"}}))
+	require.Equal(t, fiber.StatusOK, status)
+}
+
+func Test_JWT_Extractor(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		KeySet:    NewKeySet(Key{Key: testSecret}),
+		Extractor: extractors.FromCookie("token"),
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(ClaimsFromContext(c).Subject)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.AddCookie(&http.Cookie{Name: "token", Value: sign(t, "HS256", "", testSecret, map[string]any{"sub": "alice"})})
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Without an Authorization scheme, there is no challenge
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	require.Empty(t, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+}
+
+func Test_JWT_KeySetError(t *testing.T) {
+	t.Parallel()
+
+	errKeys := errors.New("key set unavailable")
+	var gotErr error
+	app := newTestApp(Config{
+		KeySet: keySetFunc(func(string) ([]Key, error) {
+			return nil, errKeys
+		}),
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			gotErr = err
+			return c.SendStatus(fiber.StatusUnauthorized)
+		},
+	})
+
+	status, _, challenge := request(t, app, sign(t, "HS256", "", testSecret, nil))
+	require.Equal(t, fiber.StatusUnauthorized, status)
+	require.ErrorIs(t, gotErr, errKeys)
+	// The error is not described to the client
+	require.Equal(t, `Bearer realm="Restricted"`, challenge)
+}
+
+func Test_JWT_Next(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		KeySet: NewKeySet(Key{Key: testSecret}),
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
+
+func Test_JWT_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	keys := NewKeySet(Key{Key: testSecret})
+	require.PanicsWithValue(t, "fiber: jwt middleware requires a key set", func() {
+		New()
+	})
+	require.PanicsWithValue(t, "fiber: jwt middleware requires a key set", func() {
+		New(Config{})
+	})
+	require.PanicsWithValue(t, "fiber: jwt unsupported algorithm none", func() {
+		New(Config{KeySet: keys, Algorithms: []string{"HS256", "none"}})
+	})
+	require.PanicsWithValue(t, "fiber: jwt scope contains invalid token", func() {
+		New(Config{KeySet: keys, Scope: `read "write"`})
+	})
+	require.PanicsWithValue(t, "fiber: jwt clock skew must not be negative", func() {
+		New(Config{KeySet: keys, ClockSkew: -time.Second})
+	})
+}
+
+func Test_Verifier(t *testing.T) {
+	t.Parallel()
+
+	v := NewVerifier(Config{
+		KeySet:   NewKeySet(Key{Key: testEd25519Key.Public()}),
+		Issuer:   "https://issuer.example.com/",
+		Audience: []string{"client-1"},
+		Scope:    "admin",
+	})
+	token := sign(t, "EdDSA", "", testEd25519Key, map[string]any{
+		"iss": "https://issuer.example.com/",
+		"aud": "client-1",
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	// The scope is not checked
+	claims, err := v.Verify(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, "alice", claims.Subject)
+
+	_, err = v.Verify(context.Background(), sign(t, "EdDSA", "", testEd25519Key, map[string]any{"iss": "https://issuer.example.com/", "aud": "client-2"}))
+	require.ErrorIs(t, err, ErrInvalidAudience)
+	_, err = v.Verify(context.Background(), token[:len(token)-4])
+	require.Error(t, err)
+
+	require.PanicsWithValue(t, "fiber: jwt middleware requires a key set", func() {
+		NewVerifier()
+	})
+}
+
+func Test_ParseUnverified(t *testing.T) {
+	t.Parallel()
+
+	// The claims are parsed, but neither the signature nor the expiration
+	// are checked
+	exp := time.Now().Add(-time.Hour).Unix()
+	token := sign(t, "HS256", "", []byte("unknown secret"), map[string]any{"sub": "alice", "exp": exp, "nonce": "n-1"})
+	claims, err := ParseUnverified(token)
+	require.NoError(t, err)
+	require.Equal(t, "alice", claims.Subject)
+	require.Equal(t, time.Unix(exp, 0), claims.ExpiresAt)
+	var custom struct {
+		Nonce string `json:"nonce"`
+	}
+	require.NoError(t, claims.Decode(&custom))
+	require.Equal(t, "n-1", custom.Nonce)
+
+	for _, token := range []string{"", "a.b", "a.b.c.d", "a.!.c", "a." + base64.RawURLEncoding.EncodeToString([]byte("[]")) + ".c"} {
+		_, err = ParseUnverified(token)
+		require.ErrorIs(t, err, ErrMalformedToken, token)
+	}
+}
+
+// keySetFunc adapts a function to a KeySet.
+type keySetFunc func(id string) ([]Key, error)
+
+func (f keySetFunc) Keys(_ context.Context, id string) ([]Key, error) {
+	return f(id)
+}
+
+func Benchmark_JWT(b *testing.B) {
+	app := fiber.New()
+	app.Use(New(Config{KeySet: NewKeySet(Key{Key: &testECKey.PublicKey})}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	token := sign(b, "ES256", "", testECKey, map[string]any{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+
+	h := app.Handler()
+
+	fctx := &fasthttp.RequestCtx{}
+	fctx.Request.Header.SetMethod(fiber.MethodGet)
+	fctx.Request.SetRequestURI("/")
+	fctx.Request.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+
+	for b.Loop() {
+		h(fctx)
+	}
+}
@@ -0,0 +1,373 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	fiberlog "github.com/gofiber/fiber/v3/log"
+)
+
+// ErrNoKeys is returned when a JWK Set holds no key that verifies signatures.
+var ErrNoKeys = errors.New("jwt: JWK Set has no signature verification key")
+
+// Key is a key verifying token signatures.
+type Key struct {
+	// Key is the key: a []byte secret for the HS algorithms, an
+	// *rsa.PublicKey for the RS and PS ones, an *ecdsa.PublicKey for the ES
+	// ones, or an ed25519.PublicKey for EdDSA.
+	Key any
+	// ID is the key ID, matched against the `kid` header of tokens. Tokens
+	// without a `kid` header are verified with every key.
+	ID string
+	// Algorithm restricts the key to an algorithm.
+	//
+	// Optional. Default: "" (every algorithm suiting the key)
+	Algorithm string
+}
+
+// allows reports whether the key verifies signatures of alg.
+func (k *Key) allows(alg string) bool {
+	if k.Algorithm != "" && k.Algorithm != alg {
+		return false
+	}
+	switch key := k.Key.(type) {
+	case []byte:
+		return strings.HasPrefix(alg, "HS") && len(key) > 0
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return curveAlgorithm(key.Curve) == alg
+	case ed25519.PublicKey:
+		return alg == "EdDSA" && len(key) == ed25519.PublicKeySize
+	default:
+		return false
+	}
+}
+
+// KeySet provides the keys verifying token signatures.
+type KeySet interface {
+	// Keys returns the keys with the given ID, or every key if id is empty.
+	Keys(ctx context.Context, id string) ([]Key, error)
+}
+
+// staticKeySet is a fixed set of keys.
+type staticKeySet []Key
+
+// NewKeySet returns a key set of fixed keys.
+func NewKeySet(keys ...Key) KeySet {
+	return staticKeySet(keys)
+}
+
+func (s staticKeySet) Keys(_ context.Context, id string) ([]Key, error) {
+	return matchKeys(s, id), nil
+}
+
+// NewFileKeySet returns a key set of the keys of a local JWK Set file.
+func NewFileKeySet(path string) (KeySet, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The path is set by the application
+	if err != nil {
+		return nil, fmt.Errorf("jwt: failed to read JWK Set: %w", err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return staticKeySet(keys), nil
+}
+
+// matchKeys returns the keys with the given ID, or every key if id is empty.
+func matchKeys(keys []Key, id string) []Key {
+	if id == "" {
+		return keys
+	}
+	var matched []Key
+	for _, key := range keys {
+		if key.ID == id {
+			matched = append(matched, key)
+		}
+	}
+	return matched
+}
+
+// jwk is the JSON form of a key of RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS returns the signature verification keys of a JWK Set (RFC 7517).
+// Keys of unsupported types or meant for encryption are skipped.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: invalid JWK Set: %w", err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for i := range set.Keys {
+		k := &set.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid JWK %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys = append(keys, Key{Key: key, ID: k.Kid, Algorithm: k.Alg})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return keys, nil
+}
+
+// key returns the key of a JWK, or nil if its type is not supported.
+func (k *jwk) key() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil //nolint:nilnil // Unsupported curves are skipped
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid EC coordinates")
+		}
+		point := make([]byte, 0, 1+2*size)
+		point = append(point, 4)
+		point = append(point, x...)
+		point = append(point, y...)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil //nolint:nilnil // Unsupported curves are skipped
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) == 0 {
+			return nil, errors.New("empty secret")
+		}
+		return secret, nil
+	default:
+		return nil, nil //nolint:nilnil // Unsupported key types are skipped
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// RemoteKeySetConfig defines the config of a key set fetched from a JWK Set
+// URL.
+type RemoteKeySetConfig struct {
+	// Storage caches the JWK Set, so that instances sharing it and restarted
+	// ones don't fetch it again.
+	//
+	// Optional. Default: nil (cached in memory only)
+	Storage fiber.Storage
+
+	// Client fetches the JWK Set.
+	//
+	// Optional. Default: client.New() with a 10 seconds timeout
+	Client *client.Client
+
+	// URL is the URL of the JWK Set, such as the `jwks_uri` of an OpenID
+	// provider.
+	//
+	// Required.
+	URL string
+
+	// TTL is how long the JWK Set is cached.
+	//
+	// Optional. Default: 1 hour
+	TTL time.Duration
+
+	// RefreshInterval is the least time between two fetches of the JWK Set
+	// for tokens signed with a key it does not hold, such as a new key after
+	// a rotation.
+	//
+	// Optional. Default: 1 minute
+	RefreshInterval time.Duration
+}
+
+// remoteKeySet is a key set fetched from a JWK Set URL.
+type remoteKeySet struct {
+	fetched time.Time
+	expires time.Time
+	cfg     RemoteKeySetConfig
+	keys    []Key
+	mu      sync.Mutex
+}
+
+// NewRemoteKeySet returns a key set fetched from a JWK Set URL, and cached
+// in memory and in the optional storage.
+func NewRemoteKeySet(config RemoteKeySetConfig) KeySet {
+	if config.URL == "" {
+		panic("fiber: jwt remote key set requires a URL")
+	}
+	if config.Client == nil {
+		config.Client = client.New().SetTimeout(10 * time.Second)
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Hour
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Minute
+	}
+	return &remoteKeySet{cfg: config}
+}
+
+func (r *remoteKeySet) Keys(ctx context.Context, id string) ([]Key, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.After(r.expires) {
+		if err := r.load(ctx, now); err != nil {
+			if r.keys == nil {
+				return nil, err
+			}
+			// Keep verifying with the keys of the stale set
+			fiberlog.Warnf("jwt: failed to refresh JWK Set: %v", err)
+		}
+	}
+
+	keys := matchKeys(r.keys, id)
+	if len(keys) == 0 && id != "" && now.Sub(r.fetched) >= r.cfg.RefreshInterval {
+		// The key may have been added since the set was fetched
+		if err := r.fetch(ctx, now); err != nil {
+			return nil, err
+		}
+		keys = matchKeys(r.keys, id)
+	}
+	return keys, nil
+}
+
+// load loads the JWK Set from the storage, or fetches it.
+func (r *remoteKeySet) load(ctx context.Context, now time.Time) error {
+	if r.cfg.Storage != nil {
+		raw, err := r.cfg.Storage.GetWithContext(ctx, r.storageKey())
+		if err != nil {
+			return fmt.Errorf("jwt: failed to get cached JWK Set: %w", err)
+		}
+		// The set is stored after the time it was fetched at
+		if len(raw) > 8 {
+			fetched := time.Unix(0, int64(binary.BigEndian.Uint64(raw))) //nolint:gosec // Stored by fetch
+			if keys, err := ParseJWKS(raw[8:]); err == nil && now.Before(fetched.Add(r.cfg.TTL)) {
+				r.keys = keys
+				r.fetched = fetched
+				r.expires = fetched.Add(r.cfg.TTL)
+				return nil
+			}
+		}
+	}
+	return r.fetch(ctx, now)
+}
+
+// fetch fetches the JWK Set, and stores it.
+func (r *remoteKeySet) fetch(ctx context.Context, now time.Time) error {
+	r.fetched = now
+
+	resp, err := r.cfg.Client.Get(r.cfg.URL, client.Config{Ctx: ctx})
+	if err != nil {
+		return fmt.Errorf("jwt: failed to fetch JWK Set: %w", err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != fiber.StatusOK {
+		return fmt.Errorf("jwt: failed to fetch JWK Set: status %d", resp.StatusCode())
+	}
+	body := resp.Body()
+	keys, err := ParseJWKS(body)
+	if err != nil {
+		return err
+	}
+	r.keys = keys
+	r.expires = now.Add(r.cfg.TTL)
+
+	if r.cfg.Storage != nil {
+		raw := make([]byte, 8, 8+len(body))
+		binary.BigEndian.PutUint64(raw, uint64(now.UnixNano())) //nolint:gosec // Times are after 1970
+		raw = append(raw, body...)
+		if err := r.cfg.Storage.SetWithContext(ctx, r.storageKey(), raw, r.cfg.TTL); err != nil {
+			fiberlog.Warnf("jwt: failed to cache JWK Set: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *remoteKeySet) storageKey() string {
+	return "jwt_jwks:" + r.cfg.URL
+}
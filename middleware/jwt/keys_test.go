@@ -0,0 +1,273 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+// toJWK returns the JWK of a test key.
+func toJWK(t *testing.T, kid string, key any) map[string]string {
+	t.Helper()
+
+	enc := base64.RawURLEncoding.EncodeToString
+	switch key := key.(type) {
+	case []byte:
+		return map[string]string{"kty": "oct", "kid": kid, "k": enc(key)}
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": kid, "n": enc(key.N.Bytes()), "e": enc(big.NewInt(int64(key.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		point, err := key.Bytes()
+		require.NoError(t, err)
+		size := (len(point) - 1) / 2
+		crv := map[string]string{"ES256": "P-256", "ES384": "P-384", "ES512": "P-521"}[curveAlgorithm(key.Curve)]
+		return map[string]string{"kty": "EC", "kid": kid, "crv": crv, "x": enc(point[1 : 1+size]), "y": enc(point[1+size:])}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": enc(key)}
+	default:
+		t.Fatalf("unsupported key %T", key)
+		return nil
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func Test_ParseJWKS(t *testing.T) {
+	t.Parallel()
+
+	encryption := toJWK(t, "enc", &testRSAKey.PublicKey)
+	encryption["use"] = "enc"
+	restricted := toJWK(t, "rs", &testRSAKey.PublicKey)
+	restricted["alg"] = "RS256"
+	restricted["use"] = "sig"
+
+	keys, err := ParseJWKS(jwks(t,
+		toJWK(t, "hmac", testSecret),
+		restricted,
+		toJWK(t, "ec", &testECKey.PublicKey),
+		toJWK(t, "ed", testEd25519Key.Public()),
+		encryption,
+		map[string]string{"kty": "OKP", "kid": "x25519", "crv": "X25519", "x": "AA"},
+		map[string]string{"kty": "EC", "kid": "k256", "crv": "secp256k1", "x": "AA", "y": "AA"},
+		map[string]string{"kty": "unknown", "kid": "unknown"},
+	))
+	require.NoError(t, err)
+	require.Equal(t, []Key{
+		{Key: testSecret, ID: "hmac"},
+		{Key: &testRSAKey.PublicKey, ID: "rs", Algorithm: "RS256"},
+		{Key: &testECKey.PublicKey, ID: "ec"},
+		{Key: testEd25519Key.Public(), ID: "ed"},
+	}, keys)
+
+	// The parsed keys verify tokens
+	app := newTestApp(Config{KeySet: NewKeySet(keys...)})
+	for alg, key := range map[string]any{"HS256": testSecret, "RS256": testRSAKey, "ES256": testECKey, "EdDSA": testEd25519Key} {
+		status, _, _ := request(t, app, sign(t, alg, "", key, map[string]any{"sub": "alice"}))
+		require.Equal(t, fiber.StatusOK, status, alg)
+	}
+	status, _, _ := request(t, app, sign(t, "PS256", "rs", testRSAKey, nil))
+	require.Equal(t, fiber.StatusUnauthorized, status)
+
+	_, err = ParseJWKS([]byte(`{"keys":[]}`))
+	require.ErrorIs(t, err, ErrNoKeys)
+	_, err = ParseJWKS(jwks(t, encryption))
+	require.ErrorIs(t, err, ErrNoKeys)
+
+	for _, invalid := range []string{
+		`not json`,
+		`{"keys":[{"kty":"RSA","n":"!","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"RSA","n":"AQAB","e":""}]}`,
+		`{"keys":[{"kty":"RSA","n":"AQAB","e":"AQ"}]}`,
+		`{"keys":[{"kty":"EC","crv":"P-256","x":"AQAB","y":"AQAB"}]}`,
+		`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AQAB"}]}`,
+		`{"keys":[{"kty":"oct","k":""}]}`,
+	} {
+		_, err = ParseJWKS([]byte(invalid))
+		require.Error(t, err, invalid)
+		require.NotErrorIs(t, err, ErrNoKeys, invalid)
+	}
+
+	// Points off the curve are rejected
+	ec := toJWK(t, "ec", &testECKey.PublicKey)
+	ec["y"] = ec["x"]
+	_, err = ParseJWKS(jwks(t, ec))
+	require.Error(t, err)
+}
+
+func Test_NewFileKeySet(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks(t, toJWK(t, "ed", testEd25519Key.Public())), 0o600))
+
+	set, err := NewFileKeySet(path)
+	require.NoError(t, err)
+	keys, err := set.Keys(context.Background(), "ed")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	keys, err = set.Keys(context.Background(), "other")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	_, err = NewFileKeySet(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// jwksServer serves a JWK Set, and counts the requests for it.
+type jwksServer struct {
+	ln       *fasthttputil.InmemoryListener
+	body     atomic.Pointer[[]byte]
+	requests atomic.Int32
+	failing  atomic.Bool
+}
+
+func startJWKSServer(t *testing.T, body []byte) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{ln: fasthttputil.NewInmemoryListener()}
+	s.body.Store(&body)
+
+	app := fiber.New()
+	app.Get("/jwks.json", func(c fiber.Ctx) error {
+		s.requests.Add(1)
+		if s.failing.Load() {
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		return c.Send(*s.body.Load())
+	})
+	go func() {
+		_ = app.Listener(s.ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped in cleanup
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+	return s
+}
+
+func (s *jwksServer) client() *client.Client {
+	cc := client.New()
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return s.ln.Dial()
+	})
+	return cc
+}
+
+func Test_RemoteKeySet(t *testing.T) {
+	t.Parallel()
+
+	server := startJWKSServer(t, jwks(t, toJWK(t, "k1", &testECKey.PublicKey)))
+	storage := memory.New()
+	set := NewRemoteKeySet(RemoteKeySetConfig{
+		URL:     "http://example.com/jwks.json",
+		Client:  server.client(),
+		Storage: storage,
+	})
+	app := newTestApp(Config{KeySet: set})
+
+	status, _, _ := request(t, app, sign(t, "ES256", "k1", testECKey, nil))
+	require.Equal(t, fiber.StatusOK, status)
+	status, _, _ = request(t, app, sign(t, "ES256", "", testECKey, nil))
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, int32(1), server.requests.Load())
+
+	// Another instance loads the set from the storage
+	other := NewRemoteKeySet(RemoteKeySetConfig{
+		URL:     "http://example.com/jwks.json",
+		Client:  server.client(),
+		Storage: storage,
+	})
+	keys, err := other.Keys(context.Background(), "k1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, int32(1), server.requests.Load())
+
+	// Unknown keys are looked up at most once per refresh interval
+	status, _, _ = request(t, app, sign(t, "ES256", "k2", testECKey, nil))
+	require.Equal(t, fiber.StatusUnauthorized, status)
+	require.Equal(t, int32(1), server.requests.Load())
+}
+
+func Test_RemoteKeySet_Rotation(t *testing.T) {
+	t.Parallel()
+
+	server := startJWKSServer(t, jwks(t, toJWK(t, "k1", &testECKey.PublicKey)))
+	set := NewRemoteKeySet(RemoteKeySetConfig{
+		URL:             "http://example.com/jwks.json",
+		Client:          server.client(),
+		RefreshInterval: time.Nanosecond,
+	})
+	app := newTestApp(Config{KeySet: set})
+
+	status, _, _ := request(t, app, sign(t, "ES256", "k1", testECKey, nil))
+	require.Equal(t, fiber.StatusOK, status)
+
+	// A token signed with a new key fetches the set again
+	body := jwks(t, toJWK(t, "k1", &testECKey.PublicKey), toJWK(t, "k2", testEd25519Key.Public()))
+	server.body.Store(&body)
+	status, _, _ = request(t, app, sign(t, "EdDSA", "k2", testEd25519Key, nil))
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, int32(2), server.requests.Load())
+}
+
+func Test_RemoteKeySet_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := startJWKSServer(t, jwks(t, toJWK(t, "k1", &testECKey.PublicKey)))
+	server.failing.Store(true)
+	set := NewRemoteKeySet(RemoteKeySetConfig{
+		URL:    "http://example.com/jwks.json",
+		Client: server.client(),
+		TTL:    time.Millisecond,
+	})
+
+	_, err := set.Keys(context.Background(), "k1")
+	require.ErrorContains(t, err, "status 503")
+
+	server.failing.Store(false)
+	keys, err := set.Keys(context.Background(), "k1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	// The stale set is kept while it cannot be refreshed
+	server.failing.Store(true)
+	time.Sleep(5 * time.Millisecond)
+	keys, err = set.Keys(context.Background(), "k1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, int32(3), server.requests.Load())
+
+	// An invalid set is not cached
+	body := []byte(`{"keys":[]}`)
+	server.body.Store(&body)
+	server.failing.Store(false)
+	set = NewRemoteKeySet(RemoteKeySetConfig{URL: "http://example.com/jwks.json", Client: server.client()})
+	_, err = set.Keys(context.Background(), "")
+	require.ErrorIs(t, err, ErrNoKeys)
+
+	require.PanicsWithValue(t, "fiber: jwt remote key set requires a URL", func() {
+		NewRemoteKeySet(RemoteKeySetConfig{})
+	})
+}
@@ -0,0 +1,340 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // Registers SHA-256 for the RS256, PS256 and ES256 algorithms
+	_ "crypto/sha512" // Registers SHA-384 and SHA-512 for the other algorithms
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Errors returned when a token is missing or invalid. They are passed to the
+// ErrorHandler.
+var (
+	ErrMissingToken         = errors.New("missing JWT")
+	ErrMalformedToken       = errors.New("malformed JWT")
+	ErrUnsupportedAlgorithm = errors.New("unsupported JWT signing algorithm")
+	ErrKeyNotFound          = errors.New("no key to verify the JWT")
+	ErrInvalidSignature     = errors.New("invalid JWT signature")
+	ErrTokenExpired         = errors.New("JWT has expired")
+	ErrTokenNotValidYet     = errors.New("JWT is not valid yet")
+	ErrInvalidIssuer        = errors.New("invalid JWT issuer")
+	ErrInvalidAudience      = errors.New("invalid JWT audience")
+	ErrInsufficientScope    = errors.New("insufficient JWT scope")
+)
+
+// tokenErrors are the errors describing an invalid token, which are safe to
+// send to the client.
+var tokenErrors = []error{
+	ErrMalformedToken, ErrUnsupportedAlgorithm, ErrKeyNotFound, ErrInvalidSignature,
+	ErrTokenExpired, ErrTokenNotValidYet, ErrInvalidIssuer, ErrInvalidAudience,
+}
+
+// supportedAlgorithms lists the JWS algorithms of RFC 7518 and RFC 8037
+// that verify tokens.
+var supportedAlgorithms = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Algorithm string   `json:"alg"`
+	KeyID     string   `json:"kid"`
+	Critical  []string `json:"crit"`
+}
+
+// Claims holds the claims of a verified token.
+type Claims struct {
+	// ExpiresAt is the `exp` claim, or the zero time if it is absent.
+	ExpiresAt time.Time
+	// NotBefore is the `nbf` claim, or the zero time if it is absent.
+	NotBefore time.Time
+	// IssuedAt is the `iat` claim, or the zero time if it is absent.
+	IssuedAt time.Time
+	// Issuer is the `iss` claim.
+	Issuer string
+	// Subject is the `sub` claim.
+	Subject string
+	// ID is the `jti` claim.
+	ID string
+	// Audience is the `aud` claim.
+	Audience []string
+	// Scopes are the scopes of the space-delimited `scope` claim, or of the
+	// `scp` claim.
+	Scopes []string
+	// raw is the JSON payload of the token.
+	raw []byte
+}
+
+// Decode unmarshals the JSON payload of the token into v, for claims
+// specific to an application.
+func (c *Claims) Decode(v any) error {
+	return json.Unmarshal(c.raw, v)
+}
+
+// HasScope reports whether the token has the given scope.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// registeredClaims is the JSON form of the claims the middleware reads.
+type registeredClaims struct {
+	ExpiresAt *numericDate `json:"exp"`
+	NotBefore *numericDate `json:"nbf"`
+	IssuedAt  *numericDate `json:"iat"`
+	Issuer    string       `json:"iss"`
+	Subject   string       `json:"sub"`
+	ID        string       `json:"jti"`
+	Scope     string       `json:"scope"`
+	Audience  stringList   `json:"aud"`
+	Scp       stringList   `json:"scp"`
+}
+
+// numericDate is a JSON number of seconds since the epoch.
+type numericDate float64
+
+func (d *numericDate) time() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(float64(*d))
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// stringList is a JSON string or array of strings.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte{'"'}) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*l = stringList{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// Verifier verifies tokens against a config outside of the middleware, such
+// as the ID tokens of an OpenID Connect login. The Scope of the config is not
+// checked.
+type Verifier struct {
+	cfg *Config
+	now func() time.Time
+}
+
+// NewVerifier creates a new Verifier. Like New, it panics if the config has
+// no KeySet.
+func NewVerifier(config ...Config) *Verifier {
+	// Set default config
+	cfg := configDefault(config...)
+
+	return &Verifier{cfg: &cfg, now: time.Now}
+}
+
+// Verify returns the claims of a token, or an error if it is not valid.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	headerPart, rest, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrMalformedToken
+	}
+	payloadPart, signaturePart, ok := strings.Cut(rest, ".")
+	if !ok || strings.Contains(signaturePart, ".") {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	if err := decodeSegment(headerPart, &h); err != nil {
+		return nil, err
+	}
+	// No extension of the header is understood
+	if h.Critical != nil {
+		return nil, ErrMalformedToken
+	}
+	if !slices.Contains(v.cfg.Algorithms, h.Algorithm) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(signaturePart)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	keys, err := v.cfg.KeySet.Keys(ctx, h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signingInput := token[:len(headerPart)+1+len(payloadPart)]
+	found := false
+	for _, key := range keys {
+		if !key.allows(h.Algorithm) {
+			continue
+		}
+		found = true
+		if verifySignature(h.Algorithm, key.Key, []byte(signingInput), signature) {
+			return v.validate(payloadPart)
+		}
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	return nil, ErrInvalidSignature
+}
+
+// validate returns the claims of a payload, or an error if they are not
+// valid.
+func (v *Verifier) validate(payloadPart string) (*Claims, error) {
+	claims, err := parseClaims(payloadPart)
+	if err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	if !claims.ExpiresAt.IsZero() && !now.Before(claims.ExpiresAt.Add(v.cfg.ClockSkew)) {
+		return nil, ErrTokenExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.cfg.ClockSkew).Before(claims.NotBefore) {
+		return nil, ErrTokenNotValidYet
+	}
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return nil, ErrInvalidIssuer
+	}
+	if len(v.cfg.Audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.cfg.Audience, aud)
+	}) {
+		return nil, ErrInvalidAudience
+	}
+	return claims, nil
+}
+
+// ParseUnverified returns the claims of a token without verifying its
+// signature or validating its claims. It is only meant for tokens verified
+// before, such as a token kept in a session after it was verified.
+func ParseUnverified(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+	return parseClaims(parts[1])
+}
+
+// parseClaims returns the claims of a payload.
+func parseClaims(payloadPart string) (*Claims, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var rc registeredClaims
+	if err := json.Unmarshal(raw, &rc); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	claims := &Claims{
+		ExpiresAt: rc.ExpiresAt.time(),
+		NotBefore: rc.NotBefore.time(),
+		IssuedAt:  rc.IssuedAt.time(),
+		Issuer:    rc.Issuer,
+		Subject:   rc.Subject,
+		ID:        rc.ID,
+		Audience:  rc.Audience,
+		Scopes:    rc.Scp,
+		raw:       raw,
+	}
+	if rc.Scope != "" {
+		claims.Scopes = strings.Fields(rc.Scope)
+	}
+	return claims, nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token into v.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+// algorithmHash returns the hash of an algorithm, from its size suffix.
+func algorithmHash(alg string) crypto.Hash {
+	switch alg[len(alg)-3:] {
+	case "256":
+		return crypto.SHA256
+	case "384":
+		return crypto.SHA384
+	default:
+		return crypto.SHA512
+	}
+}
+
+// verifySignature reports whether signature is a valid signature of input
+// with key for alg. The key is known to suit alg.
+func verifySignature(alg string, key any, input, signature []byte) bool {
+	if alg == "EdDSA" {
+		return ed25519.Verify(key.(ed25519.PublicKey), input, signature) //nolint:forcetypeassert,errcheck // Checked by Key.allows
+	}
+
+	hash := algorithmHash(alg)
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "HS":
+		mac := hmac.New(hash.New, key.([]byte)) //nolint:forcetypeassert,errcheck // Checked by Key.allows
+		mac.Write(input)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case "RS":
+		return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), hash, digest, signature) == nil //nolint:forcetypeassert,errcheck // Checked by Key.allows
+	case "PS":
+		return rsa.VerifyPSS(key.(*rsa.PublicKey), hash, digest, signature, &rsa.PSSOptions{ //nolint:forcetypeassert,errcheck // Checked by Key.allows
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		}) == nil
+	default:
+		pub := key.(*ecdsa.PublicKey) //nolint:forcetypeassert,errcheck // Checked by Key.allows
+		// The signature is the concatenation of R and S, each as long as
+		// the curve order
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(pub, digest, r, s)
+	}
+}
+
+// curveAlgorithm returns the ES algorithm of an elliptic curve.
+func curveAlgorithm(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return "ES256"
+	case elliptic.P384():
+		return "ES384"
+	case elliptic.P521():
+		return "ES512"
+	default:
+		return ""
+	}
+}
@@ -3,16 +3,17 @@ package keyauth
 import (
 	"fmt"
 	"net/url"
-	"strings"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/gofiber/fiber/v3/internal/bearer"
 )
 
+// RFC 6750 error codes for the Error field.
 const (
-	ErrorInvalidRequest    = "invalid_request"
-	ErrorInvalidToken      = "invalid_token"
-	ErrorInsufficientScope = "insufficient_scope"
+	ErrorInvalidRequest    = bearer.ErrorInvalidRequest
+	ErrorInvalidToken      = bearer.ErrorInvalidToken
+	ErrorInsufficientScope = bearer.ErrorInsufficientScope
 )
 
 // Config defines the config for middleware.
@@ -151,10 +152,8 @@ func configDefault(config ...Config) Config {
 		if cfg.Scope == "" {
 			panic("fiber: keyauth insufficient_scope requires scope")
 		}
-		for scope := range strings.SplitSeq(cfg.Scope, " ") {
-			if scope == "" || !isScopeToken(scope) {
-				panic("fiber: keyauth scope contains invalid token")
-			}
+		if !bearer.ValidScope(cfg.Scope) {
+			panic("fiber: keyauth scope contains invalid token")
 		}
 	} else if cfg.Scope != "" {
 		panic("fiber: keyauth scope requires insufficient_scope error")
@@ -162,13 +161,3 @@ func configDefault(config ...Config) Config {
 
 	return cfg
 }
-
-func isScopeToken(s string) bool {
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c < 0x21 || c > 0x7e || c == '"' || c == '\\' {
-			return false
-		}
-	}
-	return s != ""
-}
@@ -2,15 +2,14 @@ package keyauth
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"sync"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/gofiber/fiber/v3/internal/bearer"
 	"github.com/gofiber/fiber/v3/internal/redact"
 	"github.com/gofiber/fiber/v3/middleware/logger"
-	"github.com/gofiber/utils/v2"
 )
 
 // The contextKey type is unexported to prevent collisions with context keys defined in
@@ -43,23 +42,12 @@ func New(config ...Config) fiber.Handler {
 	if len(authSchemes) > 0 {
 		challenges := make([]string, 0, len(authSchemes))
 		for _, scheme := range authSchemes {
-			var b strings.Builder
-			fmt.Fprintf(&b, "%s realm=%q", scheme, cfg.Realm)
-			if utils.EqualFold(scheme, "Bearer") {
-				if cfg.Error != "" {
-					fmt.Fprintf(&b, ", error=%q", cfg.Error)
-					if cfg.ErrorDescription != "" {
-						fmt.Fprintf(&b, ", error_description=%q", cfg.ErrorDescription)
-					}
-					if cfg.ErrorURI != "" {
-						fmt.Fprintf(&b, ", error_uri=%q", cfg.ErrorURI)
-					}
-					if cfg.Error == ErrorInsufficientScope {
-						fmt.Fprintf(&b, ", scope=%q", cfg.Scope)
-					}
-				}
-			}
-			challenges = append(challenges, b.String())
+			challenges = append(challenges, bearer.Challenge(scheme, cfg.Realm, bearer.Params{
+				Error:            cfg.Error,
+				ErrorDescription: cfg.ErrorDescription,
+				ErrorURI:         cfg.ErrorURI,
+				Scope:            cfg.Scope,
+			}))
 		}
 		challengeValue = strings.Join(challenges, ", ")
 	}
@@ -1,6 +1,8 @@
 package limiter
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -34,7 +36,8 @@ type Config struct {
 
 	// A function to dynamically calculate the expiration time for rate limiter entries.
 	// Window accounting is whole-second, so a positive value below one second is floored to
-	// one second. A zero or negative value falls back to the default expiration.
+	// one second; TokenBucket and GCRA use it as is. A zero or negative value falls back to
+	// the default expiration.
 	//
 	// Default: A function that returns the static `Expiration` value from the config.
 	ExpirationFunc func(c fiber.Ctx) time.Duration
@@ -52,6 +55,15 @@ type Config struct {
 	// }
 	KeyGenerator func(fiber.Ctx) string
 
+	// Tiers are limits applied together to every key, such as 10 requests per
+	// second and 1000 per hour. A request is counted against every tier or,
+	// when one of them is exhausted, none; the state of all tiers of a key is
+	// kept in a single storage entry. Max, MaxFunc, Expiration and
+	// ExpirationFunc are ignored when it is set.
+	//
+	// Optional. Default: nil
+	Tiers []Tier
+
 	// LimitReached is called when a request hits the limit
 	//
 	// Default: func(c fiber.Ctx) error {
@@ -59,7 +71,8 @@ type Config struct {
 	// }
 	LimitReached fiber.Handler
 
-	// Max number of recent connections during `Expiration` seconds before sending a 429 response
+	// Max number of recent connections during `Expiration` seconds before sending a 429 response.
+	// TokenBucket and GCRA allow Max requests per Expiration on average.
 	//
 	// Default: 5
 	Max int
@@ -79,7 +92,8 @@ type Config struct {
 	// Default: false
 	SkipSuccessfulRequests bool
 
-	// When set to true, the middleware will not include the rate limit headers (X-RateLimit-* and Retry-After) in the response.
+	// When set to true, the middleware will not include the rate limit headers (X-RateLimit-*, RateLimit,
+	// RateLimit-Policy and Retry-After) in the response.
 	//
 	// Default: false
 	DisableHeaders bool
@@ -90,6 +104,31 @@ type Config struct {
 	DisableValueRedaction bool
 }
 
+// Tier is one of several limits applied together to every key.
+type Tier struct {
+	// Name identifies the tier in the RateLimit and RateLimit-Policy headers
+	// and in the Quota reported by QuotaFromContext.
+	//
+	// Optional. Default: "tier-" followed by the tier's position, from 1
+	Name string
+
+	// Max number of requests per Expiration.
+	//
+	// Required.
+	Max int
+
+	// Expiration is the window the tier counts requests over.
+	//
+	// Required.
+	Expiration time.Duration
+
+	// Burst is the number of requests accepted at once by TokenBucket and
+	// GCRA. The window algorithms ignore it.
+	//
+	// Optional. Default: Max
+	Burst int
+}
+
 // ConfigDefault is the default config
 var ConfigDefault = Config{
 	Max:        defaultLimiterMax,
@@ -151,6 +190,21 @@ func configDefault(config ...Config) Config {
 			return cfg.Expiration
 		}
 	}
+	if len(cfg.Tiers) > 0 {
+		// Copy the tiers so names can be filled in without changing the
+		// caller's slice.
+		tiers := make([]Tier, len(cfg.Tiers))
+		for i, tier := range cfg.Tiers {
+			if tier.Max <= 0 || tier.Expiration <= 0 {
+				panic(fmt.Sprintf("limiter: tier %d must have a positive Max and Expiration", i+1))
+			}
+			if tier.Name == "" {
+				tier.Name = "tier-" + strconv.Itoa(i+1)
+			}
+			tiers[i] = tier
+		}
+		cfg.Tiers = tiers
+	}
 	return cfg
 }
 
@@ -183,3 +237,22 @@ func (cfg *Config) currentSecond() uint64 {
 	}
 	return uint64(utils.Timestamp())
 }
+
+// period returns the expiration for c, at the sub-second precision TokenBucket
+// and GCRA account with.
+func (cfg *Config) period(c fiber.Ctx) time.Duration {
+	if d := cfg.ExpirationFunc(c); d > 0 {
+		return d
+	}
+	return ConfigDefault.Expiration
+}
+
+// now returns the current time for TokenBucket and GCRA, which account below
+// a second. Tests can inject a deterministic clock via the unexported clock
+// field.
+func (cfg *Config) now() time.Time {
+	if cfg.clock != nil {
+		return cfg.clock()
+	}
+	return time.Now()
+}
@@ -144,6 +144,7 @@ func Test_manager_get_RoundTripWithStorage(t *testing.T) {
 	it.currHits = 2
 	it.prevHits = 1
 	it.exp = 42
+	it.tiers = append(it.tiers, item{currHits: 4, exp: 43})
 	require.NoError(t, m.set(context.Background(), "k", it, time.Second))
 
 	got, err := m.get(context.Background(), "k")
@@ -151,6 +152,7 @@ func Test_manager_get_RoundTripWithStorage(t *testing.T) {
 	require.Equal(t, 2, got.currHits)
 	require.Equal(t, 1, got.prevHits)
 	require.Equal(t, uint64(42), got.exp)
+	require.Equal(t, []item{{currHits: 4, exp: 43}}, got.tiers)
 }
 
 func Test_manager_get_MemoryUnexpectedType(t *testing.T) {
@@ -169,7 +171,7 @@ func Test_manager_get_MemoryUnexpectedType(t *testing.T) {
 func Test_item_Decode_Truncated(t *testing.T) {
 	t.Parallel()
 
-	full, err := item{currHits: 3, prevHits: 5, exp: 99}.MarshalMsg(nil)
+	full, err := (&item{currHits: 3, prevHits: 5, exp: 99, tokens: 1.5, updated: 7, tat: 11, tiers: []item{{currHits: 1}}}).MarshalMsg(nil)
 	require.NoError(t, err)
 
 	for i := range len(full) {
@@ -211,14 +213,14 @@ func Test_item_Decode_UnknownField(t *testing.T) {
 func Test_item_EncodeMsg_WriterErrors(t *testing.T) {
 	t.Parallel()
 
-	full, err := item{currHits: 3, prevHits: 5, exp: 99}.MarshalMsg(nil)
+	full, err := (&item{currHits: 3, prevHits: 5, exp: 99, tokens: 1.5, updated: 7, tat: 11, tiers: []item{{currHits: 1}}}).MarshalMsg(nil)
 	require.NoError(t, err)
 
 	// A writer that accepts fewer than the full encoding's bytes must always
 	// surface an error (either mid-encode or on Flush), so assert per budget.
 	for budget := range len(full) {
 		w := msgp.NewWriterSize(&limiterErrWriter{n: budget}, 8)
-		encErr := item{currHits: 3, prevHits: 5, exp: 99}.EncodeMsg(w)
+		encErr := (&item{currHits: 3, prevHits: 5, exp: 99, tokens: 1.5, updated: 7, tat: 11, tiers: []item{{currHits: 1}}}).EncodeMsg(w)
 		if encErr == nil {
 			encErr = w.Flush()
 		}
@@ -2,9 +2,12 @@ package limiter
 
 import (
 	"errors"
+	"math"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/internal/nilerror"
+	"github.com/gofiber/utils/v2"
 )
 
 const (
@@ -12,8 +15,49 @@ const (
 	xRateLimitLimit     = "X-RateLimit-Limit"
 	xRateLimitRemaining = "X-RateLimit-Remaining"
 	xRateLimitReset     = "X-RateLimit-Reset"
+
+	// RateLimit headers, https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+	rateLimit       = "RateLimit"
+	rateLimitPolicy = "RateLimit-Policy"
+
+	// rateLimitPolicyName names the only policy the middleware applies.
+	rateLimitPolicyName = `"default"`
+)
+
+// The contextKey type is unexported to prevent collisions with context keys defined in
+// other packages.
+type contextKey int
+
+// The keys for the values in context
+const (
+	quotaKey contextKey = iota
 )
 
+// Quota describes the most restrictive limit applied to a request.
+type Quota struct {
+	// Name of the tier, or "default" for the limit set with Max.
+	Name string
+
+	// Limit is the number of requests allowed per Expiration.
+	Limit int
+
+	// Remaining is the number of requests the key can still make.
+	Remaining int
+
+	// Reset is the time until the quota is restored.
+	Reset time.Duration
+
+	// Expiration is the window of the limit.
+	Expiration time.Duration
+}
+
+// QuotaFromContext returns the quota the limiter applied to the request, both
+// in the LimitReached handler and in the handlers after the middleware.
+// It accepts fiber.CustomCtx, fiber.Ctx, *fasthttp.RequestCtx, and context.Context.
+func QuotaFromContext(ctx any) (Quota, bool) {
+	return fiber.ValueFromContext[Quota](ctx, quotaKey)
+}
+
 // Handler defines a rate-limiting strategy that can produce a middleware
 // handler using the provided configuration.
 type Handler interface {
@@ -44,3 +88,29 @@ func getEffectiveStatusCode(c fiber.Ctx, err error) int {
 	// Otherwise, use the response status code
 	return c.Response().StatusCode()
 }
+
+// setRateLimitHeaders sets the X-RateLimit-* headers and their IETF RateLimit
+// and RateLimit-Policy counterparts: limit requests are allowed per window
+// seconds, remaining are left and the quota is restored in reset seconds.
+func setRateLimitHeaders(c fiber.Ctx, limit, remaining int, reset, window uint64) {
+	c.Set(xRateLimitLimit, utils.FormatInt(int64(limit)))
+	c.Set(xRateLimitRemaining, utils.FormatInt(int64(remaining)))
+	c.Set(xRateLimitReset, utils.FormatUint(reset))
+
+	c.Set(rateLimitPolicy, rateLimitPolicyName+";q="+utils.FormatInt(int64(limit))+";w="+utils.FormatUint(window))
+	c.Set(rateLimit, rateLimitPolicyName+";r="+utils.FormatInt(int64(remaining))+";t="+utils.FormatUint(reset))
+}
+
+// ceilSeconds rounds d up to whole seconds, as the headers carry them.
+func ceilSeconds(d time.Duration) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return uint64(math.Ceil(d.Seconds()))
+}
+
+// storageTTL is how long to keep an entry that returns to its initial state
+// after d. Storage expirations have a resolution of one second.
+func storageTTL(d time.Duration) time.Duration {
+	return time.Duration(max(ceilSeconds(d), 1)) * time.Second //nolint:gosec // G115: bounded by d
+}
@@ -3,6 +3,7 @@ package limiter
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/utils/v2"
@@ -18,6 +19,10 @@ func (FixedWindow) New(cfg *Config) fiber.Handler {
 		cfg = &defaultCfg
 	}
 
+	if len(cfg.Tiers) > 0 {
+		return newTieredHandler(cfg, FixedWindow{}, 0)
+	}
+
 	// Limiter variables
 	mux := &sync.RWMutex{}
 
@@ -88,6 +93,15 @@ func (FixedWindow) New(cfg *Config) fiber.Handler {
 		// Unlock entry
 		mux.Unlock()
 
+		resetIn, _ := secondsToDuration(resetInSec)
+		fiber.StoreInContext(c, quotaKey, Quota{
+			Name:       defaultTierName,
+			Limit:      maxRequests,
+			Remaining:  max(remaining, 0),
+			Reset:      resetIn,
+			Expiration: expirationDuration,
+		})
+
 		// Check if hits exceed the max
 		if remaining < 0 {
 			// Return response with Retry-After header
@@ -140,11 +154,44 @@ func (FixedWindow) New(cfg *Config) fiber.Handler {
 
 		// We can continue, update RateLimit headers
 		if !cfg.DisableHeaders {
-			c.Set(xRateLimitLimit, utils.FormatInt(int64(maxRequests)))
-			c.Set(xRateLimitRemaining, utils.FormatInt(int64(remaining)))
-			c.Set(xRateLimitReset, utils.FormatUint(resetInSec))
+			setRateLimitHeaders(c, maxRequests, remaining, resetInSec, expiration)
 		}
 
 		return err
 	}
 }
+
+func (FixedWindow) conforms(e *item, t *Tier, now time.Time) (bool, time.Duration) {
+	ts := unixSecond(now)
+	// Start a new window if the entry is new or its window is over
+	if e.exp == 0 || ts >= e.exp {
+		e.currHits = 0
+		e.exp = ts + windowSeconds(t.Expiration)
+	}
+	if e.currHits < t.Max {
+		return true, 0
+	}
+	retry, _ := secondsToDuration(e.exp - ts)
+	return false, retry
+}
+
+func (FixedWindow) take(e *item, _ *Tier, _ time.Time) uint64 {
+	e.currHits++
+	return e.exp
+}
+
+func (FixedWindow) refund(e *item, _ *Tier, _ time.Time, windowExpiresAt uint64) {
+	// Only credit the hit back if it still belongs to the same window
+	if e.exp == windowExpiresAt && e.currHits > 0 {
+		e.currHits--
+	}
+}
+
+func (FixedWindow) quota(e *item, t *Tier, now time.Time) (remaining int, reset, keep time.Duration) {
+	ts := unixSecond(now)
+	if ts >= e.exp {
+		return t.Max, 0, 0
+	}
+	reset, _ = secondsToDuration(e.exp - ts)
+	return max(t.Max-e.currHits, 0), reset, reset
+}
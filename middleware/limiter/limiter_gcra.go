@@ -0,0 +1,78 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// GCRA implements the generic cell rate algorithm.
+//
+// Requests are spaced Expiration / Max apart on average. Every key keeps the
+// theoretical arrival time (TAT) of its next request, which moves one
+// interval forward with each accepted request; a request is rejected when
+// accepting it would push the TAT more than Burst intervals ahead of now. It
+// limits like a TokenBucket of the same Burst, with a single timestamp as its
+// state.
+type GCRA struct {
+	// Burst is the number of requests accepted at once from a key that has
+	// been idle.
+	//
+	// Optional. Default: Max
+	Burst int
+}
+
+// New creates a new GCRA middleware handler
+func (g GCRA) New(cfg *Config) fiber.Handler {
+	if cfg == nil {
+		defaultCfg := configDefault()
+		cfg = &defaultCfg
+	}
+
+	return newTieredHandler(cfg, g, max(g.Burst, 0))
+}
+
+// gcraRemaining returns how many requests would still be accepted right now.
+func gcraRemaining(tat, now, interval, tolerance int64) int {
+	return int(max(tolerance-max(tat-now, 0), 0) / interval)
+}
+
+// gcraParams returns the emission interval of t, the average spacing of its
+// requests, and the tolerance, how far ahead of now the TAT may run.
+func gcraParams(t *Tier) (interval, tolerance int64) {
+	interval = max(int64(t.Expiration)/int64(t.Max), 1)
+	return interval, interval * int64(tierBurst(t))
+}
+
+func (GCRA) conforms(e *item, t *Tier, now time.Time) (bool, time.Duration) {
+	interval, tolerance := gcraParams(t)
+	ts := now.UnixNano()
+	// The earliest time the request conforms
+	allowAt := max(e.tat, ts) + interval - tolerance
+	if ts >= allowAt {
+		return true, 0
+	}
+	return false, time.Duration(allowAt - ts)
+}
+
+func (GCRA) take(e *item, t *Tier, now time.Time) uint64 {
+	interval, _ := gcraParams(t)
+	e.tat = max(e.tat, now.UnixNano()) + interval
+	return 0
+}
+
+func (GCRA) refund(e *item, t *Tier, now time.Time, _ uint64) {
+	// Move the TAT back by the interval the request took; a TAT in the past
+	// is the same as one of now.
+	interval, _ := gcraParams(t)
+	e.tat = max(e.tat-interval, now.UnixNano())
+}
+
+func (GCRA) quota(e *item, t *Tier, now time.Time) (remaining int, reset, keep time.Duration) {
+	interval, tolerance := gcraParams(t)
+	ts := now.UnixNano()
+	reset = time.Duration(max(e.tat-ts, 0))
+	// The entry can go once the TAT has passed, as a missing entry stands
+	// for a TAT of now.
+	return gcraRemaining(e.tat, ts, interval, tolerance), reset, reset
+}
@@ -20,6 +20,10 @@ func (SlidingWindow) New(cfg *Config) fiber.Handler {
 		cfg = &defaultCfg
 	}
 
+	if len(cfg.Tiers) > 0 {
+		return newTieredHandler(cfg, SlidingWindow{}, 0)
+	}
+
 	// Limiter variables
 	mux := &sync.RWMutex{}
 
@@ -41,6 +45,7 @@ func (SlidingWindow) New(cfg *Config) fiber.Handler {
 
 		// Generate expiration from generator
 		expiration := windowSeconds(cfg.ExpirationFunc(c))
+		expirationDuration, _ := secondsToDuration(expiration)
 
 		// Get key from request
 		key := cfg.KeyGenerator(c)
@@ -96,6 +101,15 @@ func (SlidingWindow) New(cfg *Config) fiber.Handler {
 		// Unlock entry
 		mux.Unlock()
 
+		resetIn, _ := secondsToDuration(resetInSec)
+		fiber.StoreInContext(c, quotaKey, Quota{
+			Name:       defaultTierName,
+			Limit:      maxRequests,
+			Remaining:  max(remaining, 0),
+			Reset:      resetIn,
+			Expiration: expirationDuration,
+		})
+
 		// Check if hits exceed the allowed maximum for this request
 		if remaining < 0 {
 			// Return response with Retry-After header
@@ -155,9 +169,7 @@ func (SlidingWindow) New(cfg *Config) fiber.Handler {
 
 			// We can continue, update RateLimit headers
 			if !cfg.DisableHeaders {
-				c.Set(xRateLimitLimit, utils.FormatInt(int64(maxRequests)))
-				c.Set(xRateLimitRemaining, utils.FormatInt(int64(remaining)))
-				c.Set(xRateLimitReset, utils.FormatUint(resetInSec))
+				setRateLimitHeaders(c, maxRequests, remaining, resetInSec, expiration)
 			}
 		}
 
@@ -227,3 +239,43 @@ func secondsToDuration(seconds uint64) (time.Duration, bool) {
 
 	return time.Duration(seconds) * time.Second, true
 }
+
+func (SlidingWindow) conforms(e *item, t *Tier, now time.Time) (bool, time.Duration) {
+	expiration := windowSeconds(t.Expiration)
+	resetInSec := rotateWindow(e, unixSecond(now), expiration)
+	if slidingRate(e, resetInSec, expiration) < t.Max {
+		return true, 0
+	}
+	retry, _ := secondsToDuration(resetInSec)
+	return false, retry
+}
+
+func (SlidingWindow) take(e *item, _ *Tier, _ time.Time) uint64 {
+	e.currHits++
+	return e.exp
+}
+
+func (SlidingWindow) refund(e *item, t *Tier, now time.Time, windowExpiresAt uint64) {
+	ts := unixSecond(now)
+	expiration := windowSeconds(t.Expiration)
+	rotateWindow(e, ts, expiration)
+	if counter := bucketForOriginalHit(e, windowExpiresAt, ts, expiration); counter != nil && *counter > 0 {
+		*counter--
+	}
+}
+
+func (SlidingWindow) quota(e *item, t *Tier, now time.Time) (remaining int, reset, keep time.Duration) {
+	expiration := windowSeconds(t.Expiration)
+	resetInSec := rotateWindow(e, unixSecond(now), expiration)
+	reset, _ = secondsToDuration(resetInSec)
+	// Keep the entry until the end of the next window, see New
+	return max(t.Max-slidingRate(e, resetInSec, expiration), 0), reset, ttlDuration(resetInSec, expiration)
+}
+
+// slidingRate estimates the hits in the window ending now from the hits of the
+// current and previous windows.
+func slidingRate(e *item, resetInSec, expiration uint64) int {
+	// weight = time until current window reset / total window length
+	weight := float64(resetInSec) / float64(expiration)
+	return int(math.Ceil(float64(e.prevHits)*weight)) + e.currHits
+}
@@ -202,9 +202,9 @@ func (*failingLimiterStorage) Reset() error { return nil }
 
 func (*failingLimiterStorage) Close() error { return nil }
 
-type contextKey string
+type testContextKey string
 
-const markerKey contextKey = "marker"
+const markerKey testContextKey = "marker"
 
 func contextWithMarker(label string) context.Context {
 	return context.WithValue(context.Background(), markerKey, label)
@@ -1733,6 +1733,8 @@ func Test_Limiter_Headers(t *testing.T) {
 	if v := string(fctx.Response.Header.Peek("X-RateLimit-Reset")); (v != "1") && (v != "2") {
 		t.Error("The X-RateLimit-Reset header is not set correctly - value is out of bounds.")
 	}
+	require.Equal(t, `"default";q=50;w=2`, string(fctx.Response.Header.Peek("RateLimit-Policy")))
+	require.Regexp(t, `^"default";r=49;t=[12]$`, string(fctx.Response.Header.Peek("RateLimit")))
 }
 
 func Test_Limiter_Disable_Headers(t *testing.T) {
@@ -1774,6 +1776,241 @@ func Test_Limiter_Disable_Headers(t *testing.T) {
 	require.Empty(t, string(fctx2.Response.Header.Peek("X-RateLimit-Limit")))
 	require.Empty(t, string(fctx2.Response.Header.Peek("X-RateLimit-Remaining")))
 	require.Empty(t, string(fctx2.Response.Header.Peek("X-RateLimit-Reset")))
+	require.Empty(t, string(fctx2.Response.Header.Peek("RateLimit")))
+	require.Empty(t, string(fctx2.Response.Header.Peek("RateLimit-Policy")))
+}
+
+// limiterStep sends a request to app and checks its status code, along with
+// the remaining requests or, once limited, the Retry-After header.
+func limiterStep(t *testing.T, app *fiber.App, path string, status int, header string) {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, status, resp.StatusCode)
+	if status == fiber.StatusTooManyRequests {
+		require.Equal(t, header, resp.Header.Get(fiber.HeaderRetryAfter))
+		return
+	}
+	if header != "" {
+		require.Equal(t, header, resp.Header.Get("X-RateLimit-Remaining"))
+	}
+}
+
+func Test_Limiter_Burst_Algorithms(t *testing.T) {
+	t.Parallel()
+
+	algorithms := map[string]func(burst int) Handler{
+		"token bucket": func(burst int) Handler { return TokenBucket{Burst: burst} },
+		"gcra":         func(burst int) Handler { return GCRA{Burst: burst} },
+	}
+
+	for name, algorithm := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, storage := range []fiber.Storage{nil, memory.New()} {
+				clock := newTestClock(time.Now())
+				app := fiber.New()
+				app.Use(New(Config{
+					Max:               2,
+					Expiration:        2 * time.Second,
+					Storage:           storage,
+					LimiterMiddleware: algorithm(4),
+					clock:             clock.Now,
+				}))
+				app.Get("/", func(c fiber.Ctx) error {
+					return c.SendStatus(fiber.StatusOK)
+				})
+
+				// An idle client can spend the whole burst at once
+				limiterStep(t, app, "/", fiber.StatusOK, "3")
+				limiterStep(t, app, "/", fiber.StatusOK, "2")
+				limiterStep(t, app, "/", fiber.StatusOK, "1")
+				limiterStep(t, app, "/", fiber.StatusOK, "0")
+				limiterStep(t, app, "/", fiber.StatusTooManyRequests, "1")
+
+				// Then it is held to Max per Expiration: one request per second
+				clock.Add(500 * time.Millisecond)
+				limiterStep(t, app, "/", fiber.StatusTooManyRequests, "1")
+				clock.Add(500 * time.Millisecond)
+				limiterStep(t, app, "/", fiber.StatusOK, "0")
+				limiterStep(t, app, "/", fiber.StatusTooManyRequests, "1")
+
+				// The burst refills while the client is idle, up to its size
+				clock.Add(10 * time.Second)
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+				require.NoError(t, err)
+				require.Equal(t, fiber.StatusOK, resp.StatusCode)
+				require.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+				require.Equal(t, "3", resp.Header.Get("X-RateLimit-Remaining"))
+				require.Equal(t, "1", resp.Header.Get("X-RateLimit-Reset"))
+				require.Equal(t, `"default";q=2;w=2`, resp.Header.Get("RateLimit-Policy"))
+				require.Equal(t, `"default";r=3;t=1`, resp.Header.Get("RateLimit"))
+			}
+		})
+	}
+}
+
+func Test_Limiter_Burst_Algorithms_DefaultBurst(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []Handler{TokenBucket{}, GCRA{}} {
+		clock := newTestClock(time.Now())
+		app := fiber.New()
+		app.Use(New(Config{
+			Max:               3,
+			Expiration:        3 * time.Second,
+			LimiterMiddleware: algorithm,
+			clock:             clock.Now,
+		}))
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		limiterStep(t, app, "/", fiber.StatusOK, "2")
+		limiterStep(t, app, "/", fiber.StatusOK, "1")
+		limiterStep(t, app, "/", fiber.StatusOK, "0")
+		limiterStep(t, app, "/", fiber.StatusTooManyRequests, "1")
+	}
+}
+
+func Test_Limiter_Burst_Algorithms_Skip_Failed_Requests(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []Handler{TokenBucket{}, GCRA{}} {
+		clock := newTestClock(time.Now())
+		app := fiber.New()
+		app.Use(New(Config{
+			Max:                1,
+			Expiration:         2 * time.Second,
+			Storage:            memory.New(),
+			SkipFailedRequests: true,
+			LimiterMiddleware:  algorithm,
+			clock:              clock.Now,
+		}))
+		app.Get("/:status", func(c fiber.Ctx) error {
+			if c.Params("status") == "fail" {
+				return c.SendStatus(fiber.StatusBadRequest)
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		limiterStep(t, app, "/fail", fiber.StatusBadRequest, "1")
+		limiterStep(t, app, "/fail", fiber.StatusBadRequest, "1")
+		limiterStep(t, app, "/success", fiber.StatusOK, "0")
+		limiterStep(t, app, "/success", fiber.StatusTooManyRequests, "2")
+
+		clock.Add(2 * time.Second)
+		limiterStep(t, app, "/success", fiber.StatusOK, "0")
+	}
+}
+
+func Test_Limiter_Burst_Algorithms_Skip_Successful_Requests(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []Handler{TokenBucket{}, GCRA{}} {
+		clock := newTestClock(time.Now())
+		app := fiber.New()
+		app.Use(New(Config{
+			Max:                    1,
+			Expiration:             2 * time.Second,
+			SkipSuccessfulRequests: true,
+			LimiterMiddleware:      algorithm,
+			clock:                  clock.Now,
+		}))
+		app.Get("/:status", func(c fiber.Ctx) error {
+			if c.Params("status") == "fail" {
+				return c.SendStatus(fiber.StatusBadRequest)
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		limiterStep(t, app, "/success", fiber.StatusOK, "1")
+		limiterStep(t, app, "/fail", fiber.StatusBadRequest, "0")
+		limiterStep(t, app, "/fail", fiber.StatusTooManyRequests, "2")
+	}
+}
+
+func Test_Limiter_Burst_Algorithms_Max_Func(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []Handler{TokenBucket{}, GCRA{}} {
+		clock := newTestClock(time.Now())
+		app := fiber.New()
+		app.Use(New(Config{
+			MaxFunc: func(c fiber.Ctx) int {
+				if c.Path() == "/unlimited" {
+					return 0
+				}
+				return 1
+			},
+			KeyGenerator: func(c fiber.Ctx) string {
+				return c.Path()
+			},
+			ExpirationFunc: func(fiber.Ctx) time.Duration {
+				return 500 * time.Millisecond
+			},
+			LimiterMiddleware: algorithm,
+			clock:             clock.Now,
+		}))
+		app.Get("/*", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		for range 3 {
+			limiterStep(t, app, "/unlimited", fiber.StatusOK, "")
+		}
+
+		// Sub-second expirations are kept as is
+		limiterStep(t, app, "/limited", fiber.StatusOK, "0")
+		limiterStep(t, app, "/limited", fiber.StatusTooManyRequests, "1")
+		clock.Add(500 * time.Millisecond)
+		limiterStep(t, app, "/limited", fiber.StatusOK, "0")
+	}
+}
+
+func Test_Limiter_Burst_Algorithms_Storage_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []Handler{TokenBucket{}, GCRA{}} {
+		storage := newFailingLimiterStorage()
+		storage.errs["get|"+testLimiterClientKey] = errors.New("boom")
+
+		app := fiber.New()
+		app.Use(New(Config{
+			Storage:           storage,
+			LimiterMiddleware: algorithm,
+			KeyGenerator: func(fiber.Ctx) string {
+				return testLimiterClientKey
+			},
+		}))
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+		storage = newFailingLimiterStorage()
+		storage.errs["set|"+testLimiterClientKey] = errors.New("boom")
+		app = fiber.New()
+		app.Use(New(Config{
+			Storage:           storage,
+			LimiterMiddleware: algorithm,
+			KeyGenerator: func(fiber.Ctx) string {
+				return testLimiterClientKey
+			},
+		}))
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	}
 }
 
 // go test -v -run=^$ -bench=Benchmark_Limiter -benchmem -count=4
@@ -1912,3 +2149,236 @@ func assertSubSecondWindowIsFloored(t *testing.T, strategy Handler) {
 	require.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
 	require.Equal(t, "1", resp.Header.Get(fiber.HeaderRetryAfter))
 }
+
+func Test_Limiter_Tiers(t *testing.T) {
+	t.Parallel()
+
+	// Retry-After once the minute tier is exhausted: the windows wait for the
+	// end of the minute, the burst algorithms for the next request to be
+	// spaced out.
+	algorithms := map[string]struct {
+		handler Handler
+		retry   string
+	}{
+		"fixed window":   {handler: FixedWindow{}, retry: "58"},
+		"sliding window": {handler: SlidingWindow{}, retry: "58"},
+		"token bucket":   {handler: TokenBucket{}, retry: "18"},
+		"gcra":           {handler: GCRA{}, retry: "18"},
+	}
+
+	for name, algorithm := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, storage := range []fiber.Storage{nil, memory.New()} {
+				clock := newTestClock(time.Unix(1_700_000_000, 0))
+				app := fiber.New()
+				app.Use(New(Config{
+					Tiers: []Tier{
+						{Name: "second", Max: 2, Expiration: time.Second},
+						{Name: "minute", Max: 3, Expiration: time.Minute},
+					},
+					Storage:           storage,
+					LimiterMiddleware: algorithm.handler,
+					clock:             clock.Now,
+				}))
+				app.Get("/", func(c fiber.Ctx) error {
+					return c.SendStatus(fiber.StatusOK)
+				})
+
+				limiterStep(t, app, "/", fiber.StatusOK, "1")
+				limiterStep(t, app, "/", fiber.StatusOK, "0")
+				limiterStep(t, app, "/", fiber.StatusTooManyRequests, "1")
+
+				// The rejected request did not count against the minute tier
+				clock.Add(2 * time.Second)
+				limiterStep(t, app, "/", fiber.StatusOK, "0")
+				limiterStep(t, app, "/", fiber.StatusTooManyRequests, algorithm.retry)
+			}
+		})
+	}
+}
+
+func Test_Limiter_Tiers_Headers(t *testing.T) {
+	t.Parallel()
+
+	clock := newTestClock(time.Unix(1_700_000_000, 0))
+	app := fiber.New()
+	app.Use(New(Config{
+		Tiers: []Tier{
+			{Name: "second", Max: 2, Expiration: time.Second},
+			{Max: 5, Expiration: time.Minute},
+		},
+		clock: clock.Now,
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	policy := `"second";q=2;w=1, "tier-2";q=5;w=60`
+
+	// The tier with the fewest remaining requests is reported
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "2", resp.Header.Get(xRateLimitLimit))
+	require.Equal(t, "1", resp.Header.Get(xRateLimitRemaining))
+	require.Equal(t, "1", resp.Header.Get(xRateLimitReset))
+	require.Equal(t, policy, resp.Header.Get(rateLimitPolicy))
+	require.Equal(t, `"second";r=1;t=1`, resp.Header.Get(rateLimit))
+
+	// Once the second tier resets, the minute tier is the most restrictive
+	clock.Add(time.Second)
+	for range 3 {
+		resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		clock.Add(time.Second)
+	}
+	require.Equal(t, "5", resp.Header.Get(xRateLimitLimit))
+	require.Equal(t, "1", resp.Header.Get(xRateLimitRemaining))
+	require.Equal(t, "57", resp.Header.Get(xRateLimitReset))
+	require.Equal(t, policy, resp.Header.Get(rateLimitPolicy))
+	require.Equal(t, `"tier-2";r=1;t=57`, resp.Header.Get(rateLimit))
+}
+
+func Test_Limiter_Tiers_Skip_Failed_Requests(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []Handler{FixedWindow{}, SlidingWindow{}, TokenBucket{}, GCRA{}} {
+		clock := newTestClock(time.Unix(1_700_000_000, 0))
+		app := fiber.New()
+		app.Use(New(Config{
+			Tiers: []Tier{
+				{Max: 1, Expiration: time.Second},
+				{Max: 2, Expiration: time.Minute},
+			},
+			Storage:            memory.New(),
+			SkipFailedRequests: true,
+			LimiterMiddleware:  algorithm,
+			clock:              clock.Now,
+		}))
+		app.Get("/:status", func(c fiber.Ctx) error {
+			if c.Params("status") == "fail" {
+				return c.SendStatus(fiber.StatusBadRequest)
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		// Failed requests are given back to every tier
+		limiterStep(t, app, "/fail", fiber.StatusBadRequest, "1")
+		limiterStep(t, app, "/fail", fiber.StatusBadRequest, "1")
+		limiterStep(t, app, "/success", fiber.StatusOK, "0")
+		limiterStep(t, app, "/success", fiber.StatusTooManyRequests, "1")
+
+		clock.Add(2 * time.Second)
+		limiterStep(t, app, "/success", fiber.StatusOK, "0")
+	}
+}
+
+func Test_Limiter_Tiers_Changed(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.New()
+	clock := newTestClock(time.Unix(1_700_000_000, 0))
+	handler := func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Tiers:   []Tier{{Max: 1, Expiration: time.Minute}},
+		Storage: storage,
+		clock:   clock.Now,
+	}))
+	app.Get("/", handler)
+	limiterStep(t, app, "/", fiber.StatusOK, "0")
+	limiterStep(t, app, "/", fiber.StatusTooManyRequests, "60")
+
+	// A different number of tiers starts over instead of mixing up states
+	app = fiber.New()
+	app.Use(New(Config{
+		Tiers: []Tier{
+			{Max: 2, Expiration: time.Minute},
+			{Max: 3, Expiration: time.Hour},
+		},
+		Storage: storage,
+		clock:   clock.Now,
+	}))
+	app.Get("/", handler)
+	limiterStep(t, app, "/", fiber.StatusOK, "1")
+}
+
+func Test_Limiter_QuotaFromContext(t *testing.T) {
+	t.Parallel()
+
+	tiers := []Tier{
+		{Name: "second", Max: 1, Expiration: time.Second},
+		{Name: "minute", Max: 10, Expiration: time.Minute},
+	}
+
+	for _, config := range []Config{{Max: 1, Expiration: time.Minute}, {Tiers: tiers}} {
+		app := fiber.New()
+		app.Use(New(Config{
+			Max:        config.Max,
+			Expiration: config.Expiration,
+			Tiers:      config.Tiers,
+			LimitReached: func(c fiber.Ctx) error {
+				quota, ok := QuotaFromContext(c)
+				require.True(t, ok)
+				return c.Status(fiber.StatusTooManyRequests).SendString(
+					quota.Name + " " + strconv.Itoa(quota.Limit) + " " + strconv.Itoa(quota.Remaining) + " " + quota.Expiration.String(),
+				)
+			},
+		}))
+		app.Get("/", func(c fiber.Ctx) error {
+			quota, ok := QuotaFromContext(c)
+			require.True(t, ok)
+			return c.SendString(quota.Name + " " + strconv.Itoa(quota.Remaining))
+		})
+
+		name, expiration := "default", "1m0s"
+		if config.Tiers != nil {
+			name, expiration = "second", "1s"
+		}
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, name+" 0", string(body))
+
+		resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		body, err = io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, name+" 1 0 "+expiration, string(body))
+	}
+
+	_, ok := QuotaFromContext(context.Background())
+	require.False(t, ok)
+}
+
+func Test_Limiter_Tiers_Config(t *testing.T) {
+	t.Parallel()
+
+	tiers := []Tier{{Max: 1, Expiration: time.Second}, {Name: "hour", Max: 100, Expiration: time.Hour}}
+	cfg := configDefault(Config{Tiers: tiers})
+	require.Equal(t, "tier-1", cfg.Tiers[0].Name)
+	require.Equal(t, "hour", cfg.Tiers[1].Name)
+	// The caller's tiers are left untouched
+	require.Empty(t, tiers[0].Name)
+
+	require.PanicsWithValue(t, "limiter: tier 2 must have a positive Max and Expiration", func() {
+		configDefault(Config{Tiers: []Tier{{Max: 1, Expiration: time.Second}, {Max: 1}}})
+	})
+}
+
+func Test_quoteTierName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `"api"`, quoteTierName("api"))
+	require.Equal(t, `"a\"b\\c"`, quoteTierName(`a"b\c`))
+	require.Equal(t, `"ab"`, quoteTierName("a\nb"))
+}
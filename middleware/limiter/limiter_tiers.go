@@ -0,0 +1,272 @@
+package limiter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+)
+
+// tierAlgorithm evaluates a single tier of a limit. The built-in algorithms
+// implement it to apply Config.Tiers, and TokenBucket and GCRA to apply their
+// only limit as well.
+type tierAlgorithm interface {
+	// conforms moves the state of the tier forward to now and reports
+	// whether it accepts one more request, or else how long until it does.
+	conforms(e *item, t *Tier, now time.Time) (bool, time.Duration)
+
+	// take counts a request that every tier accepted. The returned mark is
+	// passed back to refund.
+	take(e *item, t *Tier, now time.Time) uint64
+
+	// refund uncounts a request taken with mark, for SkipFailedRequests and
+	// SkipSuccessfulRequests.
+	refund(e *item, t *Tier, now time.Time, mark uint64)
+
+	// quota returns how many more requests the tier accepts, how long until
+	// its quota is restored, and how long its state must be kept.
+	quota(e *item, t *Tier, now time.Time) (remaining int, reset, keep time.Duration)
+}
+
+// defaultTierName names the tier of a limit set with Max and Expiration.
+const defaultTierName = "default"
+
+// newTieredHandler creates a middleware handler applying the tiers of cfg
+// with alg. Without tiers, it applies the limit set by MaxFunc and
+// ExpirationFunc, accepting burst requests at once.
+func newTieredHandler(cfg *Config, alg tierAlgorithm, burst int) fiber.Handler {
+	// Limiter variables
+	mux := &sync.RWMutex{}
+
+	// Create manager to simplify storage operations ( see manager.go )
+	manager := newManager(cfg.Storage, !cfg.DisableValueRedaction)
+
+	// The policy header only changes with MaxFunc and ExpirationFunc
+	var policy string
+	if len(cfg.Tiers) > 0 {
+		policy = rateLimitPolicyHeader(cfg.Tiers)
+	}
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		tiers := cfg.Tiers
+		if len(tiers) == 0 {
+			// Generate maxRequests from generator, if no generator was provided the default value returned is 5
+			maxRequests := cfg.MaxFunc(c)
+
+			// Don't execute middleware if the max is 0
+			if maxRequests == 0 {
+				return c.Next()
+			}
+			tiers = []Tier{{Name: defaultTierName, Max: maxRequests, Expiration: cfg.period(c), Burst: burst}}
+		}
+
+		// Get key from request
+		key := cfg.KeyGenerator(c)
+
+		// Lock entry
+		mux.Lock()
+
+		reqCtx := c.Context()
+
+		// Get entry from pool and release when finished
+		e, err := manager.get(reqCtx, key)
+		if err != nil {
+			mux.Unlock()
+			return err
+		}
+
+		now := cfg.now()
+		states := tierStates(e, len(tiers))
+
+		// The request is counted against every tier or none
+		allowed := true
+		var retryIn time.Duration
+		for i := range tiers {
+			if ok, retry := alg.conforms(&states[i], &tiers[i], now); !ok {
+				allowed = false
+				retryIn = max(retryIn, retry)
+			}
+		}
+		var marks []uint64
+		if allowed {
+			marks = make([]uint64, len(tiers))
+			for i := range tiers {
+				marks[i] = alg.take(&states[i], &tiers[i], now)
+			}
+		}
+		quota, keep := tierQuota(alg, states, tiers, now)
+
+		// Update storage
+		if setErr := manager.set(reqCtx, key, e, storageTTL(keep)); setErr != nil {
+			mux.Unlock()
+			return fmt.Errorf("limiter: failed to persist state: %w", setErr)
+		}
+
+		// Unlock entry
+		mux.Unlock()
+
+		if !allowed {
+			fiber.StoreInContext(c, quotaKey, quota)
+
+			// Return response with Retry-After header
+			// https://tools.ietf.org/html/rfc6584
+			if !cfg.DisableHeaders {
+				c.Set(fiber.HeaderRetryAfter, utils.FormatUint(max(ceilSeconds(retryIn), 1)))
+			}
+
+			// Call LimitReached handler
+			return cfg.LimitReached(c)
+		}
+
+		fiber.StoreInContext(c, quotaKey, quota)
+
+		// Continue stack for reaching c.Response().StatusCode()
+		// Store err for returning
+		err = c.Next()
+
+		// Get the effective status code from either the error or response
+		statusCode := getEffectiveStatusCode(c, err)
+
+		// Check for SkipFailedRequests and SkipSuccessfulRequests
+		if (cfg.SkipSuccessfulRequests && statusCode < fiber.StatusBadRequest) ||
+			(cfg.SkipFailedRequests && statusCode >= fiber.StatusBadRequest) {
+			// Lock entry
+			mux.Lock()
+			entry, getErr := manager.get(reqCtx, key)
+			if getErr != nil {
+				mux.Unlock()
+				return getErr
+			}
+			e = entry
+
+			now = cfg.now()
+			states = tierStates(e, len(tiers))
+			for i := range tiers {
+				alg.refund(&states[i], &tiers[i], now, marks[i])
+			}
+			quota, keep = tierQuota(alg, states, tiers, now)
+
+			if setErr := manager.set(reqCtx, key, e, storageTTL(keep)); setErr != nil {
+				mux.Unlock()
+				return fmt.Errorf("limiter: failed to persist state: %w", setErr)
+			}
+			// Unlock entry
+			mux.Unlock()
+		}
+
+		// We can continue, update RateLimit headers
+		if !cfg.DisableHeaders {
+			reset := ceilSeconds(quota.Reset)
+			c.Set(xRateLimitLimit, utils.FormatInt(int64(quota.Limit)))
+			c.Set(xRateLimitRemaining, utils.FormatInt(int64(quota.Remaining)))
+			c.Set(xRateLimitReset, utils.FormatUint(reset))
+
+			if policy != "" {
+				c.Set(rateLimitPolicy, policy)
+			} else {
+				c.Set(rateLimitPolicy, rateLimitPolicyHeader(tiers))
+			}
+			c.Set(rateLimit, quoteTierName(quota.Name)+";r="+utils.FormatInt(int64(quota.Remaining))+";t="+utils.FormatUint(reset))
+		}
+
+		return err
+	}
+}
+
+// tierStates returns the state of each of n tiers kept in e. The states are
+// reset when the number of tiers changed since they were stored.
+func tierStates(e *item, n int) []item {
+	if len(e.tiers) != n {
+		if cap(e.tiers) >= n {
+			e.tiers = e.tiers[:n]
+		} else {
+			e.tiers = make([]item, n)
+		}
+		clear(e.tiers)
+	}
+	return e.tiers
+}
+
+// tierQuota returns the quota of the most restrictive tier: the one with the
+// fewest remaining requests, or of those the one restored last. It also
+// returns how long the state of every tier must be kept.
+func tierQuota(alg tierAlgorithm, states []item, tiers []Tier, now time.Time) (Quota, time.Duration) {
+	var quota Quota
+	var keep time.Duration
+	for i := range tiers {
+		remaining, reset, tierKeep := alg.quota(&states[i], &tiers[i], now)
+		keep = max(keep, tierKeep)
+		if i == 0 || remaining < quota.Remaining || (remaining == quota.Remaining && reset > quota.Reset) {
+			quota = Quota{
+				Name:       tiers[i].Name,
+				Limit:      tiers[i].Max,
+				Remaining:  remaining,
+				Reset:      reset,
+				Expiration: tiers[i].Expiration,
+			}
+		}
+	}
+	return quota, keep
+}
+
+// rateLimitPolicyHeader lists the tiers in a RateLimit-Policy header.
+func rateLimitPolicyHeader(tiers []Tier) string {
+	var sb strings.Builder
+	for i := range tiers {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(quoteTierName(tiers[i].Name))
+		sb.WriteString(";q=")
+		sb.WriteString(utils.FormatInt(int64(tiers[i].Max)))
+		sb.WriteString(";w=")
+		sb.WriteString(utils.FormatUint(ceilSeconds(tiers[i].Expiration)))
+	}
+	return sb.String()
+}
+
+// quoteTierName writes a tier name as a structured field string (RFC 9651).
+// Characters a string cannot hold are dropped.
+func quoteTierName(name string) string {
+	var sb strings.Builder
+	sb.Grow(len(name) + 2)
+	sb.WriteByte('"')
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		switch {
+		case ch == '"' || ch == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(ch)
+		case ch >= 0x20 && ch < 0x7f:
+			sb.WriteByte(ch)
+		default:
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// unixSecond returns now in whole Unix seconds, as the windows count them.
+func unixSecond(now time.Time) uint64 {
+	if sec := now.Unix(); sec > 0 {
+		return uint64(sec)
+	}
+	return 0
+}
+
+// tierBurst returns the number of requests t accepts at once.
+func tierBurst(t *Tier) int {
+	if t.Burst > 0 {
+		return t.Burst
+	}
+	return t.Max
+}
@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// TokenBucket implements the token bucket rate limiting strategy.
+//
+// Every key has a bucket holding up to Burst tokens, which refills
+// continuously at Max tokens per Expiration. Each request takes a token and is
+// rejected when the bucket is empty, so a client that has been idle can spend
+// a burst at once while its long-run rate stays at Max per Expiration.
+type TokenBucket struct {
+	// Burst is the capacity of the bucket: the number of requests accepted
+	// at once from a full bucket.
+	//
+	// Optional. Default: Max
+	Burst int
+}
+
+// New creates a new token bucket middleware handler
+func (tb TokenBucket) New(cfg *Config) fiber.Handler {
+	if cfg == nil {
+		defaultCfg := configDefault()
+		cfg = &defaultCfg
+	}
+
+	return newTieredHandler(cfg, tb, max(tb.Burst, 0))
+}
+
+// refillBucket adds the tokens the bucket of e gained since it was last
+// refilled. A new entry starts with a full bucket.
+func refillBucket(e *item, now int64, capacity, interval float64) {
+	if e.updated == 0 {
+		e.tokens = capacity
+	} else if elapsed := now - e.updated; elapsed > 0 {
+		e.tokens += float64(elapsed) / interval
+	}
+
+	// The capacity shrinks when MaxFunc returns less than before
+	e.tokens = min(e.tokens, capacity)
+	e.updated = max(e.updated, now)
+}
+
+// bucketRefillTime returns how long the bucket takes to refill from tokens to
+// target tokens.
+func bucketRefillTime(tokens, target, interval float64) time.Duration {
+	if tokens >= target {
+		return 0
+	}
+	return time.Duration((target - tokens) * interval)
+}
+
+// bucketParams returns the capacity of the bucket of t and the time it takes
+// to refill one token, in nanoseconds.
+func bucketParams(t *Tier) (capacity, interval float64) {
+	return float64(tierBurst(t)), float64(t.Expiration) / float64(t.Max)
+}
+
+func (TokenBucket) conforms(e *item, t *Tier, now time.Time) (bool, time.Duration) {
+	capacity, interval := bucketParams(t)
+	refillBucket(e, now.UnixNano(), capacity, interval)
+	if e.tokens >= 1 {
+		return true, 0
+	}
+	return false, bucketRefillTime(e.tokens, 1, interval)
+}
+
+func (TokenBucket) take(e *item, _ *Tier, _ time.Time) uint64 {
+	e.tokens--
+	return 0
+}
+
+func (TokenBucket) refund(e *item, t *Tier, now time.Time, _ uint64) {
+	// Give the token back
+	capacity, interval := bucketParams(t)
+	refillBucket(e, now.UnixNano(), capacity, interval)
+	e.tokens = min(e.tokens+1, capacity)
+}
+
+func (TokenBucket) quota(e *item, t *Tier, _ time.Time) (remaining int, reset, keep time.Duration) {
+	capacity, interval := bucketParams(t)
+	reset = bucketRefillTime(e.tokens, capacity, interval)
+	// The entry can go once the bucket is full again, as a missing entry
+	// stands for a full bucket.
+	return int(e.tokens), reset, reset
+}
@@ -17,6 +17,15 @@ type item struct {
 	currHits int
 	prevHits int
 	exp      uint64
+	// tokens is the number of tokens a TokenBucket held at updated.
+	tokens float64
+	// updated is when a TokenBucket was last refilled, in Unix nanoseconds.
+	updated int64
+	// tat is the theoretical arrival time of GCRA, in Unix nanoseconds.
+	tat int64
+	// tiers holds the state of each tier when the limit has several, or
+	// when TokenBucket or GCRA evaluate it.
+	tiers []item
 }
 
 //msgp:ignore manager
@@ -59,6 +68,10 @@ func (m *manager) release(e *item) {
 	e.prevHits = 0
 	e.currHits = 0
 	e.exp = 0
+	e.tokens = 0
+	e.updated = 0
+	e.tat = 0
+	e.tiers = e.tiers[:0]
 	m.pool.Put(e)
 }
 
@@ -42,6 +42,43 @@ func (z *item) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "exp")
 				return
 			}
+		case "tokens":
+			z.tokens, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "tokens")
+				return
+			}
+		case "updated":
+			z.updated, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "updated")
+				return
+			}
+		case "tat":
+			z.tat, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "tat")
+				return
+			}
+		case "tiers":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "tiers")
+				return
+			}
+			if cap(z.tiers) >= int(zb0002) {
+				z.tiers = (z.tiers)[:zb0002]
+			} else {
+				z.tiers = make([]item, zb0002)
+			}
+			for za0001 := range z.tiers {
+				err = z.tiers[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "tiers", za0001)
+					return
+				}
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -54,10 +91,10 @@ func (z *item) DecodeMsg(dc *msgp.Reader) (err error) {
 }
 
 // EncodeMsg implements msgp.Encodable
-func (z item) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 3
+func (z *item) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 7
 	// write "currHits"
-	err = en.Append(0x83, 0xa8, 0x63, 0x75, 0x72, 0x72, 0x48, 0x69, 0x74, 0x73)
+	err = en.Append(0x87, 0xa8, 0x63, 0x75, 0x72, 0x72, 0x48, 0x69, 0x74, 0x73)
 	if err != nil {
 		return
 	}
@@ -86,15 +123,62 @@ func (z item) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "exp")
 		return
 	}
+	// write "tokens"
+	err = en.Append(0xa6, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.tokens)
+	if err != nil {
+		err = msgp.WrapError(err, "tokens")
+		return
+	}
+	// write "updated"
+	err = en.Append(0xa7, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.updated)
+	if err != nil {
+		err = msgp.WrapError(err, "updated")
+		return
+	}
+	// write "tat"
+	err = en.Append(0xa3, 0x74, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.tat)
+	if err != nil {
+		err = msgp.WrapError(err, "tat")
+		return
+	}
+	// write "tiers"
+	err = en.Append(0xa5, 0x74, 0x69, 0x65, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.tiers)))
+	if err != nil {
+		err = msgp.WrapError(err, "tiers")
+		return
+	}
+	for za0001 := range z.tiers {
+		err = z.tiers[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "tiers", za0001)
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z item) MarshalMsg(b []byte) (o []byte, err error) {
+func (z *item) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 3
+	// map header, size 7
 	// string "currHits"
-	o = append(o, 0x83, 0xa8, 0x63, 0x75, 0x72, 0x72, 0x48, 0x69, 0x74, 0x73)
+	o = append(o, 0x87, 0xa8, 0x63, 0x75, 0x72, 0x72, 0x48, 0x69, 0x74, 0x73)
 	o = msgp.AppendInt(o, z.currHits)
 	// string "prevHits"
 	o = append(o, 0xa8, 0x70, 0x72, 0x65, 0x76, 0x48, 0x69, 0x74, 0x73)
@@ -102,6 +186,25 @@ func (z item) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "exp"
 	o = append(o, 0xa3, 0x65, 0x78, 0x70)
 	o = msgp.AppendUint64(o, z.exp)
+	// string "tokens"
+	o = append(o, 0xa6, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73)
+	o = msgp.AppendFloat64(o, z.tokens)
+	// string "updated"
+	o = append(o, 0xa7, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64)
+	o = msgp.AppendInt64(o, z.updated)
+	// string "tat"
+	o = append(o, 0xa3, 0x74, 0x61, 0x74)
+	o = msgp.AppendInt64(o, z.tat)
+	// string "tiers"
+	o = append(o, 0xa5, 0x74, 0x69, 0x65, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.tiers)))
+	for za0001 := range z.tiers {
+		o, err = z.tiers[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "tiers", za0001)
+			return
+		}
+	}
 	return
 }
 
@@ -141,6 +244,43 @@ func (z *item) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "exp")
 				return
 			}
+		case "tokens":
+			z.tokens, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "tokens")
+				return
+			}
+		case "updated":
+			z.updated, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "updated")
+				return
+			}
+		case "tat":
+			z.tat, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "tat")
+				return
+			}
+		case "tiers":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "tiers")
+				return
+			}
+			if cap(z.tiers) >= int(zb0002) {
+				z.tiers = (z.tiers)[:zb0002]
+			} else {
+				z.tiers = make([]item, zb0002)
+			}
+			for za0001 := range z.tiers {
+				bts, err = z.tiers[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "tiers", za0001)
+					return
+				}
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -154,7 +294,10 @@ func (z *item) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z item) Msgsize() (s int) {
-	s = 1 + 9 + msgp.IntSize + 9 + msgp.IntSize + 4 + msgp.Uint64Size
+func (z *item) Msgsize() (s int) {
+	s = 1 + 9 + msgp.IntSize + 9 + msgp.IntSize + 4 + msgp.Uint64Size + 7 + msgp.Float64Size + 8 + msgp.Int64Size + 4 + msgp.Int64Size + 6 + msgp.ArrayHeaderSize
+	for za0001 := range z.tiers {
+		s += z.tiers[za0001].Msgsize()
+	}
 	return
 }
@@ -23,6 +23,8 @@ var fiberMiddlewareTags = []string{
 	fiberlog.TagRequestIDDashed,
 	fiberlog.TagRequestID,
 	fiberlog.TagSessionID,
+	fiberlog.TagSpanID,
+	fiberlog.TagTraceID,
 	fiberlog.TagUsername,
 }
 
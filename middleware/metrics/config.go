@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	// Skipped requests are not recorded.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// ConstLabels are added to every sample, such as the name of the
+	// service.
+	//
+	// Optional. Default: nil
+	ConstLabels map[string]string
+
+	// Path is the endpoint serving the metrics. Requests to it are not
+	// recorded.
+	//
+	// Optional. Default: "/metrics"
+	Path string
+
+	// Namespace prefixes the name of every metric.
+	//
+	// Optional. Default: "fiber"
+	Namespace string
+
+	// Buckets are the upper bounds, in seconds, of the request duration
+	// histogram, in increasing order.
+	//
+	// Optional. Default: DefaultBuckets
+	Buckets []float64
+
+	// SizeBuckets are the upper bounds, in bytes, of the response size
+	// histogram, in increasing order.
+	//
+	// Optional. Default: DefaultSizeBuckets
+	SizeBuckets []float64
+}
+
+// DefaultBuckets are the default request duration buckets, in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the default response size buckets, in bytes.
+var DefaultSizeBuckets = []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:        nil,
+	Path:        "/metrics",
+	Namespace:   "fiber",
+	Buckets:     DefaultBuckets,
+	SizeBuckets: DefaultSizeBuckets,
+}
+
+// reservedLabels are the labels set by the middleware.
+var reservedLabels = []string{"method", "route", "status", "le"}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Path == "" {
+		cfg.Path = ConfigDefault.Path
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = ConfigDefault.Namespace
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = ConfigDefault.Buckets
+	}
+	if len(cfg.SizeBuckets) == 0 {
+		cfg.SizeBuckets = ConfigDefault.SizeBuckets
+	}
+	if !isIncreasing(cfg.Buckets) || !isIncreasing(cfg.SizeBuckets) {
+		panic("metrics: Buckets and SizeBuckets must be in increasing order")
+	}
+	if !validLabelName(cfg.Namespace) {
+		panic("metrics: invalid namespace " + cfg.Namespace)
+	}
+	for name := range cfg.ConstLabels {
+		if !validLabelName(name) || slices.Contains(reservedLabels, name) {
+			panic("metrics: invalid label name " + name)
+		}
+	}
+	return cfg
+}
+
+// isIncreasing reports whether every bucket is above the previous one.
+func isIncreasing(buckets []float64) bool {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// validLabelName reports whether name matches [a-zA-Z_][a-zA-Z0-9_]*.
+func validLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if ch != '_' && (ch < 'a' || ch > 'z') && (ch < 'A' || ch > 'Z') && (i == 0 || ch < '0' || ch > '9') {
+			return false
+		}
+	}
+	return true
+}
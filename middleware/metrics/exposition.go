@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// contentTypeOpenMetrics is the content type of the OpenMetrics text format.
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	// contentTypeText is the content type of the Prometheus text format, for
+	// scrapers that don't accept OpenMetrics.
+	contentTypeText = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// exposition writes the metrics of a registry in the OpenMetrics or the
+// Prometheus text format.
+type exposition struct {
+	reg    *registry
+	prefix string
+	// constLabels is the rendered ConstLabels, each followed by a comma.
+	constLabels string
+}
+
+func newExposition(reg *registry, cfg *Config) *exposition {
+	var sb strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(cfg.ConstLabels)) {
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(cfg.ConstLabels[name]))
+		sb.WriteString(`",`)
+	}
+	return &exposition{reg: reg, prefix: cfg.Namespace + "_http_", constLabels: sb.String()}
+}
+
+// write writes every metric to buf.
+func (e *exposition) write(buf *bytes.Buffer, openMetrics bool) {
+	keys, all, methods, gauges := e.reg.snapshot()
+
+	// Requests
+	requests := e.prefix + "requests"
+	if openMetrics {
+		e.header(buf, requests, "counter", "", "Total number of HTTP requests.")
+	} else {
+		e.header(buf, requests+"_total", "counter", "", "Total number of HTTP requests.")
+	}
+	for i, key := range keys {
+		buf.WriteString(requests)
+		buf.WriteString("_total")
+		e.labels(buf, key, "")
+		writeSample(buf, strconv.FormatUint(all[i].requests.Load(), 10))
+	}
+
+	// Durations
+	duration := e.prefix + "request_duration_seconds"
+	e.header(buf, duration, "histogram", unit(openMetrics, "seconds"), "Duration of HTTP requests in seconds.")
+	for i, key := range keys {
+		h := all[i].duration
+		e.histogram(buf, duration, key, h, e.reg.cfg.Buckets, strconv.FormatFloat(float64(h.sum.Load())/1e9, 'f', -1, 64))
+	}
+
+	// Response sizes
+	size := e.prefix + "response_size_bytes"
+	e.header(buf, size, "histogram", unit(openMetrics, "bytes"), "Size of HTTP response bodies in bytes.")
+	for i, key := range keys {
+		h := all[i].size
+		e.histogram(buf, size, key, h, e.reg.cfg.SizeBuckets, strconv.FormatInt(h.sum.Load(), 10))
+	}
+
+	// In-flight requests
+	inFlight := e.prefix + "requests_in_flight"
+	e.header(buf, inFlight, "gauge", "", "Number of HTTP requests being served.")
+	for i, method := range methods {
+		buf.WriteString(inFlight)
+		buf.WriteByte('{')
+		buf.WriteString(e.constLabels)
+		buf.WriteString(`method="`)
+		buf.WriteString(escapeLabelValue(method))
+		buf.WriteString(`"}`)
+		writeSample(buf, strconv.FormatInt(gauges[i], 10))
+	}
+
+	if openMetrics {
+		buf.WriteString("# EOF\n")
+	}
+}
+
+// header writes the metadata of a metric family.
+func (*exposition) header(buf *bytes.Buffer, name, typ, unit, help string) {
+	buf.WriteString("# TYPE ")
+	buf.WriteString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(typ)
+	buf.WriteByte('\n')
+	if unit != "" {
+		buf.WriteString("# UNIT ")
+		buf.WriteString(name)
+		buf.WriteByte(' ')
+		buf.WriteString(unit)
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("# HELP ")
+	buf.WriteString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(help)
+	buf.WriteByte('\n')
+}
+
+// histogram writes the buckets, count and sum of h.
+func (e *exposition) histogram(buf *bytes.Buffer, name string, key seriesKey, h *histogram, bounds []float64, sum string) {
+	var cumulative uint64
+	for i := range h.counts {
+		cumulative += h.counts[i].Load()
+		le := "+Inf"
+		if i < len(bounds) {
+			le = strconv.FormatFloat(bounds[i], 'f', -1, 64)
+		}
+		buf.WriteString(name)
+		buf.WriteString("_bucket")
+		e.labels(buf, key, le)
+		writeSample(buf, strconv.FormatUint(cumulative, 10))
+	}
+
+	buf.WriteString(name)
+	buf.WriteString("_count")
+	e.labels(buf, key, "")
+	writeSample(buf, strconv.FormatUint(cumulative, 10))
+
+	buf.WriteString(name)
+	buf.WriteString("_sum")
+	e.labels(buf, key, "")
+	writeSample(buf, sum)
+}
+
+// labels writes the label set of key, with le if it is not empty.
+func (e *exposition) labels(buf *bytes.Buffer, key seriesKey, le string) {
+	buf.WriteByte('{')
+	buf.WriteString(e.constLabels)
+	buf.WriteString(`method="`)
+	buf.WriteString(escapeLabelValue(key.method))
+	buf.WriteString(`",route="`)
+	buf.WriteString(escapeLabelValue(key.route))
+	buf.WriteString(`",status="`)
+	buf.WriteString(key.status)
+	buf.WriteByte('"')
+	if le != "" {
+		buf.WriteString(`,le="`)
+		buf.WriteString(le)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('}')
+}
+
+func writeSample(buf *bytes.Buffer, value string) {
+	buf.WriteByte(' ')
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// unit returns the unit of a metric family, which only OpenMetrics declares.
+func unit(openMetrics bool, u string) string {
+	if openMetrics {
+		return u
+	}
+	return ""
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds.
+func escapeLabelValue(v string) string {
+	if !strings.ContainsAny(v, "\\\"\n") {
+		return v
+	}
+	return labelValueReplacer.Replace(v)
+}
@@ -0,0 +1,102 @@
+// Package metrics records HTTP request metrics and serves them in the
+// OpenMetrics text format, without depending on the Prometheus client
+// library.
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// statusClasses maps the first digit of a status code to its label.
+var statusClasses = [...]string{"", "1xx", "2xx", "3xx", "4xx", "5xx"}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	reg := newRegistry(&cfg)
+	expo := newExposition(reg, &cfg)
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Serve the metrics
+		if c.Path() == cfg.Path && (c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead) {
+			openMetrics := strings.Contains(c.Get(fiber.HeaderAccept), "application/openmetrics-text")
+
+			buf := bufferPool.Get().(*bytes.Buffer) //nolint:forcetypeassert,errcheck // We store nothing else in the pool
+			buf.Reset()
+			defer bufferPool.Put(buf)
+			expo.write(buf, openMetrics)
+
+			if openMetrics {
+				c.Set(fiber.HeaderContentType, contentTypeOpenMetrics)
+			} else {
+				c.Set(fiber.HeaderContentType, contentTypeText)
+			}
+			return c.Send(buf.Bytes())
+		}
+
+		// The route of the middleware, which is still the current one after
+		// the chain if no other route matched the request
+		entry := c.Route()
+
+		inFlight := reg.inFlightGauge(c.Method())
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		start := time.Now()
+		chainErr := c.Next()
+
+		// Manually call error handler, so the recorded status and size are
+		// those of the response sent
+		if chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError) //nolint:errcheck // The error handler failed, the status is all that is left to send
+			}
+		}
+		duration := time.Since(start)
+
+		status := c.Response().StatusCode()
+		class := "other"
+		if status >= 100 && status < 600 {
+			class = statusClasses[status/100]
+		}
+
+		// Label requests no route matched with an empty route, as their
+		// paths are unbounded
+		route := c.Route().Path
+		if c.Route() == entry && (status == fiber.StatusNotFound || status == fiber.StatusMethodNotAllowed) {
+			route = ""
+		}
+
+		// Reading a body stream would consume it, so its size is the
+		// Content-Length, if known
+		resp := c.Response()
+		var size int
+		if resp.IsBodyStream() {
+			size = max(resp.Header.ContentLength(), 0)
+		} else {
+			size = len(resp.Body())
+		}
+
+		reg.observe(seriesKey{method: c.Method(), route: route, status: class}, int64(duration), int64(size))
+		return nil
+	}
+}
@@ -0,0 +1,239 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func scrape(t *testing.T, app *fiber.App, path, accept string) (body, contentType string) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, path, http.NoBody)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b), resp.Header.Get(fiber.HeaderContentType)
+}
+
+func request(t *testing.T, app *fiber.App, method, path string) int {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(method, path, http.NoBody))
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func Test_Metrics_Routes(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/users/:id", func(c fiber.Ctx) error {
+		return c.SendString("user " + c.Params("id"))
+	})
+	app.Get("/fail", func(_ fiber.Ctx) error {
+		return fiber.ErrBadGateway
+	})
+
+	require.Equal(t, fiber.StatusOK, request(t, app, fiber.MethodGet, "/users/1"))
+	require.Equal(t, fiber.StatusOK, request(t, app, fiber.MethodGet, "/users/2"))
+	require.Equal(t, fiber.StatusBadGateway, request(t, app, fiber.MethodGet, "/fail"))
+	require.Equal(t, fiber.StatusNotFound, request(t, app, fiber.MethodGet, "/nope/1"))
+	require.Equal(t, fiber.StatusNotFound, request(t, app, fiber.MethodGet, "/nope/2"))
+	require.Equal(t, fiber.StatusMethodNotAllowed, request(t, app, fiber.MethodPost, "/users/1"))
+
+	body, contentType := scrape(t, app, "/metrics", "")
+	require.Equal(t, contentTypeText, contentType)
+
+	// Labelled by route pattern, not by path
+	require.Contains(t, body, "# TYPE fiber_http_requests_total counter\n")
+	require.Contains(t, body, `fiber_http_requests_total{method="GET",route="/users/:id",status="2xx"} 2`+"\n")
+	require.Contains(t, body, `fiber_http_requests_total{method="GET",route="/fail",status="5xx"} 1`+"\n")
+	require.Contains(t, body, `fiber_http_requests_total{method="GET",route="",status="4xx"} 2`+"\n")
+	require.Contains(t, body, `fiber_http_requests_total{method="POST",route="",status="4xx"} 1`+"\n")
+	require.NotContains(t, body, "/nope")
+	require.NotContains(t, body, "/metrics")
+	require.NotContains(t, body, "# EOF")
+
+	// Response sizes
+	require.Contains(t, body, `fiber_http_response_size_bytes_bucket{method="GET",route="/users/:id",status="2xx",le="100"} 2`+"\n")
+	require.Contains(t, body, `fiber_http_response_size_bytes_bucket{method="GET",route="/users/:id",status="2xx",le="+Inf"} 2`+"\n")
+	require.Contains(t, body, `fiber_http_response_size_bytes_sum{method="GET",route="/users/:id",status="2xx"} 12`+"\n")
+	require.Contains(t, body, `fiber_http_response_size_bytes_count{method="GET",route="/users/:id",status="2xx"} 2`+"\n")
+
+	// Durations
+	require.Contains(t, body, "# TYPE fiber_http_request_duration_seconds histogram\n")
+	require.Contains(t, body, `fiber_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="10"} 2`+"\n")
+	require.Contains(t, body, `fiber_http_request_duration_seconds_count{method="GET",route="/users/:id",status="2xx"} 2`+"\n")
+}
+
+func Test_Metrics_OpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(strings.Repeat("a", 150))
+	})
+
+	require.Equal(t, fiber.StatusOK, request(t, app, fiber.MethodGet, "/"))
+
+	body, contentType := scrape(t, app, "/metrics", "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5")
+	require.Equal(t, contentTypeOpenMetrics, contentType)
+	require.Equal(t, `# TYPE fiber_http_requests counter
+# HELP fiber_http_requests Total number of HTTP requests.
+fiber_http_requests_total{method="GET",route="/",status="2xx"} 1
+# TYPE fiber_http_request_duration_seconds histogram
+# UNIT fiber_http_request_duration_seconds seconds
+# HELP fiber_http_request_duration_seconds Duration of HTTP requests in seconds.
+`, body[:strings.Index(body, "fiber_http_request_duration_seconds_bucket")])
+	require.Contains(t, body, `# TYPE fiber_http_response_size_bytes histogram
+# UNIT fiber_http_response_size_bytes bytes
+# HELP fiber_http_response_size_bytes Size of HTTP response bodies in bytes.
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="100"} 0
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="1000"} 1
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="10000"} 1
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="100000"} 1
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="1000000"} 1
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="10000000"} 1
+fiber_http_response_size_bytes_bucket{method="GET",route="/",status="2xx",le="+Inf"} 1
+fiber_http_response_size_bytes_count{method="GET",route="/",status="2xx"} 1
+fiber_http_response_size_bytes_sum{method="GET",route="/",status="2xx"} 150
+# TYPE fiber_http_requests_in_flight gauge
+# HELP fiber_http_requests_in_flight Number of HTTP requests being served.
+fiber_http_requests_in_flight{method="GET"} 0
+# EOF
+`)
+	require.True(t, strings.HasSuffix(body, "# EOF\n"))
+}
+
+func Test_Metrics_InFlight(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/", func(c fiber.Ctx) error {
+		body, _ := scrape(t, app, "/metrics", "")
+		return c.SendString(body)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `fiber_http_requests_in_flight{method="GET"} 1`+"\n")
+
+	metrics, _ := scrape(t, app, "/metrics", "")
+	require.Contains(t, metrics, `fiber_http_requests_in_flight{method="GET"} 0`+"\n")
+}
+
+func Test_Metrics_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c fiber.Ctx, _ error) error {
+			return c.Status(fiber.StatusTeapot).SendString("custom")
+		},
+	})
+	app.Use(New())
+	app.Get("/", func(_ fiber.Ctx) error {
+		return fiber.ErrInternalServerError
+	})
+
+	require.Equal(t, fiber.StatusTeapot, request(t, app, fiber.MethodGet, "/"))
+
+	body, _ := scrape(t, app, "/metrics", "")
+	require.Contains(t, body, `fiber_http_requests_total{method="GET",route="/",status="4xx"} 1`+"\n")
+	require.Contains(t, body, `fiber_http_response_size_bytes_sum{method="GET",route="/",status="4xx"} 6`+"\n")
+}
+
+func Test_Metrics_Config(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Path:        "/internal/metrics",
+		Namespace:   "shop",
+		ConstLabels: map[string]string{"service": `api "v2"`, "env": "prod"},
+		Buckets:     []float64{0.1, 1},
+		Next: func(c fiber.Ctx) bool {
+			return c.Path() == "/health"
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/health", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	require.Equal(t, fiber.StatusNoContent, request(t, app, fiber.MethodGet, "/"))
+	require.Equal(t, fiber.StatusOK, request(t, app, fiber.MethodGet, "/health"))
+
+	body, _ := scrape(t, app, "/internal/metrics", "")
+	require.Contains(t, body, `shop_http_requests_total{env="prod",service="api \"v2\"",method="GET",route="/",status="2xx"} 1`+"\n")
+	require.Contains(t, body, `shop_http_request_duration_seconds_bucket{env="prod",service="api \"v2\"",method="GET",route="/",status="2xx",le="1"} 1`+"\n")
+	require.NotContains(t, body, `le="10"`)
+	require.NotContains(t, body, "/health")
+
+	// The default path is an ordinary route now
+	require.Equal(t, fiber.StatusNotFound, request(t, app, fiber.MethodGet, "/metrics"))
+}
+
+func Test_Metrics_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "metrics: Buckets and SizeBuckets must be in increasing order", func() {
+		New(Config{Buckets: []float64{1, 1}})
+	})
+	require.PanicsWithValue(t, "metrics: Buckets and SizeBuckets must be in increasing order", func() {
+		New(Config{SizeBuckets: []float64{10, 1}})
+	})
+	require.PanicsWithValue(t, "metrics: invalid namespace my-app", func() {
+		New(Config{Namespace: "my-app"})
+	})
+	require.PanicsWithValue(t, "metrics: invalid label name route", func() {
+		New(Config{ConstLabels: map[string]string{"route": "x"}})
+	})
+	require.PanicsWithValue(t, "metrics: invalid label name 1st", func() {
+		New(Config{ConstLabels: map[string]string{"1st": "x"}})
+	})
+}
+
+func Test_escapeLabelValue(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "/users/:id", escapeLabelValue("/users/:id"))
+	require.Equal(t, `a\\b\"c\nd`, escapeLabelValue("a\\b\"c\nd"))
+}
+
+func Benchmark_Metrics(b *testing.B) {
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/users/:id", func(c fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	h := app.Handler()
+
+	fctx := &fasthttp.RequestCtx{}
+	fctx.Request.Header.SetMethod(fiber.MethodGet)
+	fctx.Request.SetRequestURI("/users/1")
+
+	for b.Loop() {
+		h(fctx)
+	}
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// seriesKey holds the labels of the samples of a request.
+type seriesKey struct {
+	method string
+	route  string
+	status string
+}
+
+// histogram counts observations into buckets. counts[i] holds the
+// observations up to the i-th bound that are above the previous one; the last
+// entry counts those above every bound.
+type histogram struct {
+	counts []atomic.Uint64
+	sum    atomic.Int64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(bounds []float64, value float64, raw int64) {
+	i, _ := slices.BinarySearch(bounds, value)
+	h.counts[i].Add(1)
+	h.sum.Add(raw)
+}
+
+// series holds the metrics of a label set.
+type series struct {
+	duration *histogram
+	size     *histogram
+	requests atomic.Uint64
+}
+
+// registry holds the metrics of the middleware.
+type registry struct {
+	series   map[seriesKey]*series
+	inFlight map[string]*atomic.Int64
+	cfg      *Config
+	mu       sync.RWMutex
+}
+
+func newRegistry(cfg *Config) *registry {
+	return &registry{
+		series:   make(map[seriesKey]*series),
+		inFlight: make(map[string]*atomic.Int64),
+		cfg:      cfg,
+	}
+}
+
+// observe records a completed request, with its duration in nanoseconds and
+// its response size in bytes.
+func (r *registry) observe(key seriesKey, duration, size int64) {
+	r.mu.RLock()
+	s, ok := r.series[key]
+	r.mu.RUnlock()
+	if !ok {
+		r.mu.Lock()
+		if s, ok = r.series[key]; !ok {
+			// The labels may point into buffers reused by the next request
+			key = seriesKey{
+				method: strings.Clone(key.method),
+				route:  strings.Clone(key.route),
+				status: key.status,
+			}
+			s = &series{
+				duration: newHistogram(r.cfg.Buckets),
+				size:     newHistogram(r.cfg.SizeBuckets),
+			}
+			r.series[key] = s
+		}
+		r.mu.Unlock()
+	}
+
+	s.requests.Add(1)
+	s.duration.observe(r.cfg.Buckets, float64(duration)/1e9, duration)
+	s.size.observe(r.cfg.SizeBuckets, float64(size), size)
+}
+
+// inFlightGauge returns the gauge of the requests of method in flight.
+func (r *registry) inFlightGauge(method string) *atomic.Int64 {
+	r.mu.RLock()
+	g, ok := r.inFlight[method]
+	r.mu.RUnlock()
+	if ok {
+		return g
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if g, ok = r.inFlight[method]; !ok {
+		g = &atomic.Int64{}
+		r.inFlight[strings.Clone(method)] = g
+	}
+	return g
+}
+
+// snapshot returns the series and in-flight gauges sorted by their labels.
+func (r *registry) snapshot() (keys []seriesKey, all []*series, methods []string, gauges []int64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys = make([]seriesKey, 0, len(r.series))
+	for key := range r.series {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		return cmp.Or(cmp.Compare(a.route, b.route), cmp.Compare(a.method, b.method), cmp.Compare(a.status, b.status))
+	})
+	all = make([]*series, len(keys))
+	for i, key := range keys {
+		all[i] = r.series[key]
+	}
+
+	methods = make([]string, 0, len(r.inFlight))
+	for method := range r.inFlight {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+	gauges = make([]int64, len(methods))
+	for i, method := range methods {
+		gauges[i] = r.inFlight[method].Load()
+	}
+	return keys, all, methods, gauges
+}
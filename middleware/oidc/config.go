@@ -0,0 +1,181 @@
+package oidc
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Session is the session store the tokens of the logged in users are
+	// kept in. When the session middleware is used, it must be given the
+	// same store.
+	//
+	// Required.
+	Session *session.Store
+
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// ErrorHandler defines a function which is executed when a request is
+	// not authenticated and cannot be redirected to the login, or when the
+	// login fails.
+	//
+	// Optional. Default: 401 Unauthorized for the errors of this package,
+	// the error otherwise
+	ErrorHandler fiber.ErrorHandler
+
+	// Client sends the requests to the provider: the discovery document,
+	// the JWK Set and the token requests.
+	//
+	// Optional. Default: client.New() with a 10 seconds timeout
+	Client *client.Client
+
+	// Issuer is the issuer identifier of the provider, which the `iss` claim
+	// of the ID tokens must be equal to.
+	//
+	// Required.
+	Issuer string
+
+	// DiscoveryURL is the URL of the discovery document of the provider. It
+	// is fetched on the first request handled by the middleware.
+	//
+	// Optional. Default: Issuer + "/.well-known/openid-configuration"
+	DiscoveryURL string
+
+	// DiscoveryFile is the path of a local discovery document, read by New
+	// instead of fetching DiscoveryURL.
+	//
+	// Optional. Default: ""
+	DiscoveryFile string
+
+	// ClientID is the client identifier registered at the provider.
+	//
+	// Required.
+	ClientID string
+
+	// ClientSecret is the client secret registered at the provider, sent
+	// with HTTP Basic authentication. Public clients have none, and rely on
+	// PKCE only.
+	//
+	// Optional. Default: ""
+	ClientSecret string
+
+	// RedirectURL is the absolute URL of the callback the provider redirects
+	// to after the login. The middleware handles the requests to its path,
+	// so it must be mounted on it.
+	//
+	// Required.
+	RedirectURL string
+
+	// LogoutPath is the path of the logout. Requests to it destroy the
+	// session, and are redirected to the end session endpoint of the
+	// provider, if it has one, or else to PostLogoutRedirectURL.
+	//
+	// Optional. Default: "" (no logout path)
+	LogoutPath string
+
+	// PostLogoutRedirectURL is where the users are redirected after the
+	// logout.
+	//
+	// Optional. Default: "/"
+	PostLogoutRedirectURL string
+
+	// Scopes lists the scopes requested. With the "openid" scope, the token
+	// response must hold an ID token, which is verified.
+	//
+	// Optional. Default: "openid", "profile", "email"
+	Scopes []string
+
+	// AuthParams are additional parameters of the authorization requests,
+	// such as "prompt" or "audience".
+	//
+	// Optional. Default: nil
+	AuthParams map[string]string
+
+	// LoginTimeout is how long the users have to log in at the provider.
+	//
+	// Optional. Default: 10 minutes
+	LoginTimeout time.Duration
+
+	// RefreshBefore is how long before its expiry the access token is
+	// refreshed with the refresh token. Sessions whose access token expires
+	// without a refresh token are logged out.
+	//
+	// Optional. Default: 1 minute
+	RefreshBefore time.Duration
+
+	// ClockSkew is the leeway allowed when checking the `exp` and `nbf`
+	// claims of the ID tokens.
+	//
+	// Optional. Default: 0
+	ClockSkew time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	ErrorHandler: func(c fiber.Ctx, err error) error {
+		if slices.ContainsFunc(loginErrors, func(target error) bool { return errors.Is(err, target) }) {
+			return c.Status(fiber.StatusUnauthorized).SendString(fiber.ErrUnauthorized.Message)
+		}
+		return err
+	},
+	PostLogoutRedirectURL: "/",
+	Scopes:                []string{"openid", "profile", "email"},
+	LoginTimeout:          10 * time.Minute,
+	RefreshBefore:         time.Minute,
+}
+
+// configDefault is a helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		panic("fiber: oidc middleware requires a Session, an Issuer, a ClientID and a RedirectURL")
+	}
+	cfg := config[0]
+
+	// Require the provider and the client registration
+	if cfg.Session == nil || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		panic("fiber: oidc middleware requires a Session, an Issuer, a ClientID and a RedirectURL")
+	}
+	if u, err := url.Parse(cfg.RedirectURL); err != nil || !u.IsAbs() {
+		panic("fiber: oidc RedirectURL must be an absolute URL")
+	}
+
+	// Set default values
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+	if cfg.Client == nil {
+		cfg.Client = client.New().SetTimeout(10 * time.Second)
+	}
+	if cfg.DiscoveryURL == "" {
+		cfg.DiscoveryURL = strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	}
+	if cfg.PostLogoutRedirectURL == "" {
+		cfg.PostLogoutRedirectURL = ConfigDefault.PostLogoutRedirectURL
+	}
+	if cfg.Scopes == nil {
+		cfg.Scopes = ConfigDefault.Scopes
+	}
+	if cfg.LoginTimeout <= 0 {
+		cfg.LoginTimeout = ConfigDefault.LoginTimeout
+	}
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = ConfigDefault.RefreshBefore
+	}
+	if cfg.ClockSkew < 0 {
+		panic("fiber: oidc clock skew must not be negative")
+	}
+
+	return cfg
+}
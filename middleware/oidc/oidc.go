@@ -0,0 +1,372 @@
+// Package oidc logs users in with an OpenID Connect provider, or an OAuth 2.0
+// authorization server, using the authorization code flow (RFC 6749) with
+// PKCE (RFC 7636), and keeps their tokens in a session.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/log"
+	"github.com/gofiber/fiber/v3/middleware/jwt"
+)
+
+// Errors passed to the ErrorHandler when a request is not authenticated or
+// a login fails.
+var (
+	ErrUnauthenticated = errors.New("oidc: not logged in")
+	ErrInvalidState    = errors.New("oidc: invalid or expired login state")
+	ErrAuthorization   = errors.New("oidc: authorization failed")
+	ErrTokenRequest    = errors.New("oidc: token request failed")
+	ErrInvalidIDToken  = errors.New("oidc: invalid ID token")
+)
+
+// loginErrors are the errors of the requests which are not logged in, or
+// whose login failed.
+var loginErrors = []error{ErrUnauthenticated, ErrInvalidState, ErrAuthorization, ErrTokenRequest, ErrInvalidIDToken}
+
+// The contextKey type is unexported to prevent collisions with context keys defined in
+// other packages.
+type contextKey int
+
+// The key for the tokens in context
+const tokensKey contextKey = 0
+
+// Tokens holds the tokens of a logged in user.
+type Tokens struct {
+	// Expiry is when the access token expires, or the zero time if the
+	// provider did not tell.
+	Expiry time.Time
+	// AccessToken is the access token, to send to the APIs of the provider
+	// or of resource servers.
+	AccessToken string
+	// TokenType is the type of the access token, usually "Bearer".
+	TokenType string
+	// RefreshToken is the refresh token, if the provider issued one.
+	RefreshToken string
+	// IDToken is the ID token, with the "openid" scope.
+	IDToken string
+}
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Init config
+	cfg := configDefault(config...)
+
+	redirect, _ := url.Parse(cfg.RedirectURL) //nolint:errcheck // Checked by configDefault
+	callbackPath := redirect.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+
+	h := &handler{
+		cfg:      &cfg,
+		provider: newProvider(&cfg),
+		sessions: newSessionManager(cfg.Session),
+		openID:   slices.Contains(cfg.Scopes, "openid"),
+	}
+
+	// Return middleware handler
+	return func(c fiber.Ctx) error {
+		// Filter request to skip middleware
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		switch path := c.Path(); {
+		case path == callbackPath:
+			return h.callback(c)
+		case cfg.LogoutPath != "" && path == cfg.LogoutPath:
+			return h.logout(c)
+		default:
+			return h.authenticate(c)
+		}
+	}
+}
+
+// TokensFromContext returns the tokens of the logged in user from the request
+// context. It accepts fiber.CustomCtx, fiber.Ctx, *fasthttp.RequestCtx, and
+// context.Context. It returns nil if the user is not logged in.
+func TokensFromContext(ctx any) *Tokens {
+	if tokens, ok := fiber.ValueFromContext[*Tokens](ctx, tokensKey); ok {
+		return tokens
+	}
+
+	return nil
+}
+
+// ClaimsFromContext returns the claims of the ID token of the logged in user
+// from the request context, which was verified at the login. It accepts
+// fiber.CustomCtx, fiber.Ctx, *fasthttp.RequestCtx, and context.Context. It
+// returns nil if the user is not logged in, or has no ID token.
+func ClaimsFromContext(ctx any) *jwt.Claims {
+	tokens := TokensFromContext(ctx)
+	if tokens == nil || tokens.IDToken == "" {
+		return nil
+	}
+	claims, err := jwt.ParseUnverified(tokens.IDToken)
+	if err != nil {
+		return nil
+	}
+	return claims
+}
+
+// handler drives the authorization code flow.
+type handler struct {
+	cfg      *Config
+	provider *provider
+	sessions *sessionManager
+	openID   bool
+}
+
+// authenticate passes the requests of logged in users to the next handler,
+// refreshing their tokens when they expire, and redirects the other ones to
+// the login.
+func (h *handler) authenticate(c fiber.Ctx) error {
+	sess, release, err := h.sessions.get(c)
+	if err != nil {
+		return h.cfg.ErrorHandler(c, err)
+	}
+
+	now := time.Now()
+	tokens, ok := sess.Get(sessionTokensKey).(Tokens)
+	save := false
+	if ok && !tokens.Expiry.IsZero() && !now.Before(tokens.Expiry.Add(-h.cfg.RefreshBefore)) {
+		refreshed, err := h.refresh(c, &tokens)
+		switch {
+		case err == nil:
+			tokens = *refreshed
+			sess.Set(sessionTokensKey, tokens)
+			save = true
+		case now.Before(tokens.Expiry):
+			// The access token may still be used
+			log.Warnf("oidc: failed to refresh tokens: %v", err)
+		default:
+			// The session is logged out
+			sess.Delete(sessionTokensKey)
+			save = true
+			ok = false
+		}
+	}
+	if !ok {
+		return h.login(c, sess, release)
+	}
+
+	if err := release(save); err != nil {
+		return h.cfg.ErrorHandler(c, err)
+	}
+	fiber.StoreInContext(c, tokensKey, &tokens)
+	return c.Next()
+}
+
+// refresh returns new tokens, requested with the refresh token.
+func (h *handler) refresh(ctx context.Context, tokens *Tokens) (*Tokens, error) {
+	if tokens.RefreshToken == "" {
+		return nil, ErrUnauthenticated
+	}
+	refreshed, err := h.provider.token(ctx, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": tokens.RefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The tokens which are not issued again are kept
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = tokens.RefreshToken
+	}
+	if refreshed.IDToken == "" {
+		refreshed.IDToken = tokens.IDToken
+	} else if h.openID {
+		claims, err := h.provider.verifyIDToken(ctx, refreshed.IDToken, "")
+		if err != nil {
+			return nil, err
+		}
+		// The new ID token must be for the same user
+		if previous, err := jwt.ParseUnverified(tokens.IDToken); err == nil && previous.Subject != claims.Subject {
+			return nil, fmt.Errorf("%w: subject changed", ErrInvalidIDToken)
+		}
+	}
+	return refreshed, nil
+}
+
+// login redirects a request to the authorization endpoint of the provider.
+func (h *handler) login(c fiber.Ctx, sess sessionData, release func(save bool) error) error {
+	// Only navigations may be redirected to the provider
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		if err := release(true); err != nil {
+			return h.cfg.ErrorHandler(c, err)
+		}
+		return h.cfg.ErrorHandler(c, ErrUnauthenticated)
+	}
+
+	doc, err := h.provider.discovery(c)
+	if err != nil {
+		_ = release(false) //nolint:errcheck // Nothing to save
+		return h.cfg.ErrorHandler(c, err)
+	}
+
+	now := time.Now()
+	login := pendingLogin{
+		State:    rand.Text(),
+		Nonce:    rand.Text(),
+		Verifier: rand.Text() + rand.Text(),
+		ReturnTo: returnTo(c),
+		Expiry:   now.Add(h.cfg.LoginTimeout),
+	}
+	logins, _ := sess.Get(sessionLoginsKey).(pendingLogins) //nolint:errcheck // The logins may not exist
+	sess.Set(sessionLoginsKey, logins.add(login, now))
+	if err := release(true); err != nil {
+		return h.cfg.ErrorHandler(c, err)
+	}
+
+	challenge := sha256.Sum256([]byte(login.Verifier))
+	query := url.Values{}
+	for key, value := range h.cfg.AuthParams {
+		query.Set(key, value)
+	}
+	query.Set("response_type", "code")
+	query.Set("client_id", h.cfg.ClientID)
+	query.Set("redirect_uri", h.cfg.RedirectURL)
+	query.Set("scope", strings.Join(h.cfg.Scopes, " "))
+	query.Set("state", login.State)
+	if h.openID {
+		query.Set("nonce", login.Nonce)
+	}
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	return c.Redirect().To(withQuery(doc.AuthorizationEndpoint, query))
+}
+
+// callback completes a login: it exchanges the authorization code of the
+// provider for tokens, and stores them in a new session.
+func (h *handler) callback(c fiber.Ctx) error {
+	sess, release, err := h.sessions.get(c)
+	if err != nil {
+		return h.cfg.ErrorHandler(c, err)
+	}
+
+	// The login is completed once
+	logins, _ := sess.Get(sessionLoginsKey).(pendingLogins) //nolint:errcheck // The logins may not exist
+	login, rest, ok := logins.take(c.Query("state"), time.Now())
+	if len(rest) > 0 {
+		sess.Set(sessionLoginsKey, rest)
+	} else {
+		sess.Delete(sessionLoginsKey)
+	}
+	err = h.exchange(c, sess, login, ok)
+	if releaseErr := release(true); err == nil {
+		err = releaseErr
+	}
+	if err != nil {
+		return h.cfg.ErrorHandler(c, err)
+	}
+	return c.Redirect().To(login.ReturnTo)
+}
+
+// exchange exchanges the authorization code of a login for tokens.
+func (h *handler) exchange(c fiber.Ctx, sess sessionData, login pendingLogin, ok bool) error {
+	if !ok {
+		return ErrInvalidState
+	}
+	if code := c.Query("error"); code != "" {
+		return fmt.Errorf("%w: %s", ErrAuthorization, code)
+	}
+	code := c.Query("code")
+	if code == "" {
+		return fmt.Errorf("%w: no authorization code", ErrAuthorization)
+	}
+
+	tokens, err := h.provider.token(c, map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  h.cfg.RedirectURL,
+		"code_verifier": login.Verifier,
+	})
+	if err != nil {
+		return err
+	}
+
+	var subject string
+	if h.openID {
+		if tokens.IDToken == "" {
+			return fmt.Errorf("%w: no ID token", ErrInvalidIDToken)
+		}
+		claims, err := h.provider.verifyIDToken(c, tokens.IDToken, login.Nonce)
+		if err != nil {
+			return err
+		}
+		subject = claims.Subject
+	}
+
+	// The logged in user gets a new session ID, against session fixation
+	if err := sess.Regenerate(); err != nil {
+		return fmt.Errorf("oidc: failed to regenerate session: %w", err)
+	}
+	sess.Set(sessionTokensKey, *tokens)
+	if subject != "" {
+		sess.SetPrincipal(subject)
+	}
+	return nil
+}
+
+// logout destroys the session, and redirects to the end session endpoint of
+// the provider, or to the PostLogoutRedirectURL.
+func (h *handler) logout(c fiber.Ctx) error {
+	sess, release, err := h.sessions.get(c)
+	if err != nil {
+		return h.cfg.ErrorHandler(c, err)
+	}
+	tokens, _ := sess.Get(sessionTokensKey).(Tokens) //nolint:errcheck // The user may not be logged in
+	err = sess.Destroy()
+	_ = release(false) //nolint:errcheck // The session is destroyed
+	if err != nil {
+		return h.cfg.ErrorHandler(c, fmt.Errorf("oidc: failed to destroy session: %w", err))
+	}
+
+	location := h.cfg.PostLogoutRedirectURL
+	if doc, err := h.provider.discovery(c); err == nil && doc.EndSessionEndpoint != "" {
+		// RP-Initiated Logout 1.0
+		query := url.Values{"client_id": {h.cfg.ClientID}}
+		if tokens.IDToken != "" {
+			query.Set("id_token_hint", tokens.IDToken)
+		}
+		if u, err := url.Parse(location); err == nil && u.IsAbs() {
+			query.Set("post_logout_redirect_uri", location)
+		}
+		location = withQuery(doc.EndSessionEndpoint, query)
+	}
+	return c.Redirect().To(location)
+}
+
+// returnTo returns the local URL of a request, to return to after the login.
+func returnTo(c fiber.Ctx) string {
+	location := c.Path()
+	if query := c.Request().URI().QueryString(); len(query) > 0 {
+		location += "?" + string(query)
+	}
+	// Paths such as //example.com would be redirected to another host
+	if !strings.HasPrefix(location, "/") || strings.HasPrefix(location, "//") || strings.HasPrefix(location, "/\\") {
+		return "/"
+	}
+	return location
+}
+
+// withQuery returns an endpoint URL with more query parameters.
+func withQuery(endpoint string, query url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+	return endpoint + "?" + query.Encode()
+}
@@ -0,0 +1,640 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+const (
+	testIssuer      = "http://idp.example.com"
+	testClientID    = "client-1"
+	testSecret      = "secret:1"
+	testRedirectURL = "https://app.example.com/callback"
+)
+
+// testCode is an authorization code issued by the test provider.
+type testCode struct {
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// testProvider is an OpenID provider whose authorization endpoint is
+// simulated by authorize.
+type testProvider struct {
+	codes          map[string]testCode
+	claims         map[string]any
+	key            ed25519.PrivateKey
+	requests       []url.Values
+	authorization  []string
+	expiresIn      int64
+	issued         int
+	refreshFails   bool
+	refreshIDToken bool
+	mu             sync.Mutex
+}
+
+// newTestProvider starts a provider, and returns a client sending its
+// requests to it.
+func newTestProvider(t *testing.T) (*testProvider, *client.Client) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p := &testProvider{key: key, codes: map[string]testCode{}, expiresIn: 3600}
+
+	app := fiber.New()
+	app.Get("/.well-known/openid-configuration", func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"issuer":                 testIssuer,
+			"authorization_endpoint": testIssuer + "/authorize",
+			"token_endpoint":         testIssuer + "/token",
+			"jwks_uri":               testIssuer + "/jwks",
+			"end_session_endpoint":   testIssuer + "/logout",
+		})
+	})
+	app.Get("/jwks", func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"keys": []fiber.Map{{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey)), //nolint:forcetypeassert,errcheck // Test key
+		}}})
+	})
+	app.Post("/token", p.token)
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped in cleanup
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	cc := client.New()
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return ln.Dial()
+	})
+	return p, cc
+}
+
+func (p *testProvider) token(c fiber.Ctx) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	form := url.Values{}
+	for key, value := range c.Request().PostArgs().All() {
+		form.Add(string(key), string(value))
+	}
+	p.requests = append(p.requests, form)
+	p.authorization = append(p.authorization, c.Get(fiber.HeaderAuthorization))
+
+	switch form.Get("grant_type") {
+	case "authorization_code":
+		code, ok := p.codes[form.Get("code")]
+		delete(p.codes, form.Get("code"))
+		challenge := sha256.Sum256([]byte(form.Get("code_verifier")))
+		if !ok || code.challenge != base64.RawURLEncoding.EncodeToString(challenge[:]) || code.redirectURI != form.Get("redirect_uri") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_grant"})
+		}
+		return c.JSON(p.tokens(code.nonce, true))
+	case "refresh_token":
+		if p.refreshFails || form.Get("refresh_token") != "refresh-1" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_grant"})
+		}
+		tokens := p.tokens("", false)
+		if p.refreshIDToken {
+			tokens["id_token"] = p.idToken("")
+		}
+		return c.JSON(tokens)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "unsupported_grant_type"})
+	}
+}
+
+// tokens returns a token response, with a refresh token and an ID token
+// for the authorization code grant.
+func (p *testProvider) tokens(nonce string, login bool) fiber.Map {
+	p.issued++
+	tokens := fiber.Map{
+		"access_token": "access-" + strconv.Itoa(p.issued),
+		"token_type":   "Bearer",
+		"expires_in":   p.expiresIn,
+	}
+	if login {
+		tokens["refresh_token"] = "refresh-1"
+		tokens["id_token"] = p.idToken(nonce)
+	}
+	return tokens
+}
+
+// idToken returns an ID token for alice, with the claims of the provider.
+func (p *testProvider) idToken(nonce string) string {
+	claims := map[string]any{
+		"iss":   testIssuer,
+		"aud":   testClientID,
+		"sub":   "alice",
+		"email": "alice@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	for key, value := range p.claims {
+		claims[key] = value
+	}
+	return p.sign(claims)
+}
+
+// sign returns an ID token of claims.
+func (p *testProvider) sign(claims map[string]any) string {
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			panic(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	input := encode(map[string]any{"alg": "EdDSA", "typ": "JWT"}) + "." + encode(claims)
+	return input + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(p.key, []byte(input)))
+}
+
+// authorize logs in at the authorization endpoint the location redirects to,
+// and returns the callback request the user is redirected to.
+func (p *testProvider) authorize(t *testing.T, location string) string {
+	t.Helper()
+
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	require.Equal(t, testIssuer+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	q := u.Query()
+	require.Equal(t, "code", q.Get("response_type"))
+	require.Equal(t, testClientID, q.Get("client_id"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+	require.NotEmpty(t, q.Get("state"))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	code := rand.Text()
+	p.codes[code] = testCode{nonce: q.Get("nonce"), challenge: q.Get("code_challenge"), redirectURI: q.Get("redirect_uri")}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	require.NoError(t, err)
+	return redirect.Path + "?" + url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+}
+
+// newTestApp returns an app logging in at the provider, whose routes
+// respond with the subject and the access token.
+func newTestApp(config Config, middlewares ...fiber.Handler) *fiber.App {
+	app := fiber.New()
+	for _, m := range middlewares {
+		app.Use(m)
+	}
+	app.Use(New(config))
+	app.All("/*", func(c fiber.Ctx) error {
+		claims := ClaimsFromContext(c)
+		tokens := TokensFromContext(c)
+		if claims == nil || tokens == nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(claims.Subject + " " + tokens.AccessToken)
+	})
+	return app
+}
+
+// browser sends requests to an app with the session cookie it was given.
+type browser struct {
+	app    *fiber.App
+	cookie string
+}
+
+func (b *browser) do(t *testing.T, method, target string) (status int, location, body string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, http.NoBody)
+	if b.cookie != "" {
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: b.cookie})
+	}
+	resp, err := b.app.Test(req)
+	require.NoError(t, err)
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "session_id" {
+			b.cookie = cookie.Value
+			if cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && cookie.Expires.Before(time.Now())) {
+				b.cookie = ""
+			}
+		}
+	}
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderLocation), string(data)
+}
+
+// login logs the browser in, from a request to target.
+func (b *browser) login(t *testing.T, p *testProvider, target string) {
+	t.Helper()
+
+	status, location, _ := b.do(t, fiber.MethodGet, target)
+	require.Equal(t, fiber.StatusSeeOther, status)
+	status, location, _ = b.do(t, fiber.MethodGet, p.authorize(t, location))
+	require.Equal(t, fiber.StatusSeeOther, status)
+	require.Equal(t, target, location)
+}
+
+func testConfig(store *session.Store, cc *client.Client) Config {
+	return Config{
+		Session:      store,
+		Client:       cc,
+		Issuer:       testIssuer,
+		ClientID:     testClientID,
+		ClientSecret: testSecret,
+		RedirectURL:  testRedirectURL,
+		LogoutPath:   "/logout",
+	}
+}
+
+func Test_OIDC_Login(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	store := session.NewStore()
+	b := &browser{app: newTestApp(testConfig(store, cc))}
+
+	// The user is redirected to the provider
+	status, location, _ := b.do(t, fiber.MethodGet, "/profile?tab=1")
+	require.Equal(t, fiber.StatusSeeOther, status)
+	q, err := url.ParseQuery(location[len(testIssuer+"/authorize?"):])
+	require.NoError(t, err)
+	require.Equal(t, testRedirectURL, q.Get("redirect_uri"))
+	require.Equal(t, "openid profile email", q.Get("scope"))
+	require.NotEmpty(t, q.Get("nonce"))
+	require.NotEmpty(t, q.Get("code_challenge"))
+	anonymous := b.cookie
+	require.NotEmpty(t, anonymous)
+
+	// The callback exchanges the code, and returns to the first page with
+	// a new session
+	callback := p.authorize(t, location)
+	status, location, _ = b.do(t, fiber.MethodGet, callback)
+	require.Equal(t, fiber.StatusSeeOther, status)
+	require.Equal(t, "/profile?tab=1", location)
+	require.NotEqual(t, anonymous, b.cookie)
+
+	status, _, body := b.do(t, fiber.MethodGet, "/profile")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice access-1", body)
+
+	// The client authenticates with HTTP Basic authentication
+	require.Len(t, p.requests, 1)
+	require.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("client-1:secret%3A1")), p.authorization[0])
+	require.Empty(t, p.requests[0].Get("client_id"))
+
+	// The session is indexed by the subject
+	sessions, err := store.ListSessions(context.Background(), "alice")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	// The callback cannot be replayed
+	status, _, _ = b.do(t, fiber.MethodGet, callback)
+	require.Equal(t, fiber.StatusUnauthorized, status)
+	status, _, body = b.do(t, fiber.MethodGet, "/profile")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice access-1", body)
+
+	// The old session is not logged in
+	status, _, _ = (&browser{app: b.app, cookie: anonymous}).do(t, fiber.MethodGet, "/profile")
+	require.Equal(t, fiber.StatusSeeOther, status)
+}
+
+func Test_OIDC_Login_SessionMiddleware(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	sessions, store := session.NewWithStore()
+	config := testConfig(store, cc)
+	config.ClientSecret = ""
+	config.Scopes = []string{"openid"}
+	b := &browser{app: newTestApp(config, sessions)}
+
+	b.login(t, p, "/")
+	status, _, body := b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice access-1", body)
+
+	// Public clients send their client ID
+	require.Empty(t, p.authorization[0])
+	require.Equal(t, testClientID, p.requests[0].Get("client_id"))
+}
+
+func Test_OIDC_ConcurrentLogins(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	b := &browser{app: newTestApp(testConfig(session.NewStore(), cc))}
+
+	// Logins started in several tabs may be completed in any order
+	_, first, _ := b.do(t, fiber.MethodGet, "/first")
+	_, second, _ := b.do(t, fiber.MethodGet, "/second")
+	status, location, _ := b.do(t, fiber.MethodGet, p.authorize(t, second))
+	require.Equal(t, fiber.StatusSeeOther, status)
+	require.Equal(t, "/second", location)
+	status, location, _ = b.do(t, fiber.MethodGet, p.authorize(t, first))
+	require.Equal(t, fiber.StatusSeeOther, status)
+	require.Equal(t, "/first", location)
+}
+
+func Test_OIDC_Callback_Errors(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	var gotErr error
+	config := testConfig(session.NewStore(), cc)
+	config.ErrorHandler = func(c fiber.Ctx, err error) error {
+		gotErr = err
+		return ConfigDefault.ErrorHandler(c, err)
+	}
+	app := newTestApp(config)
+
+	for _, tc := range []struct {
+		err      error
+		claims   map[string]any
+		callback func(callback string) string
+		name     string
+	}{
+		{name: "unknown state", err: ErrInvalidState, callback: func(string) string {
+			return "/callback?code=1&state=unknown"
+		}},
+		{name: "no state", err: ErrInvalidState, callback: func(string) string {
+			return "/callback?code=1"
+		}},
+		{name: "denied", err: ErrAuthorization, callback: func(callback string) string {
+			u, _ := url.Parse(callback) //nolint:errcheck // Test URL
+			return "/callback?error=access_denied&state=" + u.Query().Get("state")
+		}},
+		{name: "no code", err: ErrAuthorization, callback: func(callback string) string {
+			u, _ := url.Parse(callback) //nolint:errcheck // Test URL
+			return "/callback?state=" + u.Query().Get("state")
+		}},
+		{name: "invalid code", err: ErrTokenRequest, callback: func(callback string) string {
+			u, _ := url.Parse(callback) //nolint:errcheck // Test URL
+			return "/callback?code=invalid&state=" + u.Query().Get("state")
+		}},
+		{name: "invalid nonce", err: ErrInvalidIDToken, claims: map[string]any{"nonce": "other"}},
+		{name: "invalid audience", err: ErrInvalidIDToken, claims: map[string]any{"aud": "client-2"}},
+		{name: "invalid authorized party", err: ErrInvalidIDToken, claims: map[string]any{"aud": []string{testClientID, "client-2"}}},
+		{name: "invalid issuer", err: ErrInvalidIDToken, claims: map[string]any{"iss": "http://other.example.com"}},
+		{name: "expired", err: ErrInvalidIDToken, claims: map[string]any{"exp": time.Now().Add(-time.Minute).Unix()}},
+		{name: "no subject", err: ErrInvalidIDToken, claims: map[string]any{"sub": ""}},
+	} {
+		p.mu.Lock()
+		p.claims = tc.claims
+		p.mu.Unlock()
+
+		b := &browser{app: app}
+		_, location, _ := b.do(t, fiber.MethodGet, "/")
+		callback := p.authorize(t, location)
+		if tc.callback != nil {
+			callback = tc.callback(callback)
+		}
+		gotErr = nil
+		status, _, body := b.do(t, fiber.MethodGet, callback)
+		require.Equal(t, fiber.StatusUnauthorized, status, tc.name)
+		require.Equal(t, "Unauthorized", body, tc.name)
+		require.ErrorIs(t, gotErr, tc.err, tc.name)
+
+		// The user is not logged in
+		status, _, _ = b.do(t, fiber.MethodGet, "/")
+		require.Equal(t, fiber.StatusSeeOther, status, tc.name)
+	}
+}
+
+func Test_OIDC_Refresh(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	p.expiresIn = 30
+	b := &browser{app: newTestApp(testConfig(session.NewStore(), cc))}
+	b.login(t, p, "/")
+
+	// The access token expires within RefreshBefore, so it is refreshed
+	status, _, body := b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice access-2", body)
+	require.Equal(t, "refresh_token", p.requests[1].Get("grant_type"))
+	status, _, body = b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice access-3", body)
+
+	// The access token is used while it is valid, when it cannot be
+	// refreshed
+	p.mu.Lock()
+	p.refreshFails = true
+	p.mu.Unlock()
+	status, _, body = b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "alice access-3", body)
+}
+
+func Test_OIDC_Refresh_Expired(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	p.expiresIn = 1
+	p.refreshFails = true
+	config := testConfig(session.NewStore(), cc)
+	config.RefreshBefore = time.Millisecond
+	b := &browser{app: newTestApp(config)}
+	b.login(t, p, "/")
+
+	status, _, _ := b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusOK, status)
+
+	// The session is logged out once its access token expires
+	time.Sleep(1100 * time.Millisecond)
+	status, location, _ := b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusSeeOther, status)
+	require.Contains(t, location, testIssuer+"/authorize?")
+}
+
+func Test_OIDC_Unauthenticated(t *testing.T) {
+	t.Parallel()
+
+	_, cc := newTestProvider(t)
+	b := &browser{app: newTestApp(testConfig(session.NewStore(), cc))}
+
+	// Only navigations are redirected to the login
+	status, _, body := b.do(t, fiber.MethodPost, "/api/items")
+	require.Equal(t, fiber.StatusUnauthorized, status)
+	require.Equal(t, "Unauthorized", body)
+	status, _, _ = b.do(t, fiber.MethodHead, "/")
+	require.Equal(t, fiber.StatusSeeOther, status)
+
+	// The discovery document cannot be fetched
+	app := newTestApp(Config{
+		Session:     session.NewStore(),
+		Client:      cc,
+		Issuer:      "http://idp.example.com/missing",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	})
+	status, _, _ = (&browser{app: app}).do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusInternalServerError, status)
+}
+
+func Test_OIDC_Logout(t *testing.T) {
+	t.Parallel()
+
+	p, cc := newTestProvider(t)
+	config := testConfig(session.NewStore(), cc)
+	config.PostLogoutRedirectURL = "https://app.example.com/bye"
+	b := &browser{app: newTestApp(config)}
+	b.login(t, p, "/")
+	loggedIn := b.cookie
+
+	// The user is logged out at the provider too
+	status, location, _ := b.do(t, fiber.MethodPost, "/logout")
+	require.Equal(t, fiber.StatusSeeOther, status)
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	require.Equal(t, "/logout", u.Path)
+	require.Equal(t, testClientID, u.Query().Get("client_id"))
+	require.Equal(t, "https://app.example.com/bye", u.Query().Get("post_logout_redirect_uri"))
+	require.NotEmpty(t, u.Query().Get("id_token_hint"))
+	require.Empty(t, b.cookie)
+
+	status, _, _ = (&browser{app: b.app, cookie: loggedIn}).do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusSeeOther, status)
+}
+
+func Test_OIDC_DiscoveryFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "openid-configuration.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"issuer": "https://idp.example.com",
+		"authorization_endpoint": "https://idp.example.com/authorize?tenant=1",
+		"token_endpoint": "https://idp.example.com/token"
+	}`), 0o600))
+
+	config := Config{
+		Session:       session.NewStore(),
+		Client:        client.New(),
+		Issuer:        "https://idp.example.com",
+		DiscoveryFile: path,
+		ClientID:      testClientID,
+		RedirectURL:   testRedirectURL,
+		LogoutPath:    "/logout",
+		Scopes:        []string{"read"},
+		AuthParams:    map[string]string{"prompt": "login", "state": "ignored"},
+	}
+	b := &browser{app: newTestApp(config)}
+
+	status, location, _ := b.do(t, fiber.MethodGet, "/")
+	require.Equal(t, fiber.StatusSeeOther, status)
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	require.Equal(t, "1", u.Query().Get("tenant"))
+	require.Equal(t, "login", u.Query().Get("prompt"))
+	require.Equal(t, "read", u.Query().Get("scope"))
+	require.NotEqual(t, "ignored", u.Query().Get("state"))
+	require.Empty(t, u.Query().Get("nonce"))
+
+	// Without an end session endpoint, the logout redirects to the app
+	status, location, _ = b.do(t, fiber.MethodGet, "/logout")
+	require.Equal(t, fiber.StatusSeeOther, status)
+	require.Equal(t, "/", location)
+
+	// Invalid discovery documents
+	require.NoError(t, os.WriteFile(path, []byte(`{"issuer": "https://other.example.com"}`), 0o600))
+	require.Panics(t, func() {
+		New(config)
+	})
+	config.DiscoveryFile = filepath.Join(t.TempDir(), "missing.json")
+	require.Panics(t, func() {
+		New(config)
+	})
+}
+
+func Test_OIDC_ReturnTo(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/*", func(c fiber.Ctx) error {
+		return c.SendString(returnTo(c))
+	})
+
+	for target, expected := range map[string]string{
+		"/":                    "/",
+		"/a/b?c=d&e":           "/a/b?c=d&e",
+		"//evil.example.com":   "/",
+		"/%5Cevil.example.com": "/%5Cevil.example.com",
+	} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, http.NoBody))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(body), target)
+	}
+}
+
+func Test_OIDC_Next(t *testing.T) {
+	t.Parallel()
+
+	config := testConfig(session.NewStore(), client.New())
+	config.Next = func(_ fiber.Ctx) bool {
+		return true
+	}
+	app := fiber.New()
+	app.Use(New(config))
+	app.Get("/", func(c fiber.Ctx) error {
+		if TokensFromContext(c) != nil || ClaimsFromContext(c) != nil {
+			return errors.New("unexpected tokens")
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
+
+func Test_OIDC_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	const required = "fiber: oidc middleware requires a Session, an Issuer, a ClientID and a RedirectURL"
+	require.PanicsWithValue(t, required, func() {
+		New()
+	})
+	require.PanicsWithValue(t, required, func() {
+		New(Config{Issuer: testIssuer, ClientID: testClientID, RedirectURL: testRedirectURL})
+	})
+	require.PanicsWithValue(t, "fiber: oidc RedirectURL must be an absolute URL", func() {
+		New(Config{Session: session.NewStore(), Issuer: testIssuer, ClientID: testClientID, RedirectURL: "/callback"})
+	})
+	require.PanicsWithValue(t, "fiber: oidc clock skew must not be negative", func() {
+		New(Config{Session: session.NewStore(), Issuer: testIssuer, ClientID: testClientID, RedirectURL: testRedirectURL, ClockSkew: -time.Second})
+	})
+}
@@ -0,0 +1,197 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/gofiber/fiber/v3/middleware/jwt"
+)
+
+// discovery is the discovery document of a provider, as defined by OpenID
+// Connect Discovery 1.0.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// tokenResponse is the response of the token endpoint (RFC 6749, section 5).
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ExpiresIn        int64  `json:"expires_in"`
+}
+
+// provider loads the discovery document of the provider, sends it the token
+// requests, and verifies its ID tokens.
+type provider struct {
+	cfg      *Config
+	doc      *discovery
+	verifier *jwt.Verifier
+	mu       sync.Mutex
+}
+
+// newProvider creates a provider, reading the local discovery document of the
+// config if it has one.
+func newProvider(cfg *Config) *provider {
+	p := &provider{cfg: cfg}
+	if cfg.DiscoveryFile != "" {
+		data, err := os.ReadFile(cfg.DiscoveryFile)
+		if err != nil {
+			panic(fmt.Sprintf("fiber: oidc failed to read discovery document: %v", err))
+		}
+		if err := p.setDiscovery(data); err != nil {
+			panic(fmt.Sprintf("fiber: %v", err))
+		}
+	}
+	return p
+}
+
+// discovery returns the discovery document, fetching it on the first call.
+// A document which cannot be fetched is fetched again on the next call.
+func (p *provider) discovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.doc != nil {
+		return p.doc, nil
+	}
+
+	resp, err := p.cfg.Client.Get(p.cfg.DiscoveryURL, client.Config{Ctx: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("oidc: failed to fetch discovery document: %w", err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != fiber.StatusOK {
+		return nil, fmt.Errorf("oidc: failed to fetch discovery document: status %d", resp.StatusCode())
+	}
+	if err := p.setDiscovery(resp.Body()); err != nil {
+		return nil, err
+	}
+	return p.doc, nil
+}
+
+// setDiscovery parses and checks a discovery document.
+func (p *provider) setDiscovery(data []byte) error {
+	var doc discovery
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("oidc: invalid discovery document: %w", err)
+	}
+	// The issuer of the document must be the one it was looked up for
+	if doc.Issuer != p.cfg.Issuer {
+		return fmt.Errorf("oidc: discovery document issuer %q does not match %q", doc.Issuer, p.cfg.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return errors.New("oidc: discovery document has no authorization or token endpoint")
+	}
+
+	if doc.JWKSURI != "" {
+		p.verifier = jwt.NewVerifier(jwt.Config{
+			KeySet: jwt.NewRemoteKeySet(jwt.RemoteKeySetConfig{
+				URL:    doc.JWKSURI,
+				Client: p.cfg.Client,
+			}),
+			Issuer:    p.cfg.Issuer,
+			Audience:  []string{p.cfg.ClientID},
+			ClockSkew: p.cfg.ClockSkew,
+		})
+	}
+	p.doc = &doc
+	return nil
+}
+
+// token sends a token request with the parameters of a grant, and returns
+// the tokens of the response.
+func (p *provider) token(ctx context.Context, grant map[string]string) (*Tokens, error) {
+	doc, err := p.discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header := map[string]string{fiber.HeaderAccept: fiber.MIMEApplicationJSON}
+	if p.cfg.ClientSecret != "" {
+		// The credentials are form-encoded first (RFC 6749, section 2.3.1)
+		credentials := url.QueryEscape(p.cfg.ClientID) + ":" + url.QueryEscape(p.cfg.ClientSecret)
+		header[fiber.HeaderAuthorization] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	} else {
+		grant["client_id"] = p.cfg.ClientID
+	}
+
+	now := time.Now()
+	resp, err := p.cfg.Client.Post(doc.TokenEndpoint, client.Config{Ctx: ctx, Header: header, FormData: grant})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenRequest, err)
+	}
+	defer resp.Close()
+
+	var tr tokenResponse
+	if err := json.Unmarshal(resp.Body(), &tr); err != nil && resp.StatusCode() == fiber.StatusOK {
+		return nil, fmt.Errorf("%w: invalid response: %w", ErrTokenRequest, err)
+	}
+	switch {
+	case tr.Error != "":
+		return nil, fmt.Errorf("%w: %s", ErrTokenRequest, tr.Error)
+	case resp.StatusCode() != fiber.StatusOK:
+		return nil, fmt.Errorf("%w: status %d", ErrTokenRequest, resp.StatusCode())
+	case tr.AccessToken == "":
+		return nil, fmt.Errorf("%w: no access token", ErrTokenRequest)
+	default:
+	}
+
+	tokens := &Tokens{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		IDToken:      tr.IDToken,
+	}
+	if tr.ExpiresIn > 0 {
+		tokens.Expiry = now.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return tokens, nil
+}
+
+// verifyIDToken verifies an ID token, and that its `nonce` claim is the
+// nonce of the login if it is not empty.
+func (p *provider) verifyIDToken(ctx context.Context, idToken, nonce string) (*jwt.Claims, error) {
+	if p.verifier == nil {
+		return nil, fmt.Errorf("%w: the provider has no JWK Set", ErrInvalidIDToken)
+	}
+	claims, err := p.verifier.Verify(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	var extra struct {
+		Nonce           string `json:"nonce"`
+		AuthorizedParty string `json:"azp"`
+	}
+	if err := claims.Decode(&extra); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	if nonce != "" && extra.Nonce != nonce {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrInvalidIDToken)
+	}
+	// A token for several audiences must be authorized for the client
+	if (len(claims.Audience) > 1 || extra.AuthorizedParty != "") && extra.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: invalid authorized party", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
@@ -0,0 +1,175 @@
+package oidc
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+// newTestServer starts an app, and returns a client sending its requests
+// to it.
+func newTestServer(t *testing.T, app *fiber.App) *client.Client {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped in cleanup
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	cc := client.New()
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return ln.Dial()
+	})
+	return cc
+}
+
+func Test_Provider_Discovery(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+	app := fiber.New()
+	app.Get("/.well-known/openid-configuration", func(c fiber.Ctx) error {
+		// The first fetch fails
+		if fetches.Add(1) == 1 {
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		return c.JSON(fiber.Map{
+			"issuer":                 testIssuer,
+			"authorization_endpoint": testIssuer + "/authorize",
+			"token_endpoint":         testIssuer + "/token",
+		})
+	})
+	app.Get("/other/.well-known/openid-configuration", func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"issuer": testIssuer, "authorization_endpoint": testIssuer + "/authorize", "token_endpoint": testIssuer + "/token"})
+	})
+	app.Get("/invalid/.well-known/openid-configuration", func(c fiber.Ctx) error {
+		return c.SendString("{")
+	})
+	app.Get("/incomplete/.well-known/openid-configuration", func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"issuer": testIssuer + "/incomplete"})
+	})
+	cc := newTestServer(t, app)
+
+	config := configDefault(Config{Session: session.NewStore(), Client: cc, Issuer: testIssuer, ClientID: testClientID, RedirectURL: testRedirectURL})
+	p := newProvider(&config)
+
+	_, err := p.discovery(context.Background())
+	require.ErrorContains(t, err, "status 503")
+
+	// The document is fetched again after a failure, and then cached
+	doc, err := p.discovery(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, testIssuer+"/token", doc.TokenEndpoint)
+	_, err = p.discovery(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), fetches.Load())
+
+	// Without a JWK Set, ID tokens cannot be verified
+	_, err = p.verifyIDToken(context.Background(), "a.b.c", "")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+
+	for issuer, expected := range map[string]string{
+		testIssuer + "/other":      "does not match",
+		testIssuer + "/invalid":    "invalid discovery document",
+		testIssuer + "/incomplete": "no authorization or token endpoint",
+	} {
+		config := configDefault(Config{Session: session.NewStore(), Client: cc, Issuer: issuer, ClientID: testClientID, RedirectURL: testRedirectURL})
+		_, err := newProvider(&config).discovery(context.Background())
+		require.ErrorContains(t, err, expected, issuer)
+	}
+}
+
+func Test_Provider_Token(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/.well-known/openid-configuration", func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"issuer":                 testIssuer,
+			"authorization_endpoint": testIssuer + "/authorize",
+			"token_endpoint":         testIssuer + "/token",
+		})
+	})
+	app.Post("/token", func(c fiber.Ctx) error {
+		switch c.FormValue("code") {
+		case "ok":
+			return c.JSON(fiber.Map{"access_token": "access", "token_type": "Bearer", "expires_in": 60})
+		case "no-expiry":
+			return c.JSON(fiber.Map{"access_token": "access", "token_type": "Bearer"})
+		case "empty":
+			return c.JSON(fiber.Map{"token_type": "Bearer"})
+		case "invalid":
+			return c.SendString("access_token=access")
+		case "error":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_grant", "error_description": "expired"})
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	})
+	cc := newTestServer(t, app)
+
+	config := configDefault(Config{Session: session.NewStore(), Client: cc, Issuer: testIssuer, ClientID: testClientID, RedirectURL: testRedirectURL})
+	p := newProvider(&config)
+
+	tokens, err := p.token(context.Background(), map[string]string{"code": "ok"})
+	require.NoError(t, err)
+	require.Equal(t, "access", tokens.AccessToken)
+	require.Equal(t, "Bearer", tokens.TokenType)
+	require.WithinDuration(t, time.Now().Add(time.Minute), tokens.Expiry, 5*time.Second)
+
+	tokens, err = p.token(context.Background(), map[string]string{"code": "no-expiry"})
+	require.NoError(t, err)
+	require.True(t, tokens.Expiry.IsZero())
+
+	for code, expected := range map[string]string{
+		"empty":   "no access token",
+		"invalid": "invalid response",
+		"error":   "invalid_grant",
+		"down":    "status 500",
+	} {
+		_, err := p.token(context.Background(), map[string]string{"code": code})
+		require.ErrorIs(t, err, ErrTokenRequest, code)
+		require.ErrorContains(t, err, expected, code)
+	}
+}
+
+func Test_Handler_Refresh(t *testing.T) {
+	t.Parallel()
+
+	tp, cc := newTestProvider(t)
+	tp.refreshIDToken = true
+	config := configDefault(testConfig(session.NewStore(), cc))
+	h := &handler{cfg: &config, provider: newProvider(&config), openID: true}
+	previous := &Tokens{AccessToken: "access-0", RefreshToken: "refresh-1", IDToken: tp.sign(map[string]any{"sub": "alice"})}
+
+	// The new ID token is verified, and the refresh token is kept
+	refreshed, err := h.refresh(context.Background(), previous)
+	require.NoError(t, err)
+	require.Equal(t, "access-1", refreshed.AccessToken)
+	require.Equal(t, "refresh-1", refreshed.RefreshToken)
+	require.NotEqual(t, previous.IDToken, refreshed.IDToken)
+
+	// The new ID token must be valid, and for the same user
+	for _, claims := range []map[string]any{{"sub": "bob"}, {"aud": "client-2"}} {
+		tp.mu.Lock()
+		tp.claims = claims
+		tp.mu.Unlock()
+		_, err = h.refresh(context.Background(), previous)
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+	}
+
+	_, err = h.refresh(context.Background(), &Tokens{AccessToken: "access-0"})
+	require.ErrorIs(t, err, ErrUnauthenticated)
+}
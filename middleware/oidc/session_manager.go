@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+type sessionKeyType int
+
+const (
+	sessionTokensKey sessionKeyType = iota
+	sessionLoginsKey
+)
+
+// maxPendingLogins is the most logins a session may have in progress at once,
+// such as in several tabs.
+const maxPendingLogins = 4
+
+// pendingLogin is a login in progress at the provider.
+type pendingLogin struct {
+	Expiry   time.Time
+	State    string
+	Nonce    string
+	Verifier string
+	ReturnTo string
+}
+
+// pendingLogins are the logins in progress of a session.
+type pendingLogins []pendingLogin
+
+// add returns the logins with a new one, dropping the expired and the oldest
+// ones.
+func (l pendingLogins) add(login pendingLogin, now time.Time) pendingLogins {
+	kept := make(pendingLogins, 0, maxPendingLogins)
+	for _, p := range l {
+		if now.Before(p.Expiry) {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) >= maxPendingLogins {
+		kept = kept[len(kept)-maxPendingLogins+1:]
+	}
+	return append(kept, login)
+}
+
+// take returns the unexpired login of a state, and the logins without it.
+func (l pendingLogins) take(state string, now time.Time) (pendingLogin, pendingLogins, bool) {
+	for i, p := range l {
+		if p.State == state && state != "" {
+			rest := append(l[:i:i], l[i+1:]...)
+			return p, rest, now.Before(p.Expiry)
+		}
+	}
+	return pendingLogin{}, l, false
+}
+
+// sessionData is the session of a request, loaded by the session middleware
+// or from the store.
+type sessionData interface {
+	Get(key any) any
+	Set(key, val any)
+	Delete(key any)
+	Regenerate() error
+	Destroy() error
+	SetPrincipal(principal string)
+}
+
+type sessionManager struct {
+	store *session.Store
+}
+
+func newSessionManager(s *session.Store) *sessionManager {
+	// Register the types stored in the session
+	s.RegisterType(sessionKeyType(0))
+	s.RegisterType(Tokens{})
+	s.RegisterType(pendingLogins{})
+
+	return &sessionManager{store: s}
+}
+
+// get returns the session of a request, and a function releasing it, which
+// saves it first if save is true. A session loaded by the session middleware
+// is saved by the middleware instead.
+func (m *sessionManager) get(c fiber.Ctx) (sessionData, func(save bool) error, error) {
+	if sess := session.FromContext(c); sess != nil {
+		return sess, func(bool) error { return nil }, nil
+	}
+
+	sess, err := m.store.Get(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc: failed to get session: %w", err)
+	}
+	return sess, func(save bool) error {
+		defer sess.Release()
+		if !save {
+			return nil
+		}
+		if err := sess.Save(); err != nil {
+			return fmt.Errorf("oidc: failed to save session: %w", err)
+		}
+		return nil
+	}, nil
+}
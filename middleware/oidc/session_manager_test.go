@@ -0,0 +1,42 @@
+package oidc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PendingLogins(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	var logins pendingLogins
+	logins = logins.add(pendingLogin{State: "expired", Expiry: now.Add(-time.Second)}, now.Add(-time.Minute))
+	for i := range maxPendingLogins {
+		logins = logins.add(pendingLogin{State: strconv.Itoa(i), Expiry: now.Add(time.Minute)}, now)
+	}
+
+	// The expired and the oldest logins are dropped
+	require.Len(t, logins, maxPendingLogins)
+	require.Equal(t, "0", logins[0].State)
+	logins = logins.add(pendingLogin{State: "new", Expiry: now.Add(time.Minute)}, now)
+	require.Len(t, logins, maxPendingLogins)
+	require.Equal(t, "1", logins[0].State)
+
+	// A login is taken once
+	login, rest, ok := logins.take("2", now)
+	require.True(t, ok)
+	require.Equal(t, "2", login.State)
+	require.Len(t, rest, maxPendingLogins-1)
+	_, _, ok = rest.take("2", now)
+	require.False(t, ok)
+	_, _, ok = rest.take("", now)
+	require.False(t, ok)
+
+	// Expired logins are taken, but not valid
+	_, rest, ok = rest.take("new", now.Add(2*time.Minute))
+	require.False(t, ok)
+	require.Len(t, rest, maxPendingLogins-2)
+}
@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Format is the serialization the document is served in.
+type Format int
+
+const (
+	// FormatJSON serves the document as JSON.
+	FormatJSON Format = iota
+	// FormatYAML serves the document as YAML.
+	FormatYAML
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// Filter reports whether a route belongs in the document. Middleware
+	// routes registered with Use are never documented.
+	//
+	// Optional. Default: nil (every route is documented)
+	Filter func(route *fiber.Route) bool
+
+	// Path is the request path the document is served on. The route serving
+	// it is left out of the document.
+	//
+	// Optional. Default: "/openapi.json"
+	Path string
+
+	// Title of the API, for the document's info object.
+	//
+	// Optional. Default: "Fiber API"
+	Title string
+
+	// Version of the API, for the document's info object. This is the
+	// version of your API, not of the OpenAPI specification.
+	//
+	// Optional. Default: "1.0.0"
+	Version string
+
+	// Description of the API, for the document's info object.
+	//
+	// Optional. Default: ""
+	Description string
+
+	// Servers lists the base URLs the API is reachable on.
+	//
+	// Optional. Default: nil
+	Servers []Server
+
+	// Format is the serialization the document is served in. A Path ending
+	// in ".yaml" or ".yml" selects FormatYAML when Format is left unset.
+	//
+	// Optional. Default: FormatJSON
+	Format Format
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:    nil,
+	Path:    "/openapi.json",
+	Title:   "Fiber API",
+	Version: "1.0.0",
+	Format:  FormatJSON,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Path == "" {
+		cfg.Path = ConfigDefault.Path
+	}
+	if cfg.Title == "" {
+		cfg.Title = ConfigDefault.Title
+	}
+	if cfg.Version == "" {
+		cfg.Version = ConfigDefault.Version
+	}
+	if cfg.Format == FormatJSON && (strings.HasSuffix(cfg.Path, ".yaml") || strings.HasSuffix(cfg.Path, ".yml")) {
+		cfg.Format = FormatYAML
+	}
+
+	return cfg
+}
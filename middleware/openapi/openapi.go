@@ -0,0 +1,372 @@
+package openapi
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+)
+
+const mimeApplicationYAML = "application/yaml"
+
+// New creates a new middleware handler that serves the OpenAPI document of
+// the app it is registered on.
+//
+// The document is generated on the first request for it, by which time the
+// routes of mounted sub-apps have been merged into the app's route table.
+// Routes registered later, through RebuildTree, are not picked up.
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	contentType := fiber.MIMEApplicationJSONCharsetUTF8
+	if cfg.Format == FormatYAML {
+		contentType = mimeApplicationYAML
+	}
+
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Only the document path belongs to this middleware
+		if c.Path() != cfg.Path {
+			return c.Next()
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		once.Do(func() {
+			doc := Generate(c.App(), cfg)
+			if cfg.Format == FormatYAML {
+				body, err = doc.YAML()
+			} else {
+				body, err = doc.JSON()
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.Send(body)
+	}
+}
+
+// Generate builds the OpenAPI document of the routes registered on app.
+//
+// Mounted sub-apps are merged into the route table when the app starts, so
+// their routes are only included once it has: call Generate from a handler or
+// an OnListen hook, or serve the document through New.
+func Generate(app *fiber.App, config ...Config) *Document {
+	cfg := configDefault(config...)
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       cfg.Title,
+			Description: cfg.Description,
+			Version:     cfg.Version,
+		},
+		Servers: cfg.Servers,
+		Paths:   make(map[string]*PathItem),
+	}
+	schemas := newSchemaRegistry()
+
+	routes := app.GetRoutes(true)
+
+	// Every GET route answers HEAD as well, through the route Fiber generates
+	// for it. Listing those would double each GET operation, so a HEAD route
+	// is only documented where there is no GET one on its path.
+	gets := make(map[string]struct{})
+	for i := range routes {
+		if routes[i].Method == fiber.MethodGet {
+			gets[routes[i].Path] = struct{}{}
+		}
+	}
+
+	// A method and path can be registered more than once, for different
+	// domains or by different mounted apps. The first route carrying a
+	// RouteDoc describes the operation, and otherwise the first route at all.
+	documented := make(map[*Operation]bool)
+
+	for i := range routes {
+		route := &routes[i]
+		if route.Path == cfg.Path {
+			continue
+		}
+		if cfg.Filter != nil && !cfg.Filter(route) {
+			continue
+		}
+		if route.Method == fiber.MethodHead {
+			if _, ok := gets[route.Path]; ok {
+				continue
+			}
+		}
+
+		path := route.FormatPath(formatParam)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+		}
+		slot := item.operation(route.Method)
+		if slot == nil {
+			// OpenAPI 3.1 has no field for CONNECT, QUERY or custom methods
+			continue
+		}
+		if existing := *slot; existing != nil && (documented[existing] || route.Doc == nil) {
+			continue
+		}
+
+		op := newOperation(route, schemas)
+		documented[op] = route.Doc != nil
+		*slot = op
+		doc.Paths[path] = item
+	}
+
+	if len(schemas.schemas) > 0 {
+		doc.Components = &Components{Schemas: schemas.schemas}
+	}
+
+	uniqueOperationIDs(doc)
+
+	return doc
+}
+
+// operationMethods are the methods of the operations of a path item, in the
+// order of its fields.
+var operationMethods = [...]string{
+	fiber.MethodGet, fiber.MethodPut, fiber.MethodPost, fiber.MethodDelete,
+	fiber.MethodOptions, fiber.MethodHead, fiber.MethodPatch, fiber.MethodTrace,
+}
+
+// uniqueOperationIDs makes the operation IDs of doc unique, as OpenAPI
+// requires. Route names are not unique, and a RouteDoc of a route registered
+// with All or Add describes several methods, so an ID taken by an earlier
+// operation, in the order of the paths and then of the methods, gets the
+// method as a suffix, and then a number.
+func uniqueOperationIDs(doc *Document) {
+	taken := make(map[string]bool)
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		item := doc.Paths[path]
+		for _, method := range operationMethods {
+			op := *item.operation(method)
+			if op == nil || op.OperationID == "" {
+				continue
+			}
+			id := op.OperationID
+			if taken[id] {
+				id = op.OperationID + "_" + strings.ToLower(method)
+				for n := 2; taken[id]; n++ {
+					id = op.OperationID + "_" + strings.ToLower(method) + strconv.Itoa(n)
+				}
+			}
+			taken[id] = true
+			op.OperationID = id
+		}
+	}
+}
+
+// operation returns the field of the path item holding the operation for
+// method, or nil when OpenAPI has none for it.
+func (p *PathItem) operation(method string) **Operation {
+	switch method {
+	case fiber.MethodGet:
+		return &p.Get
+	case fiber.MethodPut:
+		return &p.Put
+	case fiber.MethodPost:
+		return &p.Post
+	case fiber.MethodDelete:
+		return &p.Delete
+	case fiber.MethodOptions:
+		return &p.Options
+	case fiber.MethodHead:
+		return &p.Head
+	case fiber.MethodPatch:
+		return &p.Patch
+	case fiber.MethodTrace:
+		return &p.Trace
+	default:
+		return nil
+	}
+}
+
+func newOperation(route *fiber.Route, schemas *schemaRegistry) *Operation {
+	op := &Operation{OperationID: route.Name}
+
+	for _, param := range route.PathParams() {
+		op.Parameters = append(op.Parameters, pathParameter(param))
+	}
+
+	doc := route.Doc
+	if doc == nil {
+		return op
+	}
+
+	op.Tags = doc.Tags
+	op.Summary = doc.Summary
+	op.Description = doc.Description
+	op.Deprecated = doc.Deprecated
+	if doc.OperationID != "" {
+		op.OperationID = doc.OperationID
+	}
+
+	if doc.RequestBody != nil {
+		contentType := doc.RequestContentType
+		if contentType == "" {
+			contentType = fiber.MIMEApplicationJSON
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{contentType: {Schema: schemas.schemaOf(doc.RequestBody)}},
+		}
+	}
+
+	if len(doc.Responses) > 0 {
+		op.Responses = make(map[string]*Response, len(doc.Responses))
+		for code, resp := range doc.Responses {
+			out := &Response{Description: resp.Description}
+			if out.Description == "" {
+				out.Description = utils.StatusMessage(code)
+			}
+			if resp.Body != nil {
+				contentType := resp.ContentType
+				if contentType == "" {
+					contentType = fiber.MIMEApplicationJSON
+				}
+				out.Content = map[string]*MediaType{contentType: {Schema: schemas.schemaOf(resp.Body)}}
+			}
+			op.Responses[strconv.Itoa(code)] = out
+		}
+	}
+
+	return op
+}
+
+// paramName is the name a path parameter is documented under. Unnamed
+// wildcard and plus parameters are read back as "*1" and "+1", which is not a
+// name OpenAPI tooling accepts in a path template.
+func paramName(param fiber.RouteParam) string {
+	if param.Name == "" {
+		return param.Name
+	}
+	switch param.Name[0] {
+	case '*':
+		return "wildcard" + param.Name[1:]
+	case '+':
+		return "plus" + param.Name[1:]
+	default:
+		return param.Name
+	}
+}
+
+func formatParam(param fiber.RouteParam) string {
+	return "{" + paramName(param) + "}"
+}
+
+func pathParameter(param fiber.RouteParam) *Parameter {
+	p := &Parameter{
+		Name: paramName(param),
+		In:   "path",
+		// OpenAPI requires every path parameter; an optional one is
+		// documented as such in its description instead.
+		Required: true,
+		Schema:   paramSchema(param),
+	}
+	if param.Optional {
+		p.Description = "Optional: the route also matches without this segment."
+	}
+	return p
+}
+
+// paramSchema types a path parameter from the constraints declared on it.
+// Custom constraints leave it a string.
+func paramSchema(param fiber.RouteParam) *Schema {
+	schema := &Schema{Type: "string"}
+
+	for _, constraint := range param.Constraints {
+		var raw string
+		if len(constraint.Data) > 0 {
+			raw = constraint.Data[0]
+		}
+
+		switch strings.ToLower(constraint.Name) {
+		case fiber.ConstraintInt:
+			schema.Type = "integer"
+		case fiber.ConstraintBool:
+			schema.Type = "boolean"
+		case fiber.ConstraintFloat:
+			schema.Type = "number"
+		case fiber.ConstraintGUID:
+			schema.Format = "uuid"
+		case fiber.ConstraintDatetime:
+			switch fiber.RemoveEscapeChar(raw) {
+			case time.DateOnly:
+				schema.Format = "date"
+			case time.RFC3339, time.RFC3339Nano:
+				schema.Format = "date-time"
+			default:
+				// a layout JSON Schema has no format for
+			}
+		case fiber.ConstraintMinLenLower:
+			schema.MinLength = intArg(raw)
+		case fiber.ConstraintMaxLenLower:
+			schema.MaxLength = intArg(raw)
+		case fiber.ConstraintLen:
+			schema.MinLength = intArg(raw)
+			schema.MaxLength = intArg(raw)
+		case fiber.ConstraintBetweenLenLower:
+			low, high, _ := strings.Cut(raw, ",")
+			schema.MinLength = intArg(low)
+			schema.MaxLength = intArg(high)
+		case fiber.ConstraintMin:
+			schema.Type = "integer"
+			schema.Minimum = floatArg(raw)
+		case fiber.ConstraintMax:
+			schema.Type = "integer"
+			schema.Maximum = floatArg(raw)
+		case fiber.ConstraintRange:
+			low, high, _ := strings.Cut(raw, ",")
+			schema.Type = "integer"
+			schema.Minimum = floatArg(low)
+			schema.Maximum = floatArg(high)
+		case fiber.ConstraintRegex:
+			schema.Pattern = raw
+		default:
+			// alpha and custom constraints have no JSON Schema keyword
+		}
+	}
+
+	return schema
+}
+
+func intArg(raw string) *int {
+	n, err := strconv.Atoi(utils.TrimSpace(raw))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func floatArg(raw string) *float64 {
+	n, err := strconv.ParseFloat(utils.TrimSpace(raw), 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
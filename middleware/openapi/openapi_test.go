@@ -0,0 +1,422 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+)
+
+type testUser struct {
+	CreatedAt time.Time `json:"created_at"`
+	Manager   *testUser `json:"manager,omitempty"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty" validate:"required"`
+	Tags      []string  `json:"tags,omitempty"`
+	Secret    string    `json:"-"`
+	ID        int       `json:"id"`
+	Address   testAddress
+}
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testCreateUser struct {
+	Name string `json:"name"`
+}
+
+func okHandler(c fiber.Ctx) error {
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func fetchDocument(t *testing.T, app *fiber.App, path string) *Document {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, fiber.MIMEApplicationJSONCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var doc Document
+	require.NoError(t, json.Unmarshal(body, &doc))
+	return &doc
+}
+
+func Test_OpenAPI_Default(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/users", okHandler)
+	app.Post("/users", okHandler).Name("createUser")
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.Equal(t, Version, doc.OpenAPI)
+	require.Equal(t, "Fiber API", doc.Info.Title)
+	require.Equal(t, "1.0.0", doc.Info.Version)
+	require.Nil(t, doc.Components)
+
+	require.Len(t, doc.Paths, 1)
+	item := doc.Paths["/users"]
+	require.NotNil(t, item)
+	require.NotNil(t, item.Get)
+	require.NotNil(t, item.Post)
+	require.Equal(t, "createUser", item.Post.OperationID)
+	// The HEAD route generated for GET is not listed separately
+	require.Nil(t, item.Head)
+}
+
+func Test_OpenAPI_Next(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.json", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func Test_OpenAPI_OtherPathsPassThrough(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/openapi.jsonx", func(c fiber.Ctx) error {
+		return c.SendString("route")
+	})
+	app.Post("/openapi.json", func(c fiber.Ctx) error {
+		return c.SendString("post")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.jsonx", http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "route", string(body))
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodPost, "/openapi.json", http.NoBody))
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "post", string(body))
+}
+
+func Test_OpenAPI_PathParameters(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/users/:id<int>", okHandler)
+	app.Get("/orders/:orderID<guid>/items/:pos<range(1,50)>", okHandler)
+	app.Get("/names/:name<minLen(2);maxLen(16)>", okHandler)
+	app.Get("/codes/:code<regex(^[A-Z]{3}$)>", okHandler)
+	app.Get("/days/:day<datetime(2006\\-01\\-02)>", okHandler)
+	app.Get("/flags/:flag<bool>/:ratio<float>", okHandler)
+	app.Get("/files/*", okHandler)
+	app.Get("/optional/:slug?", okHandler)
+
+	doc := fetchDocument(t, app, "/openapi.json")
+
+	param := doc.Paths["/users/{id}"].Get.Parameters[0]
+	require.Equal(t, "id", param.Name)
+	require.Equal(t, "path", param.In)
+	require.True(t, param.Required)
+	require.Equal(t, "integer", param.Schema.Type)
+
+	params := doc.Paths["/orders/{orderID}/items/{pos}"].Get.Parameters
+	require.Len(t, params, 2)
+	require.Equal(t, "uuid", params[0].Schema.Format)
+	require.Equal(t, "integer", params[1].Schema.Type)
+	require.InDelta(t, 1, *params[1].Schema.Minimum, 0)
+	require.InDelta(t, 50, *params[1].Schema.Maximum, 0)
+
+	name := doc.Paths["/names/{name}"].Get.Parameters[0].Schema
+	require.Equal(t, "string", name.Type)
+	require.Equal(t, 2, *name.MinLength)
+	require.Equal(t, 16, *name.MaxLength)
+
+	require.Equal(t, "^[A-Z]{3}$", doc.Paths["/codes/{code}"].Get.Parameters[0].Schema.Pattern)
+	require.Equal(t, "date", doc.Paths["/days/{day}"].Get.Parameters[0].Schema.Format)
+
+	params = doc.Paths["/flags/{flag}/{ratio}"].Get.Parameters
+	require.Equal(t, "boolean", params[0].Schema.Type)
+	require.Equal(t, "number", params[1].Schema.Type)
+
+	require.Equal(t, "wildcard1", doc.Paths["/files/{wildcard1}"].Get.Parameters[0].Name)
+
+	optional := doc.Paths["/optional/{slug}"].Get.Parameters[0]
+	require.True(t, optional.Required)
+	require.NotEmpty(t, optional.Description)
+}
+
+func Test_OpenAPI_RouteDoc(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{Title: "Users", Version: "2.1.0", Description: "User service"}))
+	app.Post("/users", okHandler).Describe(fiber.RouteDoc{
+		Summary:     "Create a user",
+		Description: "Creates a user from the request body.",
+		OperationID: "createUser",
+		Tags:        []string{"users"},
+		RequestBody: testCreateUser{},
+		Responses: map[int]fiber.RouteResponse{
+			fiber.StatusCreated:    {Body: testUser{}},
+			fiber.StatusBadRequest: {Description: "Invalid input"},
+		},
+	})
+	app.Get("/users", okHandler).Describe(fiber.RouteDoc{
+		Deprecated: true,
+		Responses: map[int]fiber.RouteResponse{
+			fiber.StatusOK: {Body: []testUser{}},
+		},
+	})
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.Equal(t, "Users", doc.Info.Title)
+	require.Equal(t, "2.1.0", doc.Info.Version)
+	require.Equal(t, "User service", doc.Info.Description)
+
+	post := doc.Paths["/users"].Post
+	require.Equal(t, "Create a user", post.Summary)
+	require.Equal(t, "Creates a user from the request body.", post.Description)
+	require.Equal(t, "createUser", post.OperationID)
+	require.Equal(t, []string{"users"}, post.Tags)
+	require.True(t, post.RequestBody.Required)
+	require.Equal(t, "#/components/schemas/testCreateUser", post.RequestBody.Content[fiber.MIMEApplicationJSON].Schema.Ref)
+	require.Equal(t, "Created", post.Responses["201"].Description)
+	require.Equal(t, "#/components/schemas/testUser", post.Responses["201"].Content[fiber.MIMEApplicationJSON].Schema.Ref)
+	require.Equal(t, "Invalid input", post.Responses["400"].Description)
+	require.Nil(t, post.Responses["400"].Content)
+
+	get := doc.Paths["/users"].Get
+	require.True(t, get.Deprecated)
+	list := get.Responses["200"].Content[fiber.MIMEApplicationJSON].Schema
+	require.Equal(t, "array", list.Type)
+	require.Equal(t, "#/components/schemas/testUser", list.Items.Ref)
+
+	user := doc.Components.Schemas["testUser"]
+	require.Equal(t, "object", user.Type)
+	require.Equal(t, "date-time", user.Properties["created_at"].Format)
+	require.Equal(t, "#/components/schemas/testUser", user.Properties["manager"].Ref)
+	require.Equal(t, "integer", user.Properties["id"].Type)
+	require.Equal(t, "array", user.Properties["tags"].Type)
+	require.Equal(t, "#/components/schemas/testAddress", user.Properties["Address"].Ref)
+	require.NotContains(t, user.Properties, "Secret")
+	require.ElementsMatch(t, []string{"created_at", "name", "email", "id", "Address"}, user.Required)
+
+	require.Equal(t, "string", doc.Components.Schemas["testAddress"].Properties["city"].Type)
+}
+
+func Test_OpenAPI_GroupsDomainsAndMounts(t *testing.T) {
+	t.Parallel()
+
+	sub := fiber.New()
+	sub.Get("/status", okHandler).Describe(fiber.RouteDoc{Summary: "Sub-app status"})
+
+	app := fiber.New()
+	app.Use(New())
+	api := app.Group("/api")
+	api.Get("/items/:id<int>", okHandler)
+	app.Domain("admin.example.com").Get("/admin", okHandler)
+	app.Use("/sub/:tenant", sub)
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.NotNil(t, doc.Paths["/api/items/{id}"].Get)
+	require.NotNil(t, doc.Paths["/admin"].Get)
+
+	status := doc.Paths["/sub/{tenant}/status"].Get
+	require.NotNil(t, status)
+	require.Equal(t, "Sub-app status", status.Summary)
+	require.Equal(t, "tenant", status.Parameters[0].Name)
+}
+
+func Test_OpenAPI_DuplicateRoutes(t *testing.T) {
+	t.Parallel()
+
+	// Each sub-app keeps its own route, where registering the same path on
+	// one app twice would merge them into one.
+	plain := fiber.New()
+	plain.Get("/shared", okHandler)
+	documented := fiber.New()
+	documented.Get("/shared", okHandler).Describe(fiber.RouteDoc{Summary: "documented"})
+	later := fiber.New()
+	later.Get("/shared", okHandler).Describe(fiber.RouteDoc{Summary: "later"})
+
+	app := fiber.New()
+	app.Use(New())
+	app.Domain("a.example.com").Use(plain)
+	app.Domain("b.example.com").Use(documented)
+	app.Domain("c.example.com").Use(later)
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.Equal(t, "documented", doc.Paths["/shared"].Get.Summary)
+}
+
+func Test_OpenAPI_UniqueOperationIDs(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/items", okHandler).Describe(fiber.RouteDoc{OperationID: "items"})
+	app.Post("/items", okHandler).Describe(fiber.RouteDoc{OperationID: "items"})
+	app.Get("/a", okHandler).Name("list")
+	app.Get("/b", okHandler).Name("list")
+	app.Get("/c", okHandler).Name("list_get")
+	app.Put("/d", okHandler).Name("unique")
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.Equal(t, "items", doc.Paths["/items"].Get.OperationID)
+	require.Equal(t, "items_post", doc.Paths["/items"].Post.OperationID)
+	require.Equal(t, "list", doc.Paths["/a"].Get.OperationID)
+	require.Equal(t, "list_get", doc.Paths["/b"].Get.OperationID)
+	require.Equal(t, "list_get_get", doc.Paths["/c"].Get.OperationID)
+	require.Equal(t, "unique", doc.Paths["/d"].Put.OperationID)
+
+	ids := make(map[string]bool)
+	for _, item := range doc.Paths {
+		for _, method := range operationMethods {
+			if op := *item.operation(method); op != nil && op.OperationID != "" {
+				require.False(t, ids[op.OperationID], op.OperationID)
+				ids[op.OperationID] = true
+			}
+		}
+	}
+}
+
+func Test_OpenAPI_Filter(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Filter: func(route *fiber.Route) bool {
+			return route.Path != "/internal"
+		},
+	}))
+	app.Get("/public", okHandler)
+	app.Get("/internal", okHandler)
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.Contains(t, doc.Paths, "/public")
+	require.NotContains(t, doc.Paths, "/internal")
+}
+
+func Test_OpenAPI_ServedOnRoute(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/openapi.json", New())
+	app.Get("/users", okHandler)
+
+	doc := fetchDocument(t, app, "/openapi.json")
+	require.Contains(t, doc.Paths, "/users")
+	require.NotContains(t, doc.Paths, "/openapi.json")
+}
+
+func Test_OpenAPI_YAML(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Path:    "/docs/openapi.yaml",
+		Servers: []Server{{URL: "https://api.example.com"}},
+	}))
+	app.Get("/users/:id<int>", okHandler)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/docs/openapi.yaml", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, mimeApplicationYAML, resp.Header.Get(fiber.HeaderContentType))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var doc Document
+	require.NoError(t, yaml.Unmarshal(body, &doc))
+	require.Equal(t, Version, doc.OpenAPI)
+	require.Equal(t, "https://api.example.com", doc.Servers[0].URL)
+	require.Equal(t, "integer", doc.Paths["/users/{id}"].Get.Parameters[0].Schema.Type)
+}
+
+func Test_OpenAPI_Generate(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ping", okHandler)
+	app.Connect("/tunnel", okHandler)
+
+	doc := Generate(app)
+	require.NotNil(t, doc.Paths["/ping"].Get)
+	// OpenAPI 3.1 has no CONNECT operation
+	require.NotContains(t, doc.Paths, "/tunnel")
+
+	out, err := doc.JSON()
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"openapi":"3.1.0"`)
+}
+
+func Test_SchemaRegistry_Types(t *testing.T) {
+	t.Parallel()
+
+	type embedded struct {
+		Inner string `json:"inner"`
+	}
+	type sample struct {
+		embedded
+		Any      any              `json:"any"`
+		Labels   map[string]int   `json:"labels"`
+		Raw      json.RawMessage  `json:"raw"`
+		Data     []byte           `json:"data"`
+		Timeout  time.Duration    `json:"timeout"`
+		Ratio    float32          `json:"ratio"`
+		Small    int16            `json:"small"`
+		Anon     struct{ X bool } `json:"anon"`
+		Optional *string          `json:"optional"`
+		Dashed   string           `json:"-,"`
+	}
+
+	schemas := newSchemaRegistry()
+	require.Nil(t, schemas.schemaOf(nil))
+
+	schema := schemas.schemaOf(&sample{})
+	require.Equal(t, "#/components/schemas/sample", schema.Ref)
+
+	props := schemas.schemas["sample"].Properties
+	require.Equal(t, "string", props["inner"].Type)
+	require.Equal(t, &Schema{}, props["any"])
+	require.Equal(t, "integer", props["labels"].AdditionalProperties.Type)
+	require.Equal(t, &Schema{}, props["raw"])
+	require.Equal(t, "byte", props["data"].Format)
+	require.Equal(t, "int64", props["timeout"].Format)
+	require.Equal(t, "float", props["ratio"].Format)
+	require.Equal(t, "int32", props["small"].Format)
+	require.Equal(t, "boolean", props["anon"].Properties["X"].Type)
+	require.Equal(t, "string", props["optional"].Type)
+	require.Contains(t, props, "-")
+	require.NotContains(t, schemas.schemas["sample"].Required, "optional")
+}
+
+func Test_SchemaRegistry_NameCollision(t *testing.T) {
+	t.Parallel()
+
+	schemas := newSchemaRegistry()
+	schemas.schemas["testAddress"] = &Schema{}
+
+	ref := schemas.schemaOf(testAddress{})
+	require.Equal(t, "#/components/schemas/openapi.testAddress", ref.Ref)
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/gofiber/fiber/v3/internal/fieldname"
 	"github.com/gofiber/fiber/v3/internal/headerlookup"
+	"github.com/gofiber/fiber/v3/internal/tracecontext"
 	"github.com/valyala/fasthttp"
 )
 
@@ -93,6 +94,10 @@ func Balancer(config ...Config) fiber.Handler {
 			}
 		}
 
+		// Make the span of the request, if it is traced, the parent of the
+		// upstream request
+		tracecontext.Inject(c, &req.Header)
+
 		// Modify request
 		if cfg.ModifyRequest != nil {
 			if err := cfg.ModifyRequest(c); err != nil {
@@ -412,6 +417,9 @@ func doActionWithPolicy(
 	// inherited from the inbound request (e.g. "HTTP/2" propagated by the
 	// net/http adaptor) so the serialized request line stays valid.
 	req.Header.SetProtocol("HTTP/1.1")
+	// Make the span of the request, if it is traced, the parent of the
+	// upstream request.
+	tracecontext.Inject(c, &req.Header)
 	if err := action(cli, req, res, u); err != nil {
 		return err
 	}
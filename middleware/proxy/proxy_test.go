@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gofiber/fiber/v3/internal/tlstest"
+	"github.com/gofiber/fiber/v3/middleware/tracing"
 	"github.com/valyala/fasthttp"
 )
 
@@ -383,6 +384,74 @@ func Test_Proxy_Forward(t *testing.T) {
 	require.Equal(t, "forwarded", string(b))
 }
 
+// go test -run Test_Proxy_Forward_Tracing
+func Test_Proxy_Forward_Tracing(t *testing.T) {
+	t.Parallel()
+
+	_, addr := createProxyTestServerIPv4(t, func(c fiber.Ctx) error {
+		return c.SendString(c.Get(tracing.HeaderTraceparent) + " " + c.Get(tracing.HeaderTracestate))
+	})
+
+	app := fiber.New()
+	app.Use(tracing.New())
+	app.Get("/", func(c fiber.Ctx) error {
+		if err := Forward("http://" + addr)(c); err != nil {
+			return err
+		}
+		return c.Send(append(c.Response().Body(), " "+tracing.SpanIDFromContext(c)...))
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set(tracing.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(tracing.HeaderTracestate, "congo=t61rcWkgMzE")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	parts := strings.Split(string(b), " ")
+	require.Len(t, parts, 3)
+
+	// The upstream request is a child of the server span
+	sc, err := tracing.ParseTraceparent(parts[0])
+	require.NoError(t, err)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	require.Equal(t, parts[2], sc.SpanID.String())
+	require.NotEqual(t, "00f067aa0ba902b7", sc.SpanID.String())
+	require.Equal(t, "congo=t61rcWkgMzE", parts[1])
+}
+
+// go test -run Test_Proxy_Balancer_Tracing
+func Test_Proxy_Balancer_Tracing(t *testing.T) {
+	t.Parallel()
+
+	_, addr := createProxyTestServerIPv4(t, func(c fiber.Ctx) error {
+		return c.SendString(c.Get(tracing.HeaderTraceparent))
+	})
+
+	app := fiber.New()
+	app.Use(tracing.New())
+	app.Use(Balancer(Config{
+		Servers: []string{addr},
+		ModifyResponse: func(c fiber.Ctx) error {
+			c.Set("X-Trace-Id", tracing.TraceIDFromContext(c))
+			return nil
+		},
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	sc, err := tracing.ParseTraceparent(string(b))
+	require.NoError(t, err)
+	require.Equal(t, resp.Header.Get("X-Trace-Id"), sc.TraceID.String())
+	require.True(t, sc.Sampled())
+}
+
 // go test -run Test_Proxy_Forward_ReplacesClientSuppliedRealIP
 func Test_Proxy_Forward_ReplacesClientSuppliedRealIP(t *testing.T) {
 	t.Parallel()
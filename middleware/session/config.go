@@ -70,6 +70,24 @@ type Config struct {
 	// Optional. Default: 0
 	AbsoluteTimeout time.Duration
 
+	// CookieKeys enables the stateless cookie store: instead of an ID, the
+	// session cookie holds the session data sealed with AES-GCM, split across
+	// several cookies when larger than 4 KB, and Storage is not used.
+	//
+	// The first key seals the cookies, and every key opens them, so a key is
+	// rotated by prepending its replacement. Keys are base64 encoded, and 16,
+	// 24 or 32 bytes long when decoded. The Extractor must be a cookie one.
+	//
+	// Optional. Default: nil
+	CookieKeys []string
+
+	// MaxSessionsPerPrincipal limits the number of sessions of a principal,
+	// as set with SetPrincipal. When a new session of the principal is saved
+	// beyond the limit, its least recently seen sessions are revoked.
+	//
+	// Optional. Default: 0 (unlimited)
+	MaxSessionsPerPrincipal int
+
 	// CookieSecure specifies if the session cookie should be secure.
 	//
 	// Optional. Default: false
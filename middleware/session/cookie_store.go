@@ -0,0 +1,222 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	// ErrNotSupportedByCookieStore is returned by the store operations that
+	// need server-side state when the session data is sealed into cookies.
+	ErrNotSupportedByCookieStore = errors.New("operation not supported by the cookie session store")
+	// ErrSessionCookieTooLarge is returned when the sealed session data does
+	// not fit in the session cookies.
+	ErrSessionCookieTooLarge = errors.New("sealed session data exceeds the session cookie size limit")
+)
+
+const (
+	// cookieChunkSize is the largest value of a session cookie, which leaves
+	// room for the name and attributes within the 4096 bytes browsers accept.
+	cookieChunkSize = 3800
+	// maxCookieChunks is the largest number of cookies a session is split into.
+	maxCookieChunks = 8
+	// expiresSize is the size of the expiration time that precedes the
+	// length of the ID, the ID and the data of a sealed session.
+	expiresSize = 8
+)
+
+// cookieSealer seals session data into cookies with AES-GCM.
+type cookieSealer struct {
+	// aeads holds a cipher for each key; the first one seals.
+	aeads []cipher.AEAD
+}
+
+// newCookieSealer returns a sealer for base64 encoded keys, or an error if a
+// key is invalid.
+func newCookieSealer(keys []string) (*cookieSealer, error) {
+	sealer := &cookieSealer{aeads: make([]cipher.AEAD, len(keys))}
+	for i, key := range keys {
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to base64-decode cookie key %d: %w", i, err)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie key %d: %w", i, err)
+		}
+		if sealer.aeads[i], err = cipher.NewGCMWithRandomNonce(block); err != nil {
+			return nil, fmt.Errorf("failed to create GCM mode: %w", err)
+		}
+	}
+	return sealer, nil
+}
+
+// seal returns the cookie value of a session. The cookie name is
+// authenticated with it, so a value cannot be moved to another cookie.
+func (cs *cookieSealer) seal(name, id string, expires time.Time, data []byte) string {
+	plaintext := make([]byte, 0, expiresSize+binary.MaxVarintLen64+len(id)+len(data))
+	plaintext = binary.BigEndian.AppendUint64(plaintext, uint64(expires.Unix())) //nolint:gosec // Expiration times are after 1970
+	plaintext = binary.AppendUvarint(plaintext, uint64(len(id)))
+	plaintext = append(plaintext, id...)
+	plaintext = append(plaintext, data...)
+
+	return base64.RawURLEncoding.EncodeToString(cs.aeads[0].Seal(nil, nil, plaintext, []byte(name)))
+}
+
+// open returns the ID and data of a session cookie value, and false if the
+// value was not sealed with one of the keys or the session has expired.
+func (cs *cookieSealer) open(name, value string, now time.Time) (id string, data []byte, ok bool) {
+	ciphertext, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", nil, false
+	}
+
+	for _, aead := range cs.aeads {
+		if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+			return "", nil, false
+		}
+		plaintext, err := aead.Open(nil, nil, ciphertext, []byte(name))
+		if err != nil {
+			continue
+		}
+
+		if len(plaintext) < expiresSize {
+			return "", nil, false
+		}
+		expires := time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0) //nolint:gosec // Sealed by seal
+		idLen, n := binary.Uvarint(plaintext[expiresSize:])
+		rest := plaintext[expiresSize+max(n, 0):]
+		if now.After(expires) || n <= 0 || idLen > uint64(len(rest)) {
+			return "", nil, false
+		}
+		return string(rest[:idLen]), rest[idLen:], true
+	}
+	return "", nil, false
+}
+
+// chunkName returns the name of the i-th cookie of a session.
+func chunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return name + "." + strconv.Itoa(i)
+}
+
+// getSealedSession returns the ID and data of the session sealed into the
+// cookies of the request, or an empty ID if there is no valid one.
+func (s *Store) getSealedSession(c fiber.Ctx) (id string, data []byte) {
+	name := s.Extractor.Key
+
+	var sb strings.Builder
+	for i := range maxCookieChunks {
+		chunk := c.Request().Header.Cookie(chunkName(name, i))
+		if len(chunk) == 0 {
+			break
+		}
+		sb.Write(chunk)
+	}
+	if sb.Len() == 0 {
+		return "", nil
+	}
+
+	id, data, ok := s.sealer.open(name, sb.String(), time.Now())
+	if !ok {
+		return "", nil
+	}
+	c.Locals(sessionExtractorContextKey, s.Extractor)
+	return id, data
+}
+
+// setSealedCookies seals the encoded data of the session into the response
+// cookies. It must be called with s.mu held.
+func (s *Session) setSealedCookies(data []byte) error {
+	if s.ctx == nil {
+		return nil
+	}
+
+	name := s.config.Extractor.Key
+	expires := time.Now().Add(s.idleTimeout)
+	value := s.config.sealer.seal(name, s.id, expires, data)
+	if len(value) > cookieChunkSize*maxCookieChunks {
+		return ErrSessionCookieTooLarge
+	}
+
+	chunks := 0
+	for ; len(value) > 0; chunks++ {
+		n := min(len(value), cookieChunkSize)
+		s.setSealedCookie(chunkName(name, chunks), value[:n], expires)
+		value = value[n:]
+	}
+
+	// Expire the chunks left over from a larger session
+	s.delSealedCookies(chunks)
+	return nil
+}
+
+// setSealedCookie sets a session cookie of the response.
+func (s *Session) setSealedCookie(name, value string, expires time.Time) {
+	fcookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(fcookie)
+
+	fcookie.SetKey(name)
+	fcookie.SetValue(value)
+	fcookie.SetPath(s.config.CookiePath)
+	fcookie.SetDomain(s.config.CookieDomain)
+	if !s.config.CookieSessionOnly {
+		fcookie.SetMaxAge(int(s.idleTimeout.Seconds()))
+		fcookie.SetExpire(expires)
+	}
+	s.setCookieAttributes(fcookie)
+	s.ctx.Response().Header.SetCookie(fcookie)
+}
+
+// delSealedCookies expires the session cookies of the request from the
+// from-th chunk on.
+func (s *Session) delSealedCookies(from int) {
+	name := s.config.Extractor.Key
+	for i := from; i < maxCookieChunks; i++ {
+		chunk := chunkName(name, i)
+		if len(s.ctx.Request().Header.Cookie(chunk)) == 0 {
+			// The first chunk is expired even if the request had none, like
+			// the session ID cookie
+			if i > 0 {
+				break
+			}
+		}
+		s.ctx.Request().Header.DelCookie(chunk)
+
+		fcookie := fasthttp.AcquireCookie()
+		fcookie.SetKey(chunk)
+		fcookie.SetPath(s.config.CookiePath)
+		fcookie.SetDomain(s.config.CookieDomain)
+		fcookie.SetMaxAge(-1)
+		fcookie.SetExpire(time.Now().Add(-1 * time.Minute))
+		s.setCookieAttributes(fcookie)
+		s.ctx.Response().Header.SetCookie(fcookie)
+		fasthttp.ReleaseCookie(fcookie)
+	}
+}
+
+// mustCookieSealer returns the sealer of the config, and panics if the config
+// cannot seal sessions into cookies.
+func mustCookieSealer(cfg *Config) *cookieSealer {
+	if cfg.Extractor.Source != extractors.SourceCookie || len(cfg.Extractor.Chain) > 0 {
+		panic("[session] CookieKeys requires a single cookie Extractor")
+	}
+	sealer, err := newCookieSealer(cfg.CookieKeys)
+	if err != nil {
+		panic("[session] " + err.Error())
+	}
+	return sealer
+}
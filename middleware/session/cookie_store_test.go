@@ -0,0 +1,238 @@
+package session
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCookieKey    = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 bytes
+	testOldCookieKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=" // 32 bytes
+)
+
+// cookieJar keeps the cookies of the responses of an app, like a browser.
+type cookieJar map[string]string
+
+func (jar cookieJar) do(t *testing.T, app *fiber.App, method, target string) (status int, body string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, http.NoBody)
+	for name, value := range jar {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(jar, cookie.Name)
+		} else {
+			jar[cookie.Name] = cookie.Value
+		}
+	}
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(b)
+}
+
+func newCookieStoreApp(config Config) *fiber.App {
+	// Chunked session cookies exceed the default read buffer
+	app := fiber.New(fiber.Config{ReadBufferSize: 64 * 1024})
+	app.Use(New(config))
+	app.Get("/set", func(c fiber.Ctx) error {
+		FromContext(c).Set("value", c.Query("value"))
+		return nil
+	})
+	app.Get("/get", func(c fiber.Ctx) error {
+		sess := FromContext(c)
+		value, _ := sess.Get("value").(string) //nolint:errcheck // A missing value is an empty one
+		if sess.Fresh() {
+			return c.SendString("fresh:" + value)
+		}
+		return c.SendString(value)
+	})
+	app.Get("/destroy", func(c fiber.Ctx) error {
+		return FromContext(c).Destroy()
+	})
+	app.Get("/expire", func(c fiber.Ctx) error {
+		FromContext(c).Set(absExpirationKey, time.Now().Add(-time.Second))
+		return nil
+	})
+	return app
+}
+
+func Test_CookieStore(t *testing.T) {
+	t.Parallel()
+
+	app := newCookieStoreApp(Config{CookieKeys: []string{testCookieKey}})
+	jar := cookieJar{}
+
+	_, body := jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "fresh:", body)
+
+	jar.do(t, app, fiber.MethodGet, "/set?value=hello")
+	require.Len(t, jar, 1)
+	require.Contains(t, jar, "session_id")
+	require.NotContains(t, jar["session_id"], "hello")
+
+	_, body = jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "hello", body)
+
+	// A tampered cookie opens no session
+	tampered := cookieJar{"session_id": "A" + jar["session_id"][1:]}
+	_, body = tampered.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "fresh:", body)
+
+	// Destroy expires the cookie
+	jar.do(t, app, fiber.MethodGet, "/destroy")
+	require.Empty(t, jar)
+	_, body = jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "fresh:", body)
+}
+
+func Test_CookieStore_Chunks(t *testing.T) {
+	t.Parallel()
+
+	app := newCookieStoreApp(Config{CookieKeys: []string{testCookieKey}})
+	jar := cookieJar{}
+
+	large := strings.Repeat("abcdefghij", 1000)
+	jar.do(t, app, fiber.MethodGet, "/set?value="+large)
+	require.Len(t, jar, 4)
+	for name, value := range jar {
+		require.Contains(t, []string{"session_id", "session_id.1", "session_id.2", "session_id.3"}, name)
+		require.LessOrEqual(t, len(value), cookieChunkSize)
+	}
+
+	_, body := jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, large, body)
+
+	// The chunks left over from the larger session are expired
+	jar.do(t, app, fiber.MethodGet, "/set?value=small")
+	require.Len(t, jar, 1)
+	_, body = jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "small", body)
+
+	// Sessions too large for the cookies fail to save
+	var saveErr error
+	app = newCookieStoreApp(Config{
+		CookieKeys: []string{testCookieKey},
+		ErrorHandler: func(_ fiber.Ctx, err error) {
+			saveErr = err
+		},
+	})
+	jar = cookieJar{}
+	jar.do(t, app, fiber.MethodGet, "/set?value="+strings.Repeat("a", cookieChunkSize*maxCookieChunks))
+	require.ErrorIs(t, saveErr, ErrSessionCookieTooLarge)
+	require.Empty(t, jar)
+}
+
+func Test_CookieStore_KeyRotation(t *testing.T) {
+	t.Parallel()
+
+	jar := cookieJar{}
+	oldApp := newCookieStoreApp(Config{CookieKeys: []string{testOldCookieKey}})
+	jar.do(t, oldApp, fiber.MethodGet, "/set?value=hello")
+
+	// The previous key still opens the cookie, which is sealed again with
+	// the new key
+	rotated := newCookieStoreApp(Config{CookieKeys: []string{testCookieKey, testOldCookieKey}})
+	_, body := jar.do(t, rotated, fiber.MethodGet, "/get")
+	require.Equal(t, "hello", body)
+
+	newApp := newCookieStoreApp(Config{CookieKeys: []string{testCookieKey}})
+	_, body = jar.do(t, newApp, fiber.MethodGet, "/get")
+	require.Equal(t, "hello", body)
+
+	// Without the key, the cookie opens no session
+	_, body = cookieJar{"session_id": jar["session_id"]}.do(t, oldApp, fiber.MethodGet, "/get")
+	require.Equal(t, "fresh:", body)
+}
+
+func Test_CookieStore_Timeouts(t *testing.T) {
+	t.Parallel()
+
+	sealer, err := newCookieSealer([]string{testCookieKey})
+	require.NoError(t, err)
+
+	now := time.Now()
+	value := sealer.seal("session_id", "id", now.Add(time.Minute), []byte("data"))
+
+	id, data, ok := sealer.open("session_id", value, now)
+	require.True(t, ok)
+	require.Equal(t, "id", id)
+	require.Equal(t, []byte("data"), data)
+
+	// The idle timeout is enforced from the sealed expiration time
+	_, _, ok = sealer.open("session_id", value, now.Add(2*time.Minute))
+	require.False(t, ok)
+
+	// The value is bound to the cookie name
+	_, _, ok = sealer.open("other", value, now)
+	require.False(t, ok)
+
+	_, _, ok = sealer.open("session_id", "not base64!", now)
+	require.False(t, ok)
+	_, _, ok = sealer.open("session_id", "", now)
+	require.False(t, ok)
+
+	// The absolute timeout is enforced from the sealed data
+	app := newCookieStoreApp(Config{
+		CookieKeys:      []string{testCookieKey},
+		AbsoluteTimeout: time.Hour,
+	})
+	jar := cookieJar{}
+	jar.do(t, app, fiber.MethodGet, "/set?value=hello")
+	_, body := jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "hello", body)
+
+	jar.do(t, app, fiber.MethodGet, "/expire")
+	_, body = jar.do(t, app, fiber.MethodGet, "/get")
+	require.Equal(t, "fresh:", body)
+}
+
+func Test_CookieStore_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(Config{CookieKeys: []string{testCookieKey}})
+	require.Nil(t, store.Storage)
+
+	ctx := context.Background()
+	_, err := store.GetByID(ctx, "id")
+	require.ErrorIs(t, err, ErrNotSupportedByCookieStore)
+	require.ErrorIs(t, store.Delete(ctx, "id"), ErrNotSupportedByCookieStore)
+	require.ErrorIs(t, store.Reset(ctx), ErrNotSupportedByCookieStore)
+	_, err = store.ListSessions(ctx, "alice")
+	require.ErrorIs(t, err, ErrNotSupportedByCookieStore)
+	require.ErrorIs(t, store.RevokeSession(ctx, "alice", "id"), ErrNotSupportedByCookieStore)
+	require.ErrorIs(t, store.RevokeSessions(ctx, "alice"), ErrNotSupportedByCookieStore)
+}
+
+func Test_CookieStore_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "[session] CookieKeys requires a single cookie Extractor", func() {
+		NewStore(Config{CookieKeys: []string{testCookieKey}, Extractor: extractors.FromHeader("X-Session")})
+	})
+	require.PanicsWithValue(t, "[session] CookieKeys requires a single cookie Extractor", func() {
+		NewStore(Config{
+			CookieKeys: []string{testCookieKey},
+			Extractor:  extractors.Chain(extractors.FromCookie("session_id"), extractors.FromHeader("X-Session")),
+		})
+	})
+	require.Panics(t, func() {
+		NewStore(Config{CookieKeys: []string{"c2hvcnQ="}})
+	})
+	require.Panics(t, func() {
+		NewStore(Config{CookieKeys: []string{"not base64!"}})
+	})
+}
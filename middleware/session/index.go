@@ -0,0 +1,462 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+)
+
+// ErrEmptyPrincipal is an error that occurs when the principal is empty.
+var ErrEmptyPrincipal = errors.New("session principal cannot be empty")
+
+type principalKeyType int
+
+const (
+	// principalKey is the session data key holding the principal of the session.
+	principalKey principalKeyType = iota
+)
+
+const (
+	// principalIndexPrefix prefixes the storage keys of the principal indexes,
+	// which share the storage with the sessions.
+	principalIndexPrefix = "session_principal:"
+
+	// indexTouchInterval is how often the last-seen time of an index entry is
+	// refreshed, so saving a session does not rewrite the index every time.
+	indexTouchInterval = time.Minute
+)
+
+// isIndexKey reports whether id is the storage key of a principal index,
+// which must never be loaded as a session.
+func isIndexKey(id string) bool {
+	return strings.HasPrefix(id, principalIndexPrefix)
+}
+
+// SessionInfo describes a session of a principal.
+type SessionInfo struct {
+	// CreatedAt is when the session was first saved with the principal.
+	CreatedAt time.Time
+	// LastSeen is when the session was last saved, to within a minute.
+	LastSeen time.Time
+	// ID is the session ID.
+	ID string
+	// IP is the client IP of the last request that saved the session.
+	IP string
+	// UserAgent is the User-Agent of the last request that saved the session.
+	UserAgent string
+}
+
+// indexEntry is a session in the index of a principal.
+type indexEntry struct {
+	Created   time.Time
+	LastSeen  time.Time
+	Expires   time.Time
+	ID        string
+	IP        string
+	UserAgent string
+}
+
+// expired reports whether the session of e is gone at now. A session saved
+// less than indexTouchInterval after its entry was refreshed outlives it by as
+// much.
+func (e *indexEntry) expired(now time.Time) bool {
+	return now.After(e.Expires.Add(indexTouchInterval))
+}
+
+// ListSessions returns the sessions of a principal, most recently seen first.
+//
+// Entries of sessions that have expired or were deleted without the index,
+// for example with store.Delete, are dropped from the index.
+//
+// Parameters:
+//   - ctx: The context to use for the storage operations.
+//   - principal: The principal, as set with SetPrincipal.
+//
+// Returns:
+//   - []SessionInfo: The sessions of the principal.
+//   - error: An error if the storage operations fail or if the principal is empty.
+//
+// Usage:
+//
+//	sessions, err := store.ListSessions(ctx, userID)
+//	if err != nil {
+//	    // handle error
+//	}
+func (s *Store) ListSessions(ctx context.Context, principal string) ([]SessionInfo, error) {
+	if principal == "" {
+		return nil, ErrEmptyPrincipal
+	}
+	if s.sealer != nil {
+		return nil, ErrNotSupportedByCookieStore
+	}
+	ctx = backgroundIfNil(ctx)
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	entries, err := s.loadIndex(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	live := entries[:0]
+	for _, e := range entries {
+		if e.expired(now) {
+			continue
+		}
+		raw, err := s.Storage.GetWithContext(ctx, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		if raw != nil {
+			live = append(live, e)
+		}
+	}
+	if len(live) != len(entries) {
+		if err := s.saveIndex(ctx, principal, live); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(live, func(a, b indexEntry) int {
+		return b.LastSeen.Compare(a.LastSeen)
+	})
+	sessions := make([]SessionInfo, len(live))
+	for i, e := range live {
+		sessions[i] = SessionInfo{
+			CreatedAt: e.Created,
+			LastSeen:  e.LastSeen,
+			ID:        e.ID,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession deletes a session of a principal from the storage.
+//
+// It only deletes sessions listed in the index of the principal, so a
+// session ID supplied by a client cannot revoke the session of someone else.
+//
+// Parameters:
+//   - ctx: The context to use for the storage operations.
+//   - principal: The principal, as set with SetPrincipal.
+//   - id: The ID of the session to revoke.
+//
+// Returns:
+//   - error: ErrSessionIDNotFoundInStore if the session is not a session of
+//     the principal, or an error if the storage operations fail.
+//
+// Usage:
+//
+//	err := store.RevokeSession(ctx, userID, id)
+//	if err != nil {
+//	    // handle error
+//	}
+func (s *Store) RevokeSession(ctx context.Context, principal, id string) error {
+	if principal == "" {
+		return ErrEmptyPrincipal
+	}
+	if s.sealer != nil {
+		return ErrNotSupportedByCookieStore
+	}
+	if id == "" {
+		return ErrEmptySessionID
+	}
+	ctx = backgroundIfNil(ctx)
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	entries, err := s.loadIndex(ctx, principal)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(entries, func(e indexEntry) bool {
+		return e.ID == id
+	})
+	if i < 0 {
+		return ErrSessionIDNotFoundInStore
+	}
+
+	if err := s.Storage.DeleteWithContext(ctx, id); err != nil {
+		return err
+	}
+	return s.saveIndex(ctx, principal, slices.Delete(entries, i, i+1))
+}
+
+// RevokeSessions deletes every session of a principal from the storage,
+// except the sessions whose IDs are passed in keep.
+//
+// Parameters:
+//   - ctx: The context to use for the storage operations.
+//   - principal: The principal, as set with SetPrincipal.
+//   - keep: The IDs of the sessions to keep, such as the current one.
+//
+// Returns:
+//   - error: An error if the storage operations fail or if the principal is empty.
+//
+// Usage:
+//
+//	// Log out everywhere
+//	err := store.RevokeSessions(ctx, userID)
+//
+//	// Log out every other device
+//	err := store.RevokeSessions(ctx, userID, sess.ID())
+func (s *Store) RevokeSessions(ctx context.Context, principal string, keep ...string) error {
+	if principal == "" {
+		return ErrEmptyPrincipal
+	}
+	if s.sealer != nil {
+		return ErrNotSupportedByCookieStore
+	}
+	ctx = backgroundIfNil(ctx)
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	entries, err := s.loadIndex(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	kept := make([]indexEntry, 0, len(keep))
+	for i, e := range entries {
+		if slices.Contains(keep, e.ID) {
+			kept = append(kept, e)
+			continue
+		}
+		if err := s.Storage.DeleteWithContext(ctx, e.ID); err != nil {
+			// Keep the entries of the sessions not deleted yet
+			kept = append(kept, entries[i:]...)
+			if saveErr := s.saveIndex(ctx, principal, kept); saveErr != nil {
+				return errors.Join(err, saveErr)
+			}
+			return err
+		}
+	}
+	return s.saveIndex(ctx, principal, kept)
+}
+
+// touchIndex adds or refreshes the entry of a session in the index of a
+// principal, and enforces MaxSessionsPerPrincipal when the session is new to
+// the index.
+func (s *Store) touchIndex(ctx context.Context, principal string, entry indexEntry, idleTimeout time.Duration) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	entries, err := s.loadIndex(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	live := entries[:0]
+	for _, e := range entries {
+		if !e.expired(now) {
+			live = append(live, e)
+		}
+	}
+	changed := len(live) != len(entries)
+
+	i := slices.IndexFunc(live, func(e indexEntry) bool {
+		return e.ID == entry.ID
+	})
+	if i >= 0 {
+		e := &live[i]
+		// Without a request, the client of the session is unknown
+		if entry.IP == "" && entry.UserAgent == "" {
+			entry.IP, entry.UserAgent = e.IP, e.UserAgent
+		}
+		if now.Sub(e.LastSeen) >= indexTouchInterval || e.IP != entry.IP || e.UserAgent != entry.UserAgent {
+			e.LastSeen = now
+			e.Expires = now.Add(idleTimeout)
+			e.IP, e.UserAgent = entry.IP, entry.UserAgent
+			changed = true
+		}
+	} else {
+		entry.Created = now
+		entry.LastSeen = now
+		entry.Expires = now.Add(idleTimeout)
+		live = append(live, entry)
+		changed = true
+
+		// Evict the least recently seen sessions beyond the limit
+		if limit := s.MaxSessionsPerPrincipal; limit > 0 && len(live) > limit {
+			others := live[:len(live)-1]
+			slices.SortFunc(others, func(a, b indexEntry) int {
+				return b.LastSeen.Compare(a.LastSeen)
+			})
+			for _, e := range others[limit-1:] {
+				if err := s.Storage.DeleteWithContext(ctx, e.ID); err != nil {
+					return err
+				}
+			}
+			live = append(others[:limit-1], entry)
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.saveIndex(ctx, principal, live)
+}
+
+// unindex removes sessions from the index of a principal.
+func (s *Store) unindex(ctx context.Context, principal string, ids ...string) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	entries, err := s.loadIndex(ctx, principal)
+	if err != nil {
+		return err
+	}
+	live := slices.DeleteFunc(entries, func(e indexEntry) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	if len(live) == len(entries) {
+		return nil
+	}
+	return s.saveIndex(ctx, principal, live)
+}
+
+// loadIndex returns the index of a principal from the storage.
+func (s *Store) loadIndex(ctx context.Context, principal string) ([]indexEntry, error) {
+	raw, err := s.Storage.GetWithContext(ctx, principalIndexPrefix+principal)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+
+	var entries []indexEntry
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode session index: %w", err)
+	}
+	return entries, nil
+}
+
+// saveIndex stores the index of a principal until its last session expires,
+// or deletes it if it is empty.
+func (s *Store) saveIndex(ctx context.Context, principal string, entries []indexEntry) error {
+	key := principalIndexPrefix + principal
+	if len(entries) == 0 {
+		return s.Storage.DeleteWithContext(ctx, key)
+	}
+
+	var expires time.Time
+	for i := range entries {
+		if entries[i].Expires.After(expires) {
+			expires = entries[i].Expires
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entries); err != nil {
+		return fmt.Errorf("failed to encode session index: %w", err)
+	}
+	return s.Storage.SetWithContext(ctx, key, buf.Bytes(), time.Until(expires)+indexTouchInterval)
+}
+
+// Principal returns the principal of the session, or an empty string if the
+// session has none.
+//
+// Returns:
+//   - string: The principal of the session.
+//
+// Usage:
+//
+//	userID := s.Principal()
+func (s *Session) Principal() string {
+	principal, _ := s.Get(principalKey).(string) //nolint:errcheck // A missing principal is an empty one
+	return principal
+}
+
+// SetPrincipal associates the session with a principal, such as a user ID,
+// which adds it to the index of the principal when it is saved. An empty
+// principal removes the association.
+//
+// Parameters:
+//   - principal: The principal of the session.
+//
+// Usage:
+//
+//	s.SetPrincipal(userID)
+func (s *Session) SetPrincipal(principal string) {
+	if s.data == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Remember the principal the session is indexed under, to leave its
+	// index on save
+	if current := s.Principal(); current != "" && s.formerPrincipal == "" {
+		s.formerPrincipal = current
+	}
+
+	if principal == "" {
+		s.data.Delete(principalKey)
+		return
+	}
+	s.data.Set(principalKey, principal)
+}
+
+// index updates the index of the principal of the session after it was saved.
+// It must be called with s.mu held.
+func (s *Session) index(ctx context.Context) error {
+	// Sealed sessions have no server-side state to index
+	if s.config.sealer != nil {
+		return nil
+	}
+
+	principal := s.Principal()
+
+	if former := s.formerPrincipal; former != "" && former != principal {
+		if err := s.config.unindex(ctx, former, s.id); err != nil {
+			return err
+		}
+	}
+	s.formerPrincipal = ""
+
+	if principal == "" {
+		return nil
+	}
+
+	entry := indexEntry{ID: s.id}
+	if s.ctx != nil {
+		entry.IP = utils.CopyString(s.ctx.IP())
+		entry.UserAgent = utils.CopyString(s.ctx.Get(fiber.HeaderUserAgent))
+	}
+	return s.config.touchIndex(ctx, principal, entry, s.idleTimeout)
+}
+
+// unindex removes the session from the indexes of its principals after it was
+// deleted from the storage. It must be called with s.mu held.
+func (s *Session) unindex(ctx context.Context) error {
+	if s.config.sealer != nil {
+		return nil
+	}
+
+	former := s.formerPrincipal
+	s.formerPrincipal = ""
+
+	for _, principal := range [...]string{former, s.Principal()} {
+		if principal == "" {
+			continue
+		}
+		if err := s.config.unindex(ctx, principal, s.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package session
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// newPrincipalSession saves a new session of principal, as a client with the
+// given user agent, and returns its ID.
+func newPrincipalSession(t *testing.T, app *fiber.App, store *Store, principal, userAgent string) string {
+	t.Helper()
+
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+	ctx.Request().Header.SetUserAgent(userAgent)
+
+	sess, err := store.Get(ctx)
+	require.NoError(t, err)
+	defer sess.Release()
+
+	sess.SetPrincipal(principal)
+	require.NoError(t, sess.Save())
+	return sess.ID()
+}
+
+func sessionIDs(t *testing.T, store *Store, principal string) []string {
+	t.Helper()
+
+	sessions, err := store.ListSessions(context.Background(), principal)
+	require.NoError(t, err)
+	ids := make([]string, len(sessions))
+	for i, info := range sessions {
+		ids[i] = info.ID
+	}
+	return ids
+}
+
+func Test_Store_ListSessions(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	store := NewStore()
+
+	before := time.Now()
+	laptop := newPrincipalSession(t, app, store, "alice", "laptop")
+	phone := newPrincipalSession(t, app, store, "alice", "phone")
+	other := newPrincipalSession(t, app, store, "bob", "laptop")
+
+	sessions, err := store.ListSessions(context.Background(), "alice")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, info := range sessions {
+		require.Contains(t, []string{laptop, phone}, info.ID)
+		require.False(t, info.CreatedAt.Before(before))
+		require.False(t, info.LastSeen.Before(info.CreatedAt))
+		require.Equal(t, "0.0.0.0", info.IP)
+	}
+	require.ElementsMatch(t, []string{"laptop", "phone"}, []string{sessions[0].UserAgent, sessions[1].UserAgent})
+	require.Equal(t, []string{other}, sessionIDs(t, store, "bob"))
+	require.Empty(t, sessionIDs(t, store, "carol"))
+
+	// The principal is stored with the session, and saving it without a
+	// request keeps its client
+	sess, err := store.GetByID(context.Background(), laptop)
+	require.NoError(t, err)
+	require.Equal(t, "alice", sess.Principal())
+	store.indexMu.Lock()
+	entries, err := store.loadIndex(context.Background(), "alice")
+	require.NoError(t, err)
+	for i := range entries {
+		entries[i].LastSeen = entries[i].LastSeen.Add(-time.Hour)
+	}
+	require.NoError(t, store.saveIndex(context.Background(), "alice", entries))
+	store.indexMu.Unlock()
+	require.NoError(t, sess.Save())
+	sess.Release()
+
+	sessions, err = store.ListSessions(context.Background(), "alice")
+	require.NoError(t, err)
+	require.Equal(t, laptop, sessions[0].ID)
+	require.Equal(t, "laptop", sessions[0].UserAgent)
+	require.WithinDuration(t, time.Now(), sessions[0].LastSeen, time.Minute)
+
+	// Sessions deleted without the index are dropped from it
+	require.NoError(t, store.Delete(context.Background(), phone))
+	require.Equal(t, []string{laptop}, sessionIDs(t, store, "alice"))
+
+	_, err = store.ListSessions(context.Background(), "")
+	require.ErrorIs(t, err, ErrEmptyPrincipal)
+}
+
+func Test_Store_RevokeSession(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	store := NewStore()
+
+	laptop := newPrincipalSession(t, app, store, "alice", "laptop")
+	phone := newPrincipalSession(t, app, store, "alice", "phone")
+	other := newPrincipalSession(t, app, store, "bob", "laptop")
+
+	// Sessions of other principals cannot be revoked
+	require.ErrorIs(t, store.RevokeSession(context.Background(), "alice", other), ErrSessionIDNotFoundInStore)
+	require.Equal(t, []string{other}, sessionIDs(t, store, "bob"))
+
+	require.NoError(t, store.RevokeSession(context.Background(), "alice", phone))
+	require.Equal(t, []string{laptop}, sessionIDs(t, store, "alice"))
+	_, err := store.GetByID(context.Background(), phone)
+	require.ErrorIs(t, err, ErrSessionIDNotFoundInStore)
+
+	require.ErrorIs(t, store.RevokeSession(context.Background(), "", phone), ErrEmptyPrincipal)
+	require.ErrorIs(t, store.RevokeSession(context.Background(), "alice", ""), ErrEmptySessionID)
+}
+
+func Test_Store_RevokeSessions(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	store := NewStore()
+
+	laptop := newPrincipalSession(t, app, store, "alice", "laptop")
+	phone := newPrincipalSession(t, app, store, "alice", "phone")
+	tablet := newPrincipalSession(t, app, store, "alice", "tablet")
+	other := newPrincipalSession(t, app, store, "bob", "laptop")
+
+	// Log out every other device
+	require.NoError(t, store.RevokeSessions(context.Background(), "alice", laptop))
+	require.Equal(t, []string{laptop}, sessionIDs(t, store, "alice"))
+	for _, id := range []string{phone, tablet} {
+		_, err := store.GetByID(context.Background(), id)
+		require.ErrorIs(t, err, ErrSessionIDNotFoundInStore)
+	}
+
+	// Log out everywhere
+	require.NoError(t, store.RevokeSessions(context.Background(), "alice"))
+	require.Empty(t, sessionIDs(t, store, "alice"))
+	_, err := store.GetByID(context.Background(), laptop)
+	require.ErrorIs(t, err, ErrSessionIDNotFoundInStore)
+
+	// The index is gone with the last session
+	raw, err := store.Storage.Get(principalIndexPrefix + "alice")
+	require.NoError(t, err)
+	require.Nil(t, raw)
+
+	require.Equal(t, []string{other}, sessionIDs(t, store, "bob"))
+	require.ErrorIs(t, store.RevokeSessions(context.Background(), ""), ErrEmptyPrincipal)
+}
+
+func Test_Store_MaxSessionsPerPrincipal(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	store := NewStore(Config{MaxSessionsPerPrincipal: 2})
+
+	first := newPrincipalSession(t, app, store, "alice", "first")
+	second := newPrincipalSession(t, app, store, "alice", "second")
+	newPrincipalSession(t, app, store, "bob", "first")
+
+	// Make the first session the most recently seen
+	sess, err := store.GetByID(context.Background(), first)
+	require.NoError(t, err)
+	store.indexMu.Lock()
+	entries, err := store.loadIndex(context.Background(), "alice")
+	require.NoError(t, err)
+	for i := range entries {
+		if entries[i].ID == second {
+			entries[i].LastSeen = entries[i].LastSeen.Add(-time.Hour)
+		}
+	}
+	require.NoError(t, store.saveIndex(context.Background(), "alice", entries))
+	store.indexMu.Unlock()
+	require.NoError(t, sess.Save())
+	sess.Release()
+
+	// The least recently seen session is evicted
+	third := newPrincipalSession(t, app, store, "alice", "third")
+	require.ElementsMatch(t, []string{first, third}, sessionIDs(t, store, "alice"))
+	_, err = store.GetByID(context.Background(), second)
+	require.ErrorIs(t, err, ErrSessionIDNotFoundInStore)
+
+	// Other principals are not affected
+	require.Len(t, sessionIDs(t, store, "bob"), 1)
+}
+
+func Test_Session_Principal_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	store := NewStore()
+
+	t.Run("regenerate", func(t *testing.T) {
+		t.Parallel()
+		id := newPrincipalSession(t, app, store, "regenerate", "ua")
+
+		sess, err := store.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		defer sess.Release()
+		require.NoError(t, sess.Regenerate())
+		require.Empty(t, sessionIDs(t, store, "regenerate"))
+
+		// The principal is kept, and the new ID indexed on save
+		require.NoError(t, sess.Save())
+		require.Equal(t, []string{sess.ID()}, sessionIDs(t, store, "regenerate"))
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+		id := newPrincipalSession(t, app, store, "reset", "ua")
+
+		sess, err := store.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		defer sess.Release()
+		require.NoError(t, sess.Reset())
+		require.Empty(t, sess.Principal())
+		require.NoError(t, sess.Save())
+		require.Empty(t, sessionIDs(t, store, "reset"))
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		t.Parallel()
+		id := newPrincipalSession(t, app, store, "destroy", "ua")
+
+		sess, err := store.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		defer sess.Release()
+		require.NoError(t, sess.Destroy())
+		require.Empty(t, sessionIDs(t, store, "destroy"))
+	})
+
+	t.Run("change principal", func(t *testing.T) {
+		t.Parallel()
+		id := newPrincipalSession(t, app, store, "from", "ua")
+
+		sess, err := store.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		defer sess.Release()
+		sess.SetPrincipal("to")
+		require.NoError(t, sess.Save())
+		require.Empty(t, sessionIDs(t, store, "from"))
+		require.Equal(t, []string{id}, sessionIDs(t, store, "to"))
+
+		sess.SetPrincipal("")
+		require.NoError(t, sess.Save())
+		require.Empty(t, sessionIDs(t, store, "to"))
+	})
+}
+
+func Test_Session_Middleware_Principal(t *testing.T) {
+	t.Parallel()
+
+	handler, store := NewWithStore()
+	app := fiber.New()
+	app.Use(handler)
+	app.Post("/login", func(c fiber.Ctx) error {
+		sess := FromContext(c)
+		if err := sess.Regenerate(); err != nil {
+			return err
+		}
+		sess.SetPrincipal(c.Query("user"))
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/me", func(c fiber.Ctx) error {
+		return c.SendString(FromContext(c).Principal())
+	})
+	app.Post("/logout-everywhere", func(c fiber.Ctx) error {
+		sess := FromContext(c)
+		return store.RevokeSessions(c, sess.Principal(), sess.ID())
+	})
+
+	login := func(userAgent string) *http.Cookie {
+		req := httptest.NewRequest(fiber.MethodPost, "/login?user=alice", http.NoBody)
+		req.Header.Set(fiber.HeaderUserAgent, userAgent)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		cookies := resp.Cookies()
+		require.Len(t, cookies, 1)
+		return cookies[0]
+	}
+	me := func(cookie *http.Cookie) string {
+		req := httptest.NewRequest(fiber.MethodGet, "/me", http.NoBody)
+		req.AddCookie(cookie)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body := make([]byte, 16)
+		n, _ := resp.Body.Read(body) //nolint:errcheck // The body is small
+		return string(body[:n])
+	}
+
+	laptop := login("laptop")
+	phone := login("phone")
+	require.Equal(t, "alice", me(laptop))
+	require.Equal(t, "alice", me(phone))
+	require.ElementsMatch(t, []string{laptop.Value, phone.Value}, sessionIDs(t, store, "alice"))
+
+	req := httptest.NewRequest(fiber.MethodPost, "/logout-everywhere", http.NoBody)
+	req.AddCookie(laptop)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Equal(t, "alice", me(laptop))
+	require.Empty(t, me(phone))
+	require.Equal(t, []string{laptop.Value}, sessionIDs(t, store, "alice"))
+}
+
+func Test_Session_Middleware_IndexKeyCookie(t *testing.T) {
+	t.Parallel()
+
+	handler, store := NewWithStore()
+	app := fiber.New()
+	app.Use(handler)
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(FromContext(c).ID())
+	})
+
+	newPrincipalSession(t, app, store, "alice", "laptop")
+
+	// The index of a principal with sessions and of one without must both
+	// give a fresh session, so the response tells nothing about them
+	for _, principal := range []string{"alice", "bob"} {
+		req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: principalIndexPrefix + principal})
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NotEqual(t, principalIndexPrefix+principal, string(body))
+	}
+
+	_, err := store.GetByID(context.Background(), principalIndexPrefix+"alice")
+	require.ErrorIs(t, err, ErrSessionIDNotFoundInStore)
+	require.Len(t, sessionIDs(t, store, "alice"), 1)
+}
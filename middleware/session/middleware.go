@@ -370,6 +370,39 @@ func (m *Middleware) ID() string {
 	return m.Session.ID()
 }
 
+// Principal returns the principal of the session, or an empty string if the
+// session has none.
+//
+// Returns:
+//   - string: The principal of the session.
+//
+// Usage:
+//
+//	userID := m.Principal()
+func (m *Middleware) Principal() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.Session.Principal()
+}
+
+// SetPrincipal associates the session with a principal, such as a user ID,
+// so it can be listed and revoked with the store. An empty principal removes
+// the association.
+//
+// Parameters:
+//   - principal: The principal of the session.
+//
+// Usage:
+//
+//	m.SetPrincipal(userID)
+func (m *Middleware) SetPrincipal(principal string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Session.SetPrincipal(principal)
+}
+
 // Reset resets the session.
 //
 // Returns:
@@ -18,14 +18,15 @@ import (
 // Session serializes access to its internal state with mutexes, but it is
 // request-scoped and must not be used after the request lifecycle ends.
 type Session struct {
-	ctx         fiber.Ctx            // fiber context
-	config      *Store               // store configuration
-	data        *data                // key value data
-	id          string               // session id
-	extractor   extractors.Extractor // extractor that supplied the session ID
-	idleTimeout time.Duration        // idleTimeout of this session
-	mu          sync.RWMutex         // Mutex to protect non-data fields
-	isFresh     bool                 // if new session
+	ctx             fiber.Ctx            // fiber context
+	config          *Store               // store configuration
+	data            *data                // key value data
+	id              string               // session id
+	formerPrincipal string               // principal whose index the session must leave on save
+	extractor       extractors.Extractor // extractor that supplied the session ID
+	idleTimeout     time.Duration        // idleTimeout of this session
+	mu              sync.RWMutex         // Mutex to protect non-data fields
+	isFresh         bool                 // if new session
 }
 
 type absExpirationKeyType int
@@ -92,6 +93,7 @@ func (s *Session) Release() {
 func releaseSession(s *Session) {
 	s.mu.Lock()
 	s.id = ""
+	s.formerPrincipal = ""
 	s.idleTimeout = 0
 	s.ctx = nil
 	s.config = nil
@@ -215,7 +217,7 @@ func (s *Session) DestroyWithContext(ctx context.Context) error {
 	defer s.mu.Unlock()
 
 	// Use external Storage if exist
-	if err := s.config.Storage.DeleteWithContext(ctx, s.id); err != nil {
+	if err := s.deleteStored(ctx); err != nil {
 		return err
 	}
 
@@ -259,7 +261,7 @@ func (s *Session) RegenerateWithContext(ctx context.Context) error {
 	defer s.mu.Unlock()
 
 	// Delete old id from storage
-	if err := s.config.Storage.DeleteWithContext(ctx, s.id); err != nil {
+	if err := s.deleteStored(ctx); err != nil {
 		return err
 	}
 
@@ -300,7 +302,7 @@ func (s *Session) ResetWithContext(ctx context.Context) error {
 	defer s.mu.Unlock()
 
 	// Delete old id from storage
-	if err := s.config.Storage.DeleteWithContext(ctx, s.id); err != nil {
+	if err := s.deleteStored(ctx); err != nil {
 		return err
 	}
 
@@ -320,6 +322,19 @@ func (s *Session) ResetWithContext(ctx context.Context) error {
 	return nil
 }
 
+// deleteStored deletes the session from the storage and the index of its
+// principal. Sealed sessions have nothing stored. It must be called with s.mu
+// held.
+func (s *Session) deleteStored(ctx context.Context) error {
+	if s.config.sealer != nil {
+		return nil
+	}
+	if err := s.config.Storage.DeleteWithContext(ctx, s.id); err != nil {
+		return err
+	}
+	return s.unindex(ctx)
+}
+
 // refresh generates a new session, and sets session.isFresh to be true.
 func (s *Session) refresh() {
 	s.id = s.config.KeyGenerator()
@@ -389,7 +404,9 @@ func (s *Session) saveSessionWithContext(ctx context.Context) error {
 	}
 
 	// Update client cookie
-	s.setSession()
+	if s.config.sealer == nil {
+		s.setSession()
+	}
 
 	// Encode session data
 	s.data.RLock()
@@ -399,8 +416,16 @@ func (s *Session) saveSessionWithContext(ctx context.Context) error {
 		return fmt.Errorf("failed to encode data: %w", err)
 	}
 
+	// Seal the data into the client cookies instead of the storage
+	if s.config.sealer != nil {
+		return s.setSealedCookies(encodedBytes)
+	}
+
 	// Pass copied bytes with session id to provider
-	return s.config.Storage.SetWithContext(ctx, s.id, encodedBytes, s.idleTimeout)
+	if err := s.config.Storage.SetWithContext(ctx, s.id, encodedBytes, s.idleTimeout); err != nil {
+		return err
+	}
+	return s.index(ctx)
 }
 
 // Keys retrieves all keys in the current session.
@@ -515,6 +540,11 @@ func (s *Session) delSession() {
 		return
 	}
 
+	if s.config.sealer != nil {
+		s.delSealedCookies(0)
+		return
+	}
+
 	// Get all relevant extractors
 	relevantExtractors := s.getExtractorInfo()
 
@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -33,6 +34,8 @@ const (
 // Store manages session data using the configured storage backend.
 type Store struct {
 	Config
+	sealer  *cookieSealer // seals the session data into cookies, if CookieKeys is set
+	indexMu sync.Mutex    // serializes updates of the principal indexes
 }
 
 // NewStore creates a new session store with the provided configuration.
@@ -50,14 +53,18 @@ func NewStore(config ...Config) *Store {
 	// Set default config
 	cfg := configDefault(config...)
 
-	if cfg.Storage == nil {
-		cfg.Storage = memory.New()
-	}
-
 	store := &Store{
 		Config: cfg,
 	}
 
+	if len(cfg.CookieKeys) > 0 {
+		store.sealer = mustCookieSealer(&cfg)
+	} else if cfg.Storage == nil {
+		store.Storage = memory.New()
+	}
+
+	store.RegisterType(principalKey)
+
 	if cfg.AbsoluteTimeout > 0 {
 		store.RegisterType(absExpirationKey)
 		store.RegisterType(time.Time{})
@@ -125,10 +132,17 @@ func (s *Store) Get(c fiber.Ctx) (*Session, error) {
 func (s *Store) getSession(c fiber.Ctx) (*Session, error) {
 	var rawData []byte
 	var err error
-
-	id, ok := c.Locals(sessionIDContextKey).(string)
-	if !ok {
-		id = s.getSessionID(c)
+	var id string
+
+	if s.sealer != nil {
+		// The session data is sealed into the cookies
+		id, rawData = s.getSealedSession(c)
+	} else {
+		var ok bool
+		id, ok = c.Locals(sessionIDContextKey).(string)
+		if !ok {
+			id = s.getSessionID(c)
+		}
 	}
 
 	selectedExtractor, hasExtractor := c.Locals(sessionExtractorContextKey).(extractors.Extractor)
@@ -138,8 +152,14 @@ func (s *Store) getSession(c fiber.Ctx) (*Session, error) {
 
 	isFresh := false // Session is not fresh initially; only set to true if we generate a new ID
 
+	// The keys of the principal indexes are not sessions, whatever the
+	// client sends
+	if s.sealer == nil && isIndexKey(id) {
+		id = ""
+	}
+
 	// Attempt to fetch session data if an ID is provided
-	if id != "" {
+	if id != "" && s.sealer == nil {
 		rawData, err = s.Storage.GetWithContext(c, id)
 		if err != nil {
 			return nil, err
@@ -242,6 +262,9 @@ func (s *Store) getSessionID(c fiber.Ctx) string {
 //	    // handle error
 //	}
 func (s *Store) Reset(ctx context.Context) error {
+	if s.sealer != nil {
+		return ErrNotSupportedByCookieStore
+	}
 	return s.Storage.ResetWithContext(ctx)
 }
 
@@ -263,6 +286,9 @@ func (s *Store) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return ErrEmptySessionID
 	}
+	if s.sealer != nil {
+		return ErrNotSupportedByCookieStore
+	}
 	return s.Storage.DeleteWithContext(ctx, id)
 }
 
@@ -303,6 +329,12 @@ func (s *Store) GetByID(ctx context.Context, id string) (*Session, error) {
 	if id == "" {
 		return nil, ErrEmptySessionID
 	}
+	if s.sealer != nil {
+		return nil, ErrNotSupportedByCookieStore
+	}
+	if isIndexKey(id) {
+		return nil, ErrSessionIDNotFoundInStore
+	}
 
 	rawData, err := s.Storage.GetWithContext(ctx, id)
 	if err != nil {
@@ -1,9 +1,11 @@
 package sse
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
 )
 
 // Handler writes events to a single SSE stream.
@@ -62,3 +64,97 @@ func configDefault(config ...Config) Config {
 	}
 	return cfg
 }
+
+// BufferPolicy decides what happens to an event published to a subscriber
+// whose buffer is full.
+type BufferPolicy int
+
+const (
+	// BufferDisconnect ends the stream of the slow subscriber: Hub.Subscribe
+	// returns ErrSlowConsumer. A client that reconnects with Last-Event-ID
+	// catches up from the replay buffer.
+	BufferDisconnect BufferPolicy = iota
+	// BufferDropOldest discards the oldest buffered event to make room.
+	BufferDropOldest
+	// BufferDropNewest discards the published event.
+	BufferDropNewest
+)
+
+// HubConfig defines the config for a Hub.
+type HubConfig struct {
+	// Storage holds the replay buffer of every topic, so that it outlives
+	// the process or is shared with other instances of the app reading the
+	// same storage. Events are only fanned out to the subscribers of the hub
+	// they are published to.
+	//
+	// Optional. Default: nil (the replay buffer is kept in memory)
+	Storage fiber.Storage
+
+	// JSONEncoder encodes event data that is neither a string nor a byte
+	// slice.
+	//
+	// Optional. Default: json.Marshal
+	JSONEncoder utils.JSONMarshal
+
+	// ReplaySize is the number of events kept per topic for clients that
+	// reconnect with a Last-Event-ID header. Values less than or equal to
+	// zero are replaced by the default size.
+	//
+	// Optional. Default: 100
+	ReplaySize int
+
+	// ReplayTTL is how long an event stays in the replay buffer. Values less
+	// than or equal to zero keep events until ReplaySize newer ones evict them.
+	//
+	// Optional. Default: 0
+	ReplayTTL time.Duration
+
+	// BufferSize is the number of events buffered for each subscriber while
+	// its stream is busy writing. Values less than or equal to zero are
+	// replaced by the default size.
+	//
+	// Optional. Default: 64
+	BufferSize int
+
+	// BufferPolicy decides what happens when a subscriber's buffer is full.
+	//
+	// Optional. Default: BufferDisconnect
+	BufferPolicy BufferPolicy
+
+	// DisableReplay disables the replay buffer: clients reconnecting with a
+	// Last-Event-ID header only receive events published after they
+	// subscribed.
+	//
+	// Optional. Default: false
+	DisableReplay bool
+}
+
+// HubConfigDefault is the default config of a Hub.
+var HubConfigDefault = HubConfig{
+	Storage:       nil,
+	JSONEncoder:   json.Marshal,
+	ReplaySize:    100,
+	ReplayTTL:     0,
+	BufferSize:    64,
+	BufferPolicy:  BufferDisconnect,
+	DisableReplay: false,
+}
+
+// Helper function to set default values.
+func hubConfigDefault(config ...HubConfig) HubConfig {
+	if len(config) < 1 {
+		return HubConfigDefault
+	}
+
+	cfg := config[0]
+	if cfg.JSONEncoder == nil {
+		cfg.JSONEncoder = HubConfigDefault.JSONEncoder
+	}
+	if cfg.ReplaySize <= 0 {
+		cfg.ReplaySize = HubConfigDefault.ReplaySize
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = HubConfigDefault.BufferSize
+	}
+	return cfg
+}
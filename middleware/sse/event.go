@@ -31,12 +31,22 @@ type Event struct {
 }
 
 func writeEvent(w *bufio.Writer, event Event, jsonMarshal ...utils.JSONMarshal) error {
-	data, err := eventData(event.Data, jsonMarshalOrDefault(jsonMarshal))
-	if err != nil {
+	var frame bytes.Buffer
+	if err := appendEvent(&frame, event, jsonMarshalOrDefault(jsonMarshal)); err != nil {
 		return err
 	}
+	if _, err := w.Write(frame.Bytes()); err != nil {
+		return fmt.Errorf("sse: write event: %w", err)
+	}
+	return nil
+}
 
-	var frame bytes.Buffer
+// appendEvent encodes event to frame, in the SSE wire format.
+func appendEvent(frame *bytes.Buffer, event Event, jsonMarshal utils.JSONMarshal) error {
+	data, err := eventData(event.Data, jsonMarshal)
+	if err != nil {
+		return err
+	}
 
 	if event.ID != "" {
 		id, err := sanitizeField(event.ID)
@@ -44,7 +54,7 @@ func writeEvent(w *bufio.Writer, event Event, jsonMarshal ...utils.JSONMarshal)
 			return fmt.Errorf("sse: invalid id: %w", err)
 		}
 		if id != "" {
-			appendField(&frame, "id", id)
+			appendField(frame, "id", id)
 		}
 	}
 	if event.Name != "" {
@@ -53,19 +63,16 @@ func writeEvent(w *bufio.Writer, event Event, jsonMarshal ...utils.JSONMarshal)
 			return fmt.Errorf("sse: invalid event: %w", err)
 		}
 		if name != "" {
-			appendField(&frame, "event", name)
+			appendField(frame, "event", name)
 		}
 	}
 	if event.Retry > 0 {
-		appendField(&frame, "retry", utils.FormatInt(event.Retry.Milliseconds()))
+		appendField(frame, "retry", utils.FormatInt(event.Retry.Milliseconds()))
 	}
 	if data.hasData {
-		appendData(&frame, data.data)
+		appendData(frame, data.data)
 	}
 	frame.WriteByte('\n') //nolint:errcheck // bytes.Buffer writes never fail.
-	if _, err := w.Write(frame.Bytes()); err != nil {
-		return fmt.Errorf("sse: write event: %w", err)
-	}
 	return nil
 }
 
@@ -0,0 +1,459 @@
+package sse
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var (
+	// ErrHubClosed is returned by Hub.Subscribe when the hub is closed while
+	// the stream is subscribed, and by Hub.Publish once it is closed.
+	ErrHubClosed = errors.New("sse: hub closed")
+
+	// ErrSlowConsumer is returned by Hub.Subscribe when the stream could not
+	// keep up with the events published to it and BufferDisconnect dropped it.
+	ErrSlowConsumer = errors.New("sse: slow consumer dropped")
+
+	errNoTopics = errors.New("sse: Subscribe needs at least one topic")
+)
+
+// hubStorageKeyPrefix prefixes the storage key of a topic's replay buffer.
+const hubStorageKeyPrefix = "sse_hub:"
+
+// Hub fans events published to a topic out to every stream subscribed to it,
+// and keeps the latest events of each topic to replay them to clients that
+// reconnect with a Last-Event-ID header. Hub is safe for concurrent use.
+//
+//	hub := sse.NewHub()
+//
+//	app.Get("/events/:room", sse.New(sse.Config{
+//		Handler: func(c fiber.Ctx, stream *sse.Stream) error {
+//			return hub.Subscribe(stream, c.Params("room"))
+//		},
+//	}))
+//
+//	err := hub.Publish("lobby", sse.Event{Name: "message", Data: msg})
+type Hub struct {
+	replay replayBuffer
+	topics map[string]map[*subscriber]struct{}
+	// locks serializes the replay buffer updates of each topic, so the
+	// storage round trips of one topic do not hold up the others.
+	locks map[string]*topicLock
+	done   chan struct{}
+	cfg    HubConfig
+	// lastSeq is the sequence number of the last published event.
+	lastSeq uint64
+	// streams is the number of subscribed streams.
+	streams int
+	mu      sync.Mutex
+	closed  bool
+}
+
+// topicLock is the lock of a topic, removed once no call holds or waits for it.
+type topicLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// subscriber is a stream subscribed to one or more topics.
+type subscriber struct {
+	queue    chan *hubEvent
+	dropped  chan struct{}
+	topics   []string
+	dropOnce sync.Once
+}
+
+// hubEvent is a published event, encoded once for every subscriber.
+type hubEvent struct {
+	ID    string `json:"id"`
+	Frame []byte `json:"frame"`
+	// Seq orders events across topics. It is derived from the clock, so
+	// events kept in a Storage stay ordered after a restart.
+	Seq  uint64 `json:"seq"`
+	Time int64  `json:"time"`
+}
+
+// NewHub creates a new Hub.
+func NewHub(config ...HubConfig) *Hub {
+	cfg := hubConfigDefault(config...)
+
+	h := &Hub{
+		topics: make(map[string]map[*subscriber]struct{}),
+		locks:  make(map[string]*topicLock),
+		done:   make(chan struct{}),
+		cfg:    cfg,
+	}
+	switch {
+	case cfg.DisableReplay:
+	case cfg.Storage != nil:
+		h.replay = &storageReplay{storage: cfg.Storage, size: cfg.ReplaySize, ttl: cfg.ReplayTTL}
+	default:
+		h.replay = &memoryReplay{topics: make(map[string][]*hubEvent), size: cfg.ReplaySize, ttl: cfg.ReplayTTL}
+	}
+	return h
+}
+
+// Publish sends event to every stream subscribed to topic and adds it to the
+// topic's replay buffer. An event without an ID is given one, so clients can
+// resume after it. Publishing never waits for subscribers: those whose buffer
+// is full are handled according to the BufferPolicy.
+func (h *Hub) Publish(topic string, event Event) error {
+	// The topic lock is held from the replay buffer update to the delivery,
+	// so a subscribe reading the buffer sees the event either there or live.
+	unlock := h.lockTopics(topic)
+	defer unlock()
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHubClosed
+	}
+
+	now := time.Now()
+	seq := max(uint64(now.UnixNano()), h.lastSeq+1) //nolint:gosec // G115: the clock is after 1970
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(seq, 10)
+	}
+
+	var frame bytes.Buffer
+	if err := appendEvent(&frame, event, h.cfg.JSONEncoder); err != nil {
+		h.mu.Unlock()
+		return err
+	}
+	h.lastSeq = seq
+	h.mu.Unlock()
+
+	e := &hubEvent{ID: event.ID, Frame: frame.Bytes(), Seq: seq, Time: now.UnixNano()}
+	if h.replay != nil {
+		if err := h.replay.append(topic, e); err != nil {
+			return fmt.Errorf("sse: store event: %w", err)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.topics[topic] {
+		h.deliver(sub, e)
+	}
+	return nil
+}
+
+// Subscribe writes the events published to topics to stream until the stream
+// ends, the hub is closed, or the stream is dropped as a slow consumer. It is
+// meant to be returned from a Handler.
+//
+// When the client sent a Last-Event-ID header, the buffered events published
+// after that event are written first. An ID the hub no longer knows replays
+// the whole buffer, as the client may have missed any of it.
+//
+// Subscribe returns the stream's error when a write fails, ErrHubClosed when
+// the hub is closed and ErrSlowConsumer when the stream was dropped.
+func (h *Hub) Subscribe(stream *Stream, topics ...string) error {
+	if len(topics) == 0 {
+		return errNoTopics
+	}
+
+	sub := &subscriber{
+		queue:   make(chan *hubEvent, h.cfg.BufferSize),
+		dropped: make(chan struct{}),
+		topics:  slices.Compact(slices.Sorted(slices.Values(topics))),
+	}
+
+	backlog, err := h.subscribe(sub, stream.LastEventID())
+	if err != nil {
+		return err
+	}
+	defer h.unsubscribe(sub)
+
+	for _, e := range backlog {
+		if err := stream.writeFrame(e.Frame); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case e := <-sub.queue:
+			if err := stream.writeFrame(e.Frame); err != nil {
+				return err
+			}
+		case <-sub.dropped:
+			return ErrSlowConsumer
+		case <-h.done:
+			return ErrHubClosed
+		case <-stream.Done():
+			return stream.Err()
+		}
+	}
+}
+
+// Subscribers returns the number of streams subscribed to topic.
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// Len returns the number of subscribed streams.
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.streams
+}
+
+// Close ends every subscription, which makes Subscribe return ErrHubClosed,
+// and rejects further events. Call it before shutting the app down, since open
+// streams otherwise keep the server waiting. The Storage is not closed.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+}
+
+// subscribe registers sub and returns the events to replay to it. Both happen
+// under the locks of sub's topics, which Publish holds, so sub neither misses
+// an event nor receives one twice.
+func (h *Hub) subscribe(sub *subscriber, lastEventID string) ([]*hubEvent, error) {
+	unlock := h.lockTopics(sub.topics...)
+	defer unlock()
+
+	var backlog []*hubEvent
+	if h.replay != nil && lastEventID != "" {
+		var err error
+		if backlog, err = h.backlog(sub.topics, lastEventID); err != nil {
+			return nil, fmt.Errorf("sse: read replay buffer: %w", err)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+
+	for _, topic := range sub.topics {
+		subs, ok := h.topics[topic]
+		if !ok {
+			subs = make(map[*subscriber]struct{})
+			h.topics[topic] = subs
+		}
+		subs[sub] = struct{}{}
+	}
+	h.streams++
+	return backlog, nil
+}
+
+// lockTopics locks topics, which must be sorted so that concurrent calls lock
+// them in the same order, and returns the function unlocking them.
+func (h *Hub) lockTopics(topics ...string) func() {
+	h.mu.Lock()
+	locks := make([]*topicLock, len(topics))
+	for i, topic := range topics {
+		l, ok := h.locks[topic]
+		if !ok {
+			l = &topicLock{}
+			h.locks[topic] = l
+		}
+		l.refs++
+		locks[i] = l
+	}
+	h.mu.Unlock()
+
+	for _, l := range locks {
+		l.mu.Lock()
+	}
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for i, l := range locks {
+			l.mu.Unlock()
+			if l.refs--; l.refs == 0 {
+				delete(h.locks, topics[i])
+			}
+		}
+	}
+}
+
+func (h *Hub) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(sub)
+	h.streams--
+}
+
+// removeLocked stops delivering events to sub.
+func (h *Hub) removeLocked(sub *subscriber) {
+	for _, topic := range sub.topics {
+		subs := h.topics[topic]
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+}
+
+// backlog returns the buffered events of topics published after the event
+// identified by lastEventID, oldest first.
+func (h *Hub) backlog(topics []string, lastEventID string) ([]*hubEvent, error) {
+	var events []*hubEvent
+	for _, topic := range topics {
+		buffered, err := h.replay.events(topic)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, buffered...)
+	}
+
+	// IDs assigned by Publish are sequence numbers, which stay comparable
+	// once the event itself has left the buffer.
+	after, _ := strconv.ParseUint(lastEventID, 10, 64) //nolint:errcheck // IDs set by the publisher are looked up below
+	for _, e := range events {
+		if e.ID == lastEventID {
+			after = e.Seq
+			break
+		}
+	}
+
+	events = slices.DeleteFunc(events, func(e *hubEvent) bool {
+		return e.Seq <= after
+	})
+	slices.SortFunc(events, func(a, b *hubEvent) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return events, nil
+}
+
+// deliver queues e for sub without blocking, applying the BufferPolicy when
+// the queue is full. It is called with h.mu held.
+func (h *Hub) deliver(sub *subscriber, e *hubEvent) {
+	select {
+	case sub.queue <- e:
+		return
+	default:
+	}
+
+	switch h.cfg.BufferPolicy {
+	case BufferDropNewest:
+	case BufferDropOldest:
+		// Only Publish sends to the queue, under h.mu, so a receive always
+		// makes room unless the stream emptied the queue meanwhile.
+		select {
+		case <-sub.queue:
+		default:
+		}
+		select {
+		case sub.queue <- e:
+		default:
+		}
+	default:
+		h.removeLocked(sub)
+		sub.dropOnce.Do(func() {
+			close(sub.dropped)
+		})
+	}
+}
+
+// replayBuffer keeps the latest events of each topic.
+type replayBuffer interface {
+	append(topic string, e *hubEvent) error
+	events(topic string) ([]*hubEvent, error)
+}
+
+// memoryReplay keeps the replay buffers in memory.
+type memoryReplay struct {
+	topics map[string][]*hubEvent
+	size   int
+	ttl    time.Duration
+	mu     sync.Mutex
+}
+
+func (r *memoryReplay) append(topic string, e *hubEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics[topic] = trimReplay(append(r.topics[topic], e), r.size, r.ttl)
+	return nil
+}
+
+func (r *memoryReplay) events(topic string) ([]*hubEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := trimReplay(r.topics[topic], r.size, r.ttl)
+	if len(events) == 0 {
+		delete(r.topics, topic)
+		return nil, nil
+	}
+	r.topics[topic] = events
+	return slices.Clone(events), nil
+}
+
+// storageReplay keeps the replay buffer of each topic under its own key of a
+// fiber.Storage.
+type storageReplay struct {
+	storage fiber.Storage
+	size    int
+	ttl     time.Duration
+}
+
+func (r *storageReplay) append(topic string, e *hubEvent) error {
+	events, err := r.events(topic)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(trimReplay(append(events, e), r.size, r.ttl))
+	if err != nil {
+		return fmt.Errorf("failed to encode replay buffer: %w", err)
+	}
+	if err := r.storage.Set(hubStorageKeyPrefix+topic, raw, r.ttl); err != nil {
+		return fmt.Errorf("failed to write replay buffer: %w", err)
+	}
+	return nil
+}
+
+func (r *storageReplay) events(topic string) ([]*hubEvent, error) {
+	raw, err := r.storage.Get(hubStorageKeyPrefix + topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay buffer: %w", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var events []*hubEvent
+	if err := json.Unmarshal(raw, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode replay buffer: %w", err)
+	}
+	return trimReplay(events, r.size, r.ttl), nil
+}
+
+// trimReplay drops the events that expired or exceed size, oldest first.
+func trimReplay(events []*hubEvent, size int, ttl time.Duration) []*hubEvent {
+	if ttl > 0 {
+		oldest := time.Now().Add(-ttl).UnixNano()
+		expired := 0
+		for expired < len(events) && events[expired].Time < oldest {
+			expired++
+		}
+		events = events[expired:]
+	}
+	if len(events) > size {
+		events = events[len(events)-size:]
+	}
+	return events
+}
@@ -0,0 +1,402 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// hubClient subscribes a stream to hub in the background and reads the frames
+// written to it.
+type hubClient struct {
+	frames *bufio.Reader
+	stream *Stream
+	done   chan error
+}
+
+func subscribeHub(t *testing.T, hub *Hub, lastEventID string, topics ...string) *hubClient {
+	t.Helper()
+
+	pr, pw := io.Pipe()
+	client := &hubClient{
+		frames: bufio.NewReader(pr),
+		stream: newStream(context.Background(), bufio.NewWriter(pw), lastEventID),
+		done:   make(chan error, 1),
+	}
+	t.Cleanup(func() {
+		_ = pr.Close() //nolint:errcheck // unblocks a pending write
+		client.stream.closeStream()
+	})
+
+	before := hub.Len()
+	go func() {
+		client.done <- hub.Subscribe(client.stream, topics...)
+		_ = pw.Close() //nolint:errcheck // ends the reader
+	}()
+	require.Eventually(t, func() bool {
+		return hub.Len() > before
+	}, time.Second, time.Millisecond)
+
+	return client
+}
+
+// next reads the next frame written to the stream.
+func (c *hubClient) next(t *testing.T) string {
+	t.Helper()
+
+	var frame strings.Builder
+	for {
+		line, err := c.frames.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return frame.String()
+		}
+		frame.WriteString(line)
+	}
+}
+
+// wait returns what Subscribe returned.
+func (c *hubClient) wait(t *testing.T) error {
+	t.Helper()
+
+	select {
+	case err := <-c.done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe did not return")
+		return nil
+	}
+}
+
+func Test_Hub_PublishSubscribe(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	first := subscribeHub(t, hub, "", "news")
+	second := subscribeHub(t, hub, "", "news", "sports")
+	third := subscribeHub(t, hub, "", "sports")
+
+	require.Equal(t, 2, hub.Subscribers("news"))
+	require.Equal(t, 2, hub.Subscribers("sports"))
+	require.Zero(t, hub.Subscribers("weather"))
+	require.Equal(t, 3, hub.Len())
+
+	require.NoError(t, hub.Publish("news", Event{ID: "n1", Name: "headline", Data: "hello"}))
+	require.NoError(t, hub.Publish("sports", Event{ID: "s1", Data: map[string]int{"score": 2}}))
+	require.NoError(t, hub.Publish("weather", Event{Data: "nobody listens"}))
+
+	require.Equal(t, "id: n1\nevent: headline\ndata: hello\n", first.next(t))
+	require.Equal(t, "id: n1\nevent: headline\ndata: hello\n", second.next(t))
+	require.Equal(t, "id: s1\ndata: {\"score\":2}\n", second.next(t))
+	require.Equal(t, "id: s1\ndata: {\"score\":2}\n", third.next(t))
+
+	first.stream.closeStream()
+	require.NoError(t, first.wait(t))
+	require.Equal(t, 1, hub.Subscribers("news"))
+	require.Equal(t, 2, hub.Len())
+}
+
+func Test_Hub_PublishAssignsIDs(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	client := subscribeHub(t, hub, "", "topic")
+
+	require.NoError(t, hub.Publish("topic", Event{Data: "one"}))
+	require.NoError(t, hub.Publish("topic", Event{Data: "two"}))
+
+	first := client.next(t)
+	second := client.next(t)
+	require.Regexp(t, `^id: \d+\ndata: one\n$`, first)
+	require.Regexp(t, `^id: \d+\ndata: two\n$`, second)
+	require.Less(t, first[:strings.IndexByte(first, '\n')], second[:strings.IndexByte(second, '\n')])
+}
+
+func Test_Hub_PublishInvalidEvent(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	require.ErrorIs(t, hub.Publish("topic", Event{Name: "bad\nname"}), errInvalidField)
+
+	client := subscribeHub(t, hub, "0", "topic")
+	require.NoError(t, hub.Publish("topic", Event{ID: "ok", Data: "fine"}))
+	require.Equal(t, "id: ok\ndata: fine\n", client.next(t), "the invalid event was not buffered")
+}
+
+func Test_Hub_Replay(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(HubConfig{ReplaySize: 3})
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, hub.Publish("topic", Event{ID: "e" + id, Data: id}))
+	}
+	require.NoError(t, hub.Publish("other", Event{ID: "o1", Data: "other"}))
+
+	// Events after the last one the client saw
+	client := subscribeHub(t, hub, "e3", "topic")
+	require.NoError(t, hub.Publish("topic", Event{ID: "e5", Data: "5"}))
+	require.Equal(t, "id: e4\ndata: 4\n", client.next(t))
+	require.Equal(t, "id: e5\ndata: 5\n", client.next(t))
+
+	// An evicted ID replays the whole buffer, across the subscribed topics
+	client = subscribeHub(t, hub, "e1", "topic", "other")
+	require.Equal(t, "id: e3\ndata: 3\n", client.next(t))
+	require.Equal(t, "id: e4\ndata: 4\n", client.next(t))
+	require.Equal(t, "id: o1\ndata: other\n", client.next(t))
+	require.Equal(t, "id: e5\ndata: 5\n", client.next(t))
+
+	// A new client only receives new events
+	client = subscribeHub(t, hub, "", "topic")
+	require.NoError(t, hub.Publish("topic", Event{ID: "e6", Data: "6"}))
+	require.Equal(t, "id: e6\ndata: 6\n", client.next(t))
+}
+
+func Test_Hub_ReplayAssignedIDs(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(HubConfig{ReplaySize: 1})
+	require.NoError(t, hub.Publish("topic", Event{Data: "one"}))
+	require.NoError(t, hub.Publish("topic", Event{Data: "two"}))
+
+	client := subscribeHub(t, hub, "0", "topic")
+	frame := client.next(t)
+	require.Contains(t, frame, "data: two\n")
+	lastID := strings.TrimPrefix(frame[:strings.IndexByte(frame, '\n')], "id: ")
+
+	// The ID is past every buffered event: nothing is replayed
+	client = subscribeHub(t, hub, lastID, "topic")
+	require.NoError(t, hub.Publish("topic", Event{ID: "three", Data: "three"}))
+	require.Equal(t, "id: three\ndata: three\n", client.next(t))
+}
+
+func Test_Hub_ReplayTTL(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(HubConfig{ReplayTTL: 50 * time.Millisecond})
+	require.NoError(t, hub.Publish("topic", Event{ID: "old", Data: "old"}))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, hub.Publish("topic", Event{ID: "new", Data: "new"}))
+
+	client := subscribeHub(t, hub, "unknown", "topic")
+	require.Equal(t, "id: new\ndata: new\n", client.next(t))
+}
+
+func Test_Hub_ReplayStorage(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.New()
+	publisher := NewHub(HubConfig{Storage: storage, ReplaySize: 2})
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, publisher.Publish("topic", Event{ID: id, Data: id}))
+	}
+
+	raw, err := storage.Get(hubStorageKeyPrefix + "topic")
+	require.NoError(t, err)
+	require.NotNil(t, raw)
+
+	// Another hub on the same storage, e.g. after a restart
+	hub := NewHub(HubConfig{Storage: storage})
+	client := subscribeHub(t, hub, "b", "topic")
+	require.Equal(t, "id: c\ndata: c\n", client.next(t))
+}
+
+// blockingStorage blocks the writes of a key until release is closed.
+type blockingStorage struct {
+	*memory.Storage
+	writing chan struct{}
+	release chan struct{}
+	key     string
+}
+
+func (s *blockingStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == s.key {
+		close(s.writing)
+		<-s.release
+	}
+	return s.Storage.Set(key, val, exp)
+}
+
+func Test_Hub_ReplayStorageTopicLock(t *testing.T) {
+	t.Parallel()
+
+	storage := &blockingStorage{
+		Storage: memory.New(),
+		writing: make(chan struct{}),
+		release: make(chan struct{}),
+		key:     hubStorageKeyPrefix + "slow",
+	}
+	hub := NewHub(HubConfig{Storage: storage})
+
+	published := make(chan error, 1)
+	go func() {
+		published <- hub.Publish("slow", Event{ID: "slow", Data: "slow"})
+	}()
+	<-storage.writing
+
+	// The pending write of "slow" holds up neither other topics nor the hub.
+	client := subscribeHub(t, hub, "", "fast")
+	require.NoError(t, hub.Publish("fast", Event{ID: "fast", Data: "fast"}))
+	require.Equal(t, "id: fast\ndata: fast\n", client.next(t))
+	require.Equal(t, 1, hub.Subscribers("fast"))
+
+	close(storage.release)
+	require.NoError(t, <-published)
+}
+
+func Test_Hub_DisableReplay(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(HubConfig{DisableReplay: true})
+	require.NoError(t, hub.Publish("topic", Event{ID: "missed", Data: "missed"}))
+
+	client := subscribeHub(t, hub, "0", "topic")
+	require.NoError(t, hub.Publish("topic", Event{ID: "live", Data: "live"}))
+	require.Equal(t, "id: live\ndata: live\n", client.next(t))
+}
+
+func Test_Hub_SlowConsumer(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(HubConfig{BufferSize: 1})
+	client := subscribeHub(t, hub, "", "topic")
+
+	// The client does not read, so the first event blocks the stream and
+	// the second fills its buffer.
+	for range 3 {
+		require.NoError(t, hub.Publish("topic", Event{Data: "event"}))
+	}
+	require.Zero(t, hub.Subscribers("topic"))
+
+	go func() {
+		_, _ = io.Copy(io.Discard, client.frames) //nolint:errcheck // drains the stream
+	}()
+	require.ErrorIs(t, client.wait(t), ErrSlowConsumer)
+	require.Zero(t, hub.Len())
+}
+
+func Test_Hub_BufferPolicy(t *testing.T) {
+	t.Parallel()
+
+	event := func(id string) *hubEvent {
+		return &hubEvent{ID: id}
+	}
+	queued := func(sub *subscriber) []string {
+		var ids []string
+		for len(sub.queue) > 0 {
+			ids = append(ids, (<-sub.queue).ID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name    string
+		want    []string
+		policy  BufferPolicy
+		dropped bool
+	}{
+		{name: "disconnect", policy: BufferDisconnect, want: []string{"1", "2"}, dropped: true},
+		{name: "drop oldest", policy: BufferDropOldest, want: []string{"2", "3"}},
+		{name: "drop newest", policy: BufferDropNewest, want: []string{"1", "2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			hub := NewHub(HubConfig{BufferSize: 2, BufferPolicy: tc.policy})
+			sub := &subscriber{queue: make(chan *hubEvent, 2), dropped: make(chan struct{}), topics: []string{"topic"}}
+			hub.topics["topic"] = map[*subscriber]struct{}{sub: {}}
+
+			hub.mu.Lock()
+			for _, id := range []string{"1", "2", "3"} {
+				hub.deliver(sub, event(id))
+			}
+			hub.mu.Unlock()
+
+			require.Equal(t, tc.want, queued(sub))
+			select {
+			case <-sub.dropped:
+				require.True(t, tc.dropped)
+				require.Zero(t, hub.Subscribers("topic"))
+			default:
+				require.False(t, tc.dropped)
+				require.Equal(t, 1, hub.Subscribers("topic"))
+			}
+		})
+	}
+}
+
+func Test_Hub_Close(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	client := subscribeHub(t, hub, "", "topic")
+
+	hub.Close()
+	hub.Close()
+	require.ErrorIs(t, client.wait(t), ErrHubClosed)
+	require.ErrorIs(t, hub.Publish("topic", Event{Data: "late"}), ErrHubClosed)
+
+	stream := newStream(context.Background(), bufio.NewWriter(io.Discard), "")
+	require.ErrorIs(t, hub.Subscribe(stream, "topic"), ErrHubClosed)
+}
+
+func Test_Hub_SubscribeWithoutTopics(t *testing.T) {
+	t.Parallel()
+
+	stream := newStream(context.Background(), bufio.NewWriter(io.Discard), "")
+	require.ErrorIs(t, NewHub().Subscribe(stream), errNoTopics)
+}
+
+func Test_Hub_Handler(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	require.NoError(t, hub.Publish("room", Event{ID: "1", Name: "message", Data: "missed"}))
+
+	closed := make(chan error, 1)
+	app := fiber.New()
+	app.Get("/events/:room", New(Config{
+		DisableHeartbeat: true,
+		Handler: func(c fiber.Ctx, stream *Stream) error {
+			return hub.Subscribe(stream, c.Params("room"))
+		},
+		OnClose: func(_ fiber.Ctx, err error) {
+			closed <- err
+		},
+	}))
+
+	published := make(chan error, 1)
+	go func() {
+		for hub.Subscribers("room") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		published <- hub.Publish("room", Event{ID: "2", Name: "message", Data: "live"})
+		hub.Close()
+	}()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/events/room", http.NoBody)
+	req.Header.Set(fiber.HeaderLastEventID, "0")
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, <-published)
+	require.Equal(t, "id: 1\nevent: message\ndata: missed\n\nid: 2\nevent: message\ndata: live\n\n", string(body))
+
+	select {
+	case err := <-closed:
+		require.ErrorIs(t, err, ErrHubClosed)
+	case <-time.After(time.Second):
+		t.Fatal("OnClose was not called")
+	}
+}
@@ -2,8 +2,9 @@
 //
 // The package focuses on the SSE transport: response headers, wire formatting,
 // flushing, heartbeat comments, and disconnect detection via flush errors.
-// Application-specific concerns such as topics, replay storage, authentication,
-// and pub/sub fan-out intentionally stay outside the core middleware.
+// Hub fans events out to the streams subscribed to a topic and replays missed
+// events to reconnecting clients. Authentication and bridges to external
+// pub/sub systems stay outside the package.
 package sse
 
 import (
@@ -164,6 +165,16 @@ func (s *Stream) Retry(retry time.Duration) error {
 	})
 }
 
+// writeFrame writes an already encoded event and flushes it to the client.
+func (s *Stream) writeFrame(frame []byte) error {
+	return s.write(func(w *bufio.Writer) error {
+		if _, err := w.Write(frame); err != nil {
+			return fmt.Errorf("sse: write event: %w", err)
+		}
+		return nil
+	})
+}
+
 func (s *Stream) write(fn func(w *bufio.Writer) error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,353 @@
+package static
+
+import (
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"mime"
+	pathpkg "path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// cacheControlImmutable is the Cache-Control header of fingerprinted files.
+const cacheControlImmutable = "public, max-age=31536000, immutable"
+
+// precompressedEncoding is a content coding of the files Precompressed
+// serves, with the suffix of their prebuilt siblings.
+type precompressedEncoding struct {
+	name   string
+	suffix string
+}
+
+// precompressedEncodings lists the codings served by Precompressed, from
+// the most to the least preferred.
+var precompressedEncodings = [...]precompressedEncoding{
+	{name: "br", suffix: ".br"},
+	{name: "zstd", suffix: ".zst"},
+	{name: "gzip", suffix: ".gz"},
+}
+
+// assetServer resolves requests to the files of the static root, to serve
+// their precompressed siblings, validate fingerprinted files and fall back to
+// the SPA index.
+type assetServer struct {
+	fsys    fs.FS
+	cfg     *Config
+	rewrite func(fctx *fasthttp.RequestCtx) []byte
+	// rootFile is the name of the file in fsys served for every request if
+	// the root is a file.
+	rootFile string
+	// fingerprints caches the *fingerprint of the fingerprinted files by name.
+	fingerprints sync.Map
+}
+
+// fingerprint is the hash of the content of a file, valid as long as the
+// size and the modification time of the file are unchanged.
+type fingerprint struct {
+	modTime time.Time
+	hash    string
+	size    int64
+}
+
+// resolve returns the name in fsys of the file the request is for, following
+// the index names for a directory, or an empty string if there is none.
+func (a *assetServer) resolve(fctx *fasthttp.RequestCtx) string {
+	if a.fsys == nil {
+		return ""
+	}
+	if a.rootFile != "" {
+		return a.rootFile
+	}
+
+	p := a.rewrite(fctx)
+	if string(p) == invalidPathSentinel {
+		return ""
+	}
+	name := utils.Trim(string(p), '/')
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(a.fsys, name)
+	if err != nil {
+		return ""
+	}
+	if info.Mode().IsRegular() {
+		return name
+	}
+	if !info.IsDir() {
+		return ""
+	}
+	for _, index := range a.cfg.IndexNames {
+		indexName := pathpkg.Join(name, index)
+		if info, err := fs.Stat(a.fsys, indexName); err == nil && info.Mode().IsRegular() {
+			return indexName
+		}
+	}
+	return ""
+}
+
+// serve answers the request for the file name with a 304 Not Modified
+// response if it is fingerprinted and the client has it, or with its
+// precompressed sibling in the coding the client prefers. It reports whether
+// it wrote a response; otherwise the file is left to fasthttp.
+func (a *assetServer) serve(c fiber.Ctx, name string) bool {
+	if name == "" {
+		return false
+	}
+	fctx := c.RequestCtx()
+
+	if a.cfg.Fingerprinted != nil && a.cfg.Fingerprinted(name) {
+		if tag, ok := a.notModified(c, name); ok {
+			if a.cfg.Precompressed {
+				c.Vary(fiber.HeaderAcceptEncoding)
+			}
+			fctx.NotModified()
+			fctx.Response.Header.Set(fiber.HeaderETag, tag)
+			return true
+		}
+	}
+
+	if !a.cfg.Precompressed {
+		return false
+	}
+	c.Vary(fiber.HeaderAcceptEncoding)
+
+	// Ranges are served from the identity coding, like fasthttp does
+	accept := c.Get(fiber.HeaderAcceptEncoding)
+	if accept == "" || c.Get(fiber.HeaderRange) != "" {
+		return false
+	}
+
+	// Codings with the same quality are picked in the order of preference
+	var best *precompressedEncoding
+	bestQuality := 0.0
+	for i := range precompressedEncodings {
+		enc := &precompressedEncodings[i]
+		quality := encodingQuality(accept, enc.name)
+		if quality <= bestQuality {
+			continue
+		}
+		if info, err := fs.Stat(a.fsys, name+enc.suffix); err == nil && info.Mode().IsRegular() {
+			best, bestQuality = enc, quality
+		}
+	}
+	if best == nil {
+		return false
+	}
+	return a.serveEncoded(fctx, name, *best)
+}
+
+// serveEncoded serves the sibling of the file name in the coding enc.
+func (a *assetServer) serveEncoded(fctx *fasthttp.RequestCtx, name string, enc precompressedEncoding) bool {
+	f, err := a.fsys.Open(name + enc.suffix)
+	if err != nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close() //nolint:errcheck // not needed
+		return false
+	}
+
+	// The content type of the file, as fasthttp would send it
+	hdr := &fctx.Response.Header
+	if contentType := mime.TypeByExtension(pathpkg.Ext(name)); contentType != "" {
+		hdr.SetContentType(contentType)
+	} else {
+		hdr.SetContentType(fiber.MIMEOctetStream)
+	}
+	hdr.Set(fiber.HeaderContentEncoding, enc.name)
+	if modTime := info.ModTime(); !modTime.IsZero() {
+		if !fctx.IfModifiedSince(modTime) {
+			_ = f.Close() //nolint:errcheck // not needed
+			fctx.NotModified()
+			return true
+		}
+		hdr.SetLastModified(modTime)
+	}
+
+	fctx.SetStatusCode(fiber.StatusOK)
+	if fctx.IsHead() {
+		_ = f.Close() //nolint:errcheck // not needed
+		fctx.Response.ResetBody()
+		fctx.Response.SkipBody = true
+		hdr.SetContentLength(int(info.Size()))
+		return true
+	}
+	// The body stream is closed once it is sent
+	fctx.SetBodyStream(f, int(info.Size()))
+	return true
+}
+
+// notModified returns the entity tag of the If-None-Match header of the
+// request that is that of the fingerprinted file name, in a coding the client
+// still accepts, if there is one.
+func (a *assetServer) notModified(c fiber.Ctx, name string) (string, bool) {
+	inm := c.Get(fiber.HeaderIfNoneMatch)
+	if inm == "" {
+		return "", false
+	}
+	base, ok := a.fingerprintETag(name, "")
+	if !ok {
+		return "", false
+	}
+	for tag := range strings.SplitSeq(inm, ",") {
+		tag = utils.TrimSpace(tag)
+		if tag == "*" {
+			return base, true
+		}
+		if tag == base {
+			return tag, true
+		}
+		coded, ok := strings.CutPrefix(tag, base[:len(base)-1]+"-")
+		if !ok || !strings.HasSuffix(coded, `"`) {
+			continue
+		}
+		if encodingQuality(c.Get(fiber.HeaderAcceptEncoding), coded[:len(coded)-1]) > 0 {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// setFingerprintHeaders makes the response for the fingerprinted file name
+// cacheable forever, with a strong entity tag for its content coding unless
+// it already has one.
+func (a *assetServer) setFingerprintHeaders(fctx *fasthttp.RequestCtx, name string) {
+	hdr := &fctx.Response.Header
+	hdr.Set(fiber.HeaderCacheControl, cacheControlImmutable)
+	if len(hdr.Peek(fiber.HeaderETag)) == 0 {
+		if tag, ok := a.fingerprintETag(name, string(hdr.ContentEncoding())); ok {
+			hdr.Set(fiber.HeaderETag, tag)
+		}
+	}
+}
+
+// fingerprintETag returns the strong entity tag of the fingerprinted file
+// name in the content coding encoding, from the hash of its content. The hash
+// is computed again once the size or the modification time of the file
+// changes, so a file rebuilt under the same name gets a new entity tag.
+func (a *assetServer) fingerprintETag(name, encoding string) (string, bool) {
+	info, err := fs.Stat(a.fsys, name)
+	if err != nil {
+		return "", false
+	}
+
+	var hash string
+	if v, ok := a.fingerprints.Load(name); ok {
+		if fp, ok := v.(*fingerprint); ok && fp.size == info.Size() && fp.modTime.Equal(info.ModTime()) {
+			hash = fp.hash
+		}
+	}
+	if hash == "" {
+		f, err := a.fsys.Open(name)
+		if err != nil {
+			return "", false
+		}
+		h := fnv.New64a()
+		_, err = io.Copy(h, f)
+		_ = f.Close() //nolint:errcheck // not needed
+		if err != nil {
+			return "", false
+		}
+		hash = strconv.FormatUint(h.Sum64(), 16)
+		a.fingerprints.Store(name, &fingerprint{size: info.Size(), modTime: info.ModTime(), hash: hash})
+	}
+
+	tag := `"` + hash
+	if encoding != "" {
+		tag += "-" + encoding
+	}
+	return tag + `"`, true
+}
+
+// encodingQuality returns the quality the Accept-Encoding header accept gives
+// to coding, from its own entry or else from the * entry.
+func encodingQuality(accept, coding string) float64 {
+	quality, wildcard := -1.0, 0.0
+	for entry := range strings.SplitSeq(accept, ",") {
+		name, params, _ := strings.Cut(entry, ";")
+		name = utils.TrimSpace(name)
+		if !utils.EqualFold(name, coding) && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			if v, ok := strings.CutPrefix(utils.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			quality = q
+		}
+	}
+	if quality < 0 {
+		return wildcard
+	}
+	return quality
+}
+
+// IsFingerprinted reports whether the file name contains a content hash
+// added by a bundler, as the last part of its base name before the extension:
+// a hex hash of at least 8 digits, as in app.3f9a1c2b.js, or an 8 character
+// base64url or base32 hash, as in index-DiwrgTda.js or app-5QW2LZQA.js.
+func IsFingerprinted(name string) bool {
+	base := pathpkg.Base(name)
+	stem := strings.TrimSuffix(base, pathpkg.Ext(base))
+	i := strings.LastIndexAny(stem, ".-")
+	if i <= 0 {
+		return false
+	}
+	return isContentHash(stem[i+1:])
+}
+
+// isContentHash reports whether s looks like a hash: hex digits and letters
+// mixed, or 8 characters mixing upper case letters with lower case letters
+// or digits.
+func isContentHash(s string) bool {
+	var digits, lower, upper, hexLetters, other int
+	for i := range len(s) {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits++
+		case c >= 'a' && c <= 'f':
+			hexLetters++
+			lower++
+		case c >= 'g' && c <= 'z':
+			lower++
+		case c >= 'A' && c <= 'Z':
+			upper++
+		case c == '_':
+		default:
+			other++
+		}
+	}
+	if other > 0 {
+		return false
+	}
+	if len(s) >= 8 && len(s) <= 64 && digits > 0 && hexLetters > 0 && digits+hexLetters == len(s) {
+		return true
+	}
+	return len(s) == 8 && upper > 0 && (lower > 0 || digits > 0)
+}
+
+// isSPARoute reports whether a request for path, which no file matched, is
+// for a client-side route rather than a missing asset: its last segment has
+// no extension.
+func isSPARoute(path string) bool {
+	return !strings.Contains(path[strings.LastIndexByte(path, '/')+1:], ".")
+}
@@ -30,6 +30,21 @@ type Config struct {
 	// Optional. Default: nil
 	NotFoundHandler fiber.Handler
 
+	// Fingerprinted reports whether a file name contains a content hash, so
+	// that its responses are cached as immutable, with a strong ETag, instead
+	// of for MaxAge. IsFingerprinted recognizes the hashes of common bundlers.
+	//
+	// Optional. Default: nil
+	Fingerprinted func(name string) bool
+
+	// SPAFallback is the file, relative to the root, served for the requests
+	// of single-page application routes: paths matching no file whose last
+	// segment has no extension. Its responses are not cached without
+	// revalidation. Missing assets such as /app.js are still not found.
+	//
+	// Optional. Default: ""
+	SPAFallback string `json:"spa_fallback"`
+
 	// The names of the index files for serving a directory.
 	//
 	// Optional. Default: []string{"index.html"}.
@@ -53,6 +68,13 @@ type Config struct {
 	// Optional. Default: false
 	Compress bool `json:"compress"`
 
+	// When set to true, files with a prebuilt .br, .zst or .gz sibling are
+	// served from it to the clients accepting its coding. Unlike Compress,
+	// it works with any FS, such as an embed.FS, as nothing is written.
+	//
+	// Optional. Default: false
+	Precompressed bool `json:"precompressed"`
+
 	// When set to true, enables byte range requests.
 	//
 	// Optional. Default: false
@@ -150,10 +150,15 @@ func New(root string, cfg ...Config) fiber.Handler {
 
 	var createFS sync.Once
 	var fileHandler fasthttp.RequestHandler
+	var fallbackHandler fasthttp.RequestHandler
+	var fallbackName string
 	var cacheControlValue string
 	var rootCheckErr error
 	var rootIsFile bool
 
+	assets := &assetServer{cfg: &config}
+	resolveAssets := config.Precompressed || config.Fingerprinted != nil
+
 	// adjustments for io/fs compatibility: io/fs paths are always relative and
 	// slash-separated, so a leading slash (e.g. "/" or "/dist") is never a valid
 	// fs path and makes isFile's fs.FS.Open fail, which sends every request to
@@ -213,23 +218,26 @@ func New(root string, cfg ...Config) fiber.Handler {
 				copy(fsRootPrefix[1:], root)
 			}
 
-			fileServer := &fasthttp.FS{
-				Root:                   fsRoot,
-				FS:                     config.FS,
-				AllowEmptyRoot:         true,
-				GenerateIndexPages:     config.Browse,
-				AcceptByteRange:        config.ByteRange,
-				Compress:               config.Compress,
-				CompressBrotli:         config.Compress, // Brotli compression won't work without this
-				CompressZstd:           config.Compress, // Zstd compression won't work without this
-				CompressedFileSuffixes: c.App().Config().CompressedFileSuffixes,
-				CacheDuration:          config.CacheDuration,
-				SkipCache:              config.CacheDuration < 0,
-				IndexNames:             config.IndexNames,
-				PathNotFound: func(fctx *fasthttp.RequestCtx) {
-					fctx.Response.SetStatusCode(fiber.StatusNotFound)
-				},
+			newFileServer := func() *fasthttp.FS {
+				return &fasthttp.FS{
+					Root:                   fsRoot,
+					FS:                     config.FS,
+					AllowEmptyRoot:         true,
+					GenerateIndexPages:     config.Browse,
+					AcceptByteRange:        config.ByteRange,
+					Compress:               config.Compress,
+					CompressBrotli:         config.Compress, // Brotli compression won't work without this
+					CompressZstd:           config.Compress, // Zstd compression won't work without this
+					CompressedFileSuffixes: c.App().Config().CompressedFileSuffixes,
+					CacheDuration:          config.CacheDuration,
+					SkipCache:              config.CacheDuration < 0,
+					IndexNames:             config.IndexNames,
+					PathNotFound: func(fctx *fasthttp.RequestCtx) {
+						fctx.Response.SetStatusCode(fiber.StatusNotFound)
+					},
+				}
 			}
+			fileServer := newFileServer()
 
 			fileServer.PathRewrite = func(fctx *fasthttp.RequestCtx) []byte {
 				path := fctx.Path()
@@ -283,10 +291,62 @@ func New(root string, cfg ...Config) fiber.Handler {
 			}
 
 			fileHandler = fileServer.NewRequestHandler()
+
+			// The files of the root, for precompressed siblings, fingerprinted
+			// files and the SPA fallback
+			assets.rewrite = fileServer.PathRewrite
+			switch {
+			case rootCheckErr != nil:
+			case config.FS != nil:
+				assets.fsys = config.FS
+				if rootIsFile {
+					assets.rootFile = root
+				}
+			case rootIsFile:
+				assets.fsys = os.DirFS(filepath.Dir(root))
+				assets.rootFile = filepath.Base(root)
+			default:
+				assets.fsys = os.DirFS(root)
+			}
+
+			// The SPA fallback is served like the file it names was requested
+			if config.SPAFallback != "" && !rootIsFile {
+				fallbackPath, err := sanitizePath([]byte("/"+config.SPAFallback), fileServer.FS)
+				if err != nil {
+					fallbackPath = []byte(invalidPathSentinel)
+				} else if len(fsRootPrefix) > 0 {
+					fallbackPath = append(append([]byte(nil), fsRootPrefix...), fallbackPath...)
+				}
+				fallbackName = utils.Trim(string(fallbackPath), '/')
+
+				fallbackServer := newFileServer()
+				fallbackServer.PathRewrite = func(_ *fasthttp.RequestCtx) []byte {
+					return fallbackPath
+				}
+				fallbackHandler = fallbackServer.NewRequestHandler()
+			}
 		})
 
 		// Serve file
-		fileHandler(c.RequestCtx())
+		fctx := c.RequestCtx()
+		var name string
+		if resolveAssets {
+			name = assets.resolve(fctx)
+		}
+		if !assets.serve(c, name) {
+			fileHandler(fctx)
+		}
+
+		// Serve the SPA fallback for client-side routes
+		fallback := false
+		if fallbackHandler != nil && fctx.Response.StatusCode() == fiber.StatusNotFound && isSPARoute(c.Path()) {
+			fallback = true
+			name = fallbackName
+			fctx.Response.SetStatusCode(fiber.StatusOK)
+			if !assets.serve(c, name) {
+				fallbackHandler(fctx)
+			}
+		}
 
 		// Sets the response Content-Disposition header to attachment if the Download option is true
 		if config.Download {
@@ -301,8 +361,14 @@ func New(root string, cfg ...Config) fiber.Handler {
 		status := c.RequestCtx().Response.StatusCode()
 
 		if status != fiber.StatusNotFound && status != fiber.StatusForbidden {
-			if cacheControlValue != "" {
-				c.RequestCtx().Response.Header.Set(fiber.HeaderCacheControl, cacheControlValue)
+			switch {
+			case fallback:
+				// The fallback names the assets of the current build
+				fctx.Response.Header.Set(fiber.HeaderCacheControl, "no-cache")
+			case name != "" && config.Fingerprinted != nil && config.Fingerprinted(name):
+				assets.setFingerprintHeaders(fctx, name)
+			case cacheControlValue != "":
+				fctx.Response.Header.Set(fiber.HeaderCacheControl, cacheControlValue)
 			}
 
 			if config.ModifyResponse != nil {
@@ -11,6 +11,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/stretchr/testify/require"
@@ -842,6 +843,337 @@ func Test_Static_FS_RootLeadingSlash(t *testing.T) {
 	}
 }
 
+func staticTestRequest(t *testing.T, app *fiber.App, method, target string, headers ...string) (resp *http.Response, body string) { //nolint:nonamedreturns // gocritic unnamedResult prefers naming the returned response and body
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, http.NoBody)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := app.Test(req, testConfig)
+	require.NoError(t, err, "app.Test(req)")
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(b)
+}
+
+// go test -run Test_Static_Precompressed
+func Test_Static_Precompressed(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/static*", New("", Config{
+		FS: fstest.MapFS{
+			"app.js":          {Data: []byte("identity")},
+			"app.js.br":       {Data: []byte("brotli")},
+			"app.js.gz":       {Data: []byte("gzip")},
+			"index.html":      {Data: []byte("<html></html>")},
+			"index.html.zst":  {Data: []byte("zstd")},
+			"sub/data.json":   {Data: []byte("{}")},
+			"sub/data.json.x": {Data: []byte("unknown")},
+		},
+		Precompressed: true,
+	}))
+
+	testCases := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		encoding       string
+	}{
+		{name: "preferred coding", path: "/static/app.js", acceptEncoding: "gzip, deflate, br, zstd", body: "brotli", encoding: "br"},
+		{name: "only accepted coding", path: "/static/app.js", acceptEncoding: "gzip", body: "gzip", encoding: "gzip"},
+		{name: "q-values", path: "/static/app.js", acceptEncoding: "br;q=0.5, gzip", body: "gzip", encoding: "gzip"},
+		{name: "refused coding", path: "/static/app.js", acceptEncoding: "br;q=0, gzip", body: "gzip", encoding: "gzip"},
+		{name: "no sibling in coding", path: "/static/app.js", acceptEncoding: "zstd", body: "identity"},
+		{name: "no accept-encoding", path: "/static/app.js", body: "identity"},
+		{name: "index", path: "/static/", acceptEncoding: "zstd", body: "zstd", encoding: "zstd", contentType: fiber.MIMETextHTMLCharsetUTF8},
+		{name: "no sibling", path: "/static/sub/data.json", acceptEncoding: "br, gzip", body: "{}", contentType: fiber.MIMEApplicationJSON},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, body := staticTestRequest(t, app, fiber.MethodGet, tc.path, fiber.HeaderAcceptEncoding, tc.acceptEncoding)
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+			require.Equal(t, tc.body, body)
+			require.Equal(t, tc.encoding, resp.Header.Get(fiber.HeaderContentEncoding))
+			require.Equal(t, fiber.HeaderAcceptEncoding, resp.Header.Get(fiber.HeaderVary))
+			if tc.contentType != "" {
+				require.Equal(t, tc.contentType, resp.Header.Get(fiber.HeaderContentType))
+			} else {
+				require.Equal(t, "text/javascript; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+			}
+		})
+	}
+
+	// Ranges are served from the identity coding
+	resp, body := staticTestRequest(t, app, fiber.MethodGet, "/static/app.js", fiber.HeaderAcceptEncoding, "br", fiber.HeaderRange, "bytes=0-1")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "identity", body)
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentEncoding))
+
+	// HEAD requests get the length of the sibling
+	resp, body = staticTestRequest(t, app, fiber.MethodHead, "/static/app.js", fiber.HeaderAcceptEncoding, "br")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Empty(t, body)
+	require.Equal(t, "br", resp.Header.Get(fiber.HeaderContentEncoding))
+	require.Equal(t, "6", resp.Header.Get(fiber.HeaderContentLength))
+
+	// Siblings are not served without the file they are for
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/static/missing.js", fiber.HeaderAcceptEncoding, "br")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// go test -run Test_Static_Precompressed_Dir
+func Test_Static_Precompressed_Dir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "style.css"), []byte("body{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "style.css.gz"), []byte("gzip"), 0o600))
+	modTime := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "style.css.gz"), modTime, modTime))
+
+	app := fiber.New()
+	app.Get("/*", New(dir, Config{Precompressed: true, MaxAge: 60}))
+
+	resp, body := staticTestRequest(t, app, fiber.MethodGet, "/style.css", fiber.HeaderAcceptEncoding, "gzip")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "gzip", body)
+	require.Equal(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+	require.Equal(t, fiber.MIMETextCSSCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+	require.Equal(t, modTime.Format(http.TimeFormat), resp.Header.Get(fiber.HeaderLastModified))
+	require.Equal(t, "public, max-age=60", resp.Header.Get(fiber.HeaderCacheControl))
+
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/style.css",
+		fiber.HeaderAcceptEncoding, "gzip", fiber.HeaderIfModifiedSince, modTime.Format(http.TimeFormat))
+	require.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	require.Empty(t, body)
+
+	// A file root has siblings too
+	app = fiber.New()
+	app.Get("/theme.css", New(filepath.Join(dir, "style.css"), Config{Precompressed: true}))
+
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/theme.css", fiber.HeaderAcceptEncoding, "gzip")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "gzip", body)
+}
+
+// go test -run Test_Static_Fingerprinted
+func Test_Static_Fingerprinted(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/*", New("dist", Config{
+		FS: fstest.MapFS{
+			"dist/assets/app.3f9a1c2b.js":    {Data: []byte("identity")},
+			"dist/assets/app.3f9a1c2b.js.br": {Data: []byte("brotli")},
+			"dist/assets/main.js":            {Data: []byte("main")},
+		},
+		Fingerprinted: IsFingerprinted,
+		Precompressed: true,
+		MaxAge:        60,
+	}))
+
+	resp, body := staticTestRequest(t, app, fiber.MethodGet, "/assets/app.3f9a1c2b.js")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "identity", body)
+	require.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get(fiber.HeaderCacheControl))
+	etag := resp.Header.Get(fiber.HeaderETag)
+	require.Regexp(t, `^"[0-9a-f]+"$`, etag)
+
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/assets/app.3f9a1c2b.js", fiber.HeaderAcceptEncoding, "br")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "brotli", body)
+	require.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get(fiber.HeaderCacheControl))
+	brETag := resp.Header.Get(fiber.HeaderETag)
+	require.Equal(t, strings.TrimSuffix(etag, `"`)+`-br"`, brETag)
+
+	// Revalidation
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/assets/app.3f9a1c2b.js", fiber.HeaderIfNoneMatch, `"other", `+etag)
+	require.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	require.Empty(t, body)
+	require.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+	require.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get(fiber.HeaderCacheControl))
+
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/assets/app.3f9a1c2b.js",
+		fiber.HeaderIfNoneMatch, brETag, fiber.HeaderAcceptEncoding, "br")
+	require.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	require.Equal(t, brETag, resp.Header.Get(fiber.HeaderETag))
+
+	// A client no longer accepting the coding gets the file again
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/assets/app.3f9a1c2b.js",
+		fiber.HeaderIfNoneMatch, brETag, fiber.HeaderAcceptEncoding, "gzip")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "identity", body)
+
+	// Other files keep MaxAge
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/assets/main.js")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "public, max-age=60", resp.Header.Get(fiber.HeaderCacheControl))
+	require.Empty(t, resp.Header.Get(fiber.HeaderETag))
+
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/assets/app.00000000.js", fiber.HeaderIfNoneMatch, "*")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// go test -run Test_Static_Fingerprinted_ContentChange
+func Test_Static_Fingerprinted_ContentChange(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"dist/app.3f9a1c2b.js": {Data: []byte("first"), ModTime: time.Unix(1, 0)},
+	}
+	app := fiber.New()
+	app.Get("/*", New("dist", Config{
+		FS:            fsys,
+		Fingerprinted: IsFingerprinted,
+		CacheDuration: -1,
+	}))
+
+	resp, body := staticTestRequest(t, app, fiber.MethodGet, "/app.3f9a1c2b.js")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "first", body)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	require.Regexp(t, `^"[0-9a-f]+"$`, etag)
+
+	// The file is rebuilt under the same name
+	fsys["dist/app.3f9a1c2b.js"] = &fstest.MapFile{Data: []byte("second"), ModTime: time.Unix(2, 0)}
+
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/app.3f9a1c2b.js", fiber.HeaderIfNoneMatch, etag)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "second", body)
+	require.NotEqual(t, etag, resp.Header.Get(fiber.HeaderETag))
+}
+
+// go test -run Test_Static_SPAFallback
+func Test_Static_SPAFallback(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"web/index.html":             {Data: []byte("<html>app</html>")},
+		"web/index.html.br":          {Data: []byte("brotli")},
+		"web/assets/app.3f9a1c2b.js": {Data: []byte("js")},
+	}
+
+	app := fiber.New()
+	app.Get("/app*", New("web", Config{
+		FS:            fsys,
+		SPAFallback:   "index.html",
+		Fingerprinted: IsFingerprinted,
+		Precompressed: true,
+		MaxAge:        60,
+	}))
+	app.Get("/api/users", func(c fiber.Ctx) error {
+		return c.SendString("users")
+	})
+
+	// Client-side routes get the index
+	for _, path := range []string{"/app/settings", "/app/users/42/", "/app/users/42"} {
+		resp, body := staticTestRequest(t, app, fiber.MethodGet, path)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode, path)
+		require.Equal(t, "<html>app</html>", body, path)
+		require.Equal(t, fiber.MIMETextHTMLCharsetUTF8, resp.Header.Get(fiber.HeaderContentType), path)
+		require.Equal(t, "no-cache", resp.Header.Get(fiber.HeaderCacheControl), path)
+	}
+
+	resp, body := staticTestRequest(t, app, fiber.MethodGet, "/app/settings", fiber.HeaderAcceptEncoding, "br")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "brotli", body)
+	require.Equal(t, "no-cache", resp.Header.Get(fiber.HeaderCacheControl))
+
+	// Files are served as usual
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/app/assets/app.3f9a1c2b.js")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "js", body)
+	require.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get(fiber.HeaderCacheControl))
+
+	// Missing assets are not found
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/app/assets/app.00000000.js")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Other routes are left alone
+	resp, body = staticTestRequest(t, app, fiber.MethodGet, "/api/users")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "users", body)
+
+	// A missing fallback is not found
+	app = fiber.New()
+	app.Get("/*", New("", Config{FS: fsys, SPAFallback: "missing.html"}))
+
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/settings")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// go test -run Test_Static_SPAFallback_Dir
+func Test_Static_SPAFallback_Dir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>app</html>"), 0o600))
+
+	notFound := 0
+	app := fiber.New()
+	app.Get("/*", New(dir, Config{
+		SPAFallback: "index.html",
+		NotFoundHandler: func(c fiber.Ctx) error {
+			notFound++
+			return c.SendStatus(fiber.StatusTeapot)
+		},
+	}))
+
+	resp, body := staticTestRequest(t, app, fiber.MethodGet, "/dashboard")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "<html>app</html>", body)
+
+	resp, _ = staticTestRequest(t, app, fiber.MethodGet, "/favicon.ico")
+	require.Equal(t, fiber.StatusTeapot, resp.StatusCode)
+	require.Equal(t, 1, notFound)
+}
+
+func Test_encodingQuality(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 1.0, encodingQuality("gzip, br", "br"), 0)
+	require.InDelta(t, 0.5, encodingQuality("gzip, BR;q=0.5", "br"), 0)
+	require.InDelta(t, 0.0, encodingQuality("gzip", "br"), 0)
+	require.InDelta(t, 0.0, encodingQuality("*;q=0.3, br;q=0", "br"), 0)
+	require.InDelta(t, 0.3, encodingQuality("*;q=0.3, gzip", "br"), 0)
+	require.InDelta(t, 0.0, encodingQuality("", "br"), 0)
+}
+
+func Test_IsFingerprinted(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{
+		"app.3f9a1c2b.js",
+		"assets/main.3f9a1c2b4d5e6f70.css",
+		"assets/index-DiwrgTda.js",
+		"chunk-5QW2LZQA.js",
+		"logo.a1b2c3d4e5.svg",
+	} {
+		require.True(t, IsFingerprinted(name), name)
+	}
+
+	for _, name := range []string{
+		"app.js",
+		"3f9a1c2b.js",
+		"style.min.css",
+		"vendor-20240101.js",
+		"index-component.js",
+		"app.deadbeef.js",
+		"app.3f9a1c2.js",
+		"assets/app.3f9a1c2b/main.js",
+		"chunk-ABCDEFGH.js",
+	} {
+		require.False(t, IsFingerprinted(name), name)
+	}
+}
+
 func Test_isFile(t *testing.T) {
 	t.Parallel()
 
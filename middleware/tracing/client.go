@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3/client"
+)
+
+// ClientTracer propagates trace context on the requests of a client.Client
+// and records them as client spans. Its hooks are added to a client with
+// Register:
+//
+//	ct := tracing.NewClient(tracing.ClientConfig{Exporter: exporter})
+//	cc := ct.Register(client.New())
+//
+// A request made with a context holding a span is recorded as a child of that
+// span. In a handler, that is c.Context() if the app sets PassLocalsToContext,
+// or ContextWithSpan(c.Context(), SpanFromContext(c)).
+type ClientTracer struct {
+	pending map[*client.Request]*Span
+	cfg     ClientConfig
+	mu      sync.Mutex
+}
+
+// NewClient creates a new ClientTracer.
+func NewClient(config ...ClientConfig) *ClientTracer {
+	// Set default config
+	cfg := clientConfigDefault(config...)
+
+	return &ClientTracer{
+		pending: make(map[*client.Request]*Span),
+		cfg:     cfg,
+	}
+}
+
+// Register adds the request, response and error hooks of the tracer to c and
+// returns it.
+func (t *ClientTracer) Register(c *client.Client) *client.Client {
+	return c.AddRequestHook(t.RequestHook).
+		AddResponseHook(t.ResponseHook).
+		AddErrorHook(t.ErrorHook)
+}
+
+// RequestHook starts the span of the request and sets its traceparent and
+// tracestate headers.
+func (t *ClientTracer) RequestHook(c *client.Client, req *client.Request) error {
+	var parent SpanContext
+	if span := SpanFromContext(req.Context()); span != nil {
+		parent = span.SpanContext
+	}
+	span := newSpan(req.Method(), SpanKindClient, parent,
+		!parent.IsValid() && (t.cfg.Sampler == nil || t.cfg.Sampler(req)))
+
+	req.SetHeader(HeaderTraceparent, span.SpanContext.Traceparent())
+	if span.SpanContext.TraceState != "" {
+		req.SetHeader(HeaderTracestate, span.SpanContext.TraceState)
+	}
+
+	if t.cfg.Exporter == nil || !span.SpanContext.Sampled() {
+		return nil
+	}
+	full := requestURL(c, req)
+	span.SetAttributes(
+		String("http.request.method", req.Method()),
+		String("url.full", full),
+	)
+	if u, err := url.Parse(full); err == nil {
+		span.SetAttributes(String("server.address", u.Hostname()))
+	}
+
+	t.mu.Lock()
+	t.pending[req] = span
+	t.mu.Unlock()
+	return nil
+}
+
+// ResponseHook ends the span of the request with the status of its response.
+func (t *ClientTracer) ResponseHook(_ *client.Client, resp *client.Response, req *client.Request) error {
+	span := t.started(req)
+	if span == nil {
+		return nil
+	}
+
+	status := resp.StatusCode()
+	span.SetAttributes(Int("http.response.status_code", status))
+	if status >= 400 {
+		span.SetStatus(StatusError, "")
+	}
+	export(t.cfg.Exporter, span)
+	return nil
+}
+
+// ErrorHook ends the span of a request that failed before its response
+// reached ResponseHook, such as on connection errors and timeouts.
+func (t *ClientTracer) ErrorHook(_ *client.Client, req *client.Request, err error) {
+	span := t.started(req)
+	if span == nil {
+		return
+	}
+
+	span.SetStatus(StatusError, err.Error())
+	export(t.cfg.Exporter, span)
+}
+
+// started removes and returns the span of req, or nil if it is not recorded.
+func (t *ClientTracer) started(req *client.Request) *Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := t.pending[req]
+	delete(t.pending, req)
+	return span
+}
+
+// requestURL returns the URL req is sent to.
+func requestURL(c *client.Client, req *client.Request) string {
+	raw := req.URL()
+	if !strings.Contains(raw, "://") {
+		raw = c.BaseURL() + raw
+	}
+	return raw
+}
@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+)
+
+func startClientTestServer(t *testing.T) *fasthttputil.InmemoryListener {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	app := fiber.New()
+	app.Get("/:status", func(c fiber.Ctx) error {
+		if c.Params("status") == "fail" {
+			c.Status(fiber.StatusInternalServerError)
+		}
+		return c.SendString(c.Get(HeaderTraceparent) + " " + c.Get(HeaderTracestate))
+	})
+
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // stopped in cleanup
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+	return ln
+}
+
+func newTestClient(t *testing.T, ct *ClientTracer) *client.Client {
+	t.Helper()
+
+	ln := startClientTestServer(t)
+	cc := ct.Register(client.New())
+	cc.SetDial(func(_ string) (net.Conn, error) {
+		return ln.Dial()
+	})
+	return cc
+}
+
+func Test_ClientTracer_Root(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	cc := newTestClient(t, NewClient(ClientConfig{Exporter: rec}))
+
+	resp, err := cc.Get("http://backend.local/ok")
+	require.NoError(t, err)
+
+	spans := rec.exported()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "GET", span.Name)
+	require.Equal(t, SpanKindClient, span.Kind)
+	require.Equal(t, StatusUnset, span.Status)
+	require.False(t, span.Parent.IsValid())
+	require.Equal(t, span.SpanContext.Traceparent()+" ", string(resp.Body()))
+	require.Equal(t, "http://backend.local/ok", attribute(span, "url.full"))
+	require.Equal(t, "backend.local", attribute(span, "server.address"))
+	require.Equal(t, int64(200), attribute(span, "http.response.status_code"))
+}
+
+func Test_ClientTracer_Child(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	cc := newTestClient(t, NewClient(ClientConfig{Exporter: rec}))
+
+	app := fiber.New(fiber.Config{PassLocalsToContext: true})
+	app.Use(New(Config{Exporter: rec}))
+	app.Get("/", func(c fiber.Ctx) error {
+		resp, err := cc.R().SetContext(c.Context()).Get("http://backend.local/ok")
+		if err != nil {
+			return err
+		}
+		return c.SendString(string(resp.Body()))
+	})
+
+	req, err := http.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set(HeaderTraceparent, testTraceparent)
+	req.Header.Set(HeaderTracestate, "rojo=1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// The client span ends first
+	spans := rec.exported()
+	require.Len(t, spans, 2)
+	clientSpan, serverSpan := spans[0], spans[1]
+	require.Equal(t, SpanKindClient, clientSpan.Kind)
+	require.Equal(t, SpanKindServer, serverSpan.Kind)
+	require.Equal(t, testTraceID, clientSpan.SpanContext.TraceID.String())
+	require.Equal(t, serverSpan.SpanContext.SpanID, clientSpan.Parent)
+	require.Equal(t, clientSpan.SpanContext.Traceparent()+" rojo=1", string(body))
+}
+
+func Test_ClientTracer_Failures(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	ct := NewClient(ClientConfig{Exporter: rec})
+	cc := newTestClient(t, ct)
+
+	_, err := cc.Get("http://backend.local/fail")
+	require.NoError(t, err)
+
+	down := ct.Register(client.New())
+	down.SetDial(func(_ string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+	_, err = down.Get("http://backend.local/ok")
+	require.ErrorContains(t, err, "connection refused")
+
+	spans := rec.exported()
+	require.Len(t, spans, 2)
+	require.Equal(t, StatusError, spans[0].Status)
+	require.Equal(t, int64(500), attribute(spans[0], "http.response.status_code"))
+	require.Equal(t, StatusError, spans[1].Status)
+	require.Contains(t, spans[1].StatusMessage, "connection refused")
+	require.Nil(t, attribute(spans[1], "http.response.status_code"))
+	require.Empty(t, ct.pending)
+}
+
+func Test_ClientTracer_Sampling(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	cc := newTestClient(t, NewClient(ClientConfig{
+		Exporter: rec,
+		Sampler: func(_ *client.Request) bool {
+			return false
+		},
+	}))
+
+	// Unsampled traces are propagated, but not recorded
+	resp, err := cc.Get("http://backend.local/ok")
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(resp.Body()), "-00 "))
+
+	// Child spans follow their parent, whatever the sampler says
+	parent := &Span{SpanContext: SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, TraceFlags: FlagSampled}}
+	resp, err = cc.R().SetContext(ContextWithSpan(context.Background(), parent)).Get("http://backend.local/ok")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(resp.Body()), "00-01000000000000000000000000000000-"))
+	require.True(t, strings.HasSuffix(string(resp.Body()), "-01 "))
+
+	spans := rec.exported()
+	require.Len(t, spans, 1)
+	require.Equal(t, parent.SpanContext.SpanID, spans[0].Parent)
+}
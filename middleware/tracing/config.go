@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/client"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// Exporter receives the server spans that are sampled once they end.
+	// Without one, trace context is still propagated but no span is recorded.
+	//
+	// Optional. Default: nil
+	Exporter Exporter
+
+	// Sampler decides whether to sample a request that starts a new trace.
+	// Requests carrying a traceparent header follow the sampled flag of
+	// their caller.
+	//
+	// Optional. Default: nil, which samples every trace
+	Sampler func(c fiber.Ctx) bool
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next: nil,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	return config[0]
+}
+
+// ClientConfig defines the config for ClientTracer.
+type ClientConfig struct {
+	// Exporter receives the client spans that are sampled once they end.
+	// Without one, trace context is still propagated but no span is recorded.
+	//
+	// Optional. Default: nil
+	Exporter Exporter
+
+	// Sampler decides whether to sample a request made outside of any trace.
+	// Requests made with the context of a span follow its sampled flag.
+	//
+	// Optional. Default: nil, which samples every trace
+	Sampler func(req *client.Request) bool
+}
+
+// ClientConfigDefault is the default config of ClientTracer.
+var ClientConfigDefault = ClientConfig{}
+
+// Helper function to set default values
+func clientConfigDefault(config ...ClientConfig) ClientConfig {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ClientConfigDefault
+	}
+
+	// Override default config
+	return config[0]
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Exporter sends ended spans to a tracing backend. ExportSpans is called from
+// the goroutine serving the request, so exporters that do I/O should queue
+// the spans and send them in the background.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []*Span) error
+}
+
+// scopeName is the instrumentation scope of the exported spans.
+const scopeName = "github.com/gofiber/fiber/v3/middleware/tracing"
+
+// JSONExporter writes spans to an io.Writer in the OTLP JSON format, one
+// export request per line, as read by the file receiver of the OpenTelemetry
+// Collector. It suits development and tests, with os.Stdout or a file.
+type JSONExporter struct {
+	w        io.Writer
+	resource []otlpAttribute
+	mu       sync.Mutex
+}
+
+// NewJSONExporter creates a JSONExporter writing to w. The resource
+// attributes, such as String("service.name", "api"), describe the service
+// every span comes from.
+func NewJSONExporter(w io.Writer, resource ...Attribute) *JSONExporter {
+	return &JSONExporter{w: w, resource: otlpAttributes(resource)}
+}
+
+// ExportSpans writes spans as a line of OTLP JSON.
+func (e *JSONExporter) ExportSpans(_ context.Context, spans []*Span) error {
+	out := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		out[i] = otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			TraceState:        span.SpanContext.TraceState,
+			Name:              span.Name,
+			Kind:              int(span.Kind),
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: int(span.Status), Message: span.StatusMessage},
+		}
+		if span.Parent.IsValid() {
+			out[i].ParentSpanID = span.Parent.String()
+		}
+	}
+
+	line, err := json.Marshal(otlpExport{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: e.resource},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: out}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("tracing: failed to encode spans: %w", err)
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.w.Write(line); err != nil {
+		return fmt.Errorf("tracing: failed to export spans: %w", err)
+	}
+	return nil
+}
+
+// The OTLP JSON encoding of an export request. IDs are hex encoded and 64 bit
+// integers are strings.
+type (
+	otlpExport struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		TraceState        string          `json:"traceState,omitempty"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Status            otlpStatus      `json:"status"`
+		Attributes        []otlpAttribute `json:"attributes"`
+		Kind              int             `json:"kind"`
+	}
+	otlpStatus struct {
+		Message string `json:"message,omitempty"`
+		Code    int    `json:"code,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func otlpAttributes(attrs []Attribute) []otlpAttribute {
+	out := make([]otlpAttribute, 0, len(attrs))
+	for _, attr := range attrs {
+		var value otlpValue
+		switch v := attr.Value.(type) {
+		case string:
+			value.StringValue = &v
+		case bool:
+			value.BoolValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case float64:
+			value.DoubleValue = &v
+		default:
+			continue
+		}
+		out = append(out, otlpAttribute{Key: attr.Key, Value: value})
+	}
+	return out
+}
@@ -0,0 +1,241 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Header names of the W3C Trace Context.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+)
+
+const (
+	// traceparentLen is the length of a version 00 traceparent header.
+	traceparentLen = 55
+	// maxTracestateLen and maxTracestateMembers bound the tracestate headers
+	// propagated, as the W3C Trace Context requires of vendors.
+	maxTracestateLen     = 512
+	maxTracestateMembers = 32
+)
+
+// ErrInvalidTraceparent is returned by ParseTraceparent for a header that is
+// not a valid W3C traceparent.
+var ErrInvalidTraceparent = errors.New("tracing: invalid traceparent")
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the ID in lowercase hex.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the ID in lowercase hex.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// FlagSampled is the trace flag set when the caller may have recorded the
+// trace.
+const FlagSampled byte = 0x01
+
+// SpanContext is the part of a span that is propagated to other services.
+type SpanContext struct {
+	// TraceState holds the vendor specific tracestate header, passed on
+	// unchanged.
+	TraceState string
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags byte
+	// Remote is true if the span context was received from another service.
+	Remote bool
+}
+
+// IsValid reports whether both IDs of the span context are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Sampled reports whether the sampled flag is set.
+func (sc SpanContext) Sampled() bool {
+	return sc.TraceFlags&FlagSampled != 0
+}
+
+// Traceparent returns the span context as a version 00 traceparent header.
+func (sc SpanContext) Traceparent() string {
+	var buf [traceparentLen]byte
+	buf[0], buf[1], buf[2] = '0', '0', '-'
+	hex.Encode(buf[3:35], sc.TraceID[:])
+	buf[35] = '-'
+	hex.Encode(buf[36:52], sc.SpanID[:])
+	buf[52] = '-'
+	hex.Encode(buf[53:55], []byte{sc.TraceFlags})
+	return string(buf[:])
+}
+
+// ParseTraceparent parses a W3C traceparent header. Headers of later versions
+// are parsed as version 00, ignoring the fields it doesn't know.
+func ParseTraceparent(h string) (SpanContext, error) {
+	var sc SpanContext
+	if len(h) < traceparentLen || h[2] != '-' || h[35] != '-' || h[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+
+	var version [1]byte
+	if !decodeLowerHex(version[:], h[0:2]) || version[0] == 0xff {
+		return sc, ErrInvalidTraceparent
+	}
+	// Version 00 has no more fields, later ones may append fields after a dash
+	if len(h) > traceparentLen && (version[0] == 0 || h[traceparentLen] != '-') {
+		return sc, ErrInvalidTraceparent
+	}
+
+	var flags [1]byte
+	if !decodeLowerHex(sc.TraceID[:], h[3:35]) ||
+		!decodeLowerHex(sc.SpanID[:], h[36:52]) ||
+		!decodeLowerHex(flags[:], h[53:55]) ||
+		!sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.TraceFlags = flags[0]
+	sc.Remote = true
+	return sc, nil
+}
+
+// decodeLowerHex decodes src into dst, which must be half its length. The W3C
+// Trace Context doesn't allow uppercase hex digits.
+func decodeLowerHex(dst []byte, src string) bool {
+	for i := range dst {
+		hi, ok1 := fromLowerHex(src[2*i])
+		lo, ok2 := fromLowerHex(src[2*i+1])
+		if !ok1 || !ok2 {
+			return false
+		}
+		dst[i] = hi<<4 | lo
+	}
+	return true
+}
+
+func fromLowerHex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	default:
+		return 0, false
+	}
+}
+
+// validTracestate reports whether h is a tracestate header that may be
+// propagated: at most 32 comma separated key=value members with distinct
+// keys, 512 bytes long at most.
+func validTracestate(h string) bool {
+	if len(h) > maxTracestateLen {
+		return false
+	}
+
+	keys := make([]string, 0, 4)
+	for member := range strings.SplitSeq(h, ",") {
+		member = strings.Trim(member, " \t")
+		if member == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(member, "=")
+		if !ok || !validTracestateKey(key) || !validTracestateValue(value) {
+			return false
+		}
+		for _, k := range keys {
+			if k == key {
+				return false
+			}
+		}
+		if len(keys) == maxTracestateMembers {
+			return false
+		}
+		keys = append(keys, key)
+	}
+	return true
+}
+
+// validTracestateKey reports whether key is a simple key or a multi-tenant
+// key of the form tenant@system.
+func validTracestateKey(key string) bool {
+	if key == "" || len(key) > 256 {
+		return false
+	}
+	tenant, system, multiTenant := strings.Cut(key, "@")
+	if multiTenant {
+		return len(tenant) <= 241 && len(system) <= 14 &&
+			validTracestateKeyPart(tenant, true) && validTracestateKeyPart(system, false)
+	}
+	return validTracestateKeyPart(key, false)
+}
+
+// validTracestateKeyPart reports whether s is made of lowercase letters,
+// digits and the characters _-*/, starting with a letter, or a digit if
+// digitFirst is set.
+func validTracestateKeyPart(s string, digitFirst bool) bool { //nolint:revive // flag-parameter: tenants and systems only differ by their first character
+	if s == "" {
+		return false
+	}
+	if c := s[0]; (c < 'a' || c > 'z') && (!digitFirst || c < '0' || c > '9') {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '-' && c != '*' && c != '/' {
+			return false
+		}
+	}
+	return true
+}
+
+// validTracestateValue reports whether value is made of at most 256
+// printable ASCII characters other than comma and equals.
+func validTracestateValue(value string) bool {
+	if value == "" || len(value) > 256 {
+		return false
+	}
+	for i := range len(value) {
+		if c := value[i]; c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// newTraceID returns a random trace ID.
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:]) //nolint:errcheck // crypto/rand.Read never returns an error
+	}
+	return id
+}
+
+// newSpanID returns a random span ID.
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:]) //nolint:errcheck // crypto/rand.Read never returns an error
+	}
+	return id
+}
@@ -0,0 +1,172 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// The contextKey type is unexported to prevent collisions with context keys defined in
+// other packages.
+type contextKey int
+
+// The keys for the values in context
+const (
+	spanKey contextKey = iota
+)
+
+// SpanKind is the role of a span in a trace.
+type SpanKind int
+
+// The kinds of the spans recorded by the package, numbered as in OTLP.
+const (
+	SpanKindServer SpanKind = 2
+	SpanKindClient SpanKind = 3
+)
+
+// StatusCode is the status of a span, numbered as in OTLP.
+type StatusCode int
+
+// The status codes of a span.
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Attribute is a key-value pair describing a span. Its value is a string,
+// bool, int64 or float64.
+type Attribute struct {
+	Value any
+	Key   string
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a timed operation of a trace. The fields of an ended span, as
+// passed to an Exporter, must not be modified.
+type Span struct {
+	Start         time.Time
+	End           time.Time
+	Name          string
+	StatusMessage string
+	Attributes    []Attribute
+	SpanContext   SpanContext
+	Kind          SpanKind
+	Status        StatusCode
+	// Parent is the ID of the parent span, zero for the root span of a trace.
+	Parent SpanID
+	mu     sync.Mutex
+	ended  bool
+}
+
+// newSpan starts a span, as a child of parent if it is valid. sampled decides
+// whether a root span is sampled; child spans follow their parent.
+func newSpan(name string, kind SpanKind, parent SpanContext, sampled bool) *Span { //nolint:revive // flag-parameter: the sampling decision is made by the caller
+
+	span := &Span{
+		Name:  name,
+		Kind:  kind,
+		Start: time.Now(),
+	}
+	if parent.IsValid() {
+		span.Parent = parent.SpanID
+		span.SpanContext = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			TraceFlags: parent.TraceFlags,
+			TraceState: parent.TraceState,
+		}
+		return span
+	}
+
+	span.SpanContext = SpanContext{TraceID: newTraceID(), SpanID: newSpanID()}
+	if sampled {
+		span.SpanContext.TraceFlags = FlagSampled
+	}
+	return span
+}
+
+// SetAttributes adds attributes to the span. It does nothing once the span
+// has ended.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Attributes = append(s.Attributes, attrs...)
+	}
+}
+
+// SetStatus sets the status of the span, with a description for StatusError.
+// It does nothing once the span has ended.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Status = code
+		s.StatusMessage = message
+	}
+}
+
+// end ends the span and reports whether it should be exported. Only the first
+// call ends it.
+func (s *Span) end() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return false
+	}
+	s.ended = true
+	s.End = time.Now()
+	return s.SpanContext.Sampled()
+}
+
+// SpanFromContext returns the span from context, the server span of the
+// request if it was stored by the middleware.
+// It accepts fiber.CustomCtx, fiber.Ctx, *fasthttp.RequestCtx, and context.Context.
+// If there is no span, nil is returned.
+func SpanFromContext(ctx any) *Span {
+	if span, ok := fiber.ValueFromContext[*Span](ctx, spanKey); ok {
+		return span
+	}
+	return nil
+}
+
+// ContextWithSpan returns a copy of ctx holding span, so that the requests of
+// a client tracer made with it are recorded as its children.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+// TraceIDFromContext returns the trace ID of the span from context in hex,
+// or an empty string if there is no span.
+func TraceIDFromContext(ctx any) string {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext.TraceID.String()
+	}
+	return ""
+}
+
+// SpanIDFromContext returns the ID of the span from context in hex, or an
+// empty string if there is no span.
+func SpanIDFromContext(ctx any) string {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext.SpanID.String()
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+// Package tracing propagates W3C Trace Context across services and records
+// the server spans of requests, and the client spans of the requests of a
+// client.Client, without depending on the OpenTelemetry SDK.
+package tracing
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/tracecontext"
+	fiberlog "github.com/gofiber/fiber/v3/log"
+	"github.com/gofiber/fiber/v3/middleware/logger"
+	"github.com/valyala/fasthttp"
+)
+
+var registerOnce sync.Once
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	registerOnce.Do(register)
+
+	// Set default config
+	cfg := configDefault(config...)
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		parent := extract(c)
+		span := newSpan(c.Method(), SpanKindServer, parent, !parent.IsValid() && (cfg.Sampler == nil || cfg.Sampler(c)))
+
+		// Add the span to locals
+		fiber.StoreInContext(c, spanKey, span)
+
+		// The route of the middleware, which is still the current one after
+		// the chain if no other route matched the request
+		entry := c.Route()
+
+		chainErr := c.Next()
+
+		// Manually call error handler, so the recorded status is that of the
+		// response sent
+		if chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError) //nolint:errcheck // The error handler failed, the status is all that is left to send
+			}
+		}
+
+		if cfg.Exporter == nil || !span.SpanContext.Sampled() {
+			return nil
+		}
+
+		// The path and host point into buffers reused by the next request
+		status := c.Response().StatusCode()
+		span.SetAttributes(
+			String("http.request.method", c.Method()),
+			String("url.path", strings.Clone(c.Path())),
+			String("url.scheme", c.Scheme()),
+			String("server.address", strings.Clone(c.Hostname())),
+			Int("http.response.status_code", status),
+		)
+		if c.Route() != entry || (status != fiber.StatusNotFound && status != fiber.StatusMethodNotAllowed) {
+			route := c.Route().Path
+			span.Name = c.Method() + " " + route
+			span.SetAttributes(String("http.route", route))
+		}
+		if status >= fiber.StatusInternalServerError {
+			message := ""
+			if chainErr != nil {
+				message = chainErr.Error()
+			}
+			span.SetStatus(StatusError, message)
+		}
+
+		export(cfg.Exporter, span)
+		return nil
+	}
+}
+
+// extract returns the span context of the traceparent and tracestate
+// headers of the request, or an invalid one if there is no valid
+// traceparent.
+func extract(c fiber.Ctx) SpanContext {
+	sc, err := ParseTraceparent(c.Get(HeaderTraceparent))
+	if err != nil {
+		return SpanContext{}
+	}
+
+	// The tracestate header may be split across several fields
+	var state string
+	for i, v := range c.Request().Header.PeekAll(HeaderTracestate) {
+		if i > 0 {
+			state += ","
+		}
+		state += string(v)
+	}
+	if state != "" && validTracestate(state) {
+		sc.TraceState = state
+	}
+	return sc
+}
+
+// Inject sets the traceparent and tracestate headers of h to the span from
+// context, making it the parent of the request. It does nothing if there is
+// no span. The proxy middleware calls it on the requests it forwards once the
+// middleware is created.
+func Inject(ctx any, h *fasthttp.RequestHeader) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	h.Set(HeaderTraceparent, span.SpanContext.Traceparent())
+	if span.SpanContext.TraceState != "" {
+		h.Set(HeaderTracestate, span.SpanContext.TraceState)
+	} else {
+		h.Del(HeaderTracestate)
+	}
+}
+
+// export ends span and passes it to exporter if it is sampled.
+func export(exporter Exporter, span *Span) {
+	if !span.end() || exporter == nil {
+		return
+	}
+	if err := exporter.ExportSpans(context.Background(), []*Span{span}); err != nil {
+		fiberlog.Errorf("tracing: failed to export span: %v", err)
+	}
+}
+
+// register registers the logger tags of the trace and span IDs, and Inject
+// as the injector of the trace context into the requests the proxy forwards.
+func register() {
+	logger.RegisterContextTag(fiberlog.TagTraceID, TraceIDFromContext)
+	logger.RegisterContextTag(fiberlog.TagSpanID, SpanIDFromContext)
+	tracecontext.SetInjector(Inject)
+}
@@ -0,0 +1,449 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/logger"
+)
+
+const (
+	testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentID    = "00f067aa0ba902b7"
+)
+
+// recorder is an Exporter keeping the spans it receives.
+type recorder struct {
+	err   error
+	spans []*Span
+	mu    sync.Mutex
+}
+
+func (r *recorder) ExportSpans(_ context.Context, spans []*Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return r.err
+}
+
+func (r *recorder) exported() []*Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Span(nil), r.spans...)
+}
+
+func attribute(span *Span, key string) any {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+func Test_Tracing_Root(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	app := fiber.New()
+	app.Use(New(Config{Exporter: rec}))
+	app.Get("/users/:id", func(c fiber.Ctx) error {
+		span := SpanFromContext(c)
+		require.NotNil(t, span)
+		span.SetAttributes(String("user.id", c.Params("id")))
+		return c.SendString(span.SpanContext.TraceID.String())
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "http://example.com/users/42", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	spans := rec.exported()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "GET /users/:id", span.Name)
+	require.Equal(t, SpanKindServer, span.Kind)
+	require.Equal(t, StatusUnset, span.Status)
+	require.Equal(t, string(body), span.SpanContext.TraceID.String())
+	require.True(t, span.SpanContext.Sampled())
+	require.False(t, span.Parent.IsValid())
+	require.False(t, span.End.Before(span.Start))
+	require.Equal(t, "42", attribute(span, "user.id"))
+	require.Equal(t, "GET", attribute(span, "http.request.method"))
+	require.Equal(t, "/users/:id", attribute(span, "http.route"))
+	require.Equal(t, "/users/42", attribute(span, "url.path"))
+	require.Equal(t, "example.com", attribute(span, "server.address"))
+	require.Equal(t, int64(200), attribute(span, "http.response.status_code"))
+}
+
+func Test_Tracing_Remote_Parent(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	app := fiber.New()
+	app.Use(New(Config{Exporter: rec}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set(HeaderTraceparent, testTraceparent)
+	req.Header.Add(HeaderTracestate, "rojo=00f067aa0ba902b7")
+	req.Header.Add(HeaderTracestate, "congo=t61rcWkgMzE")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+	spans := rec.exported()
+	require.Len(t, spans, 1)
+	require.Equal(t, testTraceID, spans[0].SpanContext.TraceID.String())
+	require.Equal(t, testParentID, spans[0].Parent.String())
+	require.NotEqual(t, testParentID, spans[0].SpanContext.SpanID.String())
+	require.Equal(t, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", spans[0].SpanContext.TraceState)
+
+	// An invalid tracestate is dropped, an invalid traceparent starts a new trace
+	req = httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set(HeaderTraceparent, strings.ToUpper(testTraceparent))
+	req.Header.Set(HeaderTracestate, "rojo=00f067aa0ba902b7")
+	_, err = app.Test(req)
+	require.NoError(t, err)
+
+	req = httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set(HeaderTraceparent, testTraceparent)
+	req.Header.Set(HeaderTracestate, "Rojo=00f067aa0ba902b7")
+	_, err = app.Test(req)
+	require.NoError(t, err)
+
+	spans = rec.exported()
+	require.Len(t, spans, 3)
+	require.NotEqual(t, testTraceID, spans[1].SpanContext.TraceID.String())
+	require.False(t, spans[1].Parent.IsValid())
+	require.Empty(t, spans[1].SpanContext.TraceState)
+	require.Equal(t, testTraceID, spans[2].SpanContext.TraceID.String())
+	require.Empty(t, spans[2].SpanContext.TraceState)
+}
+
+func Test_Tracing_Sampling(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Exporter: rec,
+		Sampler: func(c fiber.Ctx) bool {
+			return c.Path() != "/unsampled"
+		},
+	}))
+	app.Get("/*", func(c fiber.Ctx) error {
+		return c.SendString(SpanFromContext(c).SpanContext.Traceparent())
+	})
+
+	traceparent := func(path, parent string) string {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, path, http.NoBody)
+		if parent != "" {
+			req.Header.Set(HeaderTraceparent, parent)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	// Root spans are sampled by the sampler
+	require.True(t, strings.HasSuffix(traceparent("/unsampled", ""), "-00"))
+	require.Empty(t, rec.exported())
+	require.True(t, strings.HasSuffix(traceparent("/sampled", ""), "-01"))
+	require.Len(t, rec.exported(), 1)
+
+	// Child spans follow their parent
+	require.True(t, strings.HasSuffix(traceparent("/sampled", strings.TrimSuffix(testTraceparent, "01")+"00"), "-00"))
+	require.True(t, strings.HasSuffix(traceparent("/unsampled", testTraceparent), "-01"))
+	require.Len(t, rec.exported(), 2)
+}
+
+func Test_Tracing_Status(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	app := fiber.New()
+	app.Use(New(Config{Exporter: rec}))
+	app.Get("/fail", func(_ fiber.Ctx) error {
+		return errors.New("database is down")
+	})
+	app.Get("/missing", func(_ fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+
+	for _, path := range []string{"/fail", "/missing", "/unknown"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, http.NoBody))
+		require.NoError(t, err)
+	}
+
+	spans := rec.exported()
+	require.Len(t, spans, 3)
+
+	require.Equal(t, "GET /fail", spans[0].Name)
+	require.Equal(t, StatusError, spans[0].Status)
+	require.Equal(t, "database is down", spans[0].StatusMessage)
+	require.Equal(t, int64(500), attribute(spans[0], "http.response.status_code"))
+
+	// Client errors are not span errors
+	require.Equal(t, "GET /missing", spans[1].Name)
+	require.Equal(t, StatusUnset, spans[1].Status)
+	require.Equal(t, int64(404), attribute(spans[1], "http.response.status_code"))
+
+	// Requests no route matched have no route
+	require.Equal(t, "GET", spans[2].Name)
+	require.Nil(t, attribute(spans[2], "http.route"))
+}
+
+func Test_Tracing_Next(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Exporter: rec,
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		require.Nil(t, SpanFromContext(c))
+		require.Empty(t, TraceIDFromContext(c))
+		require.Empty(t, SpanIDFromContext(c))
+		return nil
+	})
+
+	_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Empty(t, rec.exported())
+}
+
+func Test_Tracing_PassLocalsToContext(t *testing.T) {
+	t.Parallel()
+
+	for _, pass := range []bool{false, true} {
+		app := fiber.New(fiber.Config{PassLocalsToContext: pass})
+		app.Use(New())
+		app.Get("/", func(c fiber.Ctx) error {
+			span := SpanFromContext(c)
+			require.NotNil(t, span)
+			require.Equal(t, span, SpanFromContext(c.RequestCtx()))
+			if pass {
+				require.Equal(t, span, SpanFromContext(c.Context()))
+			} else {
+				require.Nil(t, SpanFromContext(c.Context()))
+			}
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+		require.NoError(t, err)
+	}
+}
+
+func Test_Tracing_Logger(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(New())
+	app.Use(logger.New(logger.Config{
+		Format: "${trace-id} ${span-id}\n",
+		Stream: buf,
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(TraceIDFromContext(c) + " " + SpanIDFromContext(c) + "\n")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, string(body), buf.String())
+}
+
+func Test_Inject(t *testing.T) {
+	t.Parallel()
+
+	var h fasthttp.RequestHeader
+	h.Set(HeaderTracestate, "stale=1")
+
+	// Without a span, the headers are left alone
+	Inject(context.Background(), &h)
+	require.Empty(t, h.Peek(HeaderTraceparent))
+	require.Equal(t, "stale=1", string(h.Peek(HeaderTracestate)))
+
+	span := &Span{SpanContext: SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, TraceFlags: FlagSampled}}
+	Inject(ContextWithSpan(context.Background(), span), &h)
+	require.Equal(t, "00-01000000000000000000000000000000-0200000000000000-01", string(h.Peek(HeaderTraceparent)))
+	require.Empty(t, h.Peek(HeaderTracestate))
+
+	span.SpanContext.TraceState = "rojo=1"
+	Inject(ContextWithSpan(context.Background(), span), &h)
+	require.Equal(t, "rojo=1", string(h.Peek(HeaderTracestate)))
+}
+
+func Test_ParseTraceparent(t *testing.T) {
+	t.Parallel()
+
+	sc, err := ParseTraceparent(testTraceparent)
+	require.NoError(t, err)
+	require.Equal(t, testTraceID, sc.TraceID.String())
+	require.Equal(t, testParentID, sc.SpanID.String())
+	require.True(t, sc.Sampled())
+	require.True(t, sc.Remote)
+	require.Equal(t, testTraceparent, sc.Traceparent())
+
+	// Later versions may append fields
+	sc, err = ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-what-the-future-holds")
+	require.NoError(t, err)
+	require.False(t, sc.Sampled())
+
+	for _, h := range []string{
+		"",
+		testTraceparent + "-",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(h)
+		require.ErrorIs(t, err, ErrInvalidTraceparent, h)
+	}
+}
+
+func Test_validTracestate(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, validTracestate("rojo=00f067aa0ba902b7"))
+	require.True(t, validTracestate("rojo=00f067aa0ba902b7, congo=t61rcWkgMzE,,"))
+	require.True(t, validTracestate("fw529a3039@dt=ZGQ6MDAwMDAwMDAwMDAwMDAwMA"))
+	members := make([]string, maxTracestateMembers)
+	for i := range members {
+		members[i] = "k" + strconv.Itoa(i) + "=v"
+	}
+	require.True(t, validTracestate(strings.Join(members, ",")))
+	require.False(t, validTracestate(strings.Join(members, ",")+",more=v"))
+
+	require.False(t, validTracestate("rojo"))
+	require.False(t, validTracestate("Rojo=1"))
+	require.False(t, validTracestate("rojo=1,rojo=2"))
+	require.False(t, validTracestate("rojo=a=b"))
+	require.False(t, validTracestate("rojo=a\tb"))
+	require.False(t, validTracestate("@dt=1"))
+	require.False(t, validTracestate("tenant@=1"))
+	require.False(t, validTracestate("rojo="+strings.Repeat("a", maxTracestateLen)))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrShortWrite
+}
+
+func Test_JSONExporter_WriteError(t *testing.T) {
+	t.Parallel()
+
+	err := NewJSONExporter(failingWriter{}).ExportSpans(context.Background(), []*Span{{Name: "GET /"}})
+	require.ErrorIs(t, err, io.ErrShortWrite)
+	require.ErrorContains(t, err, "tracing: failed to export spans")
+}
+
+func Test_JSONExporter(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	exporter := NewJSONExporter(buf, String("service.name", "api"))
+
+	app := fiber.New()
+	app.Use(New(Config{Exporter: exporter}))
+	app.Get("/", func(c fiber.Ctx) error {
+		SpanFromContext(c).SetAttributes(Bool("cache.hit", true), Attribute{Key: "ratio", Value: 0.5})
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set(HeaderTraceparent, testTraceparent)
+	_, err := app.Test(req)
+	require.NoError(t, err)
+	_, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var export struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []map[string]any `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Scope struct {
+					Name string `json:"name"`
+				} `json:"scope"`
+				Spans []map[string]any `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &export))
+	require.Len(t, export.ResourceSpans, 1)
+	rs := export.ResourceSpans[0]
+	require.Equal(t, []map[string]any{{"key": "service.name", "value": map[string]any{"stringValue": "api"}}}, rs.Resource.Attributes)
+	require.Equal(t, scopeName, rs.ScopeSpans[0].Scope.Name)
+
+	span := rs.ScopeSpans[0].Spans[0]
+	require.Equal(t, testTraceID, span["traceId"])
+	require.Equal(t, testParentID, span["parentSpanId"])
+	require.Equal(t, "GET /", span["name"])
+	require.InDelta(t, 2, span["kind"], 0)
+	require.IsType(t, "", span["startTimeUnixNano"])
+	require.Contains(t, span["attributes"], map[string]any{"key": "cache.hit", "value": map[string]any{"boolValue": true}})
+	require.Contains(t, span["attributes"], map[string]any{"key": "ratio", "value": map[string]any{"doubleValue": 0.5}})
+	require.Contains(t, span["attributes"], map[string]any{"key": "http.response.status_code", "value": map[string]any{"intValue": "200"}})
+
+	// Root spans have no parent
+	require.NotContains(t, lines[1], "parentSpanId")
+}
+
+func Benchmark_Tracing(b *testing.B) {
+	app := fiber.New()
+	app.Use(New(Config{Exporter: NewJSONExporter(io.Discard)}))
+	app.Get("/users/:id", func(c fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	h := app.Handler()
+
+	fctx := &fasthttp.RequestCtx{}
+	fctx.Request.Header.SetMethod(fiber.MethodGet)
+	fctx.Request.SetRequestURI("/users/1")
+	fctx.Request.Header.Set(HeaderTraceparent, testTraceparent)
+
+	for b.Loop() {
+		h(fctx)
+	}
+}
@@ -0,0 +1,87 @@
+package tus
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Storage stores the state of the uploads: their size, offset, metadata
+	// and expiry. Instances sharing it serve the same uploads, given their
+	// Sink writes to a shared location too.
+	//
+	// Optional. Default: an in-memory storage for this process only.
+	Storage fiber.Storage
+
+	// Sink writes the data of the uploads.
+	//
+	// Required.
+	Sink Sink
+
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// OnComplete is called when the last bytes of an upload are written,
+	// before the response is sent. The data of the upload can be moved from
+	// the Sink to its destination there. An error is returned to the client,
+	// which cannot retry the upload, as it is complete.
+	//
+	// Optional. Default: nil
+	OnComplete func(c fiber.Ctx, upload *Upload) error
+
+	// BasePath is the path of the upload creation endpoint, such as
+	// "/files/". The URL of each upload is the base path followed by its ID.
+	//
+	// Required.
+	BasePath string
+
+	// MaxSize is the maximum size of an upload, in bytes.
+	//
+	// Optional. Default: 0 (unlimited)
+	MaxSize int64
+
+	// Expiration is how long an unfinished upload is kept after it was last
+	// written to.
+	//
+	// Optional. Default: 24 * time.Hour
+	Expiration time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Expiration: 24 * time.Hour,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	if len(config) < 1 {
+		panic("tus: Sink and BasePath are required")
+	}
+	cfg := config[0]
+
+	if cfg.Sink == nil || cfg.BasePath == "" {
+		panic("tus: Sink and BasePath are required")
+	}
+	if cfg.MaxSize < 0 {
+		panic("tus: MaxSize must not be negative")
+	}
+
+	// The upload IDs are appended to the base path
+	if !strings.HasSuffix(cfg.BasePath, "/") {
+		cfg.BasePath += "/"
+	}
+	if cfg.Expiration <= 0 {
+		cfg.Expiration = ConfigDefault.Expiration
+	}
+	if cfg.Storage == nil {
+		cfg.Storage = memory.New()
+	}
+
+	return cfg
+}
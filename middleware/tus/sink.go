@@ -0,0 +1,157 @@
+package tus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Sink writes the data of the uploads, such as to files or to an object
+// storage.
+type Sink interface {
+	// Create prepares an empty upload.
+	Create(ctx context.Context, id string) error
+	// Append writes the data of r at offset, which is the number of bytes
+	// received before, and returns the number of bytes written. The data
+	// written when an error is returned is kept, and the upload is resumed
+	// after it. Data at or after offset, left by a rejected request, is
+	// overwritten.
+	Append(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+	// Delete deletes the data of an upload.
+	Delete(ctx context.Context, id string) error
+}
+
+var errInvalidID = errors.New("tus: invalid upload ID")
+
+// DiskSink is a Sink writing each upload to a file of a local directory.
+type DiskSink struct {
+	dir string
+}
+
+// NewDiskSink returns a Sink writing the uploads to files of dir, which is
+// created when missing.
+func NewDiskSink(dir string) *DiskSink {
+	return &DiskSink{dir: dir}
+}
+
+// Path returns the path of the file of an upload.
+func (s *DiskSink) Path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id))
+}
+
+// Open opens the file of an upload for reading.
+func (s *DiskSink) Open(id string) (*os.File, error) {
+	if !validID(id) {
+		return nil, errInvalidID
+	}
+	return os.Open(s.Path(id))
+}
+
+// Create creates an empty file for an upload.
+func (s *DiskSink) Create(_ context.Context, id string) error {
+	if !validID(id) {
+		return errInvalidID
+	}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("tus: failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(s.Path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("tus: failed to create file: %w", err)
+	}
+	return f.Close()
+}
+
+// Append writes the data of r at offset in the file of an upload. Bytes after
+// offset, left by a failed write, are discarded first.
+func (s *DiskSink) Append(_ context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	if !validID(id) {
+		return 0, errInvalidID
+	}
+	f, err := os.OpenFile(s.Path(id), os.O_WRONLY, 0)
+	if err != nil {
+		return 0, fmt.Errorf("tus: failed to open file: %w", err)
+	}
+	if err := f.Truncate(offset); err != nil {
+		_ = f.Close() //nolint:errcheck // the truncate error is returned
+		return 0, fmt.Errorf("tus: failed to truncate file: %w", err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		_ = f.Close() //nolint:errcheck // the seek error is returned
+		return 0, fmt.Errorf("tus: failed to seek file: %w", err)
+	}
+
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("tus: failed to close file: %w", closeErr)
+	}
+	return n, err
+}
+
+// Delete removes the file of an upload. A missing file is not an error.
+func (s *DiskSink) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return errInvalidID
+	}
+	if err := os.Remove(s.Path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("tus: failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// Purge removes the files of the uploads which were last written to before a
+// time and are gone from storage, the Storage of the middleware. The state
+// of expired uploads is removed from the Storage when it expires, but their
+// files are only removed when their URL is requested, so Purge is meant to be
+// called periodically with the current time minus the Expiration. The files
+// of completed uploads are kept until the uploads are terminated.
+func (s *DiskSink) Purge(ctx context.Context, storage fiber.Storage, before time.Time) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("tus: failed to read directory: %w", err)
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !validID(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+
+		u, err := loadUpload(ctx, storage, entry.Name())
+		if err != nil {
+			return err
+		}
+		if u != nil && (u.Complete() || now.Before(u.Expires)) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("tus: failed to delete file: %w", err)
+		}
+	}
+	return nil
+}
+
+// validID reports whether id is an upload ID generated by the middleware:
+// base64url characters only, so that it is a safe file name.
+func validID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_'
+	}) < 0
+}
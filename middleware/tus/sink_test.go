@@ -0,0 +1,111 @@
+package tus
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiskSink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := NewDiskSink(filepath.Join(t.TempDir(), "uploads"))
+
+	require.NoError(t, s.Create(ctx, "upload"))
+	require.Error(t, s.Create(ctx, "upload"))
+
+	n, err := s.Append(ctx, "upload", 0, strings.NewReader("hello"))
+	require.NoError(t, err)
+	require.Equal(t, int64(5), n)
+
+	// The bytes after the offset are discarded
+	n, err = s.Append(ctx, "upload", 3, strings.NewReader("p me"))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), n)
+
+	data, err := os.ReadFile(s.Path("upload"))
+	require.NoError(t, err)
+	require.Equal(t, "help me", string(data))
+
+	f, err := s.Open("upload")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, s.Delete(ctx, "upload"))
+	require.NoError(t, s.Delete(ctx, "upload"))
+	_, err = os.Stat(s.Path("upload"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = s.Append(ctx, "upload", 0, strings.NewReader("hello"))
+	require.Error(t, err)
+}
+
+func Test_DiskSink_InvalidID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := NewDiskSink(t.TempDir())
+
+	for _, id := range []string{"", "../upload", "a/b", "a.b", strings.Repeat("a", 129)} {
+		require.ErrorIs(t, s.Create(ctx, id), errInvalidID, id)
+		_, err := s.Append(ctx, id, 0, strings.NewReader(""))
+		require.ErrorIs(t, err, errInvalidID, id)
+		require.ErrorIs(t, s.Delete(ctx, id), errInvalidID, id)
+		_, err = s.Open(id)
+		require.ErrorIs(t, err, errInvalidID, id)
+	}
+}
+
+func Test_DiskSink_Purge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := NewDiskSink(dir)
+	storage := memory.New()
+
+	saveUpload := func(u *Upload) {
+		t.Helper()
+		data, err := json.Marshal(u)
+		require.NoError(t, err)
+		require.NoError(t, storage.Set(storageKeyPrefix+u.ID, data, 0))
+	}
+
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+	for _, id := range []string{"gone", "expired", "live", "complete", "new"} {
+		require.NoError(t, s.Create(ctx, id))
+		if id != "new" {
+			require.NoError(t, os.Chtimes(s.Path(id), old, old))
+		}
+	}
+	saveUpload(&Upload{ID: "expired", Size: 10, Expires: now.Add(-time.Minute)})
+	saveUpload(&Upload{ID: "live", Size: 10, Expires: now.Add(time.Hour)})
+	saveUpload(&Upload{ID: "complete", Size: 10, Offset: 10, Expires: now.Add(-time.Hour)})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), nil, 0o600))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "other.txt"), old, old))
+
+	require.NoError(t, s.Purge(ctx, storage, now.Add(-time.Hour)))
+
+	for _, id := range []string{"gone", "expired"} {
+		_, err := os.Stat(s.Path(id))
+		require.ErrorIs(t, err, os.ErrNotExist, id)
+	}
+	// Completed uploads are kept until they are terminated
+	for _, id := range []string{"live", "complete", "new"} {
+		_, err := os.Stat(s.Path(id))
+		require.NoError(t, err, id)
+	}
+	_, err := os.Stat(filepath.Join(dir, "other.txt"))
+	require.NoError(t, err)
+
+	require.NoError(t, NewDiskSink(filepath.Join(dir, "missing")).Purge(ctx, storage, time.Now()))
+}
@@ -0,0 +1,379 @@
+package tus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+)
+
+// Version is the version of the tus protocol implemented by the middleware.
+const Version = "1.0.0"
+
+// Extensions lists the tus protocol extensions supported by the middleware.
+const Extensions = "creation,creation-with-upload,termination,expiration"
+
+// Headers of the tus protocol
+const (
+	HeaderTusResumable  = "Tus-Resumable"
+	HeaderTusVersion    = "Tus-Version"
+	HeaderTusExtension  = "Tus-Extension"
+	HeaderTusMaxSize    = "Tus-Max-Size"
+	HeaderUploadLength  = "Upload-Length"
+	HeaderUploadOffset  = "Upload-Offset"
+	HeaderUploadExpires = "Upload-Expires"
+	HeaderUploadMeta    = "Upload-Metadata"
+
+	headerMethodOverride = "X-HTTP-Method-Override"
+)
+
+// ContentTypeOffset is the content type of the data of PATCH requests.
+const ContentTypeOffset = "application/offset+octet-stream"
+
+// storageKeyPrefix prefixes the upload IDs in the Storage.
+const storageKeyPrefix = "tus_"
+
+var errTooLarge = errors.New("tus: body exceeds the upload length")
+
+// New creates a new middleware handler serving the tus uploads under
+// BasePath. Requests for other paths, and requests of other methods such as
+// GET, are passed to the next handlers.
+func New(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+
+	h := &handler{cfg: &cfg}
+	basePath := strings.TrimSuffix(cfg.BasePath, "/")
+
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		var id string
+		switch path := c.Path(); {
+		case path == basePath || path == cfg.BasePath:
+		case strings.HasPrefix(path, cfg.BasePath) && !strings.Contains(path[len(cfg.BasePath):], "/"):
+			id = path[len(cfg.BasePath):]
+		default:
+			return c.Next()
+		}
+
+		// Clients which cannot send PATCH or DELETE requests use POST instead
+		method := c.Method()
+		if override := c.Get(headerMethodOverride); override != "" {
+			method = strings.ToUpper(override)
+		}
+
+		if method == fiber.MethodOptions {
+			c.Set(HeaderTusVersion, Version)
+			c.Set(HeaderTusExtension, Extensions)
+			if cfg.MaxSize > 0 {
+				c.Set(HeaderTusMaxSize, strconv.FormatInt(cfg.MaxSize, 10))
+			}
+			return c.SendStatus(fiber.StatusNoContent)
+		}
+
+		var serve func(c fiber.Ctx, id string) error
+		switch {
+		case method == fiber.MethodPost && id == "":
+			serve = h.create
+		case method == fiber.MethodHead && id != "":
+			serve = h.head
+		case method == fiber.MethodPatch && id != "":
+			serve = h.patch
+		case method == fiber.MethodDelete && id != "":
+			serve = h.terminate
+		default:
+			return c.Next()
+		}
+
+		c.Set(HeaderTusResumable, Version)
+		if c.Get(HeaderTusResumable) != Version {
+			c.Set(HeaderTusVersion, Version)
+			return fiber.ErrPreconditionFailed
+		}
+		if id != "" && !validID(id) {
+			return fiber.ErrNotFound
+		}
+
+		return serve(c, id)
+	}
+}
+
+// handler serves the requests of the tus protocol.
+type handler struct {
+	cfg *Config
+	// locks holds the IDs of the uploads being written to by this process
+	locks sync.Map
+}
+
+// create serves a POST request creating an upload, which may contain its
+// first bytes.
+func (h *handler) create(c fiber.Ctx, _ string) error {
+	size, err := strconv.ParseInt(c.Get(HeaderUploadLength), 10, 64)
+	if err != nil || size < 0 {
+		return fiber.ErrBadRequest
+	}
+	if h.cfg.MaxSize > 0 && size > h.cfg.MaxSize {
+		return fiber.ErrRequestEntityTooLarge
+	}
+	if n := c.Request().Header.ContentLength(); n > 0 && int64(n) > size {
+		return fiber.ErrRequestEntityTooLarge
+	}
+	metadata, err := parseMetadata(c.Get(HeaderUploadMeta))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	u := &Upload{
+		ID:       utils.SecureToken(),
+		Size:     size,
+		Metadata: metadata,
+		Expires:  time.Now().Add(h.cfg.Expiration),
+	}
+	if err := h.cfg.Sink.Create(c, u.ID); err != nil {
+		return err
+	}
+	if err := h.save(c, u); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderLocation, c.BaseURL()+h.cfg.BasePath+u.ID)
+	c.Set(HeaderUploadExpires, u.Expires.UTC().Format(http.TimeFormat))
+
+	// creation-with-upload
+	if c.Get(fiber.HeaderContentType) == ContentTypeOffset {
+		if err := h.write(c, u); err != nil {
+			return err
+		}
+		c.Set(HeaderUploadOffset, strconv.FormatInt(u.Offset, 10))
+	}
+
+	if u.Complete() && h.cfg.OnComplete != nil {
+		if err := h.cfg.OnComplete(c, u); err != nil {
+			return err
+		}
+	}
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// head serves a HEAD request for the offset of an upload.
+func (h *handler) head(c fiber.Ctx, id string) error {
+	u, err := h.get(c, id)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(HeaderUploadOffset, strconv.FormatInt(u.Offset, 10))
+	c.Set(HeaderUploadLength, strconv.FormatInt(u.Size, 10))
+	if len(u.Metadata) > 0 {
+		c.Set(HeaderUploadMeta, formatMetadata(u.Metadata))
+	}
+	if !u.Complete() {
+		c.Set(HeaderUploadExpires, u.Expires.UTC().Format(http.TimeFormat))
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// patch serves a PATCH request appending bytes to an upload.
+func (h *handler) patch(c fiber.Ctx, id string) error {
+	if c.Get(fiber.HeaderContentType) != ContentTypeOffset {
+		return fiber.ErrUnsupportedMediaType
+	}
+	offset, err := strconv.ParseInt(c.Get(HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return fiber.ErrBadRequest
+	}
+
+	unlock, ok := h.lock(id)
+	if !ok {
+		return fiber.ErrLocked
+	}
+	defer unlock()
+
+	u, err := h.get(c, id)
+	if err != nil {
+		return err
+	}
+	if offset != u.Offset {
+		return fiber.ErrConflict
+	}
+
+	completed := u.Complete()
+	if err := h.write(c, u); err != nil {
+		return err
+	}
+
+	c.Set(HeaderUploadOffset, strconv.FormatInt(u.Offset, 10))
+	if !u.Complete() {
+		c.Set(HeaderUploadExpires, u.Expires.UTC().Format(http.TimeFormat))
+	} else if !completed && h.cfg.OnComplete != nil {
+		if err := h.cfg.OnComplete(c, u); err != nil {
+			return err
+		}
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// terminate serves a DELETE request deleting an upload.
+func (h *handler) terminate(c fiber.Ctx, id string) error {
+	unlock, ok := h.lock(id)
+	if !ok {
+		return fiber.ErrLocked
+	}
+	defer unlock()
+
+	if _, err := h.get(c, id); err != nil {
+		return err
+	}
+	if err := h.delete(c, id); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// write appends the body of the request to an upload, and saves its new
+// offset. The offset is saved when the body is only partially written, so
+// that the client resumes the upload after the bytes received.
+func (h *handler) write(c fiber.Ctx, u *Upload) error {
+	remaining := u.Size - u.Offset
+	if n := c.Request().Header.ContentLength(); n > 0 && int64(n) > remaining {
+		return fiber.ErrRequestEntityTooLarge
+	}
+
+	// The size of the upload is limited by the middleware: the chunk may be
+	// larger than the body limit of the app
+	c.SetBodyLimit(int(min(remaining+1, math.MaxInt)))
+	body := &limitReader{r: c.BodyStream(), n: remaining}
+	n, err := h.cfg.Sink.Append(c, u.ID, u.Offset, body)
+	if body.exceeded {
+		// The bytes written are discarded by the next Append at this offset
+		return fiber.ErrRequestEntityTooLarge
+	}
+
+	u.Offset += n
+	u.Expires = time.Now().Add(h.cfg.Expiration)
+	if saveErr := h.save(c, u); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// lock reserves an upload for a request of this process. It returns false
+// when another request is writing to the upload.
+func (h *handler) lock(id string) (func(), bool) {
+	if _, loaded := h.locks.LoadOrStore(id, struct{}{}); loaded {
+		return nil, false
+	}
+	return func() { h.locks.Delete(id) }, true
+}
+
+// get returns the state of an upload, or fiber.ErrNotFound if it does not
+// exist and fiber.ErrGone if it expired.
+func (h *handler) get(c fiber.Ctx, id string) (*Upload, error) {
+	u, err := loadUpload(c, h.cfg.Storage, id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fiber.ErrNotFound
+	}
+	if !u.Complete() && time.Now().After(u.Expires) {
+		if err := h.delete(c, id); err != nil {
+			return nil, err
+		}
+		return nil, fiber.ErrGone
+	}
+	return u, nil
+}
+
+// loadUpload returns the state of an upload from storage, or nil if it does
+// not exist.
+func loadUpload(ctx context.Context, storage fiber.Storage, id string) (*Upload, error) {
+	data, err := storage.GetWithContext(ctx, storageKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("tus: failed to get upload: %w", err)
+	}
+	if data == nil {
+		return nil, nil //nolint:nilnil // a missing upload is not an error
+	}
+
+	u := &Upload{}
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, fmt.Errorf("tus: failed to decode upload: %w", err)
+	}
+	return u, nil
+}
+
+// save stores the state of an unfinished upload until it expires, and that
+// of a completed upload until it is terminated. An unfinished upload which
+// already expired is deleted instead, and fiber.ErrGone returned.
+func (h *handler) save(ctx context.Context, u *Upload) error {
+	var exp time.Duration
+	if !u.Complete() {
+		if exp = time.Until(u.Expires); exp <= 0 {
+			if err := h.delete(ctx, u.ID); err != nil {
+				return err
+			}
+			return fiber.ErrGone
+		}
+	}
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		return fmt.Errorf("tus: failed to encode upload: %w", err)
+	}
+	if err := h.cfg.Storage.SetWithContext(ctx, storageKeyPrefix+u.ID, data, exp); err != nil {
+		return fmt.Errorf("tus: failed to save upload: %w", err)
+	}
+	return nil
+}
+
+// delete deletes the data and the state of an upload.
+func (h *handler) delete(ctx context.Context, id string) error {
+	if err := h.cfg.Sink.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := h.cfg.Storage.DeleteWithContext(ctx, storageKeyPrefix+id); err != nil {
+		return fmt.Errorf("tus: failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+// limitReader reads up to n bytes, and fails if the reader has more.
+type limitReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Check for a byte past the limit
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			l.exceeded = true
+			return 0, errTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,552 @@
+package tus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func newTusApp(t *testing.T, cfg Config) *fiber.App {
+	t.Helper()
+
+	if cfg.Sink == nil {
+		cfg.Sink = NewDiskSink(t.TempDir())
+	}
+	if cfg.BasePath == "" {
+		cfg.BasePath = "/files/"
+	}
+
+	app := fiber.New()
+	app.Use(New(cfg))
+	app.Get("/files/:id", func(c fiber.Ctx) error {
+		return c.SendString("download " + c.Params("id"))
+	})
+	return app
+}
+
+func tusRequest(method, target, body string, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(HeaderTusResumable, Version)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func createUpload(t *testing.T, app *fiber.App, size string) string {
+	t.Helper()
+
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/files", "", HeaderUploadLength, size))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	location := resp.Header.Get(fiber.HeaderLocation)
+	require.True(t, strings.HasPrefix(location, "http://example.com/files/"), location)
+	return strings.TrimPrefix(location, "http://example.com")
+}
+
+func Test_Tus_Upload(t *testing.T) {
+	t.Parallel()
+
+	sink := NewDiskSink(t.TempDir())
+	var completed atomic.Pointer[Upload]
+	app := newTusApp(t, Config{
+		Sink: sink,
+		OnComplete: func(_ fiber.Ctx, upload *Upload) error {
+			completed.Store(upload)
+			return nil
+		},
+	})
+
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/files/", "",
+		HeaderUploadLength, "11",
+		HeaderUploadMeta, "filename aGVsbG8udHh0,is_confidential"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Equal(t, Version, resp.Header.Get(HeaderTusResumable))
+	expires, err := http.ParseTime(resp.Header.Get(HeaderUploadExpires))
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), expires, time.Minute)
+	path := strings.TrimPrefix(resp.Header.Get(fiber.HeaderLocation), "http://example.com")
+	id := strings.TrimPrefix(path, "/files/")
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "0", resp.Header.Get(HeaderUploadOffset))
+	require.Equal(t, "11", resp.Header.Get(HeaderUploadLength))
+	require.Equal(t, "filename aGVsbG8udHh0,is_confidential", resp.Header.Get(HeaderUploadMeta))
+	require.Equal(t, "no-store", resp.Header.Get(fiber.HeaderCacheControl))
+
+	resp, err = app.Test(tusRequest(fiber.MethodPatch, path, "hello",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "5", resp.Header.Get(HeaderUploadOffset))
+	require.NotEmpty(t, resp.Header.Get(HeaderUploadExpires))
+	require.Nil(t, completed.Load())
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, "5", resp.Header.Get(HeaderUploadOffset))
+
+	resp, err = app.Test(tusRequest(fiber.MethodPatch, path, " world",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "5"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "11", resp.Header.Get(HeaderUploadOffset))
+	require.Empty(t, resp.Header.Get(HeaderUploadExpires))
+
+	upload := completed.Load()
+	require.NotNil(t, upload)
+	require.Equal(t, id, upload.ID)
+	require.Equal(t, int64(11), upload.Size)
+	require.True(t, upload.Complete())
+	require.Equal(t, map[string]string{"filename": "hello.txt", "is_confidential": ""}, upload.Metadata)
+
+	data, err := os.ReadFile(sink.Path(id))
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+
+	// Other methods are passed to the next handlers
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, path, http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "download "+id, string(body))
+}
+
+func Test_Tus_Patch_StreamRequestBody(t *testing.T) {
+	t.Parallel()
+
+	sink := NewDiskSink(t.TempDir())
+	app := fiber.New(fiber.Config{StreamRequestBody: true, BodyLimit: 16})
+	app.Use(New(Config{Sink: sink, BasePath: "/files/"}))
+
+	path := createUpload(t, app, "64")
+	chunk := strings.Repeat("a", 64)
+
+	// A chunk larger than the BodyLimit of the app is limited by the size of
+	// the upload instead
+	resp, err := app.Test(tusRequest(fiber.MethodPatch, path, chunk,
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "64", resp.Header.Get(HeaderUploadOffset))
+
+	data, err := os.ReadFile(sink.Path(strings.TrimPrefix(path, "/files/")))
+	require.NoError(t, err)
+	require.Equal(t, chunk, string(data))
+}
+
+func Test_Tus_CreationWithUpload(t *testing.T) {
+	t.Parallel()
+
+	var completed atomic.Int32
+	app := newTusApp(t, Config{
+		OnComplete: func(_ fiber.Ctx, _ *Upload) error {
+			completed.Add(1)
+			return nil
+		},
+	})
+
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/files/", "hello",
+		HeaderUploadLength, "11",
+		fiber.HeaderContentType, ContentTypeOffset))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Equal(t, "5", resp.Header.Get(HeaderUploadOffset))
+	require.Equal(t, int32(0), completed.Load())
+
+	resp, err = app.Test(tusRequest(fiber.MethodPost, "/files/", "hello",
+		HeaderUploadLength, "5",
+		fiber.HeaderContentType, ContentTypeOffset))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Equal(t, "5", resp.Header.Get(HeaderUploadOffset))
+	require.Equal(t, int32(1), completed.Load())
+
+	// Empty uploads are complete when created
+	createUpload(t, app, "0")
+	require.Equal(t, int32(2), completed.Load())
+
+	resp, err = app.Test(tusRequest(fiber.MethodPost, "/files/", "hello world",
+		HeaderUploadLength, "5",
+		fiber.HeaderContentType, ContentTypeOffset))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func Test_Tus_Create_Invalid(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{MaxSize: 100})
+
+	for _, tc := range []struct {
+		headers []string
+		status  int
+	}{
+		{headers: nil, status: fiber.StatusBadRequest},
+		{headers: []string{HeaderUploadLength, "-1"}, status: fiber.StatusBadRequest},
+		{headers: []string{HeaderUploadLength, "ten"}, status: fiber.StatusBadRequest},
+		{headers: []string{HeaderUploadLength, "101"}, status: fiber.StatusRequestEntityTooLarge},
+		{headers: []string{HeaderUploadLength, "10", HeaderUploadMeta, "filename !"}, status: fiber.StatusBadRequest},
+		{headers: []string{HeaderUploadLength, "100"}, status: fiber.StatusCreated},
+	} {
+		resp, err := app.Test(tusRequest(fiber.MethodPost, "/files/", "", tc.headers...))
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode, tc.headers)
+	}
+
+	// POST requests to an upload are passed to the next handlers
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/files/abc", "", HeaderUploadLength, "10"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func Test_Tus_Patch_Invalid(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{})
+	path := createUpload(t, app, "5")
+
+	for _, tc := range []struct {
+		path    string
+		body    string
+		headers []string
+		status  int
+	}{
+		{path: path, body: "abc", headers: []string{HeaderUploadOffset, "0"}, status: fiber.StatusUnsupportedMediaType},
+		{path: path, body: "abc", headers: []string{fiber.HeaderContentType, ContentTypeOffset}, status: fiber.StatusBadRequest},
+		{path: path, body: "abc", headers: []string{fiber.HeaderContentType, ContentTypeOffset, HeaderUploadOffset, "-1"}, status: fiber.StatusBadRequest},
+		{path: path, body: "abc", headers: []string{fiber.HeaderContentType, ContentTypeOffset, HeaderUploadOffset, "2"}, status: fiber.StatusConflict},
+		{path: path, body: "abcdef", headers: []string{fiber.HeaderContentType, ContentTypeOffset, HeaderUploadOffset, "0"}, status: fiber.StatusRequestEntityTooLarge},
+		{path: "/files/unknown", body: "abc", headers: []string{fiber.HeaderContentType, ContentTypeOffset, HeaderUploadOffset, "0"}, status: fiber.StatusNotFound},
+		{path: "/files/in.valid", body: "abc", headers: []string{fiber.HeaderContentType, ContentTypeOffset, HeaderUploadOffset, "0"}, status: fiber.StatusNotFound},
+	} {
+		resp, err := app.Test(tusRequest(fiber.MethodPatch, tc.path, tc.body, tc.headers...))
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode, tc.headers)
+	}
+
+	resp, err := app.Test(tusRequest(fiber.MethodHead, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, "0", resp.Header.Get(HeaderUploadOffset))
+}
+
+func Test_LimitReader(t *testing.T) {
+	t.Parallel()
+
+	// Bodies without Content-Length are checked while they are read
+	r := &limitReader{r: strings.NewReader("hello world"), n: 5}
+	n, err := io.Copy(io.Discard, r)
+	require.ErrorIs(t, err, errTooLarge)
+	require.Equal(t, int64(5), n)
+	require.True(t, r.exceeded)
+
+	r = &limitReader{r: strings.NewReader("hello"), n: 5}
+	n, err = io.Copy(io.Discard, r)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), n)
+	require.False(t, r.exceeded)
+}
+
+func Test_Tus_Patch_Resume(t *testing.T) {
+	t.Parallel()
+
+	sink := &failingSink{DiskSink: NewDiskSink(t.TempDir()), failAfter: 3}
+	app := newTusApp(t, Config{Sink: sink})
+	path := createUpload(t, app, "11")
+	id := strings.TrimPrefix(path, "/files/")
+
+	// The bytes written before a failure are kept
+	resp, err := app.Test(tusRequest(fiber.MethodPatch, path, "hello world",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, "3", resp.Header.Get(HeaderUploadOffset))
+
+	sink.failAfter = 0
+	resp, err = app.Test(tusRequest(fiber.MethodPatch, path, "lo world",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "3"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "11", resp.Header.Get(HeaderUploadOffset))
+
+	data, err := os.ReadFile(sink.Path(id))
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+}
+
+func Test_Tus_Patch_Locked(t *testing.T) {
+	t.Parallel()
+
+	sink := &blockingSink{DiskSink: NewDiskSink(t.TempDir()), started: make(chan struct{}), release: make(chan struct{})}
+	app := newTusApp(t, Config{Sink: sink})
+	path := createUpload(t, app, "5")
+
+	done := make(chan int)
+	go func() {
+		resp, err := app.Test(tusRequest(fiber.MethodPatch, path, "hello",
+			fiber.HeaderContentType, ContentTypeOffset,
+			HeaderUploadOffset, "0"), fiber.TestConfig{Timeout: 0})
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+	<-sink.started
+
+	resp, err := app.Test(tusRequest(fiber.MethodPatch, path, "hello",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusLocked, resp.StatusCode)
+
+	resp, err = app.Test(tusRequest(fiber.MethodDelete, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusLocked, resp.StatusCode)
+
+	close(sink.release)
+	require.Equal(t, fiber.StatusNoContent, <-done)
+}
+
+func Test_Tus_OnComplete_Error(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{
+		OnComplete: func(_ fiber.Ctx, _ *Upload) error {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "invalid file")
+		},
+	})
+	path := createUpload(t, app, "5")
+
+	resp, err := app.Test(tusRequest(fiber.MethodPatch, path, "hello",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+	// The upload is complete, so the hook is not called again
+	resp, err = app.Test(tusRequest(fiber.MethodPatch, path, "",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "5"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
+
+func Test_Tus_Terminate(t *testing.T) {
+	t.Parallel()
+
+	sink := NewDiskSink(t.TempDir())
+	app := newTusApp(t, Config{Sink: sink})
+	path := createUpload(t, app, "5")
+
+	resp, err := app.Test(tusRequest(fiber.MethodDelete, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+	_, err = os.Stat(sink.Path(strings.TrimPrefix(path, "/files/")))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(tusRequest(fiber.MethodDelete, path, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func Test_Tus_Expired(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.New()
+	sink := NewDiskSink(t.TempDir())
+	app := newTusApp(t, Config{Storage: storage, Sink: sink})
+
+	require.NoError(t, sink.Create(context.Background(), "expired"))
+	data, err := json.Marshal(&Upload{ID: "expired", Size: 5, Expires: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	require.NoError(t, storage.Set(storageKeyPrefix+"expired", data, 0))
+
+	resp, err := app.Test(tusRequest(fiber.MethodHead, "/files/expired", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusGone, resp.StatusCode)
+
+	_, err = os.Stat(sink.Path("expired"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	data, err = storage.Get(storageKeyPrefix + "expired")
+	require.NoError(t, err)
+	require.Nil(t, data)
+}
+
+func Test_Tus_Save_Expiry(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.New()
+	sink := NewDiskSink(t.TempDir())
+	cfg := configDefault(Config{Storage: storage, Sink: sink, BasePath: "/files/"})
+	h := &handler{cfg: &cfg}
+	ctx := context.Background()
+
+	// A completed upload is kept past its expiry
+	completed := &Upload{ID: "completed", Size: 5, Offset: 5, Expires: time.Now().Add(-time.Minute)}
+	require.NoError(t, h.save(ctx, completed))
+	data, err := storage.Get(storageKeyPrefix + "completed")
+	require.NoError(t, err)
+	require.NotNil(t, data)
+
+	// An unfinished upload past its expiry is deleted
+	require.NoError(t, sink.Create(ctx, "expired"))
+	require.NoError(t, storage.Set(storageKeyPrefix+"expired", []byte("{}"), 0))
+	expired := &Upload{ID: "expired", Size: 5, Offset: 2, Expires: time.Now().Add(-time.Minute)}
+	require.ErrorIs(t, h.save(ctx, expired), fiber.ErrGone)
+	data, err = storage.Get(storageKeyPrefix + "expired")
+	require.NoError(t, err)
+	require.Nil(t, data)
+	_, err = os.Stat(sink.Path("expired"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_Tus_Options(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{MaxSize: 1 << 20})
+
+	for _, path := range []string{"/files", "/files/", "/files/abc"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodOptions, path, http.NoBody))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusNoContent, resp.StatusCode, path)
+		require.Equal(t, Version, resp.Header.Get(HeaderTusVersion))
+		require.Equal(t, Extensions, resp.Header.Get(HeaderTusExtension))
+		require.Equal(t, "1048576", resp.Header.Get(HeaderTusMaxSize))
+	}
+}
+
+func Test_Tus_Version(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/files/", http.NoBody)
+	req.Header.Set(HeaderUploadLength, "5")
+	req.Header.Set(HeaderTusResumable, "0.2.2")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+	require.Equal(t, Version, resp.Header.Get(HeaderTusVersion))
+
+	req.Header.Del(HeaderTusResumable)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+}
+
+func Test_Tus_MethodOverride(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{})
+	path := createUpload(t, app, "5")
+
+	resp, err := app.Test(tusRequest(fiber.MethodPost, path, "hello",
+		"X-HTTP-Method-Override", "PATCH",
+		fiber.HeaderContentType, ContentTypeOffset,
+		HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "5", resp.Header.Get(HeaderUploadOffset))
+
+	resp, err = app.Test(tusRequest(fiber.MethodPost, path, "", "X-HTTP-Method-Override", "delete"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
+
+func Test_Tus_Next(t *testing.T) {
+	t.Parallel()
+
+	app := newTusApp(t, Config{
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	})
+
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/files/", "", HeaderUploadLength, "5"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Paths outside the base path are passed to the next handlers
+	app = newTusApp(t, Config{})
+	app.Post("/other", func(c fiber.Ctx) error {
+		return c.SendString("other")
+	})
+	resp, err = app.Test(tusRequest(fiber.MethodPost, "/other", "", HeaderUploadLength, "5"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func Test_Tus_Config(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "tus: Sink and BasePath are required", func() { New() })
+	require.PanicsWithValue(t, "tus: Sink and BasePath are required", func() { New(Config{BasePath: "/files/"}) })
+	require.PanicsWithValue(t, "tus: Sink and BasePath are required", func() { New(Config{Sink: NewDiskSink(t.TempDir())}) })
+	require.PanicsWithValue(t, "tus: MaxSize must not be negative", func() {
+		New(Config{Sink: NewDiskSink(t.TempDir()), BasePath: "/files/", MaxSize: -1})
+	})
+
+	cfg := configDefault(Config{Sink: NewDiskSink(t.TempDir()), BasePath: "/files"})
+	require.Equal(t, "/files/", cfg.BasePath)
+	require.Equal(t, 24*time.Hour, cfg.Expiration)
+	require.NotNil(t, cfg.Storage)
+}
+
+// failingSink fails after writing failAfter bytes when failAfter is set.
+type failingSink struct {
+	*DiskSink
+	failAfter int64
+}
+
+func (s *failingSink) Append(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	if s.failAfter == 0 {
+		return s.DiskSink.Append(ctx, id, offset, r)
+	}
+	n, err := s.DiskSink.Append(ctx, id, offset, io.LimitReader(r, s.failAfter))
+	if err != nil {
+		return n, err
+	}
+	return n, errors.New("write failed")
+}
+
+// blockingSink blocks the writes until release is closed.
+type blockingSink struct {
+	*DiskSink
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSink) Append(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	close(s.started)
+	<-s.release
+	return s.DiskSink.Append(ctx, id, offset, r)
+}
@@ -0,0 +1,75 @@
+package tus
+
+import (
+	"encoding/base64"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Upload is the state of an upload, kept in the Storage of the middleware.
+type Upload struct {
+	// Expires is the time after which an unfinished upload is deleted. A
+	// completed upload is kept until it is terminated.
+	Expires time.Time `json:"expires"`
+	// Metadata holds the decoded values of the Upload-Metadata header sent
+	// when the upload was created, such as its file name.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// ID identifies the upload in its URL and in the Sink.
+	ID string `json:"id"`
+	// Size is the total size of the upload, in bytes.
+	Size int64 `json:"size"`
+	// Offset is the number of bytes received.
+	Offset int64 `json:"offset"`
+}
+
+// Complete reports whether all the bytes of the upload are received.
+func (u *Upload) Complete() bool {
+	return u.Offset == u.Size
+}
+
+var errInvalidMetadata = errors.New("tus: invalid Upload-Metadata header")
+
+// parseMetadata parses an Upload-Metadata header: comma-separated pairs of a
+// key and a base64 encoded value, where the value may be omitted.
+func parseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil //nolint:nilnil // no metadata
+	}
+
+	metadata := make(map[string]string)
+	for pair := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errInvalidMetadata
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, errInvalidMetadata
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errInvalidMetadata
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+// formatMetadata formats metadata as an Upload-Metadata header, with the
+// keys sorted.
+func formatMetadata(metadata map[string]string) string {
+	var b strings.Builder
+	for i, key := range slices.Sorted(maps.Keys(metadata)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(key)
+		if metadata[key] != "" {
+			b.WriteByte(' ')
+			b.WriteString(base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package tus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseMetadata(t *testing.T) {
+	t.Parallel()
+
+	metadata, err := parseMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==, is_confidential,type dGV4dC9wbGFpbg==")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"filename":        "world_domination_plan.pdf",
+		"is_confidential": "",
+		"type":            "text/plain",
+	}, metadata)
+
+	metadata, err = parseMetadata(" ")
+	require.NoError(t, err)
+	require.Nil(t, metadata)
+
+	for _, header := range []string{"filename !!!", "a YQ==,a Yg==", ",filename", "filename YQ==,"} {
+		_, err := parseMetadata(header)
+		require.ErrorIs(t, err, errInvalidMetadata, header)
+	}
+}
+
+func Test_FormatMetadata(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]string{"type": "text/plain", "filename": "a.txt", "is_confidential": ""}
+	header := formatMetadata(metadata)
+	require.Equal(t, "filename YS50eHQ=,is_confidential,type dGV4dC9wbGFpbg==", header)
+
+	parsed, err := parseMetadata(header)
+	require.NoError(t, err)
+	require.Equal(t, metadata, parsed)
+
+	require.Empty(t, formatMetadata(nil))
+}
@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Handler serves a single WebSocket connection. The connection is closed when
+// it returns: with a normal closure (1000) when it returns nil, and with an
+// internal error (1011) otherwise.
+type Handler func(c fiber.Ctx, conn *Conn) error
+
+// Config defines the config for the WebSocket handler.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// Handler serves each accepted connection.
+	//
+	// Required.
+	Handler Handler
+
+	// AllowOrigins lists the origins allowed to open a connection, e.g.
+	// "https://example.com". "*" allows any origin. When it is empty, only
+	// requests without an Origin header or from the origin of the request
+	// host are accepted, which guards against cross-site WebSocket hijacking.
+	//
+	// Optional. Default: nil
+	AllowOrigins []string
+
+	// Subprotocols lists the subprotocols the server supports, in order of
+	// preference. The first one the client also offers is selected and can
+	// be read back through Conn.Subprotocol.
+	//
+	// Optional. Default: nil
+	Subprotocols []string
+
+	// HandshakeTimeout limits how long the opening handshake may take.
+	//
+	// Optional. Default: 0 (no limit)
+	HandshakeTimeout time.Duration
+
+	// ReadBufferSize and WriteBufferSize set the I/O buffer sizes in bytes.
+	// They do not limit the size of the messages that can be read or
+	// written.
+	//
+	// Optional. Default: 0 (the buffers of the HTTP server are reused)
+	ReadBufferSize  int
+	WriteBufferSize int
+
+	// ReadLimit is the maximum size in bytes of a message read from the
+	// peer. The connection is closed with 1009 (message too big) when a
+	// message exceeds it.
+	//
+	// Optional. Default: 4 * 1024 * 1024
+	ReadLimit int64
+
+	// PingInterval is how often a ping is sent to the peer to keep the
+	// connection alive through intermediaries. Values less than or equal to
+	// zero disable pings.
+	//
+	// Optional. Default: 0
+	PingInterval time.Duration
+
+	// CloseTimeout is how long the peer has to answer the close frame sent
+	// when the server shuts down, before the connection is dropped.
+	//
+	// Optional. Default: 5 * time.Second
+	CloseTimeout time.Duration
+
+	// EnableCompression negotiates per-message deflate (RFC 7692) with
+	// clients that offer it. Only the "no context takeover" mode is
+	// supported.
+	//
+	// Optional. Default: false
+	EnableCompression bool
+}
+
+// ConfigDefault is the default config.
+var ConfigDefault = Config{
+	Next:              nil,
+	Handler:           nil,
+	ReadLimit:         4 * 1024 * 1024,
+	CloseTimeout:      5 * time.Second,
+	EnableCompression: false,
+}
+
+// Helper function to set default values.
+func configDefault(config ...Config) Config {
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	cfg := config[0]
+	if cfg.ReadLimit <= 0 {
+		cfg.ReadLimit = ConfigDefault.ReadLimit
+	}
+	if cfg.CloseTimeout <= 0 {
+		cfg.CloseTimeout = ConfigDefault.CloseTimeout
+	}
+	return cfg
+}
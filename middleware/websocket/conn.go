@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+// Message types, as defined in RFC 6455 Section 11.8.
+const (
+	// TextMessage denotes a text data message, encoded as UTF-8.
+	TextMessage = websocket.TextMessage
+	// BinaryMessage denotes a binary data message.
+	BinaryMessage = websocket.BinaryMessage
+	// CloseMessage denotes a close control message.
+	CloseMessage = websocket.CloseMessage
+	// PingMessage denotes a ping control message.
+	PingMessage = websocket.PingMessage
+	// PongMessage denotes a pong control message.
+	PongMessage = websocket.PongMessage
+)
+
+// Close codes, as defined in RFC 6455 Section 11.7.
+const (
+	CloseNormalClosure           = websocket.CloseNormalClosure
+	CloseGoingAway               = websocket.CloseGoingAway
+	CloseProtocolError           = websocket.CloseProtocolError
+	CloseUnsupportedData         = websocket.CloseUnsupportedData
+	CloseNoStatusReceived        = websocket.CloseNoStatusReceived
+	CloseAbnormalClosure         = websocket.CloseAbnormalClosure
+	CloseInvalidFramePayloadData = websocket.CloseInvalidFramePayloadData
+	ClosePolicyViolation         = websocket.ClosePolicyViolation
+	CloseMessageTooBig           = websocket.CloseMessageTooBig
+	CloseMandatoryExtension      = websocket.CloseMandatoryExtension
+	CloseInternalServerErr       = websocket.CloseInternalServerErr
+	CloseServiceRestart          = websocket.CloseServiceRestart
+	CloseTryAgainLater           = websocket.CloseTryAgainLater
+	CloseTLSHandshake            = websocket.CloseTLSHandshake
+)
+
+// controlTimeout bounds how long writing a control frame may block.
+const controlTimeout = time.Second
+
+// CloseError is the error returned by the read methods once the peer has
+// sent a close frame. Code holds its close code.
+type CloseError = websocket.CloseError
+
+// IsCloseError reports whether err is a *CloseError with one of the codes.
+func IsCloseError(err error, codes ...int) bool {
+	return websocket.IsCloseError(err, codes...)
+}
+
+// IsUnexpectedCloseError reports whether err is a *CloseError with a code
+// that is not among the expected ones.
+func IsUnexpectedCloseError(err error, expectedCodes ...int) bool {
+	return websocket.IsUnexpectedCloseError(err, expectedCodes...)
+}
+
+// FormatCloseMessage formats a close code and text as the payload of a close
+// frame.
+func FormatCloseMessage(code int, text string) []byte {
+	return websocket.FormatCloseMessage(code, text)
+}
+
+// Conn is an accepted WebSocket connection.
+//
+// It embeds the connection of github.com/fasthttp/websocket, which provides
+// ReadMessage, WriteMessage, ReadJSON, WriteJSON, the streaming NextReader and
+// NextWriter, deadlines, and ping, pong and close handlers. Pings are
+// answered automatically. As with that package, at most one goroutine may
+// read and one may write at a time; WriteControl and WriteClose may be called
+// from any goroutine.
+type Conn struct {
+	*websocket.Conn
+}
+
+// WriteClose sends a close frame with code and text to start the closing
+// handshake. The peer's answer is returned by the next read as a *CloseError,
+// after which the handler should return.
+func (c *Conn) WriteClose(code int, text string) error {
+	return c.WriteControl(CloseMessage, FormatCloseMessage(code, text), time.Now().Add(controlTimeout))
+}
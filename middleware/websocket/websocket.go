@@ -0,0 +1,184 @@
+// Package websocket accepts RFC 6455 WebSocket connections on Fiber routes.
+//
+// The handler runs on the hijacked connection with the request's fiber.Ctx
+// still usable: params, locals and headers can be read for as long as the
+// connection is open. When the app shuts down, open connections are sent a
+// close frame with 1001 (going away).
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/log"
+	"github.com/valyala/fasthttp"
+)
+
+// New creates a new WebSocket handler. Requests that are not a WebSocket
+// upgrade are answered with 426 Upgrade Required.
+func New(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+	if cfg.Handler == nil {
+		panic("websocket: Handler must not be nil")
+	}
+
+	upgrader := websocket.FastHTTPUpgrader{
+		HandshakeTimeout:  cfg.HandshakeTimeout,
+		ReadBufferSize:    cfg.ReadBufferSize,
+		WriteBufferSize:   cfg.WriteBufferSize,
+		Subprotocols:      cfg.Subprotocols,
+		EnableCompression: cfg.EnableCompression,
+		CheckOrigin:       originChecker(cfg.AllowOrigins),
+		// The handshake error is returned to Fiber, so the app's error
+		// handler writes the response.
+		Error: func(ctx *fasthttp.RequestCtx, status int, _ error) {
+			ctx.SetStatusCode(status)
+		},
+	}
+
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		if !c.IsWebSocket() {
+			return fiber.ErrUpgradeRequired
+		}
+
+		// Closed by fasthttp when the server shuts down.
+		shutdown := c.RequestCtx().Done()
+
+		err := upgrader.Upgrade(c.RequestCtx(), func(ws *websocket.Conn) {
+			// The request handler returned before the connection was
+			// handed over, so nothing else uses c any more.
+			defer c.ForceRelease()
+
+			serve(c, &Conn{Conn: ws}, &cfg, shutdown)
+		})
+		if err != nil {
+			return fiber.NewError(c.Response().StatusCode(), err.Error())
+		}
+
+		// Keep c out of the pool while the connection uses it.
+		c.Abandon()
+		return nil
+	}
+}
+
+// serve runs the handler on an accepted connection and closes it afterwards.
+func serve(c fiber.Ctx, conn *Conn, cfg *Config, shutdown <-chan struct{}) {
+	conn.SetReadLimit(cfg.ReadLimit)
+
+	stop := make(chan struct{})
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		watch(conn, cfg, shutdown, stop)
+	}()
+
+	err := runHandler(c, conn, cfg.Handler)
+
+	close(stop)
+	<-watched
+
+	code := CloseNormalClosure
+	if err != nil && !isDisconnect(err) {
+		code = CloseInternalServerErr
+	}
+	// Fails with ErrCloseSent when the handler, the peer or a shutdown
+	// already started the closing handshake, which is fine.
+	_ = conn.WriteClose(code, "") //nolint:errcheck // best effort, the connection is closed next
+	_ = conn.Close()              //nolint:errcheck // nothing left to do with the error
+}
+
+// watch sends pings every PingInterval and, when the server shuts down, the
+// close frame with 1001 (going away). It returns when stop is closed.
+func watch(conn *Conn, cfg *Config, shutdown, stop <-chan struct{}) {
+	var ticks <-chan time.Time
+	if cfg.PingInterval > 0 {
+		ticker := time.NewTicker(cfg.PingInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-shutdown:
+			if err := conn.WriteClose(CloseGoingAway, "server shutting down"); err == nil {
+				// The handler's pending read returns once the peer answers,
+				// or once the peer has had CloseTimeout to do so.
+				_ = conn.SetReadDeadline(time.Now().Add(cfg.CloseTimeout)) //nolint:errcheck // the deadline only cuts the wait short
+			}
+			<-stop
+			return
+		case <-ticks:
+			if err := conn.WriteControl(PingMessage, nil, time.Now().Add(controlTimeout)); err != nil {
+				// The connection is gone; the handler's next read fails.
+				ticks = nil
+			}
+		}
+	}
+}
+
+// runHandler calls the handler, turning a panic into an error so the
+// connection is still closed and the hijacked goroutine does not crash the
+// process.
+func runHandler(c fiber.Ctx, conn *Conn, handler Handler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("websocket: handler panic: %v", recovered)
+			log.Errorf("%v", err)
+		}
+	}()
+	return handler(c, conn)
+}
+
+// isDisconnect reports whether err only says the peer closed the connection.
+func isDisconnect(err error) bool {
+	var closeErr *CloseError
+	return errors.As(err, &closeErr) || errors.Is(err, websocket.ErrCloseSent)
+}
+
+// originChecker returns the CheckOrigin function of the upgrader, or nil to
+// keep its same-origin default.
+func originChecker(allowOrigins []string) func(ctx *fasthttp.RequestCtx) bool {
+	if len(allowOrigins) == 0 {
+		return nil
+	}
+	if slices.Contains(allowOrigins, "*") {
+		return func(*fasthttp.RequestCtx) bool { return true }
+	}
+
+	allowed := make(map[string]struct{}, len(allowOrigins))
+	for _, origin := range allowOrigins {
+		allowed[normalizeOrigin(origin)] = struct{}{}
+	}
+
+	return func(ctx *fasthttp.RequestCtx) bool {
+		origin := ctx.Request.Header.Peek(fiber.HeaderOrigin)
+		if len(origin) == 0 {
+			return true
+		}
+		_, ok := allowed[normalizeOrigin(string(origin))]
+		return ok
+	}
+}
+
+// normalizeOrigin lowercases the scheme and host of an origin and drops a
+// trailing slash, so configured and received origins compare equal.
+func normalizeOrigin(origin string) string {
+	u, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return strings.ToLower(origin)
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
@@ -0,0 +1,344 @@
+package websocket
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// serveApp starts app on an in-memory listener and returns a dialer for it.
+func serveApp(t *testing.T, app *fiber.App) *websocket.Dialer {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true})
+	}()
+
+	t.Cleanup(func() {
+		if err := app.Shutdown(); err != nil && !errors.Is(err, fiber.ErrNotRunning) {
+			require.NoError(t, err)
+		}
+		if err := <-errCh; err != nil && !errors.Is(err, net.ErrClosed) {
+			require.NoError(t, err)
+		}
+	})
+
+	return &websocket.Dialer{
+		NetDial: func(_, _ string) (net.Conn, error) {
+			return ln.Dial()
+		},
+		HandshakeTimeout: time.Second,
+	}
+}
+
+func dial(t *testing.T, dialer *websocket.Dialer, path string, header http.Header) *websocket.Conn {
+	t.Helper()
+
+	conn, resp, err := dialer.Dial("ws://example.com"+path, header)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusSwitchingProtocols, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	t.Cleanup(func() {
+		_ = conn.Close() //nolint:errcheck // the server may have closed it already
+	})
+	return conn
+}
+
+func echo(_ fiber.Ctx, conn *Conn) error {
+	for {
+		messageType, msg, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if err := conn.WriteMessage(messageType, msg); err != nil {
+			return err
+		}
+	}
+}
+
+func Test_WebSocket_Echo(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{Handler: echo}))
+
+	conn := dial(t, serveApp(t, app), "/ws", nil)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	messageType, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.TextMessage, messageType)
+	require.Equal(t, "hello", string(msg))
+
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{0, 1, 2}))
+	messageType, msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.BinaryMessage, messageType)
+	require.Equal(t, []byte{0, 1, 2}, msg)
+}
+
+func Test_WebSocket_Ctx(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		fiber.Locals(c, "user", "john")
+		return c.Next()
+	})
+	app.Get("/ws/:room", New(Config{
+		Handler: func(c fiber.Ctx, conn *Conn) error {
+			// The ctx outlives the request handler for as long as the
+			// connection is open.
+			time.Sleep(10 * time.Millisecond)
+			reply := c.Params("room") + ":" + fiber.Locals[string](c, "user") + ":" + c.Query("token")
+			return conn.WriteMessage(TextMessage, []byte(reply))
+		},
+	}))
+
+	conn := dial(t, serveApp(t, app), "/ws/lobby?token=abc", nil)
+
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, "lobby:john:abc", string(msg))
+}
+
+func Test_WebSocket_CloseCodes(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ok", New(Config{Handler: func(_ fiber.Ctx, _ *Conn) error {
+		return nil
+	}}))
+	app.Get("/fail", New(Config{Handler: func(_ fiber.Ctx, _ *Conn) error {
+		return errors.New("boom")
+	}}))
+	app.Get("/panic", New(Config{Handler: func(_ fiber.Ctx, _ *Conn) error {
+		panic("boom")
+	}}))
+	app.Get("/custom", New(Config{Handler: func(_ fiber.Ctx, conn *Conn) error {
+		if err := conn.WriteClose(ClosePolicyViolation, "not allowed"); err != nil {
+			return err
+		}
+		_, _, err := conn.ReadMessage()
+		return err
+	}}))
+
+	dialer := serveApp(t, app)
+
+	testCases := []struct {
+		path string
+		text string
+		code int
+	}{
+		{path: "/ok", code: CloseNormalClosure},
+		{path: "/fail", code: CloseInternalServerErr},
+		{path: "/panic", code: CloseInternalServerErr},
+		{path: "/custom", code: ClosePolicyViolation, text: "not allowed"},
+	}
+	for _, tc := range testCases {
+		conn := dial(t, dialer, tc.path, nil)
+		_, _, err := conn.ReadMessage()
+
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr, tc.path)
+		require.Equal(t, tc.code, closeErr.Code, tc.path)
+		require.Equal(t, tc.text, closeErr.Text, tc.path)
+	}
+}
+
+func Test_WebSocket_NotUpgrade(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{Handler: echo}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/ws", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+}
+
+func Test_WebSocket_Next(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Handler: echo,
+		Next: func(c fiber.Ctx) bool {
+			return !c.IsWebSocket()
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("plain")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func Test_WebSocket_Origin(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/same", New(Config{Handler: echo}))
+	app.Get("/allowed", New(Config{Handler: echo, AllowOrigins: []string{"https://app.example.org"}}))
+	app.Get("/any", New(Config{Handler: echo, AllowOrigins: []string{"*"}}))
+
+	dialer := serveApp(t, app)
+	foreign := http.Header{fiber.HeaderOrigin: []string{"https://evil.example.net"}}
+
+	_, resp, err := dialer.Dial("ws://example.com/same", foreign)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	dial(t, dialer, "/same", http.Header{fiber.HeaderOrigin: []string{"http://example.com"}})
+
+	_, resp, err = dialer.Dial("ws://example.com/allowed", foreign)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	dial(t, dialer, "/allowed", http.Header{fiber.HeaderOrigin: []string{"HTTPS://App.Example.org/"}})
+	dial(t, dialer, "/any", foreign)
+}
+
+func Test_WebSocket_Subprotocols(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		Subprotocols: []string{"v2.chat", "v1.chat"},
+		Handler: func(_ fiber.Ctx, conn *Conn) error {
+			return conn.WriteMessage(TextMessage, []byte(conn.Subprotocol()))
+		},
+	}))
+
+	dialer := serveApp(t, app)
+	dialer.Subprotocols = []string{"v1.chat", "v2.chat"}
+
+	conn := dial(t, dialer, "/ws", nil)
+	require.Equal(t, "v2.chat", conn.Subprotocol())
+
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, "v2.chat", string(msg))
+}
+
+func Test_WebSocket_Compression(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{Handler: echo, EnableCompression: true}))
+
+	dialer := serveApp(t, app)
+	dialer.EnableCompression = true
+
+	conn, resp, err := dialer.Dial("ws://example.com/ws", nil)
+	require.NoError(t, err)
+	require.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+	require.NoError(t, resp.Body.Close())
+	defer conn.Close() //nolint:errcheck // closed at the end of the test
+
+	payload := []byte("compressible compressible compressible compressible")
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, payload))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, payload, msg)
+}
+
+func Test_WebSocket_ReadLimit(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{Handler: echo, ReadLimit: 8}))
+
+	conn := dial(t, serveApp(t, app), "/ws", nil)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("longer than eight bytes")))
+	_, _, err := conn.ReadMessage()
+
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, CloseMessageTooBig, closeErr.Code)
+}
+
+func Test_WebSocket_Ping(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{Handler: echo, PingInterval: 10 * time.Millisecond}))
+
+	conn := dial(t, serveApp(t, app), "/ws", nil)
+
+	var pings atomic.Int32
+	conn.SetPingHandler(func(string) error {
+		pings.Add(1)
+		return nil
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	require.Eventually(t, func() bool {
+		return pings.Load() >= 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func Test_WebSocket_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	handlerDone := make(chan error, 1)
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		Handler: func(c fiber.Ctx, conn *Conn) error {
+			err := echo(c, conn)
+			handlerDone <- err
+			return err
+		},
+	}))
+
+	conn := dial(t, serveApp(t, app), "/ws", nil)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	_, _, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	require.NoError(t, app.ShutdownWithTimeout(time.Second))
+
+	// The client reads the close frame and answers it
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, CloseGoingAway, closeErr.Code)
+
+	select {
+	case err := <-handlerDone:
+		require.True(t, IsCloseError(err, CloseGoingAway), "got %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("handler did not return after shutdown")
+	}
+}
+
+func Test_WebSocket_NilHandler(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "websocket: Handler must not be nil", func() {
+		New()
+	})
+}
@@ -0,0 +1,387 @@
+package fiber
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/gofiber/fiber/v3/internal/nilerror"
+	"github.com/gofiber/utils/v2"
+)
+
+// problemXMLNamespace is the namespace RFC 9457 Appendix B assigns to the XML
+// form of a problem details object.
+const problemXMLNamespace = "urn:ietf:rfc:7807"
+
+// ProblemDetails is an error carrying an RFC 9457 problem details object.
+// Return one from a handler to control every member of the error response
+// rendered by ProblemErrorHandler; DefaultErrorHandler answers with its Status
+// and Detail.
+type ProblemDetails struct {
+	// Extensions holds additional members, rendered next to the standard
+	// ones. Keys naming a standard member are ignored.
+	Extensions map[string]any
+
+	// Type is a URI reference identifying the problem type. When it is
+	// empty, the problem has no semantics beyond its status code
+	// ("about:blank").
+	Type string
+
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+
+	// Detail is a human-readable explanation specific to this occurrence.
+	Detail string
+
+	// Instance is a URI reference identifying this occurrence.
+	Instance string
+
+	// Status is the HTTP status code of the response. Zero is sent as 500.
+	Status int
+}
+
+// ProblemFieldError describes one input of a request that failed binding or
+// validation. ProblemErrorHandler lists them in the "errors" extension member.
+type ProblemFieldError struct {
+	// Source is where the input was read from, one of the BindSource*
+	// constants, or empty when it is not known.
+	Source string `json:"source,omitempty" xml:"source,omitempty" cbor:"source,omitempty"`
+	// Field is the struct field or key of the input.
+	Field string `json:"field,omitempty" xml:"field,omitempty" cbor:"field,omitempty"`
+	// Detail explains what is wrong with the input.
+	Detail string `json:"detail" xml:"detail" cbor:"detail"`
+}
+
+// NewProblem creates a problem details error for status, titled with its
+// status text and carrying an optional detail.
+func NewProblem(status int, detail ...string) *ProblemDetails {
+	p := &ProblemDetails{
+		Status: status,
+		Title:  utils.StatusMessage(status),
+	}
+	if len(detail) > 0 {
+		p.Detail = detail[0]
+	}
+	return p
+}
+
+// Error returns the detail of the problem, or its title when it has none.
+func (p *ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	if p.Title != "" {
+		return p.Title
+	}
+	return utils.StatusMessage(p.status())
+}
+
+// status returns the status code the problem is sent with.
+func (p *ProblemDetails) status() int {
+	if p.Status == 0 {
+		return StatusInternalServerError
+	}
+	return p.Status
+}
+
+// members returns the problem as the members of its JSON object, extensions
+// included. Empty standard members are left out.
+func (p *ProblemDetails) members() map[string]any {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		if !isProblemMember(key) {
+			members[key] = value
+		}
+	}
+	if p.Type != "" {
+		members["type"] = p.Type
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return members
+}
+
+// MarshalJSON encodes the problem as a JSON object, with its extension members
+// next to the standard ones.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	out, err := json.Marshal(p.members())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode problem: %w", err)
+	}
+	return out, nil
+}
+
+// MarshalXML encodes the problem in the XML form of RFC 9457 Appendix B.
+func (p *ProblemDetails) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Space: problemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return fmt.Errorf("failed to encode problem: %w", err)
+	}
+
+	members := p.members()
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := encodeProblemXMLValue(e, key, members[key]); err != nil {
+			return err
+		}
+	}
+
+	if err := e.EncodeToken(start.End()); err != nil {
+		return fmt.Errorf("failed to encode problem: %w", err)
+	}
+	return nil
+}
+
+// encodeProblemXMLValue encodes a member as an element named after it. Array
+// items are wrapped in "i" elements and map entries in elements named after
+// their keys, as RFC 9457 Appendix B lays out; other values are left to
+// encoding/xml.
+func encodeProblemXMLValue(e *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return encodeProblemXMLElement(e, nil, start)
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return encodeProblemXMLElement(e, value, start)
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return fmt.Errorf("failed to encode problem member %q: %w", name, err)
+		}
+		for i := range rv.Len() {
+			if err := encodeProblemXMLValue(e, "i", rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return encodeProblemXMLElement(e, value, start)
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return fmt.Errorf("failed to encode problem member %q: %w", name, err)
+		}
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+		for _, key := range keys {
+			if err := encodeProblemXMLValue(e, key.String(), rv.MapIndex(key).Interface()); err != nil {
+				return err
+			}
+		}
+	default:
+		return encodeProblemXMLElement(e, value, start)
+	}
+
+	if err := e.EncodeToken(start.End()); err != nil {
+		return fmt.Errorf("failed to encode problem member %q: %w", name, err)
+	}
+	return nil
+}
+
+func encodeProblemXMLElement(e *xml.Encoder, value any, start xml.StartElement) error {
+	if value == nil {
+		if err := e.EncodeToken(start); err != nil {
+			return fmt.Errorf("failed to encode problem member %q: %w", start.Name.Local, err)
+		}
+		if err := e.EncodeToken(start.End()); err != nil {
+			return fmt.Errorf("failed to encode problem member %q: %w", start.Name.Local, err)
+		}
+		return nil
+	}
+	if err := e.EncodeElement(value, start); err != nil {
+		return fmt.Errorf("failed to encode problem member %q: %w", start.Name.Local, err)
+	}
+	return nil
+}
+
+func isProblemMember(key string) bool {
+	switch key {
+	case "type", "title", "status", "detail", "instance":
+		return true
+	default:
+		return false
+	}
+}
+
+// AsProblem describes err as a problem details object:
+//
+//   - a *ProblemDetails in err's chain is returned as a copy, with its status
+//     and title filled in when they are empty;
+//   - a *Error, as created by NewError and NewErrorf, keeps its code, and its
+//     message becomes the detail when it is not the status text;
+//   - a *BindError is a 400 Bad Request listing the failed inputs in the
+//     "errors" extension member;
+//   - a validation error whose entries report the field they are about, such
+//     as the ValidationErrors of go-playground/validator, is a 400 Bad Request
+//     listing them the same way;
+//   - any other error is a 500 Internal Server Error detailed with its text,
+//     as DefaultErrorHandler sends it.
+func AsProblem(err error) *ProblemDetails {
+	if nilerror.IsNil(err) {
+		return NewProblem(StatusInternalServerError)
+	}
+
+	var problem *ProblemDetails
+	if errors.As(err, &problem) && problem != nil {
+		p := *problem
+		p.Status = problem.status()
+		if p.Title == "" && (p.Type == "" || p.Type == "about:blank") {
+			p.Title = utils.StatusMessage(p.Status)
+		}
+		return &p
+	}
+
+	if e, ok := asFiberError(err); ok && e != nil {
+		p := NewProblem(e.Code)
+		if e.Message != p.Title {
+			p.Detail = e.Message
+		}
+		return p
+	}
+
+	var bindErr *BindError
+	if errors.As(err, &bindErr) && bindErr != nil {
+		p := NewProblem(StatusBadRequest, bindErr.Error())
+		p.Extensions = map[string]any{"errors": bindFieldErrors(bindErr)}
+		return p
+	}
+
+	var badRequest *badRequestError
+	isBadRequest := errors.As(err, &badRequest)
+	if isBadRequest {
+		err = badRequest.err
+	}
+
+	if fields := validationFieldErrors(err); len(fields) > 0 {
+		p := NewProblem(StatusBadRequest, err.Error())
+		p.Extensions = map[string]any{"errors": fields}
+		return p
+	}
+
+	if isBadRequest {
+		return NewProblem(StatusBadRequest, err.Error())
+	}
+
+	return NewProblem(StatusInternalServerError, err.Error())
+}
+
+// bindFieldErrors lists the inputs a binding failure is about: every key of a
+// schema.MultiError, or the one field the error names.
+func bindFieldErrors(bindErr *BindError) []ProblemFieldError {
+	var multi MultiError
+	if errors.As(bindErr.Err, &multi) && len(multi) > 0 {
+		keys := make([]string, 0, len(multi))
+		for key := range multi {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := make([]ProblemFieldError, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, ProblemFieldError{Source: bindErr.Source, Field: key, Detail: multi[key].Error()})
+		}
+		return fields
+	}
+
+	return []ProblemFieldError{{Source: bindErr.Source, Field: bindErr.Field, Detail: bindErr.Err.Error()}}
+}
+
+// fieldError is the shape of a validation error about a single field, which
+// go-playground/validator's FieldError and most other validators share.
+type fieldError interface {
+	error
+	Field() string
+}
+
+// validationFieldErrors lists the field errors err is made of: the entries of
+// a slice of field errors, such as validator.ValidationErrors, or of an error
+// joining several with errors.Join. It returns nil when err is not one.
+func validationFieldErrors(err error) []ProblemFieldError {
+	for ; err != nil; err = errors.Unwrap(err) {
+		var entries []error
+		if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint // the chain is walked by the loop
+			entries = joined.Unwrap()
+		} else if rv := reflect.ValueOf(err); rv.Kind() == reflect.Slice {
+			for i := range rv.Len() {
+				if entry, ok := rv.Index(i).Interface().(error); ok {
+					entries = append(entries, entry)
+				}
+			}
+		}
+
+		var fields []ProblemFieldError
+		for _, entry := range entries {
+			var fe fieldError
+			if errors.As(entry, &fe) {
+				fields = append(fields, ProblemFieldError{Field: fe.Field(), Detail: fe.Error()})
+			}
+		}
+		if len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// ProblemErrorHandler is an ErrorHandler that renders every error as an
+// RFC 9457 problem details object, described by AsProblem. It answers in
+// application/problem+json unless the client prefers XML, which is sent as
+// application/problem+xml, or CBOR, when the app has a CBOREncoder.
+//
+//	app := fiber.New(fiber.Config{
+//		ErrorHandler: fiber.ProblemErrorHandler,
+//	})
+func ProblemErrorHandler(c Ctx, err error) error {
+	problem := AsProblem(err)
+	c.Status(problem.Status)
+
+	// The representation depends on the Accept header (RFC 9110 Section 12.5.5).
+	c.Vary(HeaderAccept)
+
+	switch c.Accepts(MIMEApplicationProblemJSON, MIMEApplicationJSON, MIMEApplicationProblemXML, MIMEApplicationXML, MIMETextXML, MIMEApplicationCBOR) {
+	case MIMEApplicationProblemXML, MIMEApplicationXML, MIMETextXML:
+		if err := c.XML(problem); err != nil {
+			return err
+		}
+		c.Set(HeaderContentType, MIMEApplicationProblemXML)
+		return nil
+	case MIMEApplicationCBOR:
+		// CBOR has no encoder unless the app configures one, and the
+		// error response must not fail for it: JSON is sent instead.
+		if err := c.CBOR(problem.members()); err == nil {
+			return nil
+		}
+	default:
+		// application/problem+json, sent as well when nothing is acceptable
+	}
+
+	return c.JSON(problem.members(), MIMEApplicationProblemJSON)
+}
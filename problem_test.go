@@ -0,0 +1,310 @@
+package fiber
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// problemFieldErr mimics the FieldError of go-playground/validator.
+type problemFieldErr struct {
+	field string
+	tag   string
+}
+
+func (e problemFieldErr) Field() string { return e.field }
+
+func (e problemFieldErr) Error() string {
+	return fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' tag", e.field, e.field, e.tag)
+}
+
+// problemValidationErrs mimics validator.ValidationErrors.
+type problemValidationErrs []problemFieldErr
+
+func (e problemValidationErrs) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func problemResponse(t *testing.T, app *App, accept string) (*http.Response, []byte) {
+	t.Helper()
+
+	req := httptest.NewRequest(MethodGet, "/", http.NoBody)
+	if accept != "" {
+		req.Header.Set(HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, body
+}
+
+func Test_ProblemErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", func(_ Ctx) error {
+		return &ProblemDetails{
+			Type:     "https://example.com/probs/out-of-credit",
+			Title:    "You do not have enough credit.",
+			Status:   StatusForbidden,
+			Detail:   "Your current balance is 30, but that costs 50.",
+			Instance: "/account/12345/msgs/abc",
+			Extensions: map[string]any{
+				"balance": 30,
+				"status":  "ignored",
+			},
+		}
+	})
+
+	resp, body := problemResponse(t, app, "")
+	require.Equal(t, StatusForbidden, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemJSON, resp.Header.Get(HeaderContentType))
+	require.Equal(t, HeaderAccept, resp.Header.Get(HeaderVary))
+	require.JSONEq(t, `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30
+	}`, string(body))
+}
+
+func Test_ProblemErrorHandler_FiberError(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", func(_ Ctx) error {
+		return NewError(StatusConflict, `user "john" already exists`)
+	})
+	app.Get("/plain", func(_ Ctx) error {
+		return ErrNotFound
+	})
+
+	resp, body := problemResponse(t, app, MIMEApplicationJSON)
+	require.Equal(t, StatusConflict, resp.StatusCode)
+	require.JSONEq(t, `{"title":"Conflict","status":409,"detail":"user \"john\" already exists"}`, string(body))
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/plain", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"title":"Not Found","status":404}`, string(body))
+}
+
+func Test_ProblemErrorHandler_UnmatchedRoute(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/missing", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemJSON, resp.Header.Get(HeaderContentType))
+}
+
+func Test_ProblemErrorHandler_GenericError(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", func(_ Ctx) error {
+		return errors.New("database unavailable")
+	})
+
+	resp, body := problemResponse(t, app, "")
+	require.Equal(t, StatusInternalServerError, resp.StatusCode)
+	require.JSONEq(t, `{"title":"Internal Server Error","status":500,"detail":"database unavailable"}`, string(body))
+}
+
+func Test_ProblemErrorHandler_BindError(t *testing.T) {
+	t.Parallel()
+
+	type query struct {
+		Page  int `query:"page"`
+		Limit int `query:"limit"`
+	}
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", func(c Ctx) error {
+		var q query
+		return c.Bind().Query(&q)
+	})
+
+	req := httptest.NewRequest(MethodGet, "/?page=first&limit=ten", http.NoBody)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+
+	var problem struct {
+		Errors []ProblemFieldError `json:"errors"`
+		Title  string              `json:"title"`
+		Status int                 `json:"status"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	require.Equal(t, StatusBadRequest, problem.Status)
+	require.Equal(t, "Bad Request", problem.Title)
+	require.Len(t, problem.Errors, 2)
+	require.Equal(t, BindSourceQuery, problem.Errors[0].Source)
+	require.Equal(t, "limit", problem.Errors[0].Field)
+	require.Equal(t, "page", problem.Errors[1].Field)
+	require.NotEmpty(t, problem.Errors[1].Detail)
+}
+
+func Test_ProblemErrorHandler_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", func(_ Ctx) error {
+		return fmt.Errorf("create user: %w", problemValidationErrs{
+			{field: "Name", tag: "required"},
+			{field: "Age", tag: "gte"},
+		})
+	})
+	app.Get("/joined", func(_ Ctx) error {
+		return errors.Join(problemFieldErr{field: "Email", tag: "email"}, errors.New("not a field error"))
+	})
+
+	resp, body := problemResponse(t, app, "")
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+
+	var problem struct {
+		Errors []ProblemFieldError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(body, &problem))
+	require.Equal(t, []ProblemFieldError{
+		{Field: "Name", Detail: "Key: 'Name' Error:Field validation for 'Name' failed on the 'required' tag"},
+		{Field: "Age", Detail: "Key: 'Age' Error:Field validation for 'Age' failed on the 'gte' tag"},
+	}, problem.Errors)
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/joined", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	require.Len(t, problem.Errors, 1)
+	require.Equal(t, "Email", problem.Errors[0].Field)
+}
+
+func Test_ProblemErrorHandler_XML(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", func(_ Ctx) error {
+		p := NewProblem(StatusUnprocessableEntity, "invalid order")
+		p.Extensions = map[string]any{
+			"errors": []ProblemFieldError{{Field: "qty", Detail: "must be positive"}},
+			"limits": map[string]int{"max": 10},
+		}
+		return p
+	})
+
+	resp, body := problemResponse(t, app, MIMEApplicationProblemXML)
+	require.Equal(t, StatusUnprocessableEntity, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemXML, resp.Header.Get(HeaderContentType))
+	require.Equal(t,
+		`<problem xmlns="urn:ietf:rfc:7807">`+
+			`<detail>invalid order</detail>`+
+			`<errors><i><field>qty</field><detail>must be positive</detail></i></errors>`+
+			`<limits><max>10</max></limits>`+
+			`<status>422</status>`+
+			`<title>Unprocessable Entity</title>`+
+			`</problem>`, string(body))
+}
+
+func Test_ProblemErrorHandler_CBOR(t *testing.T) {
+	t.Parallel()
+
+	handler := func(_ Ctx) error {
+		return NewProblem(StatusTeapot, "short and stout")
+	}
+
+	app := New(Config{ErrorHandler: ProblemErrorHandler, CBOREncoder: cbor.Marshal})
+	app.Get("/", handler)
+
+	resp, body := problemResponse(t, app, MIMEApplicationCBOR)
+	require.Equal(t, StatusTeapot, resp.StatusCode)
+	require.Equal(t, MIMEApplicationCBOR, resp.Header.Get(HeaderContentType))
+
+	var problem map[string]any
+	require.NoError(t, cbor.Unmarshal(body, &problem))
+	require.Equal(t, "short and stout", problem["detail"])
+	require.Equal(t, "I'm a teapot", problem["title"])
+
+	// Without a CBOR encoder the problem is sent as JSON
+	app = New(Config{ErrorHandler: ProblemErrorHandler})
+	app.Get("/", handler)
+
+	resp, body = problemResponse(t, app, MIMEApplicationCBOR)
+	require.Equal(t, StatusTeapot, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemJSON, resp.Header.Get(HeaderContentType))
+	require.JSONEq(t, `{"title":"I'm a teapot","status":418,"detail":"short and stout"}`, string(body))
+}
+
+func Test_DefaultErrorHandler_ProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/", func(_ Ctx) error {
+		return NewProblem(StatusPaymentRequired, "out of credit")
+	})
+
+	resp, body := problemResponse(t, app, "")
+	require.Equal(t, StatusPaymentRequired, resp.StatusCode)
+	require.Equal(t, "out of credit", string(body))
+}
+
+func Test_AsProblem(t *testing.T) {
+	t.Parallel()
+
+	shared := &ProblemDetails{Type: "https://example.com/probs/gone"}
+	p := AsProblem(fmt.Errorf("wrapped: %w", shared))
+	require.Equal(t, StatusInternalServerError, p.Status)
+	require.Empty(t, p.Title, "a typed problem keeps its own title")
+	require.Zero(t, shared.Status, "the returned problem is a copy")
+
+	p = AsProblem(&ProblemDetails{Status: StatusGone})
+	require.Equal(t, "Gone", p.Title)
+
+	p = AsProblem(nil)
+	require.Equal(t, StatusInternalServerError, p.Status)
+	require.Empty(t, p.Detail)
+}
+
+func Test_ProblemDetails_Error(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "out of credit", NewProblem(StatusForbidden, "out of credit").Error())
+	require.Equal(t, "Forbidden", NewProblem(StatusForbidden).Error())
+	require.Equal(t, "Internal Server Error", (&ProblemDetails{}).Error())
+}
+
+func Test_ProblemDetails_Marshal(t *testing.T) {
+	t.Parallel()
+
+	p := NewProblem(StatusNotFound, "no such user")
+	p.Extensions = map[string]any{"user": "john"}
+
+	out, err := json.Marshal(p)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"title":"Not Found","status":404,"detail":"no such user","user":"john"}`, string(out))
+
+	out, err = xml.Marshal(p)
+	require.NoError(t, err)
+	require.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><detail>no such user</detail><status>404</status><title>Not Found</title><user>john</user></problem>`, string(out))
+}
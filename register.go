@@ -21,6 +21,8 @@ type Register interface {
 	Add(methods []string, handler any, handlers ...any) Register
 
 	RouteChain(path string) Register
+
+	Describe(doc RouteDoc) Register
 }
 
 var _ Register = (*Registering)(nil)
@@ -129,3 +131,10 @@ func (r *Registering) RouteChain(path string) Register {
 
 	return route
 }
+
+// Describe attaches documentation to the route registered last through this
+// chain. See App.Describe.
+func (r *Registering) Describe(doc RouteDoc) Register {
+	r.app.Describe(doc)
+	return r
+}
@@ -3,6 +3,7 @@ package fiber
 import (
 	"bytes"
 	"errors"
+	"io"
 	"math"
 	"mime/multipart"
 	"net"
@@ -102,7 +103,7 @@ func (r *DefaultReq) tryDecodeBodyInOrder(
 	encodings []string,
 ) (body []byte, decodesRealized uint8, err error) {
 	request := &r.c.fasthttp.Request
-	maxBodySize := r.c.app.config.BodyLimit
+	maxBodySize := requestBodyLimit(r)
 	for idx := range encodings {
 		i := len(encodings) - 1 - idx
 		encoding := encodings[i]
@@ -148,6 +149,33 @@ func (r *DefaultReq) tryDecodeBodyInOrder(
 // Don't store direct references to the returned data.
 // If you need to keep the body's data later, make a copy or use the Immutable option.
 func (r *DefaultReq) Body() []byte {
+	body, err := r.ReadBody()
+	if err == nil {
+		return body
+	}
+
+	switch {
+	case errors.Is(err, ErrRequestEntityTooLarge):
+		// A streamed body exceeding the body limit is only reported by
+		// ReadBody, the body is empty
+		return nil
+	case errors.Is(err, ErrUnsupportedMediaType):
+		_ = r.c.DefaultRes.SendStatus(StatusUnsupportedMediaType) //nolint:errcheck,staticcheck // It is fine to ignore the error and the static check
+	case errors.Is(err, ErrNotImplemented):
+		_ = r.c.DefaultRes.SendStatus(StatusNotImplemented) //nolint:errcheck,staticcheck // It is fine to ignore the error and the static check
+	case errors.Is(err, fasthttp.ErrBodyTooLarge):
+		_ = r.c.DefaultRes.SendStatus(StatusRequestEntityTooLarge) //nolint:errcheck,staticcheck // It is fine to ignore the error and the static check
+	default:
+		// do nothing
+	}
+	return []byte(err.Error())
+}
+
+// ReadBody returns the body like Body, and the error which prevented reading
+// or decoding it, without changing the response. It returns
+// ErrRequestEntityTooLarge when a streamed body exceeds the body limit, which
+// Body only reports with an empty body.
+func (r *DefaultReq) ReadBody() ([]byte, error) {
 	var (
 		err                error
 		body, originalBody []byte
@@ -162,7 +190,7 @@ func (r *DefaultReq) Body() []byte {
 	// An empty value is still a present field line and must be joined with
 	// duplicates below before RFC 9110 empty-list elements are ignored.
 	if request.Header.ContentEncoding() == nil {
-		return r.getBody()
+		return rawBody(r)
 	}
 
 	// Multiple field lines form one list (RFC 9110 §5.2), so join before splitting.
@@ -177,9 +205,12 @@ func (r *DefaultReq) Body() []byte {
 	// headerEncoding was already lowercased wholesale above.
 	encodingOrder = getSplicedStrList(headerEncoding, encodingOrder)
 	if len(encodingOrder) == 0 {
-		return r.getBody()
+		return rawBody(r)
 	}
 
+	if err = readLimitedBodyStream(r); err != nil {
+		return nil, err
+	}
 	var decodesRealized uint8
 	body, decodesRealized, err = r.tryDecodeBodyInOrder(&originalBody, encodingOrder)
 
@@ -188,20 +219,66 @@ func (r *DefaultReq) Body() []byte {
 		request.SetBodyRaw(originalBody)
 	}
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrUnsupportedMediaType):
-			_ = r.c.DefaultRes.SendStatus(StatusUnsupportedMediaType) //nolint:errcheck,staticcheck // It is fine to ignore the error and the static check
-		case errors.Is(err, ErrNotImplemented):
-			_ = r.c.DefaultRes.SendStatus(StatusNotImplemented) //nolint:errcheck,staticcheck // It is fine to ignore the error and the static check
-		case errors.Is(err, fasthttp.ErrBodyTooLarge):
-			_ = r.c.DefaultRes.SendStatus(StatusRequestEntityTooLarge) //nolint:errcheck,staticcheck // It is fine to ignore the error and the static check
-		default:
-			// do nothing
-		}
-		return []byte(err.Error())
+		return nil, err
+	}
+
+	return r.c.app.GetBytes(body), nil
+}
+
+// BodyStream returns a reader of the raw request body, without decoding its
+// Content-Encoding. With Config.StreamRequestBody, the part of the body beyond
+// the BodyLimit is read from the connection as the reader is read, so that
+// large bodies can be piped to their destination without being buffered in
+// memory. The reader fails with ErrRequestEntityTooLarge once the limit set by
+// SetBodyLimit, or else Config.BodyLimit, is exceeded.
+// The reader is only valid within the handler, and the body can only be read
+// once.
+func (r *DefaultReq) BodyStream() io.Reader {
+	request := &r.c.fasthttp.Request
+
+	var body io.Reader
+	if stream := request.BodyStream(); stream != nil {
+		body = stream
+	} else {
+		body = bytes.NewReader(request.Body())
 	}
+	if limit := requestBodyLimit(r); limit > 0 {
+		body = &bodyLimitReader{r: body, fctx: r.c.fasthttp, n: int64(limit)}
+	}
+	return body
+}
+
+// MultipartReader returns a reader of the parts of a multipart request body,
+// read one by one from BodyStream, so that large files can be streamed to
+// their destination. The form must not have been parsed before: with
+// Config.StreamRequestBody, Config.DisablePreParseMultipartForm must be set
+// for the parts to be read from the connection.
+func (r *DefaultReq) MultipartReader() (*multipart.Reader, error) {
+	if mediatype.IsForm(r.c.fasthttp.Request.Header.ContentType()) {
+		mediatype.NormalizeRequestContentType(&r.c.fasthttp.Request.Header)
+	}
+
+	boundary := r.c.fasthttp.Request.Header.MultipartFormBoundary()
+	if len(boundary) == 0 {
+		return nil, fasthttp.ErrNoMultipartForm
+	}
+	return multipart.NewReader(r.BodyStream(), string(boundary)), nil
+}
 
-	return r.c.app.GetBytes(body)
+// SetBodyLimit sets the maximum size of the request body for the rest of the
+// handlers, in place of Config.BodyLimit. It applies to Body, BodyRaw,
+// BodyStream, MultipartForm and MultipartReader. A limit above
+// Config.BodyLimit only takes effect with Config.StreamRequestBody, as the
+// server rejects larger bodies before routing otherwise.
+func (r *DefaultReq) SetBodyLimit(limit int) {
+	r.c.bodyLimit = limit
+}
+
+// BodyLimit returns the maximum size of the request body: the limit set by
+// SetBodyLimit, or else Config.BodyLimit. Middleware reading the body, such
+// as decompress, uses it to stay within the limit of the route.
+func (r *DefaultReq) BodyLimit() int {
+	return requestBodyLimit(r)
 }
 
 // RequestCtx returns *fasthttp.RequestCtx that carries a deadline
@@ -982,7 +1059,7 @@ func (r *DefaultReq) MultipartForm() (*multipart.Form, error) {
 		mediatype.NormalizeRequestContentType(&r.c.fasthttp.Request.Header)
 	}
 
-	return r.c.fasthttp.MultipartFormWithLimit(r.c.app.config.BodyLimit)
+	return r.c.fasthttp.MultipartFormWithLimit(requestBodyLimit(r))
 }
 
 // OriginalURL contains the original request URL.
@@ -1467,5 +1544,85 @@ func (r *DefaultReq) IsFromUnixSocket() bool {
 }
 
 func (r *DefaultReq) getBody() []byte {
-	return r.c.app.GetBytes(r.c.fasthttp.Request.Body())
+	body, err := rawBody(r)
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// rawBody returns the raw body of the request, or the error which ended the
+// read of a streamed body.
+func rawBody(r *DefaultReq) ([]byte, error) {
+	if err := readLimitedBodyStream(r); err != nil {
+		return nil, err
+	}
+	return r.c.app.GetBytes(r.c.fasthttp.Request.Body()), nil
+}
+
+// readLimitedBodyStream reads a streamed body within the body limit, as
+// fasthttp reads it without any limit. The error ending the read, such as
+// ErrRequestEntityTooLarge for a body exceeding the limit, is kept for the
+// later reads, and the body left empty.
+func readLimitedBodyStream(r *DefaultReq) error {
+	if r.c.bodyErr != nil {
+		return r.c.bodyErr
+	}
+	request := &r.c.fasthttp.Request
+	if !request.IsBodyStream() {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.BodyStream())
+	if err != nil {
+		r.c.bodyErr = err
+		body = nil
+	}
+	request.SetBodyRaw(body)
+	return err
+}
+
+// requestBodyLimit returns the body limit of the request, set by SetBodyLimit
+// or else by Config.BodyLimit.
+func requestBodyLimit(r *DefaultReq) int {
+	if r.c.bodyLimit > 0 {
+		return r.c.bodyLimit
+	}
+	return r.c.app.config.BodyLimit
+}
+
+// bodyLimitReader reads a request body until it exceeds its limit, like
+// http.MaxBytesReader. The rest of a body exceeding it is not read, so the
+// connection is closed after the response.
+type bodyLimitReader struct {
+	r    io.Reader
+	err  error
+	fctx *fasthttp.RequestCtx
+	n    int64
+}
+
+func (l *bodyLimitReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// Read one byte more than the limit, to tell a body of its exact size
+	// from a larger one
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+		l.err = err
+		return n, err
+	}
+
+	n = int(l.n)
+	l.n = 0
+	l.err = ErrRequestEntityTooLarge
+	l.fctx.SetConnectionClose()
+	return n, l.err
 }
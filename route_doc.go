@@ -0,0 +1,144 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 GitHub Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"strings"
+)
+
+// RouteDoc describes a route for API documentation generators such as the
+// openapi middleware. None of it takes part in routing: a route without one is
+// still documented from its method, path and path parameters.
+type RouteDoc struct {
+	// RequestBody is a value of the type the route binds its body into, for
+	// example CreateUser{}. Only its type is inspected.
+	RequestBody any
+
+	// Responses maps the status codes the route answers with to what it sends.
+	Responses map[int]RouteResponse
+
+	// Summary is a short description of what the route does.
+	Summary string
+
+	// Description is a longer explanation of the route's behavior.
+	Description string
+
+	// OperationID identifies the route to code generators. The route name is
+	// used when it is empty.
+	OperationID string
+
+	// RequestContentType is the media type of RequestBody.
+	// Default: MIMEApplicationJSON
+	RequestContentType string
+
+	// Tags group the route with others in generated documentation.
+	Tags []string
+
+	// Deprecated marks the route as one clients should stop using.
+	Deprecated bool
+}
+
+// RouteResponse describes one response of a documented route.
+type RouteResponse struct {
+	// Body is a value of the type sent as the response body, or nil when the
+	// response has none. Only its type is inspected.
+	Body any
+
+	// Description explains when the response is sent. The status text is used
+	// when it is empty.
+	Description string
+
+	// ContentType is the media type of Body.
+	// Default: MIMEApplicationJSON
+	ContentType string
+}
+
+// RouteParam describes a parameter of a route path.
+type RouteParam struct {
+	// Name is the key the value is read back with through c.Params, which for
+	// an unnamed wildcard or plus parameter carries its position ("*1", "+2").
+	Name string
+
+	// Constraints are the ones declared in the route pattern, e.g. <int>.
+	Constraints []*Constraint
+
+	// Optional is set for parameters ending in '?' and for '*' wildcards.
+	Optional bool
+
+	// Greedy is set for '*' and '+' parameters, which can span '/'.
+	Greedy bool
+}
+
+// PathParams returns the parameters of the route path in the order they
+// appear, with the constraints declared on them in the route pattern.
+//
+//nolint:gocritic // hugeParam: app.GetRoutes returns values, so PathParams must be callable on one directly.
+func (r Route) PathParams() []RouteParam {
+	return r.pathParams(parseRoute(r.Path, nil))
+}
+
+// FormatPath rewrites the route path with every parameter replaced by what
+// format returns for it, keeping the constant parts as they were registered.
+// It is what turns "/users/:id<int>" into "/users/{id}" for a document format
+// that has its own parameter syntax.
+//
+//nolint:gocritic // hugeParam: app.GetRoutes returns values, so FormatPath must be callable on one directly.
+func (r Route) FormatPath(format func(param RouteParam) string) string {
+	parsed := parseRoute(r.Path, nil)
+	params := r.pathParams(parsed)
+
+	var sb strings.Builder
+	i := 0
+	for _, seg := range parsed.segs {
+		if !seg.IsParam {
+			sb.WriteString(seg.Const)
+			continue
+		}
+		sb.WriteString(format(params[i]))
+		i++
+	}
+
+	if sb.Len() == 0 {
+		return "/"
+	}
+
+	return sb.String()
+}
+
+// pathParams describes the parameters of raw, a parse of the path as it was
+// registered.
+//
+// The router's own parser holds the prettified path, which is lowercased when
+// routing is case-insensitive, so names and constraint arguments are read from
+// raw to keep the case they were declared with. raw was parsed without the
+// app's custom constraints, though, and drops any it names; where that leaves
+// it short of the router's, the router's constraints are reported instead.
+func (r Route) pathParams(raw routeParser) []RouteParam { //nolint:gocritic // hugeParam: see PathParams
+	var routed []*routeSegment
+	for _, seg := range r.routeParser.segs {
+		if seg.IsParam {
+			routed = append(routed, seg)
+		}
+	}
+
+	params := make([]RouteParam, 0, len(raw.params))
+	for _, seg := range raw.segs {
+		if !seg.IsParam {
+			continue
+		}
+		param := RouteParam{
+			Name:        seg.ParamName,
+			Constraints: seg.Constraints,
+			Optional:    seg.IsOptional,
+			Greedy:      seg.IsGreedy,
+		}
+		if i := len(params); i < len(routed) && len(routed[i].Constraints) > len(param.Constraints) {
+			param.Constraints = routed[i].Constraints
+		}
+		params = append(params, param)
+	}
+
+	return params
+}
@@ -44,6 +44,7 @@ type Router interface {
 	Route(prefix string, fn func(router Router), name ...string) Router
 
 	Name(name string) Router
+	Describe(doc RouteDoc) Router
 }
 
 // Route is a struct that holds all metadata for each registered handler.
@@ -85,7 +86,8 @@ type Route struct {
 	Method string `json:"method"` // HTTP method
 	Name   string `json:"name"`   // Route's name
 	//nolint:revive // Having both a Path (uppercase) and a path (lowercase) is fine
-	Path string `json:"path"` // Original registered route path
+	Path string    `json:"path"` // Original registered route path
+	Doc  *RouteDoc `json:"-"`    // Documentation attached through Describe, nil when there is none
 }
 
 var (
@@ -894,6 +896,7 @@ func (*App) copyRoute(route *Route) *Route {
 		Name:     route.Name,
 		Method:   route.Method,
 		Handlers: route.Handlers,
+		Doc:      route.Doc,
 	}
 }
 